@@ -6,6 +6,7 @@ import (
 	"github.com/iotaledger/hive.go/app/components/shutdown"
 	"github.com/iotaledger/inx-app/components/inx"
 	"github.com/iotaledger/inx-faucet/components/faucet"
+	"github.com/iotaledger/inx-faucet/components/prometheus"
 )
 
 var (
@@ -24,6 +25,7 @@ func App() *app.App {
 			faucet.Component,
 			shutdown.Component,
 			profiling.Component,
+			prometheus.Component,
 		),
 	)
 }
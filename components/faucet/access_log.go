@@ -0,0 +1,189 @@
+package faucet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// AccessLogFormat defines the format of the lines in the access log.
+type AccessLogFormat string
+
+const (
+	// AccessLogFormatCommon is the common log format of web servers.
+	AccessLogFormatCommon AccessLogFormat = "common"
+	// AccessLogFormatCombined is the common log format extended by the referer and the user agent.
+	AccessLogFormatCombined AccessLogFormat = "combined"
+	// AccessLogFormatJSON writes every request as JSON object in a single line.
+	AccessLogFormatJSON AccessLogFormat = "json"
+)
+
+const (
+	// the time layout of the common log format.
+	accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+// ParseAccessLogFormat parses the name of an access log format.
+func ParseAccessLogFormat(name string) (AccessLogFormat, error) {
+	switch format := AccessLogFormat(name); format {
+	case AccessLogFormatCommon, AccessLogFormatCombined, AccessLogFormatJSON:
+		return format, nil
+	default:
+		return "", ierrors.Errorf("unknown access log format \"%s\", expected \"%s\", \"%s\" or \"%s\"", name, AccessLogFormatCommon, AccessLogFormatCombined, AccessLogFormatJSON)
+	}
+}
+
+// accessLogEntry is a single request in the access log with the JSON format.
+type accessLogEntry struct {
+	Time       time.Time `json:"time"`
+	RemoteIP   string    `json:"remoteIp"`
+	Host       string    `json:"host"`
+	Method     string    `json:"method"`
+	URI        string    `json:"uri"`
+	Protocol   string    `json:"protocol"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	DurationMs float64   `json:"durationMs"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+}
+
+// accessLogger writes the requests of the faucet servers to the access log.
+type accessLogger struct {
+	mutex  sync.Mutex
+	format AccessLogFormat
+	writer io.Writer
+	// the file of the access log, it is nil if the log is written to stdout.
+	file *os.File
+}
+
+// accessLog is the access log of the faucet servers, it is nil if the access log is disabled.
+var accessLog *accessLogger
+
+// newAccessLogger creates the access log with the configured format in the configured file or on stdout.
+func newAccessLogger() (*accessLogger, error) {
+	format, err := ParseAccessLogFormat(ParamsFaucet.AccessLog.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := &accessLogger{
+		format: format,
+		writer: os.Stdout,
+	}
+
+	if ParamsFaucet.AccessLog.FilePath != "" {
+		if err := os.MkdirAll(filepath.Dir(ParamsFaucet.AccessLog.FilePath), 0o700); err != nil {
+			return nil, ierrors.Wrap(err, "failed to create the access log directory")
+		}
+
+		file, err := os.OpenFile(ParamsFaucet.AccessLog.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, ierrors.Wrap(err, "failed to open the access log")
+		}
+
+		logger.file = file
+		logger.writer = file
+	}
+
+	return logger, nil
+}
+
+// formatValue returns the value or "-" if it is empty, as used by the common log format.
+func formatValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+// line formats the given request in the format of the access log.
+func (l *accessLogger) line(c echo.Context, start time.Time) ([]byte, error) {
+	request := c.Request()
+	response := c.Response()
+
+	if l.format == AccessLogFormatJSON {
+		line, err := json.Marshal(&accessLogEntry{
+			Time:       start,
+			RemoteIP:   c.RealIP(),
+			Host:       request.Host,
+			Method:     request.Method,
+			URI:        request.RequestURI,
+			Protocol:   request.Proto,
+			Status:     response.Status,
+			Bytes:      response.Size,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			Referer:    request.Referer(),
+			UserAgent:  request.UserAgent(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return append(line, '\n'), nil
+	}
+
+	bytes := "-"
+	if response.Size > 0 {
+		bytes = strconv.FormatInt(response.Size, 10)
+	}
+
+	line := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s", c.RealIP(), start.Format(accessLogTimeLayout), request.Method, request.RequestURI, request.Proto, response.Status, bytes)
+	if l.format == AccessLogFormatCombined {
+		line += fmt.Sprintf(" %q %q", formatValue(request.Referer()), formatValue(request.UserAgent()))
+	}
+
+	return []byte(line + "\n"), nil
+}
+
+// middleware writes every request to the access log after it was handled.
+func (l *accessLogger) middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			if err := next(c); err != nil {
+				// the error handler writes the response, so the status is known afterwards
+				c.Error(err)
+			}
+
+			line, err := l.line(c, start)
+			if err != nil {
+				Component.LogWarnf("failed to format access log entry: %s", err)
+
+				return nil
+			}
+
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+
+			if _, err := l.writer.Write(line); err != nil {
+				Component.LogWarnf("failed to write to the access log: %s", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// Close closes the file of the access log.
+func (l *accessLogger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.file.Close()
+}
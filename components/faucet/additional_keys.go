@@ -0,0 +1,109 @@
+package faucet
+
+import (
+	"crypto/ed25519"
+	"os"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// faucetKeys are the restricted addresses of the faucet and the signer that holds their keys.
+type faucetKeys struct {
+	// the restricted address of the active faucet key.
+	address iotago.Address
+	// the restricted addresses of the additional keys the payouts are funded from.
+	sourceAddresses []iotago.Address
+	// the restricted addresses of the retired keys, their funds are swept to the faucet address.
+	retiredAddresses []iotago.Address
+	signer           iotago.AddressSigner
+}
+
+// loadAdditionalFaucetKeys loads the optional private keys of additional faucet addresses from the given environment variable.
+// It returns the restricted addresses of the keys and the keys themselves.
+func loadAdditionalFaucetKeys(name string, knownAddresses map[string]struct{}) ([]iotago.Address, []iotago.AddressKeys, error) {
+	if keys, exists := os.LookupEnv(name); !exists || keys == "" {
+		return nil, nil, nil
+	}
+
+	privateKeys, err := loadEd25519PrivateKeysFromEnvironment(name)
+	if err != nil {
+		return nil, nil, ierrors.Errorf("loading additional faucet private keys failed, err: %w", err)
+	}
+
+	addresses := make([]iotago.Address, 0, len(privateKeys))
+	addressKeys := make([]iotago.AddressKeys, 0, len(privateKeys))
+	for _, privateKey := range privateKeys {
+		if len(privateKey) != ed25519.PrivateKeySize {
+			return nil, nil, ierrors.Errorf("loading additional faucet private keys failed, err: wrong private key length in '%s'", name)
+		}
+
+		//nolint:forcetypeassert // we can safely assume that this is an ed25519.PublicKey
+		address := iotago.Ed25519AddressFromPubKey(privateKey.Public().(ed25519.PublicKey))
+		if _, exists := knownAddresses[address.Key()]; exists {
+			return nil, nil, ierrors.Errorf("loading additional faucet private keys failed, err: the key of address %s is given more than once", address)
+		}
+		knownAddresses[address.Key()] = struct{}{}
+
+		addresses = append(addresses, iotago.RestrictedAddressWithCapabilities(
+			address,
+			iotago.WithAddressCanReceiveMana(true),
+		))
+		addressKeys = append(addressKeys, iotago.NewAddressKeysForEd25519Address(address, privateKey))
+	}
+
+	return addresses, addressKeys, nil
+}
+
+// additionalKeysAddressSigner signs for the additional faucet addresses with their keys
+// and delegates all other addresses to the signer of the active key.
+type additionalKeysAddressSigner struct {
+	activeSigner     iotago.AddressSigner
+	additionalSigner iotago.AddressSigner
+	// the keys of the additional Ed25519 addresses.
+	additionalAddresses map[string]struct{}
+}
+
+var _ iotago.AddressSigner = &additionalKeysAddressSigner{}
+
+func newAdditionalKeysAddressSigner(activeSigner iotago.AddressSigner, additionalKeys []iotago.AddressKeys) *additionalKeysAddressSigner {
+	s := &additionalKeysAddressSigner{
+		activeSigner:        activeSigner,
+		additionalSigner:    iotago.NewInMemoryAddressSigner(additionalKeys...),
+		additionalAddresses: make(map[string]struct{}, len(additionalKeys)),
+	}
+
+	for _, addressKeys := range additionalKeys {
+		s.additionalAddresses[addressKeys.Address.Key()] = struct{}{}
+	}
+
+	return s
+}
+
+// signerForAddress returns the signer that holds the key of the given address.
+func (s *additionalKeysAddressSigner) signerForAddress(addr iotago.Address) iotago.AddressSigner {
+	if restrictedAddress, ok := addr.(*iotago.RestrictedAddress); ok {
+		addr = restrictedAddress.Address
+	}
+
+	if _, additional := s.additionalAddresses[addr.Key()]; additional {
+		return s.additionalSigner
+	}
+
+	return s.activeSigner
+}
+
+// SignerUIDForAddress returns the signer unique identifier for a given address.
+func (s *additionalKeysAddressSigner) SignerUIDForAddress(addr iotago.Address) (iotago.Identifier, error) {
+	return s.signerForAddress(addr).SignerUIDForAddress(addr)
+}
+
+// Sign signs the message with the key of the given address.
+func (s *additionalKeysAddressSigner) Sign(addr iotago.Address, msg []byte) (iotago.Signature, error) {
+	return s.signerForAddress(addr).Sign(addr, msg)
+}
+
+// EmptySignatureForAddress returns an empty signature for the given address.
+func (s *additionalKeysAddressSigner) EmptySignatureForAddress(addr iotago.Address) (iotago.Signature, error) {
+	return s.signerForAddress(addr).EmptySignatureForAddress(addr)
+}
@@ -0,0 +1,112 @@
+package faucet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// configuredAddressLists returns the address lists of the given configuration.
+func configuredAddressLists(allow []string, deny []string, allowBaseTokenAmount iotago.BaseToken) *faucet.AddressLists {
+	lists := &faucet.AddressLists{
+		Allow: make([]*faucet.AddressListEntry, 0, len(allow)),
+		Deny:  make([]*faucet.AddressListEntry, 0, len(deny)),
+	}
+
+	for _, address := range allow {
+		if address == "" {
+			continue
+		}
+		lists.Allow = append(lists.Allow, &faucet.AddressListEntry{Address: address, BaseTokenAmount: allowBaseTokenAmount})
+	}
+
+	for _, address := range deny {
+		if address == "" {
+			continue
+		}
+		lists.Deny = append(lists.Deny, &faucet.AddressListEntry{Address: address})
+	}
+
+	return lists
+}
+
+// loadAddressLists applies the address lists that were persisted after they were changed via the admin API.
+// if nothing was persisted, the configured lists are applied.
+func loadAddressLists(f *faucet.Faucet, configuredLists *faucet.AddressLists) error {
+	lists := configuredLists
+
+	if filePath := ParamsFaucet.AddressLists.StoragePath; filePath != "" {
+		data, err := os.ReadFile(filePath)
+		switch {
+		case err == nil:
+			persistedLists := &faucet.AddressLists{}
+			if err := json.Unmarshal(data, persistedLists); err != nil {
+				return ierrors.Wrap(err, "failed to parse the address lists file")
+			}
+			lists = persistedLists
+
+			Component.LogInfof("applying the persisted address lists from %s", filePath)
+
+		case !os.IsNotExist(err):
+			return ierrors.Wrap(err, "failed to read the address lists file")
+		}
+	}
+
+	if err := f.SetAddressLists(lists); err != nil {
+		return ierrors.Wrap(err, "invalid address lists")
+	}
+
+	return nil
+}
+
+// persistAddressLists persists the current address lists, so the changes of the admin API survive a restart.
+func persistAddressLists() {
+	if ParamsFaucet.AddressLists.StoragePath == "" {
+		return
+	}
+
+	if err := writeJSONFile(ParamsFaucet.AddressLists.StoragePath, deps.Faucet.AddressLists()); err != nil {
+		// the lists are applied anyway, but the changes are lost after a restart
+		Component.LogWarnf("failed to persist the address lists: %s", err)
+	}
+}
+
+// addAddressListEntry adds an address to the list given in the path.
+func addAddressListEntry(c echo.Context) (*faucet.AddressListEntry, error) {
+	entry := &faucet.AddressListEntry{}
+	if err := c.Bind(entry); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	list := faucet.AddressList(c.Param(ParameterAddressList))
+	if err := deps.Faucet.AddAddressListEntry(list, entry); err != nil {
+		return nil, err
+	}
+	persistAddressLists()
+
+	Component.LogInfof("added %s to the %slist of the faucet", entry.Address, list)
+
+	return entry, nil
+}
+
+// removeAddressListEntry removes the address given in the path from the list given in the path.
+func removeAddressListEntry(c echo.Context) error {
+	list := faucet.AddressList(c.Param(ParameterAddressList))
+	address := c.Param(ParameterAddress)
+
+	if !deps.Faucet.RemoveAddressListEntry(list, address) {
+		return faucet.NewRequestError(faucet.ErrorCodeNotFound, http.StatusNotFound, fmt.Sprintf("Address %s is not on the %slist.", address, list))
+	}
+	persistAddressLists()
+
+	Component.LogInfof("removed %s from the %slist of the faucet", address, list)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/skip2/go-qrcode"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+const (
+	// the default amount of pixels per module of the QR code.
+	addressQRCodeDefaultScale = 8
+	// the maximum amount of pixels per module of the QR code.
+	addressQRCodeMaxScale = 32
+)
+
+// getAddressQRCode renders the deposit address of the faucet as PNG QR code.
+// the optional "scale" query parameter defines the amount of pixels per module.
+func getAddressQRCode(c echo.Context) error {
+	scale := addressQRCodeDefaultScale
+	if scaleParam := c.QueryParam("scale"); scaleParam != "" {
+		var err error
+		scale, err = strconv.Atoi(scaleParam)
+		if err != nil || scale < 1 || scale > addressQRCodeMaxScale {
+			return faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid scale provided! It must be between 1 and %d.", addressQRCodeMaxScale))
+		}
+	}
+
+	address := deps.Faucet.Address().Bech32(deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP())
+
+	code, err := qrcode.New(address, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+
+	// a negative size renders the code with the given amount of pixels per module
+	pngData, err := code.PNG(-scale)
+	if err != nil {
+		return err
+	}
+
+	// the deposit address of the faucet never changes while it is running
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=3600")
+
+	return c.Blob(http.StatusOK, "image/png", pngData)
+}
@@ -0,0 +1,109 @@
+package faucet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// airdropFormFile is the name of the form field that contains the CSV file of a multipart upload.
+	airdropFormFile = "file"
+	// the maximum size of an uploaded CSV file.
+	airdropMaxFileSize = 8 << 20
+)
+
+// parseAirdropCSV parses the "address,amount" lines of an airdrop file.
+// the amount is optional and defaults to the configured amount, a header line and lines starting with "#" are skipped.
+// amounts with a decimal point are in token units, e.g. "10.5".
+func parseAirdropCSV(reader io.Reader, defaultAmount iotago.BaseToken, parseAmount func(string) (iotago.BaseToken, error)) ([]*faucet.AirdropPayout, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	csvReader.Comment = '#'
+
+	payouts := make([]*faucet.AirdropPayout, 0)
+	for line := 1; ; line++ {
+		record, err := csvReader.Read()
+		if ierrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid CSV file! Error: %s", err))
+		}
+
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		payout := &faucet.AirdropPayout{
+			Address:         strings.TrimSpace(record[0]),
+			BaseTokenAmount: defaultAmount,
+		}
+
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			amount, err := parseAmount(record[1])
+			if err != nil {
+				if line == 1 {
+					// header line
+					continue
+				}
+
+				return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid amount in line %d: %s", line, record[1])).WithDetail("line", line)
+			}
+			payout.BaseTokenAmount = amount
+		}
+
+		payouts = append(payouts, payout)
+		if len(payouts) > ParamsFaucet.Airdrop.MaxEntries {
+			return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Too many entries! An airdrop must not contain more than %d entries.", ParamsFaucet.Airdrop.MaxEntries)).
+				WithDetail("maxEntries", ParamsFaucet.Airdrop.MaxEntries)
+		}
+	}
+
+	return payouts, nil
+}
+
+// startAirdrop schedules the payouts of the uploaded CSV file.
+// the file is either the raw request body or the "file" field of a multipart form.
+func startAirdrop(c echo.Context) (*faucet.AirdropStatus, error) {
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, airdropMaxFileSize)
+
+	var reader io.Reader = body
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		c.Request().Body = body
+
+		fileHeader, err := c.FormFile(airdropFormFile)
+		if err != nil {
+			return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+		}
+		defer file.Close()
+
+		reader = file
+	}
+
+	payouts, err := parseAirdropCSV(reader, iotago.BaseToken(ParamsFaucet.BaseTokenAmount), deps.Faucet.ParseBaseTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := deps.Faucet.StartAirdrop(c.QueryParam("name"), c.QueryParam("tag"), payouts)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,158 @@
+package faucet
+
+import (
+	"context"
+	"time"
+
+	"github.com/iotaledger/hive.go/runtime/timeutil"
+	"github.com/iotaledger/inx-faucet/pkg/audit"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+const (
+	// the maximum amount of events whose entries wait to be written to the audit log.
+	auditLogQueueSize = 10000
+)
+
+func newAuditEntry(kind audit.EntryKind, payout *faucet.Payout) *audit.Entry {
+	enqueuedAt := payout.EnqueuedAt
+
+	return &audit.Entry{
+		Timestamp:       time.Now(),
+		Kind:            kind,
+		Address:         payout.Bech32,
+		Type:            string(payout.Type),
+		Tag:             payout.Tag,
+		RemoteIP:        payout.RemoteIP,
+		UserAgent:       payout.UserAgent,
+		Decision:        string(payout.AmountKind),
+		BaseTokenAmount: payout.BaseTokenAmount,
+		ManaAmount:      payout.ManaAmount,
+		EnqueuedAt:      &enqueuedAt,
+	}
+}
+
+func writeAuditEntries(entries []*audit.Entry) {
+	if err := deps.AuditLog.Append(entries...); err != nil {
+		Component.LogWarnf("failed to write to the audit log: %s", err)
+	}
+}
+
+// setupAuditLog records the lifecycle of all requests in the audit log and removes the expired entries.
+// Most events are triggered while the faucet holds its lock, so the entries are written by a background worker.
+func setupAuditLog() {
+	if deps.AuditLog == nil {
+		return
+	}
+
+	queue := make(chan []*audit.Entry, auditLogQueueSize)
+
+	appendAuditEntries := func(entries ...*audit.Entry) {
+		if len(entries) == 0 {
+			return
+		}
+
+		select {
+		case queue <- entries:
+		default:
+			Component.LogWarnf("failed to write %d entries to the audit log: the queue of the audit log is full", len(entries))
+		}
+	}
+
+	deps.Faucet.Events.RequestEnqueued.Hook(func(payout *faucet.Payout) {
+		appendAuditEntries(newAuditEntry(audit.EntryKindEnqueued, payout))
+	})
+
+	deps.Faucet.Events.RequestRejected.Hook(func(rejectedRequest *faucet.RejectedRequest) {
+		entry := &audit.Entry{
+			Timestamp: time.Now(),
+			Kind:      audit.EntryKindRejected,
+			Address:   rejectedRequest.Bech32,
+			Type:      string(rejectedRequest.Type),
+			Tag:       rejectedRequest.Tag,
+			ErrorCode: string(rejectedRequest.Error.Code),
+			Reason:    rejectedRequest.Error.Message,
+		}
+		if rejectedRequest.Client != nil {
+			entry.RemoteIP = rejectedRequest.Client.RemoteIP
+			entry.UserAgent = rejectedRequest.Client.UserAgent
+		}
+
+		appendAuditEntries(entry)
+	})
+
+	deps.Faucet.Events.TransactionConfirmed.Hook(func(confirmedTx *faucet.ConfirmedTransaction) {
+		entries := make([]*audit.Entry, 0, len(confirmedTx.Payouts))
+		for _, payout := range confirmedTx.Payouts {
+			entry := newAuditEntry(audit.EntryKindConfirmed, payout)
+			entry.TransactionID = confirmedTx.TransactionID.ToHex()
+			entry.BlockID = confirmedTx.BlockID.ToHex()
+			entries = append(entries, entry)
+		}
+
+		appendAuditEntries(entries...)
+	})
+
+	deps.Faucet.Events.TransactionFailed.Hook(func(failedTx *faucet.FailedTransaction) {
+		entries := make([]*audit.Entry, 0, len(failedTx.Payouts))
+		for _, payout := range failedTx.Payouts {
+			entry := newAuditEntry(audit.EntryKindFailed, payout)
+			entry.TransactionID = failedTx.TransactionID.ToHex()
+			entry.BlockID = failedTx.BlockID.ToHex()
+			if failedTx.Reason != nil {
+				entry.Reason = failedTx.Reason.Error()
+			}
+			entries = append(entries, entry)
+		}
+
+		appendAuditEntries(entries...)
+	})
+
+	// create a background worker that writes the entries, removes the expired entries and closes the audit log after the faucet was stopped
+	if err := Component.Daemon().BackgroundWorker("Faucet[AuditLog]", func(ctx context.Context) {
+		pruneAuditLog := func() {
+			removed, err := deps.AuditLog.Prune(time.Now())
+			if err != nil {
+				Component.LogWarnf("failed to prune the audit log: %s", err)
+
+				return
+			}
+
+			if removed > 0 {
+				Component.LogInfof("removed %d expired entries from the audit log", removed)
+			}
+		}
+		pruneAuditLog()
+
+		// the entries are kept forever if pruning is disabled
+		var pruneTicker <-chan time.Time
+		if ParamsFaucet.AuditLog.Retention > 0 && ParamsFaucet.AuditLog.PruneInterval > 0 {
+			ticker := time.NewTicker(ParamsFaucet.AuditLog.PruneInterval)
+			defer timeutil.CleanupTicker(ticker)
+			pruneTicker = ticker.C
+		}
+
+		for {
+			select {
+			case entries := <-queue:
+				writeAuditEntries(entries)
+			case <-ctx.Done():
+				// the faucet was stopped before, so the remaining entries are written before the audit log is closed
+				for len(queue) > 0 {
+					writeAuditEntries(<-queue)
+				}
+
+				if err := deps.AuditLog.Close(); err != nil {
+					Component.LogWarnf("failed to close the audit log: %s", err)
+				}
+
+				return
+			case <-pruneTicker:
+				pruneAuditLog()
+			}
+		}
+	}, daemon.PriorityCloseAuditLog); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
@@ -0,0 +1,133 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+// batchEnqueueRequest defines the request of a POST RouteFaucetEnqueueBatch REST API call.
+type batchEnqueueRequest struct {
+	// The bech32 addresses that receive a basic payout.
+	Addresses []string `json:"addresses,omitempty"`
+	// The requests with further options, e.g. a tag or the request type.
+	Requests []*faucet.EnqueueRequest `json:"requests,omitempty"`
+}
+
+// batchEnqueueResult is the result of a single request of a batch.
+type batchEnqueueResult struct {
+	// The bech32 address.
+	Address string `json:"address"`
+	// Whether the request was added to the queue.
+	Accepted bool `json:"accepted"`
+	// The tag that is added to the data of the faucet transaction.
+	Tag string `json:"tag,omitempty"`
+	// The error if the request was rejected.
+	Error *faucet.ErrorResponse `json:"error,omitempty"`
+}
+
+// batchEnqueueResponse defines the response of a POST RouteFaucetEnqueueBatch REST API call.
+type batchEnqueueResponse struct {
+	// The amount of requests that were added to the queue.
+	Accepted int `json:"accepted"`
+	// The amount of requests that were rejected.
+	Rejected int `json:"rejected"`
+	// The number of waiting requests in the queue.
+	WaitingRequests int `json:"waitingRequests"`
+	// The results in the order of the requests.
+	Results []*batchEnqueueResult `json:"results"`
+}
+
+// authorizeTrustedClient only allows clients with a priority API key or a valid admin JWT, e.g. to enqueue batches.
+func authorizeTrustedClient(c echo.Context, action string) error {
+	if hasPriorityAPIKey(c) {
+		return nil
+	}
+
+	if ParamsFaucet.Admin.Enabled && ParamsFaucet.Admin.JWTSecret != "" && strings.HasPrefix(c.Request().Header.Get(headerAuthorization), bearerPrefix) {
+		subject, err := verifyAdminToken(c, []byte(ParamsFaucet.Admin.JWTSecret))
+		if err != nil {
+			return err
+		}
+		Component.LogInfof("%s request by %s", action, subject)
+
+		return nil
+	}
+
+	return faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "A priority API key or an admin bearer token is required.")
+}
+
+// addFaucetOutputsToQueue enqueues the requests of a batch.
+// every request is validated on its own, so a rejected request doesn't fail the whole batch.
+func addFaucetOutputsToQueue(c echo.Context) (*batchEnqueueResponse, error) {
+	if err := authorizeTrustedClient(c, "batch enqueue"); err != nil {
+		return nil, err
+	}
+
+	batch := &batchEnqueueRequest{}
+	if err := c.Bind(batch); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	requests := make([]*faucet.EnqueueRequest, 0, len(batch.Addresses)+len(batch.Requests))
+	for _, address := range batch.Addresses {
+		requests = append(requests, &faucet.EnqueueRequest{Address: address})
+	}
+	requests = append(requests, batch.Requests...)
+
+	switch {
+	case len(requests) == 0:
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, "No addresses provided!")
+	case len(requests) > ParamsFaucet.BatchEnqueue.MaxRequests:
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Too many addresses provided! A batch must not contain more than %d addresses.", ParamsFaucet.BatchEnqueue.MaxRequests)).
+			WithDetail("maxRequests", ParamsFaucet.BatchEnqueue.MaxRequests)
+	}
+
+	clientMetadata := &faucet.ClientMetadata{
+		// the pending requests of a batch are not limited per IP
+		UserAgent:     c.Request().UserAgent(),
+		Header:        c.Request().Header,
+		Authenticated: true,
+		APIKey:        c.Request().Header.Get(headerAPIKey),
+	}
+
+	response := &batchEnqueueResponse{
+		Results: make([]*batchEnqueueResult, 0, len(requests)),
+	}
+
+	for _, request := range requests {
+		if request == nil {
+			continue
+		}
+
+		result := &batchEnqueueResult{Address: request.Address}
+		response.Results = append(response.Results, result)
+
+		enqueueResponse, err := deps.Faucet.Enqueue(request, clientMetadata)
+		if err != nil {
+			reqErr := faucet.AsRequestError(err)
+			logRequestError(c, err, reqErr)
+			result.Error = &faucet.ErrorResponse{
+				Code:    reqErr.Code,
+				Message: reqErr.Message,
+				Details: reqErr.Details,
+			}
+			response.Rejected++
+
+			continue
+		}
+
+		result.Accepted = true
+		result.Tag = enqueueResponse.Tag
+		response.Accepted++
+		response.WaitingRequests = enqueueResponse.WaitingRequests
+	}
+
+	Component.LogInfof("enqueued batch of %d requests, %d rejected", response.Accepted, response.Rejected)
+
+	return response, nil
+}
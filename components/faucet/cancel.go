@@ -0,0 +1,52 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+// cancelRequest defines the optional request body of a DELETE RouteFaucetRequest REST API call.
+type cancelRequest struct {
+	// The challenge that was signed to prove the ownership of the address.
+	OwnershipChallenge string `json:"ownershipChallenge,omitempty"`
+	// The hex encoded public key of the address.
+	PublicKey string `json:"publicKey,omitempty"`
+	// The hex encoded signature of the ownership challenge.
+	Signature string `json:"signature,omitempty"`
+}
+
+// cancelFaucetRequest removes the queued request of the address given in the path.
+// if a proof is required, the client either sends the idempotency key of the request or signs an ownership challenge.
+func cancelFaucetRequest(c echo.Context) (*faucet.CancelResponse, error) {
+	address := c.Param(ParameterAddress)
+
+	request := &cancelRequest{}
+	if err := c.Bind(request); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	authorized := !ParamsFaucet.CancelRequests.RequireProof
+	if !authorized && request.Signature != "" {
+		if deps.OwnershipVerifier == nil {
+			return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, "Ownership proofs are not supported by this faucet, use the idempotency key of the request instead.")
+		}
+
+		if err := deps.OwnershipVerifier.VerifyOwnership(address, request.OwnershipChallenge, request.PublicKey, request.Signature); err != nil {
+			return nil, err
+		}
+		authorized = true
+	}
+
+	response, err := deps.Faucet.CancelRequest(address, c.Request().Header.Get(headerIdempotencyKey), authorized)
+	if err != nil {
+		return nil, err
+	}
+
+	Component.LogInfof("canceled queued request of %s", address)
+
+	return response, nil
+}
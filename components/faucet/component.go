@@ -3,13 +3,17 @@ package faucet
 import (
 	"context"
 	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/dig"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,16 +21,14 @@ import (
 	"github.com/iotaledger/hive.go/app"
 	"github.com/iotaledger/hive.go/app/shutdown"
 	"github.com/iotaledger/hive.go/crypto"
-	"github.com/iotaledger/hive.go/ds/types"
 	"github.com/iotaledger/hive.go/ierrors"
-	"github.com/iotaledger/hive.go/lo"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 	"github.com/iotaledger/inx-app/pkg/nodebridge"
+	"github.com/iotaledger/inx-faucet/pkg/audit"
 	"github.com/iotaledger/inx-faucet/pkg/daemon"
 	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/payouts"
 	iotago "github.com/iotaledger/iota.go/v4"
-	"github.com/iotaledger/iota.go/v4/api"
-	"github.com/iotaledger/iota.go/v4/builder"
 	"github.com/iotaledger/iota.go/v4/nodeclient"
 )
 
@@ -52,19 +54,38 @@ var (
 
 type dependencies struct {
 	dig.In
-	NodeBridge      nodebridge.NodeBridge
-	Faucet          *faucet.Faucet
-	ShutdownHandler *shutdown.ShutdownHandler
+	NodeBridge        nodebridge.NodeBridge
+	Faucet            *faucet.Faucet
+	ShutdownHandler   *shutdown.ShutdownHandler
+	RedisClient       *redis.Client             `optional:"true"`
+	PoWChallenger     *faucet.PoWChallenger     `optional:"true"`
+	OwnershipVerifier *faucet.OwnershipVerifier `optional:"true"`
+	RiskScorer        *faucet.RiskScorer        `optional:"true"`
+	PayoutStore       *payouts.Store            `optional:"true"`
+	AuditLog          *audit.Log                `optional:"true"`
+	AppConfigFilePath *string                   `name:"appConfigFilePath"`
 }
 
 func provide(c *dig.Container) error {
+	// the admin API can restore snapshots, change the parameters and issue transactions, so it is never exposed without authentication
+	if ParamsFaucet.Admin.Enabled && ParamsFaucet.Admin.JWTSecret == "" {
+		Component.LogFatal("the admin API routes are enabled, but faucet.admin.jwtSecret is not set")
+	}
+
 	// we use a restricted address for the faucet, so we don't need to filter indexer requests.
 	// we only allow to receive mana, the rest is blocked.
-	faucetAddressRestricted, faucetSigner, err := getRestrictedFaucetAddressAndSigner()
+	signingKeys, err := loadFaucetKeys()
 	if err != nil {
 		Component.LogFatal(err.Error())
 	}
 
+	// the signer is tested before the faucet starts, so a wrong key is detected before any transaction is issued
+	for _, address := range append(append([]iotago.Address{signingKeys.address}, signingKeys.sourceAddresses...), signingKeys.retiredAddresses...) {
+		if err := faucet.CheckAddressSigner(signingKeys.signer, address); err != nil {
+			Component.LogFatalf("signer check failed: %s", err)
+		}
+	}
+
 	// get the block issuer client
 	type blockIssuerClientDeps struct {
 		dig.In
@@ -89,32 +110,113 @@ func provide(c *dig.Container) error {
 		Component.LogPanic(err.Error())
 	}
 
-	type faucetDeps struct {
-		dig.In
-		NodeBridge        nodebridge.NodeBridge
-		BlockIssuerClient nodeclient.BlockIssuerClient
-	}
-
-	if err := c.Provide(func(deps faucetDeps) (*faucet.Faucet, error) {
-		fetchTransactionMetadata := func(transactionID iotago.TransactionID) (*api.TransactionMetadataResponse, error) {
+	if ParamsFaucet.Redis.Enabled {
+		if err := c.Provide(func() (*redis.Client, error) {
 			ctx, cancel := context.WithTimeout(Component.Daemon().ContextStopped(), 5*time.Second)
 			defer cancel()
 
-			metadata, err := deps.NodeBridge.TransactionMetadata(ctx, transactionID)
-			if err != nil {
-				st, ok := status.FromError(err)
-				if ok && st.Code() == codes.NotFound {
-					// the block is either not found, or it was evicted
-					//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
-					return nil, nil
-				}
+			Component.LogInfo("Connecting to redis...")
+
+			client := redis.NewClient(&redis.Options{
+				Addr:        ParamsFaucet.Redis.Address,
+				Password:    ParamsFaucet.Redis.Password,
+				DB:          ParamsFaucet.Redis.Database,
+				DialTimeout: 5 * time.Second,
+			})
+			if err := client.Ping(ctx).Err(); err != nil {
+				_ = client.Close()
 
+				return nil, ierrors.Wrap(err, "failed to connect to redis")
+			}
+
+			Component.LogInfo("Connecting to redis... done!")
+
+			return client, nil
+		}); err != nil {
+			Component.LogPanic(err.Error())
+		}
+	}
+
+	if ParamsFaucet.Payouts.StoragePath != "" {
+		if err := c.Provide(func() (*payouts.Store, error) {
+			Component.LogInfo("Loading payout ledger...")
+
+			store, err := payouts.NewStore(ParamsFaucet.Payouts.StoragePath)
+			if err != nil {
 				return nil, err
 			}
 
-			return metadata, nil
+			Component.LogInfo("Loading payout ledger... done!")
+
+			return store, nil
+		}); err != nil {
+			Component.LogPanic(err.Error())
+		}
+	}
+
+	if ParamsFaucet.AuditLog.StoragePath != "" {
+		if err := c.Provide(func() (*audit.Log, error) {
+			return audit.NewLog(ParamsFaucet.AuditLog.StoragePath, ParamsFaucet.AuditLog.Retention)
+		}); err != nil {
+			Component.LogPanic(err.Error())
+		}
+	}
+
+	type challengeDeps struct {
+		dig.In
+		RedisClient *redis.Client `optional:"true"`
+	}
+
+	// the challenges are shared via redis, so they are accepted by all faucet instances
+	challengeStore := func(deps challengeDeps, name string) faucet.ChallengeStore {
+		if deps.RedisClient == nil {
+			return nil
 		}
 
+		return newRedisChallengeStore(deps.RedisClient, ParamsFaucet.Redis.KeyPrefix+":"+name)
+	}
+
+	if ParamsFaucet.PoWChallenge.Enabled || geoIPRequiresChallenge() || networkFiltersRequireChallenge() {
+		if err := c.Provide(func(deps challengeDeps) (*faucet.PoWChallenger, error) {
+			return faucet.NewPoWChallenger(ParamsFaucet.PoWChallenge.Difficulty, ParamsFaucet.PoWChallenge.TTL, faucet.SystemClock, challengeStore(deps, "powChallenge"))
+		}); err != nil {
+			Component.LogPanic(err.Error())
+		}
+	}
+
+	if ParamsFaucet.Ownership.Enabled {
+		if err := c.Provide(func(deps challengeDeps) (*faucet.OwnershipVerifier, error) {
+			return faucet.NewOwnershipVerifier(iotago.BaseToken(ParamsFaucet.BaseTokenAmountSmall), ParamsFaucet.Ownership.ChallengeTTL, faucet.SystemClock, challengeStore(deps, "ownershipChallenge"))
+		}); err != nil {
+			Component.LogPanic(err.Error())
+		}
+	}
+
+	if ParamsFaucet.RiskScoring.Enabled {
+		if err := c.Provide(func() *faucet.RiskScorer {
+			return faucet.NewRiskScorer(
+				ParamsFaucet.RiskScoring.Window,
+				ParamsFaucet.RiskScoring.DowngradeScore,
+				ParamsFaucet.RiskScoring.RejectScore,
+				iotago.BaseToken(ParamsFaucet.BaseTokenAmountSmall),
+				faucet.SystemClock,
+			)
+		}); err != nil {
+			Component.LogPanic(err.Error())
+		}
+	}
+
+	type faucetDeps struct {
+		dig.In
+		NodeBridge        nodebridge.NodeBridge
+		BlockIssuerClient nodeclient.BlockIssuerClient
+		RedisClient       *redis.Client             `optional:"true"`
+		PoWChallenger     *faucet.PoWChallenger     `optional:"true"`
+		OwnershipVerifier *faucet.OwnershipVerifier `optional:"true"`
+		RiskScorer        *faucet.RiskScorer        `optional:"true"`
+	}
+
+	if err := c.Provide(func(deps faucetDeps) (*faucet.Faucet, error) {
 		Component.LogInfo("Initializing indexer...")
 
 		ctxIndexer, cancelIndexer := context.WithTimeout(Component.Daemon().ContextStopped(), indexerPluginAvailableTimeout)
@@ -127,157 +229,192 @@ func provide(c *dig.Container) error {
 
 		Component.LogInfo("Initializing indexer... done!")
 
-		collectUnlockableFaucetOutputs := func() ([]faucet.UTXOBasicOutput, error) {
-			ctxRequest, cancelRequest := context.WithTimeout(Component.Daemon().ContextStopped(), inxRequestTimeout)
-			defer cancelRequest()
-
-			// the restricted address only returns simple outputs, which are basic outputs without timelocks,
-			// expiration, native tokens, storage deposit return unlocks conditions.
-			query := &api.BasicOutputsQuery{
-				AddressBech32: faucetAddressRestricted.Bech32(deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()),
-			}
+		nodeAdapter := &inxNodeAdapter{
+			nodeBridge:        deps.NodeBridge,
+			blockIssuerClient: deps.BlockIssuerClient,
+			indexer:           indexer,
+			faucetAddress:     signingKeys.address,
+			sourceAddresses:   signingKeys.sourceAddresses,
+			retiredAddresses:  signingKeys.retiredAddresses,
+		}
 
-			result, err := indexer.Outputs(ctxRequest, query)
-			if err != nil {
-				return nil, err
-			}
+		var sharedQueue faucet.SharedQueue
+		var leaderLease faucet.LeaderLease
+		if deps.RedisClient != nil {
+			sharedQueue = newRedisSharedQueue(deps.RedisClient, ParamsFaucet.Redis.KeyPrefix)
 
-			faucetOutputs := make([]faucet.UTXOBasicOutput, 0)
-			for result.Next() {
-				outputs, err := result.Outputs(ctxRequest)
+			if ParamsFaucet.LeaderElection.Enabled {
+				instanceID, err := newInstanceID()
 				if err != nil {
 					return nil, err
 				}
-
-				outputIDs := result.Response.Items.MustOutputIDs()
-
-				for i := range outputs {
-					basicOutput, ok := outputs[i].(*iotago.BasicOutput)
-					if !ok {
-						Component.LogWarnf("invalid type: expected *iotago.BasicOutput, got %T", outputs[i])
-
-						continue
-					}
-
-					faucetOutputs = append(faucetOutputs, faucet.UTXOBasicOutput{
-						OutputID: outputIDs[i],
-						Output:   basicOutput,
-					})
-				}
-			}
-			if result.Error != nil {
-				return nil, result.Error
+				leaderLease = newRedisLeaderLease(deps.RedisClient, ParamsFaucet.Redis.KeyPrefix, instanceID)
 			}
+		} else if ParamsFaucet.LeaderElection.Enabled {
+			return nil, ierrors.New("leader election requires redis to be enabled")
+		}
 
-			return faucetOutputs, nil
+		queueOverflowPolicy, err := faucet.ParseQueueOverflowPolicy(ParamsFaucet.Queue.OverflowPolicy)
+		if err != nil {
+			return nil, err
 		}
 
-		computeUnlockableAddressBalance := func(address iotago.Address) (iotago.BaseToken, error) {
-			ctxRequest, cancelRequest := context.WithTimeout(Component.Daemon().ContextStopped(), inxRequestTimeout)
-			defer cancelRequest()
+		inputSelectionStrategy, err := faucet.ParseInputSelectionStrategy(ParamsFaucet.InputSelection.Strategy)
+		if err != nil {
+			return nil, err
+		}
 
-			// collect all possible outputs that are owned by that address and evaluate later if they are unlockable.
-			query := &api.OutputsQuery{
-				IndexerUnlockableByAddressParams: api.IndexerUnlockableByAddressParams{
-					UnlockableByAddressBech32: address.Bech32(deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()),
-				},
-			}
+		storageDepositPolicy, err := faucet.ParseStorageDepositPolicy(ParamsFaucet.StorageDepositPolicy)
+		if err != nil {
+			return nil, err
+		}
 
-			result, err := indexer.Outputs(ctxRequest, query)
-			if err != nil {
-				return 0, err
-			}
+		if ParamsFaucet.RequestableAmount.Max > 0 && ParamsFaucet.RequestableAmount.Min > ParamsFaucet.RequestableAmount.Max {
+			return nil, ierrors.New("the minimum requestable amount must not be greater than the maximum requestable amount")
+		}
 
-			var unlockableBalance iotago.BaseToken
-			for result.Next() {
-				outputs, err := result.Outputs(ctxRequest)
-				if err != nil {
-					return 0, err
-				}
+		if ParamsFaucet.BaseTokenAmountJitter < 0 || ParamsFaucet.BaseTokenAmountJitter >= 1 {
+			return nil, ierrors.New("the base token amount jitter must be at least 0 and less than 1")
+		}
 
-				for i := range outputs {
-					output := outputs[i]
-
-					if output.UnlockConditionSet().HasStorageDepositReturnCondition() && output.UnlockConditionSet().StorageDepositReturn().ReturnAddress.Equal(address) {
-						// we don't care about addresses in the storage deposit return unlock conditions
-						continue
-					}
-
-					lastAcceptedBlockSlot := iotago.SlotIndex(deps.NodeBridge.NodeStatus().GetLastAcceptedBlockSlot())
-					if output.UnlockConditionSet().HasTimelockUntil(lastAcceptedBlockSlot) {
-						// ignore timelocked outputs for balance calculation
-						continue
-					}
-
-					//nolint:godox
-					// TODO: what are the correct bounds here?
-					maxFutureBoundedSlotIndex := lastAcceptedBlockSlot + deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().MinCommittableAge()
-					minPastBoundedSlotIndex := lastAcceptedBlockSlot + deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().MaxCommittableAge()
-
-					actualIdentToUnlock, err := output.UnlockConditionSet().CheckExpirationCondition(maxFutureBoundedSlotIndex, minPastBoundedSlotIndex)
-					if err != nil {
-						// this means the output has an unlock condition and it is currently in the blocked range around the expiration slot.
-						// => add the balance to the expiration return address, because it will belong to this address after the blocked range.
-						if !output.UnlockConditionSet().Expiration().ReturnAddress.Equal(address) {
-							// the output belongs to the expiration return address, but this is not the address in the request
-							continue
-						}
-					} else if actualIdentToUnlock != nil && !actualIdentToUnlock.Equal(address) {
-						// the output belongs to the expiration return address, but this is not the address in the request
-						continue
-					}
-
-					unlockableBalance += outputs[i].BaseTokenAmount()
-				}
-			}
-			if result.Error != nil {
-				return 0, result.Error
-			}
+		explorerURL, err := faucet.ParseExplorerURL(ParamsFaucet.ExplorerURL)
+		if err != nil {
+			return nil, err
+		}
 
-			return unlockableBalance, nil
+		burnAddresses, err := parseBurnAddresses(ParamsFaucet.TargetAddresses.Burn, deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP())
+		if err != nil {
+			return nil, err
 		}
 
-		getLatestSlot := func() iotago.SlotIndex {
-			return iotago.SlotIndex(deps.NodeBridge.NodeStatus().GetLastAcceptedBlockSlot())
+		var requestValidators []faucet.RequestValidator
+
+		// the challenger of the policies is only needed if not all requests require a solved challenge anyway
+		policyChallenger := deps.PoWChallenger
+		if ParamsFaucet.PoWChallenge.Enabled {
+			requestValidators = append(requestValidators, deps.PoWChallenger)
+			policyChallenger = nil
 		}
 
-		submitTransactionPayload := func(ctx context.Context, builder *builder.TransactionBuilder, storedManaOutputIndex int, numPoWWorkers ...int) (iotago.ApplicationPayload, iotago.BlockID, error) {
-			Component.LogDebug("sending transaction payload...")
-			signedTx, blockCreatedResponse, err := deps.BlockIssuerClient.SendPayloadWithTransactionBuilder(ctx, builder, storedManaOutputIndex, numPoWWorkers...)
+		if ParamsFaucet.GeoIP.Enabled {
+			geoIPPolicy, err := newGeoIPPolicy(iotago.BaseToken(ParamsFaucet.BaseTokenAmountSmall), policyChallenger)
 			if err != nil {
-				return nil, iotago.EmptyBlockID, err
+				return nil, err
 			}
-			//nolint:forcetypeassert // we can safely assume that this is a SignedTransaction
-			Component.LogDebugf("sent transaction payload, blockID: %s, txID: %s", blockCreatedResponse.BlockID, lo.Return1(signedTx.(*iotago.SignedTransaction).ID()))
+			// the policy is checked first, so blocked requests don't consume a challenge
+			requestValidators = append([]faucet.RequestValidator{geoIPPolicy}, requestValidators...)
+		}
 
-			return signedTx, blockCreatedResponse.BlockID, nil
+		networkFilterPolicy, err := newNetworkFilterPolicy(iotago.BaseToken(ParamsFaucet.BaseTokenAmountSmall), policyChallenger)
+		if err != nil {
+			return nil, err
+		}
+		if networkFilterPolicy != nil {
+			requestValidators = append([]faucet.RequestValidator{networkFilterPolicy}, requestValidators...)
+		}
+		if deps.OwnershipVerifier != nil {
+			requestValidators = append(requestValidators, deps.OwnershipVerifier)
+		}
+		if deps.RiskScorer != nil {
+			// the risk scorer is the last validator, so requests that are rejected by others don't count as risky
+			requestValidators = append(requestValidators, deps.RiskScorer)
 		}
 
 		Component.LogInfo("Initializing faucet...")
 
 		faucet := faucet.New(
 			Component.Daemon(),
-			deps.NodeBridge.IsNodeHealthy,
-			fetchTransactionMetadata,
-			collectUnlockableFaucetOutputs,
-			computeUnlockableAddressBalance,
-			getLatestSlot,
-			submitTransactionPayload,
+			nodeAdapter,
 			deps.NodeBridge.APIProvider(),
-			faucetAddressRestricted,
-			faucetSigner,
+			signingKeys.address,
+			signingKeys.signer,
 			faucet.WithLogger(Component.Logger),
 			faucet.WithTokenName(deps.NodeBridge.NodeConfig().GetBaseToken().GetName()),
+			faucet.WithTokenDecimals(deps.NodeBridge.NodeConfig().GetBaseToken().GetDecimals()),
 			faucet.WithBaseTokenAmount(iotago.BaseToken(ParamsFaucet.BaseTokenAmount)),
 			faucet.WithBaseTokenAmountSmall(iotago.BaseToken(ParamsFaucet.BaseTokenAmountSmall)),
 			faucet.WithBaseTokenAmountMaxTarget(iotago.BaseToken(ParamsFaucet.BaseTokenAmountMaxTarget)),
+			faucet.WithRequestableAmountRange(iotago.BaseToken(ParamsFaucet.RequestableAmount.Min), iotago.BaseToken(ParamsFaucet.RequestableAmount.Max)),
+			faucet.WithBaseTokenAmountJitter(ParamsFaucet.BaseTokenAmountJitter),
 			faucet.WithManaAmount(iotago.Mana(ParamsFaucet.ManaAmount)),
 			faucet.WithManaAmountMinFaucet(iotago.Mana(ParamsFaucet.ManaAmountMinFaucet)),
+			faucet.WithTargetAddressTypes(targetAddressTypes()...),
+			faucet.WithBurnAddresses(burnAddresses...),
+			faucet.WithManaAmountMaxTarget(iotago.Mana(ParamsFaucet.ManaAmountMaxTarget), ParamsFaucet.ManaOnlyPayouts),
+			faucet.WithStorageDepositPolicy(storageDepositPolicy),
+			faucet.WithManaClaimInterval(ParamsFaucet.ManaClaim.Interval),
+			faucet.WithManaClaimMinPotentialMana(iotago.Mana(ParamsFaucet.ManaClaim.MinPotentialMana)),
 			faucet.WithTagMessage(ParamsFaucet.TagMessage),
+			faucet.WithTransactionTag(faucet.TransactionTypePayout, ParamsFaucet.TransactionTags.Payout),
+			faucet.WithTransactionTag(faucet.TransactionTypeAirdrop, ParamsFaucet.TransactionTags.Airdrop),
+			faucet.WithTransactionTag(faucet.TransactionTypeManaTopUp, ParamsFaucet.TransactionTags.ManaTopUp),
+			faucet.WithTransactionTag(faucet.TransactionTypeSweep, ParamsFaucet.TransactionTags.Sweep),
+			faucet.WithTransactionTag(faucet.TransactionTypeDelegation, ParamsFaucet.TransactionTags.Delegation),
+			faucet.WithRequestTagMaxLength(ParamsFaucet.RequestTagMaxLength),
+			faucet.WithExplorerURL(explorerURL),
 			faucet.WithBatchTimeout(ParamsFaucet.BatchTimeout),
+			faucet.WithBatchTimeoutMin(ParamsFaucet.BatchTimeoutMin),
+			faucet.WithBatchMaxSize(ParamsFaucet.BatchMaxSize),
+			faucet.WithMaxBlockReattachments(ParamsFaucet.MaxBlockReattachments),
+			faucet.WithMaxReferenceManaCost(iotago.Mana(ParamsFaucet.MaxReferenceManaCost)),
+			faucet.WithMaxTransactionsPerMinute(ParamsFaucet.MaxTransactionsPerMinute),
+			faucet.WithBalanceCacheTTL(ParamsFaucet.BalanceCacheTTL),
+			faucet.WithSourceAddresses(signingKeys.sourceAddresses...),
+			faucet.WithRetiredAddresses(signingKeys.retiredAddresses...),
+			faucet.WithSignerCheckInterval(ParamsFaucet.Signer.CheckInterval),
+			faucet.WithBalanceCheckRateLimit(ParamsFaucet.BalanceCheck.MaxPerSecond, ParamsFaucet.BalanceCheck.MaxConcurrent),
+			faucet.WithMaxPendingRequestsPerIP(ParamsFaucet.MaxPendingRequestsPerIP),
+			faucet.WithQueueSize(ParamsFaucet.Queue.Size),
+			faucet.WithQueueOverflowPolicy(queueOverflowPolicy, ParamsFaucet.Queue.BlockTimeout),
+			faucet.WithAirdropBatchSize(ParamsFaucet.Airdrop.BatchSize),
+			faucet.WithDonationsMaxCount(ParamsFaucet.Donations.MaxCount),
+			faucet.WithInputSelection(inputSelectionStrategy, ParamsFaucet.InputSelection.MaxInputs),
+			faucet.WithDelegationAmount(iotago.BaseToken(ParamsFaucet.Delegation.Amount)),
+			faucet.WithAllotmentManaAmount(iotago.Mana(ParamsFaucet.Allotment.ManaAmount)),
+			faucet.WithDailyBudget(faucet.Budget{
+				BaseTokens: iotago.BaseToken(ParamsFaucet.Budget.Daily.BaseTokenAmount),
+				Mana:       iotago.Mana(ParamsFaucet.Budget.Daily.ManaAmount),
+			}),
+			faucet.WithEpochBudget(faucet.Budget{
+				BaseTokens: iotago.BaseToken(ParamsFaucet.Budget.Epoch.BaseTokenAmount),
+				Mana:       iotago.Mana(ParamsFaucet.Budget.Epoch.ManaAmount),
+			}),
+			faucet.WithSubmitErrorClassifier(classifySubmitError),
+			faucet.WithSubmitRetryPolicy(faucet.SubmitErrorClassUnavailable, &faucet.RetryPolicy{
+				MaxRetries:     ParamsFaucet.SubmitRetry.Unavailable.MaxRetries,
+				InitialBackoff: ParamsFaucet.SubmitRetry.Unavailable.InitialBackoff,
+				MaxBackoff:     ParamsFaucet.SubmitRetry.Unavailable.MaxBackoff,
+				Jitter:         ParamsFaucet.SubmitRetry.Unavailable.Jitter,
+			}),
 			faucet.WithPoWWorkerCount(ParamsFaucet.PoW.WorkerCount),
+			faucet.WithSharedQueue(sharedQueue),
+			faucet.WithIssuanceEnabled(ParamsFaucet.IssueTransactions),
+			faucet.WithLeaderLease(leaderLease, ParamsFaucet.LeaderElection.LeaseDuration),
+			faucet.WithShutdownTimeout(ParamsFaucet.Shutdown.PendingTransactionTimeout),
+			faucet.WithQueueFilePath(ParamsFaucet.Shutdown.QueueFilePath),
+			faucet.WithRequestValidators(requestValidators...),
 		)
 
+		if err := loadRuntimeParameters(faucet); err != nil {
+			return nil, err
+		}
+
+		if err := loadAddressLists(faucet, configuredAddressLists(
+			ParamsFaucet.AddressLists.Allow,
+			ParamsFaucet.AddressLists.Deny,
+			iotago.BaseToken(ParamsFaucet.AddressLists.AllowBaseTokenAmount),
+		)); err != nil {
+			return nil, err
+		}
+
+		if err := loadShadowBanRules(faucet); err != nil {
+			return nil, err
+		}
+
+		if err := loadSubscriptions(faucet); err != nil {
+			return nil, err
+		}
+
 		Component.LogInfo("Initializing faucet... done!")
 
 		return faucet, nil
@@ -289,57 +426,281 @@ func provide(c *dig.Container) error {
 }
 
 func run() error {
-	// create a background worker that handles the accepted transactions
-	if err := Component.Daemon().BackgroundWorker("Faucet[ListenToAcceptedTransactions]", func(ctx context.Context) {
-		if err := deps.NodeBridge.ListenToAcceptedTransactions(ctx, func(tx *nodebridge.AcceptedTransaction) error {
-			// create maps for faster lookup.
-			// outputs that are created and consumed in the same update exist in both maps.
-			createdOutputs := make(map[iotago.OutputID]struct{})
-			for _, output := range tx.Created {
-				createdOutputs[output.OutputID] = types.Void
+	runAcceptedTransactionsListener()
+
+	setupPayoutLedger()
+	setupAuditLog()
+	runClientMetadataAnonymization()
+	setupRecentPayoutsFeed()
+	setupWebhooks()
+	runNetworkFilters()
+	runSubscriptions()
+	runRollbackDetection()
+
+	// create a background worker that handles the enqueued faucet requests
+	if err := Component.Daemon().BackgroundWorker("Faucet", func(ctx context.Context) {
+		if err := deps.Faucet.RunFaucetLoop(ctx); err != nil && faucet.IsCriticalError(err) != nil {
+			deps.ShutdownHandler.SelfShutdown(fmt.Sprintf("faucet plugin hit a critical error: %s", err.Error()), true)
+		}
+	}, daemon.PriorityStopFaucet); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+
+	if deps.RedisClient != nil {
+		if ParamsFaucet.IssueTransactions {
+			// create a background worker that moves the requests from the shared queue to the faucet
+			if err := Component.Daemon().BackgroundWorker("Faucet[SharedQueue]", func(ctx context.Context) {
+				if err := deps.Faucet.RunSharedQueueConsumer(ctx); err != nil {
+					deps.ShutdownHandler.SelfShutdown(fmt.Sprintf("faucet shared queue hit a critical error: %s", err.Error()), true)
+				}
+			}, daemon.PriorityStopFaucetSharedQueue); err != nil {
+				Component.LogPanicf("failed to start worker: %s", err)
 			}
-			consumedOutputs := make(map[iotago.OutputID]struct{})
-			for _, output := range tx.Consumed {
-				consumedOutputs[output.OutputID] = types.Void
+		}
+
+		if ParamsFaucet.IssueTransactions && ParamsFaucet.LeaderElection.Enabled {
+			// create a background worker that acquires and renews the leader lease
+			if err := Component.Daemon().BackgroundWorker("Faucet[LeaderElection]", func(ctx context.Context) {
+				if err := deps.Faucet.RunLeaderElection(ctx); err != nil {
+					deps.ShutdownHandler.SelfShutdown(fmt.Sprintf("faucet leader election hit a critical error: %s", err.Error()), true)
+				}
+			}, daemon.PriorityStopFaucetLeaderElection); err != nil {
+				Component.LogPanicf("failed to start worker: %s", err)
 			}
+		}
 
-			deps.Faucet.ApplyAcceptedTransaction(createdOutputs, consumedOutputs)
+		if err := Component.Daemon().BackgroundWorker("Faucet[Redis]", func(ctx context.Context) {
+			<-ctx.Done()
 
-			return nil
-		}); err != nil {
-			deps.ShutdownHandler.SelfShutdown(fmt.Sprintf("Listening to AcceptedTransactions failed, error: %s", err), false)
+			if err := deps.RedisClient.Close(); err != nil {
+				Component.LogWarnf("failed to close redis client: %s", err)
+			}
+		}, daemon.PriorityCloseRedis); err != nil {
+			Component.LogPanicf("failed to start worker: %s", err)
 		}
-	}, daemon.PriorityStopFaucetAcceptedTransactions); err != nil {
+	}
+
+	// create a background worker that reloads the parameters on SIGHUP
+	if err := Component.Daemon().BackgroundWorker("Faucet[Reload]", runReloadOnSignal, daemon.PriorityStopFaucetReload); err != nil {
 		Component.LogPanicf("failed to start worker: %s", err)
 	}
 
-	// create a background worker that handles the enqueued faucet requests
-	if err := Component.Daemon().BackgroundWorker("Faucet", func(ctx context.Context) {
-		if err := deps.Faucet.RunFaucetLoop(ctx); err != nil && faucet.IsCriticalError(err) != nil {
-			deps.ShutdownHandler.SelfShutdown(fmt.Sprintf("faucet plugin hit a critical error: %s", err.Error()), true)
+	var err error
+	if ParamsFaucet.TLS.CertPath != "" || ParamsFaucet.TLS.KeyPath != "" {
+		if certificates, err = newCertificateReloader(ParamsFaucet.TLS.CertPath, ParamsFaucet.TLS.KeyPath); err != nil {
+			return err
 		}
-	}, daemon.PriorityStopFaucet); err != nil {
+	}
+
+	if ipExtractor, err = newIPExtractor(); err != nil {
+		return err
+	}
+
+	if ParamsFaucet.Compression.Enabled {
+		if compression, err = newCompressionMiddleware(ParamsFaucet.Compression.Level, ParamsFaucet.Compression.MinLength); err != nil {
+			return err
+		}
+	}
+
+	if ParamsFaucet.AccessLog.Enabled {
+		if accessLog, err = newAccessLogger(); err != nil {
+			return err
+		}
+	}
+
+	e := newEcho()
+	if err := setupRoutes(e); err != nil {
+		return err
+	}
+
+	servers := map[string]*echo.Echo{
+		ParamsFaucet.BindAddress: e,
+	}
+
+	if ParamsFaucet.Frontend.Enabled {
+		if frontendBindAddress() == ParamsFaucet.BindAddress {
+			// the website is served together with the API
+			setupFrontendRoutes(e)
+		} else {
+			eFrontend := newEcho()
+			setupFrontendRoutes(eFrontend)
+			servers[frontendBindAddress()] = eFrontend
+		}
+	}
+
+	for bindAddress, server := range servers {
+		if err := configureServer(server, bindAddress); err != nil {
+			return ierrors.Wrapf(err, "failed to configure the faucet server on %s", bindAddress)
+		}
+	}
+
+	// create a background worker that serves the faucet website and API.
+	// it is stopped first on shutdown, so no new requests are accepted while the queue is persisted.
+	if err := Component.Daemon().BackgroundWorker("Faucet[API]", func(ctx context.Context) {
+		if ParamsFaucet.Frontend.Enabled {
+			Component.LogInfof("You can now access the faucet website using: %s://%s%s/", serverScheme(), frontendBindAddress(), basePath())
+		}
+		Component.LogInfof("You can now access the faucet API using: %s://%s%s/api", serverScheme(), ParamsFaucet.BindAddress, basePath())
+		Component.LogInfof("The deposit address of the faucet is %s", deps.Faucet.Address().Bech32(deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()))
+
+		for bindAddress, server := range servers {
+			go func() {
+				if err := server.Start(bindAddress); err != nil && !ierrors.Is(err, http.ErrServerClosed) {
+					Component.LogWarnf("Stopped faucet server on %s due to an error (%s)", bindAddress, err)
+				}
+			}()
+		}
+
+		// rotated certificates are loaded while the servers are running
+		waitAndReloadCertificates(ctx)
+
+		ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+
+		for bindAddress, server := range servers {
+			if err := server.Shutdown(ctxShutdown); err != nil {
+				Component.LogWarnf("Failed to stop faucet server on %s (%s)", bindAddress, err)
+			}
+		}
+
+		if accessLog != nil {
+			if err := accessLog.Close(); err != nil {
+				Component.LogWarnf("failed to close the access log: %s", err)
+			}
+		}
+	}, daemon.PriorityStopFaucetAPI); err != nil {
 		Component.LogPanicf("failed to start worker: %s", err)
 	}
 
+	return nil
+}
+
+// newEcho creates a new echo instance with the common configuration of the faucet servers.
+func newEcho() *echo.Echo {
 	e := httpserver.NewEcho(Component.Logger, nil, ParamsFaucet.DebugRequestLoggerEnabled)
+	e.HTTPErrorHandler = errorHandler
+	// the client IP is used for rate limiting, the audit log and the abuse detection
+	e.IPExtractor = ipExtractor
+	if accessLog != nil {
+		e.Use(accessLog.middleware())
+	}
+	if ParamsFaucet.Server.MaxBodySize != "" {
+		e.Use(middleware.BodyLimit(ParamsFaucet.Server.MaxBodySize))
+	}
+	if compression != nil {
+		e.Use(compression)
+	}
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost},
+		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
 	}))
 
-	setupRoutes(e)
+	return e
+}
 
-	go func() {
-		Component.LogInfof("You can now access the faucet website using: http://%s", ParamsFaucet.BindAddress)
-		Component.LogInfof("The deposit address of the faucet is %s", deps.Faucet.Address().Bech32(deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()))
+// basePath returns the normalized path prefix the faucet API and website are served under.
+// it starts with a slash and has no trailing slash, or it is empty if they are served at the root.
+func basePath() string {
+	prefix := strings.Trim(strings.TrimSpace(ParamsFaucet.BasePath), "/")
+	if prefix == "" {
+		return ""
+	}
 
-		if err := e.Start(ParamsFaucet.BindAddress); err != nil && !ierrors.Is(err, http.ErrServerClosed) {
-			Component.LogWarnf("Stopped faucet website server due to an error (%s)", err)
+	return "/" + prefix
+}
+
+// frontendBindAddress returns the bind address on which the faucet website is served.
+func frontendBindAddress() string {
+	if ParamsFaucet.Frontend.BindAddress != "" {
+		return ParamsFaucet.Frontend.BindAddress
+	}
+
+	return ParamsFaucet.BindAddress
+}
+
+// classifySubmitError classifies the errors returned by the block issuer.
+func classifySubmitError(err error) faucet.SubmitErrorClass {
+	switch {
+	case ierrors.Is(err, nodeclient.ErrHTTPBadRequest):
+		return faucet.SubmitErrorClassRejected
+	case ierrors.Is(err, nodeclient.ErrHTTPInternalServerError):
+		// the block issuer may have failed after the block was issued
+		return faucet.SubmitErrorClassUnknown
+	}
+
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.DeadlineExceeded:
+			return faucet.SubmitErrorClassTimeout
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+			return faucet.SubmitErrorClassUnavailable
+		case codes.InvalidArgument, codes.FailedPrecondition, codes.AlreadyExists:
+			return faucet.SubmitErrorClassRejected
 		}
-	}()
+	}
 
-	return nil
+	return faucet.DefaultSubmitErrorClassifier(err)
+}
+
+// targetAddressTypes returns the address types that are accepted as targets of basic requests.
+func targetAddressTypes() []iotago.AddressType {
+	toggles := []struct {
+		enabled     bool
+		addressType iotago.AddressType
+	}{
+		{ParamsFaucet.TargetAddresses.Ed25519, iotago.AddressEd25519},
+		{ParamsFaucet.TargetAddresses.ImplicitAccountCreation, iotago.AddressImplicitAccountCreation},
+		{ParamsFaucet.TargetAddresses.Account, iotago.AddressAccount},
+		{ParamsFaucet.TargetAddresses.NFT, iotago.AddressNFT},
+		{ParamsFaucet.TargetAddresses.Anchor, iotago.AddressAnchor},
+	}
+
+	addressTypes := make([]iotago.AddressType, 0, len(toggles))
+	for _, toggle := range toggles {
+		if toggle.enabled {
+			addressTypes = append(addressTypes, toggle.addressType)
+		}
+	}
+
+	return addressTypes
+}
+
+// parseBurnAddresses parses the configured burn addresses, they must belong to the network of the faucet.
+func parseBurnAddresses(bech32Addrs []string, networkPrefix iotago.NetworkPrefix) ([]iotago.Address, error) {
+	burnAddresses := make([]iotago.Address, 0, len(bech32Addrs))
+	for _, bech32Addr := range bech32Addrs {
+		if bech32Addr == "" {
+			continue
+		}
+
+		hrp, addr, err := iotago.ParseBech32(bech32Addr)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "invalid burn address \"%s\"", bech32Addr)
+		}
+
+		if hrp != networkPrefix {
+			return nil, ierrors.Errorf("invalid burn address \"%s\": it doesn't belong to network \"%s\"", bech32Addr, networkPrefix)
+		}
+
+		burnAddresses = append(burnAddresses, addr)
+	}
+
+	return burnAddresses, nil
+}
+
+// newInstanceID creates a unique ID for this faucet instance that is used for the leader lease.
+func newInstanceID() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", ierrors.Wrap(err, "failed to get hostname")
+	}
+
+	randomBytes := make([]byte, 8)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", ierrors.Wrap(err, "failed to create random instance ID")
+	}
+
+	return hostname + "-" + hex.EncodeToString(randomBytes), nil
 }
 
 // loadEd25519PrivateKeysFromEnvironment loads ed25519 private keys from the given environment variable.
@@ -366,7 +727,43 @@ func loadEd25519PrivateKeysFromEnvironment(name string) ([]ed25519.PrivateKey, e
 	return privateKeys, nil
 }
 
-func getRestrictedFaucetAddressAndSigner() (iotago.Address, iotago.AddressSigner, error) {
+// loadFaucetKeys loads the key of the faucet and the optional source and retired keys.
+// It returns the restricted addresses of the keys and a signer that holds the keys of all of them.
+func loadFaucetKeys() (*faucetKeys, error) {
+	faucetAddress, faucetSigner, err := loadFaucetAddressAndSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	knownAddresses := map[string]struct{}{faucetAddress.Key(): {}}
+
+	sourceAddresses, sourceKeys, err := loadAdditionalFaucetKeys("FAUCET_SOURCE_PRV_KEYS", knownAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	retiredAddresses, retiredKeys, err := loadAdditionalFaucetKeys("FAUCET_RETIRED_PRV_KEYS", knownAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	if additionalKeys := append(sourceKeys, retiredKeys...); len(additionalKeys) > 0 {
+		faucetSigner = newAdditionalKeysAddressSigner(faucetSigner, additionalKeys)
+	}
+
+	return &faucetKeys{
+		address: iotago.RestrictedAddressWithCapabilities(
+			faucetAddress,
+			iotago.WithAddressCanReceiveMana(true),
+		),
+		sourceAddresses:  sourceAddresses,
+		retiredAddresses: retiredAddresses,
+		signer:           faucetSigner,
+	}, nil
+}
+
+// loadLocalFaucetAddressAndSigner loads the private key of the faucet from the environment.
+func loadLocalFaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
 	privateKeys, err := loadEd25519PrivateKeysFromEnvironment("FAUCET_PRV_KEY")
 	if err != nil {
 		return nil, nil, ierrors.Errorf("loading faucet private key failed, err: %w", err)
@@ -393,10 +790,5 @@ func getRestrictedFaucetAddressAndSigner() (iotago.Address, iotago.AddressSigner
 	faucetAddress := iotago.Ed25519AddressFromPubKey(publicKey)
 	faucetSigner := iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(faucetAddress, privateKey))
 
-	faucetAddressRestricted := iotago.RestrictedAddressWithCapabilities(
-		faucetAddress,
-		iotago.WithAddressCanReceiveMana(true),
-	)
-
-	return faucetAddressRestricted, faucetSigner, nil
+	return faucetAddress, faucetSigner, nil
 }
@@ -0,0 +1,44 @@
+package faucet
+
+import (
+	"compress/gzip"
+	"path"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// compression compresses the responses of the faucet servers, it is nil if the compression is disabled.
+var compression echo.MiddlewareFunc
+
+// isCompressedAsset returns whether the file with the given path is already compressed,
+// so compressing it again only costs CPU time.
+func isCompressedAsset(filePath string) bool {
+	switch path.Ext(filePath) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".woff", ".woff2", ".gz", ".zip":
+		return true
+	default:
+		return false
+	}
+}
+
+// newCompressionMiddleware creates the middleware that compresses the responses with gzip if the client supports it.
+// Responses smaller than the minimum length and already compressed website assets are sent uncompressed.
+func newCompressionMiddleware(level int, minLength int) (echo.MiddlewareFunc, error) {
+	if level != gzip.DefaultCompression && (level < gzip.BestSpeed || level > gzip.BestCompression) {
+		return nil, ierrors.Errorf("invalid compression level %d, expected -1 or a value between %d and %d", level, gzip.BestSpeed, gzip.BestCompression)
+	}
+	if minLength < 0 {
+		return nil, ierrors.Errorf("invalid minimum length %d for compressed responses", minLength)
+	}
+
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		Skipper: func(c echo.Context) bool {
+			return isCompressedAsset(c.Request().URL.Path)
+		},
+		Level:     level,
+		MinLength: minLength,
+	}), nil
+}
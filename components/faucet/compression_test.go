@@ -0,0 +1,91 @@
+package faucet
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionTestServer(t *testing.T, minLength int) *echo.Echo {
+	t.Helper()
+
+	compressionMiddleware, err := newCompressionMiddleware(-1, minLength)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(compressionMiddleware)
+	e.GET("/api/info", func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("faucet", 100))
+	})
+	e.GET("/api/small", func(c echo.Context) error {
+		return c.String(http.StatusOK, "faucet")
+	})
+	e.GET("/logo.png", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "image/png", []byte(strings.Repeat("png", 100)))
+	})
+
+	return e
+}
+
+func serveCompressionTestRequest(e *echo.Echo, path string, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	e := newCompressionTestServer(t, 64)
+
+	rec := serveCompressionTestRequest(e, "/api/info", "gzip, deflate, br")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "gzip", rec.Header().Get(echo.HeaderContentEncoding))
+	require.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderAcceptEncoding)
+
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("faucet", 100), string(body))
+}
+
+func TestCompressionMiddlewareUncompressed(t *testing.T) {
+	e := newCompressionTestServer(t, 64)
+
+	for name, test := range map[string]struct {
+		path           string
+		acceptEncoding string
+		body           string
+	}{
+		"no accepted encoding": {path: "/api/info", body: strings.Repeat("faucet", 100)},
+		"only deflate":         {path: "/api/info", acceptEncoding: "deflate", body: strings.Repeat("faucet", 100)},
+		"below min length":     {path: "/api/small", acceptEncoding: "gzip", body: "faucet"},
+		"compressed asset":     {path: "/logo.png", acceptEncoding: "gzip", body: strings.Repeat("png", 100)},
+	} {
+		rec := serveCompressionTestRequest(e, test.path, test.acceptEncoding)
+		require.Equal(t, http.StatusOK, rec.Code, name)
+		require.Empty(t, rec.Header().Get(echo.HeaderContentEncoding), name)
+		require.Equal(t, test.body, rec.Body.String(), name)
+	}
+}
+
+func TestNewCompressionMiddlewareInvalid(t *testing.T) {
+	_, err := newCompressionMiddleware(10, 0)
+	require.Error(t, err)
+
+	_, err = newCompressionMiddleware(0, 0)
+	require.Error(t, err)
+
+	_, err = newCompressionMiddleware(-1, -1)
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package faucet
+
+import (
+	"time"
+
+	"github.com/iotaledger/inx-app/pkg/nodebridge"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// donation is a received donation in the public feed.
+type donation struct {
+	// The time the donation was detected.
+	Timestamp time.Time `json:"timestamp"`
+	// The bech32 address of the sender, it is omitted if it is unknown.
+	Sender string `json:"sender,omitempty"`
+	// The amount of base tokens of the donation.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The stored mana of the donation.
+	ManaAmount iotago.Mana `json:"mana"`
+	// The ID of the received output.
+	OutputID string `json:"outputId"`
+	// The ID of the transaction that created the output.
+	TransactionID string `json:"transactionId"`
+	// The explorer link of the transaction, it is omitted if no explorer URL is configured.
+	TransactionURL string `json:"transactionUrl,omitempty"`
+}
+
+// donationsResponse defines the response of a GET RouteFaucetDonations REST API call.
+type donationsResponse struct {
+	// The amount of donations since the faucet was started.
+	Count int `json:"count"`
+	// The base tokens of all donations since the faucet was started.
+	TotalBaseTokens iotago.BaseToken `json:"totalAmount"`
+	// The stored mana of all donations since the faucet was started.
+	TotalMana iotago.Mana `json:"totalMana"`
+	// The latest donations, newest first.
+	Donations []*donation `json:"donations"`
+}
+
+func newDonation(d *faucet.Donation) *donation {
+	return &donation{
+		Timestamp:       d.Timestamp,
+		Sender:          d.Sender,
+		BaseTokenAmount: d.BaseTokenAmount,
+		ManaAmount:      d.ManaAmount,
+		OutputID:        d.OutputID.ToHex(),
+		TransactionID:   d.TransactionID.ToHex(),
+		TransactionURL:  deps.Faucet.ExplorerURL(faucet.ExplorerLinkKindTransaction, d.TransactionID.ToHex()),
+	}
+}
+
+// ledgerOutputs converts the outputs of an accepted transaction.
+func ledgerOutputs(outputs []*nodebridge.Output) []*faucet.LedgerOutput {
+	ledgerOutputs := make([]*faucet.LedgerOutput, 0, len(outputs))
+	for _, output := range outputs {
+		ledgerOutputs = append(ledgerOutputs, &faucet.LedgerOutput{
+			OutputID: output.OutputID,
+			Output:   output.Output,
+		})
+	}
+
+	return ledgerOutputs
+}
+
+// getDonations returns the latest donations and the totals.
+func getDonations() *donationsResponse {
+	donations, stats := deps.Faucet.Donations()
+
+	response := &donationsResponse{
+		Count:           stats.Count,
+		TotalBaseTokens: stats.TotalBaseTokens,
+		TotalMana:       stats.TotalMana,
+		Donations:       make([]*donation, 0, len(donations)),
+	}
+	for _, d := range donations {
+		response.Donations = append(response.Donations, newDonation(d))
+	}
+
+	return response
+}
@@ -0,0 +1,94 @@
+package faucet
+
+import (
+	"context"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+	"github.com/iotaledger/iota.go/v4/nodeclient"
+)
+
+// claimableExpiredOutput checks if the given output expired and can be claimed by the given return address
+// in a faucet transaction without creating additional outputs.
+func claimableExpiredOutput(output *iotago.BasicOutput, returnAddress iotago.Address, claimableSlot iotago.SlotIndex) bool {
+	unlockConditions := output.UnlockConditionSet()
+
+	if canUnlock, expirationReturnAddress := unlockConditions.ReturnAddressCanUnlock(claimableSlot); !canUnlock || !expirationReturnAddress.Equal(returnAddress) {
+		return false
+	}
+
+	if unlockConditions.HasTimelockCondition() {
+		// the timelock would need to be checked against the slot of the transaction
+		return false
+	}
+
+	if storageDepositReturn := unlockConditions.StorageDepositReturn(); storageDepositReturn != nil && !storageDepositReturn.ReturnAddress.Equal(returnAddress) {
+		// the storage deposit only doesn't need to be returned if it belongs to the address that unlocks the output
+		return false
+	}
+
+	// native tokens would need to be transferred to a separate output
+	return output.FeatureSet().NativeToken() == nil
+}
+
+// collectExpiredFaucetOutputs collects the outputs that expired and are returned to one of the given addresses of the faucet.
+func collectExpiredFaucetOutputs(ctx context.Context, indexer nodeclient.IndexerClient, returnAddresses ...iotago.Address) ([]faucet.UTXOBasicOutput, error) {
+	protocolParams := deps.NodeBridge.APIProvider().CommittedAPI().ProtocolParameters()
+
+	// the transaction references a commitment that is older than the last accepted block,
+	// so only outputs that expired before the maximum committable age are claimed.
+	lastAcceptedBlockSlot := iotago.SlotIndex(deps.NodeBridge.NodeStatus().GetLastAcceptedBlockSlot())
+	if lastAcceptedBlockSlot <= protocolParams.MaxCommittableAge() {
+		return nil, nil
+	}
+	claimableSlot := lastAcceptedBlockSlot - protocolParams.MaxCommittableAge()
+
+	expiredOutputs := make([]faucet.UTXOBasicOutput, 0)
+	for _, returnAddress := range returnAddresses {
+		query := &api.BasicOutputsQuery{
+			IndexerExpirationParams: api.IndexerExpirationParams{
+				ExpiresBefore:                 claimableSlot,
+				ExpirationReturnAddressBech32: returnAddress.Bech32(protocolParams.Bech32HRP()),
+			},
+		}
+
+		result, err := indexer.Outputs(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for result.Next() {
+			outputs, err := result.Outputs(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			outputIDs := result.Response.Items.MustOutputIDs()
+
+			for i := range outputs {
+				basicOutput, ok := outputs[i].(*iotago.BasicOutput)
+				if !ok {
+					Component.LogWarnf("invalid type: expected *iotago.BasicOutput, got %T", outputs[i])
+
+					continue
+				}
+
+				if !claimableExpiredOutput(basicOutput, returnAddress, claimableSlot) {
+					continue
+				}
+
+				expiredOutputs = append(expiredOutputs, faucet.UTXOBasicOutput{
+					OutputID:     outputIDs[i],
+					Output:       basicOutput,
+					UnlockTarget: returnAddress,
+				})
+			}
+		}
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	}
+
+	return expiredOutputs, nil
+}
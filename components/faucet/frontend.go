@@ -1,71 +1,188 @@
 package faucet
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"fmt"
+	"html"
 	"io/fs"
 	"net/http"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// headerETag is the header that contains the hash of a served file.
+	headerETag = "ETag"
+	// headerIfNoneMatch is the header that contains the hashes of the files the client already has.
+	headerIfNoneMatch = "If-None-Match"
+)
+
+const (
+	// the path of the index.html of the website, it is served for all unknown routes.
+	frontendIndexPath = "index.html"
+)
+
 //go:embed frontend/public
 var distFiles embed.FS
 
-func frontendFileSystem() http.FileSystem {
+func frontendFileSystem() fs.FS {
 	f, err := fs.Sub(distFiles, "frontend/public")
 	if err != nil {
 		panic(err)
 	}
 
-	return http.FS(f)
+	return f
 }
 
-func calculateMimeType(e echo.Context) string {
-	url := e.Request().URL.String()
-
-	switch {
-	case strings.HasSuffix(url, ".html"):
+// calculateMimeType returns the content type of the file with the given path.
+func calculateMimeType(filePath string) string {
+	switch path.Ext(filePath) {
+	case ".html":
 		return echo.MIMETextHTMLCharsetUTF8
-	case strings.HasSuffix(url, ".css"):
-		return "text/css"
-	case strings.HasSuffix(url, ".js"):
-		return echo.MIMEApplicationJavaScript
-	case strings.HasSuffix(url, ".json"):
+	case ".css":
+		return "text/css; charset=utf-8"
+	case ".js":
+		return echo.MIMEApplicationJavaScriptCharsetUTF8
+	case ".json", ".map":
 		return echo.MIMEApplicationJSON
-	case strings.HasSuffix(url, ".png"):
+	case ".png":
 		return "image/png"
-	case strings.HasSuffix(url, ".svg"):
+	case ".svg":
 		return "image/svg+xml"
+	case ".ico":
+		return "image/x-icon"
+	case ".woff":
+		return "font/woff"
+	case ".woff2":
+		return "font/woff2"
 	default:
-		return echo.MIMETextHTMLCharsetUTF8
+		return echo.MIMEOctetStream
+	}
+}
+
+// frontendAsset is an embedded file of the website.
+type frontendAsset struct {
+	content     []byte
+	contentType string
+	// the hash of the content, so clients only download files again if they changed.
+	etag string
+}
+
+func newFrontendAsset(filePath string, content []byte) *frontendAsset {
+	hash := sha256.Sum256(content)
+
+	return &frontendAsset{
+		content:     content,
+		contentType: calculateMimeType(filePath),
+		etag:        fmt.Sprintf("\"%s\"", hex.EncodeToString(hash[:16])),
 	}
 }
 
+// frontendIndexHTML returns the index.html of the website with a base URL of the given path prefix,
+// so the relative asset paths resolve correctly for every route of the website.
+func frontendIndexHTML(indexHTML []byte, prefix string) []byte {
+	baseTag := fmt.Sprintf("<head>\n    <base href=\"%s/\">", html.EscapeString(prefix))
+
+	return bytes.Replace(indexHTML, []byte("<head>"), []byte(baseTag), 1)
+}
+
+// loadFrontendAssets reads all embedded files of the website and computes their hashes.
+func loadFrontendAssets(prefix string) (map[string]*frontendAsset, error) {
+	fileSystem := frontendFileSystem()
+
+	assets := make(map[string]*frontendAsset)
+	if err := fs.WalkDir(fileSystem, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(fileSystem, filePath)
+		if err != nil {
+			return err
+		}
+
+		if filePath == frontendIndexPath {
+			content = frontendIndexHTML(content, prefix)
+		}
+		assets[filePath] = newFrontendAsset(filePath, content)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, exists := assets[frontendIndexPath]; !exists {
+		return nil, fs.ErrNotExist
+	}
+
+	return assets, nil
+}
+
+// etagMatches returns whether the "If-None-Match" header of the client contains the given ETag.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheControl returns the "Cache-Control" header of the given asset.
+func cacheControl(filePath string) string {
+	// the index.html references the assets without hashes in their names, so it is always revalidated
+	if filePath == frontendIndexPath || ParamsFaucet.Frontend.CacheMaxAge <= 0 {
+		return "no-cache"
+	}
+
+	return fmt.Sprintf("public, max-age=%d", int64(ParamsFaucet.Frontend.CacheMaxAge/time.Second))
+}
+
 func frontendMiddleware() echo.MiddlewareFunc {
-	fs := frontendFileSystem()
+	prefix := basePath()
+
+	assets, err := loadFrontendAssets(prefix)
+	if err != nil {
+		panic(err)
+	}
 
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(_ echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			contentType := calculateMimeType(c)
+			if prefix != "" && c.Request().URL.Path == prefix {
+				// the relative asset paths of the website only resolve with a trailing slash
+				target := prefix + "/"
+				if c.Request().URL.RawQuery != "" {
+					target += "?" + c.Request().URL.RawQuery
+				}
 
-			path := strings.TrimPrefix(c.Request().RequestURI, "/")
-			if len(path) == 0 {
-				path = "index.html"
-				contentType = echo.MIMETextHTMLCharsetUTF8
+				return c.Redirect(http.StatusMovedPermanently, target)
 			}
-			staticBlob, err := fs.Open(path)
-			if err != nil {
+
+			filePath := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(c.Request().URL.Path, prefix)), "/")
+			asset, exists := assets[filePath]
+			if !exists {
 				// If the asset cannot be found, fall back to the index.html for routing
-				path = "index.html"
-				contentType = echo.MIMETextHTMLCharsetUTF8
-				staticBlob, err = fs.Open(path)
-				if err != nil {
-					return next(c)
-				}
+				filePath = frontendIndexPath
+				asset = assets[filePath]
+			}
+
+			header := c.Response().Header()
+			header.Set(echo.HeaderCacheControl, cacheControl(filePath))
+			header.Set(headerETag, asset.etag)
+
+			if etagMatches(c.Request().Header.Get(headerIfNoneMatch), asset.etag) {
+				return c.NoContent(http.StatusNotModified)
 			}
 
-			return c.Stream(http.StatusOK, contentType, staticBlob)
+			return c.Blob(http.StatusOK, asset.contentType, asset.content)
 		}
 	}
 }
@@ -0,0 +1,110 @@
+package faucet
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/geoip"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// geoIPLabel normalizes a configured country code (e.g. "cu") or autonomous system (e.g. "AS14061" or "14061").
+func geoIPLabel(entry string) (string, error) {
+	entry = strings.ToUpper(strings.TrimSpace(entry))
+
+	if asn, err := strconv.ParseUint(strings.TrimPrefix(entry, "AS"), 10, 32); err == nil {
+		return geoip.FormatASN(asn), nil
+	}
+
+	if len(entry) == 2 && entry[0] >= 'A' && entry[0] <= 'Z' && entry[1] >= 'A' && entry[1] <= 'Z' {
+		return entry, nil
+	}
+
+	return "", ierrors.Errorf("invalid country code or autonomous system: %s", entry)
+}
+
+// geoIPRules returns the configured actions per country code and autonomous system.
+func geoIPRules() (map[string]faucet.IPPolicyAction, error) {
+	rules := make(map[string]faucet.IPPolicyAction)
+
+	for action, entries := range map[faucet.IPPolicyAction][]string{
+		faucet.IPPolicyActionDowngrade: ParamsFaucet.GeoIP.Downgrade,
+		faucet.IPPolicyActionChallenge: ParamsFaucet.GeoIP.Challenge,
+		faucet.IPPolicyActionBlock:     ParamsFaucet.GeoIP.Block,
+	} {
+		for _, entry := range entries {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+
+			label, err := geoIPLabel(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			if existingAction, exists := rules[label]; exists && existingAction != action {
+				return nil, ierrors.Errorf("%s is configured for several GeoIP actions", label)
+			}
+			rules[label] = action
+		}
+	}
+
+	return rules, nil
+}
+
+// geoIPRequiresChallenge checks if the GeoIP policy requires a solved proof-of-work challenge for some requests.
+func geoIPRequiresChallenge() bool {
+	if !ParamsFaucet.GeoIP.Enabled {
+		return false
+	}
+
+	for _, entry := range ParamsFaucet.GeoIP.Challenge {
+		if strings.TrimSpace(entry) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newGeoIPPolicy creates the request policy per country and autonomous system of the client.
+func newGeoIPPolicy(baseTokenAmountSmall iotago.BaseToken, challenger *faucet.PoWChallenger) (*faucet.IPPolicy, error) {
+	if ParamsFaucet.GeoIP.CountryDatabasePath == "" && ParamsFaucet.GeoIP.ASNDatabasePath == "" {
+		return nil, ierrors.New("GeoIP policy requires a country or an ASN database")
+	}
+
+	rules, err := geoIPRules()
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := geoip.OpenDatabase(ParamsFaucet.GeoIP.CountryDatabasePath, ParamsFaucet.GeoIP.ASNDatabasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier := faucet.IPClassifierFunc(func(ip net.IP) []string {
+		location, err := database.Lookup(ip)
+		if err != nil {
+			// the request is not restricted if the location is unknown
+			Component.LogWarnf("failed to look up the location of %s: %s", ip, err)
+
+			return nil
+		}
+
+		var labels []string
+		if location.CountryCode != "" {
+			labels = append(labels, location.CountryCode)
+		}
+		if location.ASN != 0 {
+			labels = append(labels, geoip.FormatASN(location.ASN))
+		}
+
+		return labels
+	})
+
+	return faucet.NewIPPolicy(classifier, rules, baseTokenAmountSmall, challenger), nil
+}
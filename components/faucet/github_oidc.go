@@ -0,0 +1,111 @@
+package faucet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/oidc"
+	"github.com/iotaledger/inx-faucet/pkg/ratelimit"
+)
+
+const (
+	// githubOIDCTimeout is the timeout for fetching the signing keys of GitHub Actions.
+	githubOIDCTimeout = 5 * time.Second
+)
+
+// githubOIDCVerifier verifies the OIDC tokens of GitHub Actions workflows.
+// it is nil if the GitHub OIDC endpoint is disabled.
+var githubOIDCVerifier *oidc.Verifier
+
+// githubRateLimiter limits the requests per repository of GitHub Actions workflows.
+// it is nil if the GitHub OIDC endpoint is disabled.
+var githubRateLimiter ratelimit.Limiter
+
+// isGitHubRepositoryAllowed checks if the given repository matches one of the configured repositories.
+// "owner/*" allows all repositories of an owner, GitHub names are case-insensitive.
+func isGitHubRepositoryAllowed(repository string) bool {
+	repository = strings.ToLower(repository)
+
+	for _, pattern := range ParamsFaucet.GitHubOIDC.Repositories {
+		if pattern == "" {
+			continue
+		}
+
+		if matched, err := path.Match(strings.ToLower(pattern), repository); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyGitHubOIDCToken verifies the bearer token of a GitHub Actions workflow and returns its claims.
+func verifyGitHubOIDCToken(c echo.Context) (*oidc.Claims, error) {
+	authorization := c.Request().Header.Get(headerAuthorization)
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "Missing bearer token.")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), githubOIDCTimeout)
+	defer cancel()
+
+	claims, err := githubOIDCVerifier.Verify(ctx, strings.TrimPrefix(authorization, bearerPrefix))
+	if err != nil {
+		switch {
+		case ierrors.Is(err, oidc.ErrTokenExpired):
+			return nil, faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "Bearer token expired.")
+		case ierrors.Is(err, oidc.ErrInvalidToken):
+			return nil, faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "Invalid bearer token.")
+		default:
+			Component.LogWarnf("failed to verify GitHub OIDC token: %s", err)
+
+			return nil, faucet.NewRequestError(faucet.ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "The token can't be verified. Please try again later!").WithRetryAfter(githubOIDCTimeout)
+		}
+	}
+
+	if !isGitHubRepositoryAllowed(claims.Repository) {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeForbidden, http.StatusForbidden, fmt.Sprintf("Repository \"%s\" is not allowed to request funds.", claims.Repository))
+	}
+
+	return claims, nil
+}
+
+// addGitHubFaucetOutputToQueue enqueues a request of a GitHub Actions workflow.
+// the requests are rate limited per repository instead of per IP and get a higher priority in the queue.
+func addGitHubFaucetOutputToQueue(c echo.Context) (*faucet.EnqueueResponse, error) {
+	claims, err := verifyGitHubOIDCToken(c)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := bindEnqueueRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := githubRateLimiter.Allow(ratelimit.Identifier(ratelimit.ScopeKey, "github:"+strings.ToLower(claims.Repository)))
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, newRateLimitedError(githubRateLimiter.Limits())
+	}
+
+	Component.LogDebugf("GitHub Actions request of %s by %s (workflow: %s, ref: %s)", claims.Repository, claims.Actor, claims.Workflow, claims.Ref)
+
+	return deps.Faucet.Enqueue(request, &faucet.ClientMetadata{
+		// runners share their IP addresses, so the pending requests are limited per repository
+		RemoteIP:      "github:" + strings.ToLower(claims.Repository),
+		UserAgent:     c.Request().UserAgent(),
+		Header:        c.Request().Header,
+		Authenticated: true,
+	})
+}
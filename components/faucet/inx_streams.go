@@ -0,0 +1,49 @@
+package faucet
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// the delay before an interrupted INX stream is opened again for the first time.
+	inxReconnectDelayMin = 1 * time.Second
+	// the maximum delay before an interrupted INX stream is opened again.
+	inxReconnectDelayMax = 1 * time.Minute
+)
+
+// runINXStream keeps the INX stream with the given name open until the context is canceled.
+// If the stream is interrupted, it is opened again with an exponential backoff instead of shutting down the faucet.
+// The listen function must call connected as soon as the stream was opened successfully.
+func runINXStream(ctx context.Context, name string, listen func(ctx context.Context, connected func()) error) {
+	reconnectDelay := inxReconnectDelayMin
+
+	for {
+		var connectedSince time.Time
+		err := listen(ctx, func() {
+			connectedSince = time.Now()
+			deps.Faucet.SetINXStreamConnected(name)
+		})
+		if ctx.Err() != nil {
+			// the faucet is shutting down
+			return
+		}
+
+		deps.Faucet.SetINXStreamDisconnected(name)
+
+		if !connectedSince.IsZero() && time.Since(connectedSince) >= inxReconnectDelayMax {
+			// the stream was stable for a while, so start over with the shortest delay
+			reconnectDelay = inxReconnectDelayMin
+		}
+
+		Component.LogWarnf("INX stream %s was interrupted, reconnecting in %s, error: %v", name, reconnectDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+
+		reconnectDelay = min(2*reconnectDelay, inxReconnectDelayMax)
+	}
+}
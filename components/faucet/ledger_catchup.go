@@ -0,0 +1,144 @@
+package faucet
+
+import (
+	"context"
+
+	"github.com/iotaledger/hive.go/ds/types"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/lo"
+	"github.com/iotaledger/inx-app/pkg/nodebridge"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// applyAcceptedTransaction applies the outputs of an accepted transaction to the faucet.
+// If applyDonations is false, the transaction may have been applied before and the donations are skipped.
+func applyAcceptedTransaction(tx *nodebridge.AcceptedTransaction, applyDonations bool) {
+	// create maps for faster lookup.
+	// outputs that are created and consumed in the same update exist in both maps.
+	createdOutputs := make(map[iotago.OutputID]struct{})
+	for _, output := range tx.Created {
+		createdOutputs[output.OutputID] = types.Void
+	}
+	consumedOutputs := make(map[iotago.OutputID]struct{})
+	for _, output := range tx.Consumed {
+		consumedOutputs[output.OutputID] = types.Void
+	}
+
+	deps.Faucet.ApplyAcceptedTransaction(createdOutputs, consumedOutputs)
+
+	if applyDonations {
+		deps.Faucet.ApplyDonations(tx.TransactionID, ledgerOutputs(tx.Consumed), ledgerOutputs(tx.Created))
+	}
+}
+
+// acceptedTransactionsOfLedgerUpdate splits the outputs of a ledger update into the transactions that created or consumed them.
+func acceptedTransactionsOfLedgerUpdate(update *nodebridge.LedgerUpdate) []*nodebridge.AcceptedTransaction {
+	txs := make(map[iotago.TransactionID]*nodebridge.AcceptedTransaction)
+	txIDs := make([]iotago.TransactionID, 0)
+
+	acceptedTransaction := func(txID iotago.TransactionID) *nodebridge.AcceptedTransaction {
+		tx, exists := txs[txID]
+		if !exists {
+			tx = &nodebridge.AcceptedTransaction{
+				API:           update.API,
+				Slot:          update.CommitmentID.Slot(),
+				TransactionID: txID,
+			}
+			txs[txID] = tx
+			txIDs = append(txIDs, txID)
+		}
+
+		return tx
+	}
+
+	for _, output := range update.Consumed {
+		if output.Metadata == nil || output.Metadata.Spent == nil {
+			continue
+		}
+
+		tx := acceptedTransaction(output.Metadata.Spent.TransactionID)
+		tx.Consumed = append(tx.Consumed, output)
+	}
+
+	for _, output := range update.Created {
+		tx := acceptedTransaction(output.OutputID.TransactionID())
+		tx.Created = append(tx.Created, output)
+	}
+
+	return lo.Map(txIDs, func(txID iotago.TransactionID) *nodebridge.AcceptedTransaction {
+		return txs[txID]
+	})
+}
+
+// latestCommittedSlot returns the slot of the latest commitment of the node.
+func latestCommittedSlot() iotago.SlotIndex {
+	latestCommitment := deps.NodeBridge.LatestCommitment()
+	if latestCommitment == nil {
+		return 0
+	}
+
+	return latestCommitment.CommitmentID.Slot()
+}
+
+// catchUpAcceptedTransactions replays the ledger updates of the committed slots starting at startSlot,
+// so the transactions that were accepted while the stream was interrupted are applied to the faucet.
+// Transactions of slots up to lastAppliedSlot may have been applied before, their donations are skipped.
+// Transactions that are accepted in slots that are not committed yet are covered by the periodic state check of the pending transaction.
+func catchUpAcceptedTransactions(ctx context.Context, startSlot iotago.SlotIndex, lastAppliedSlot iotago.SlotIndex) error {
+	endSlot := latestCommittedSlot()
+	if startSlot == 0 || startSlot > endSlot {
+		return nil
+	}
+
+	Component.LogInfof("replaying ledger updates of slots %d to %d ...", startSlot, endSlot)
+
+	if err := deps.NodeBridge.ListenToLedgerUpdates(ctx, startSlot, endSlot, func(update *nodebridge.LedgerUpdate) error {
+		for _, tx := range acceptedTransactionsOfLedgerUpdate(update) {
+			applyAcceptedTransaction(tx, tx.Slot > lastAppliedSlot)
+		}
+
+		return nil
+	}); err != nil {
+		return ierrors.Wrapf(err, "failed to replay ledger updates of slots %d to %d", startSlot, endSlot)
+	}
+
+	Component.LogInfof("replaying ledger updates of slots %d to %d ... done", startSlot, endSlot)
+
+	return nil
+}
+
+// runAcceptedTransactionsListener applies the accepted transactions to the faucet.
+// If the stream is interrupted, the ledger updates of the missed slots are replayed before the stream is opened again.
+func runAcceptedTransactionsListener() {
+	if err := Component.Daemon().BackgroundWorker("Faucet[ListenToAcceptedTransactions]", func(ctx context.Context) {
+		// the first slot that needs to be replayed after the stream was interrupted, 0 if there is nothing to replay
+		var catchUpStartSlot iotago.SlotIndex
+		// the slot of the latest accepted transaction that was applied
+		var lastAppliedSlot iotago.SlotIndex
+
+		runINXStream(ctx, "AcceptedTransactions", func(ctx context.Context, connected func()) error {
+			if err := catchUpAcceptedTransactions(ctx, catchUpStartSlot, lastAppliedSlot); err != nil {
+				// the replay is retried with the next reconnect
+				return err
+			}
+			catchUpStartSlot = 0
+
+			connected()
+
+			err := deps.NodeBridge.ListenToAcceptedTransactions(ctx, func(tx *nodebridge.AcceptedTransaction) error {
+				applyAcceptedTransaction(tx, true)
+				lastAppliedSlot = max(lastAppliedSlot, tx.Slot)
+
+				return nil
+			})
+
+			// all slots after the latest commitment may have been missed until the stream is opened again
+			catchUpStartSlot = latestCommittedSlot() + 1
+
+			return err
+		})
+	}, daemon.PriorityStopFaucetAcceptedTransactions); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
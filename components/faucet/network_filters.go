@@ -0,0 +1,109 @@
+package faucet
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/iplist"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// networkFilterTor is the label of the exit nodes of the Tor network.
+	networkFilterTor = "tor"
+	// networkFilterDatacenter is the label of the IP ranges of cloud providers.
+	networkFilterDatacenter = "datacenter"
+
+	// networkFilterTimeout is the timeout for downloading a single IP list.
+	networkFilterTimeout = 30 * time.Second
+)
+
+// networkFilterLists are the IP lists of the enabled network filters per label.
+// they are empty until they are downloaded for the first time.
+var networkFilterLists = make(map[string]*iplist.List)
+
+// networkFilterURLs returns the non-empty URLs of the given list.
+func networkFilterURLs(urls []string) []string {
+	var filtered []string
+	for _, url := range urls {
+		if url = strings.TrimSpace(url); url != "" {
+			filtered = append(filtered, url)
+		}
+	}
+
+	return filtered
+}
+
+// networkFiltersRequireChallenge checks if the network filters require a solved proof-of-work challenge for some requests.
+func networkFiltersRequireChallenge() bool {
+	return (ParamsFaucet.NetworkFilters.Tor.Enabled && strings.EqualFold(ParamsFaucet.NetworkFilters.Tor.Action, string(faucet.IPPolicyActionChallenge))) ||
+		(ParamsFaucet.NetworkFilters.Datacenter.Enabled && strings.EqualFold(ParamsFaucet.NetworkFilters.Datacenter.Action, string(faucet.IPPolicyActionChallenge)))
+}
+
+// newNetworkFilterPolicy creates the request policy for clients from Tor exit nodes and datacenters.
+// it returns nil if no network filter is enabled.
+func newNetworkFilterPolicy(baseTokenAmountSmall iotago.BaseToken, challenger *faucet.PoWChallenger) (*faucet.IPPolicy, error) {
+	rules := make(map[string]faucet.IPPolicyAction)
+
+	for label, filter := range map[string]struct {
+		enabled bool
+		action  string
+		urls    []string
+	}{
+		networkFilterTor:        {ParamsFaucet.NetworkFilters.Tor.Enabled, ParamsFaucet.NetworkFilters.Tor.Action, ParamsFaucet.NetworkFilters.Tor.URLs},
+		networkFilterDatacenter: {ParamsFaucet.NetworkFilters.Datacenter.Enabled, ParamsFaucet.NetworkFilters.Datacenter.Action, ParamsFaucet.NetworkFilters.Datacenter.URLs},
+	} {
+		if !filter.enabled {
+			continue
+		}
+
+		action, err := faucet.ParseIPPolicyAction(filter.action)
+		if err != nil {
+			return nil, err
+		}
+
+		urls := networkFilterURLs(filter.urls)
+		if len(urls) == 0 {
+			return nil, ierrors.Errorf("%s network filter requires at least one URL", label)
+		}
+
+		rules[label] = action
+		networkFilterLists[label] = iplist.NewList(urls, networkFilterTimeout)
+	}
+
+	if len(rules) == 0 {
+		//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
+		return nil, nil
+	}
+
+	classifier := faucet.IPClassifierFunc(func(ip net.IP) []string {
+		var labels []string
+		for label, list := range networkFilterLists {
+			if list.Contains(ip) {
+				labels = append(labels, label)
+			}
+		}
+
+		return labels
+	})
+
+	return faucet.NewIPPolicy(classifier, rules, baseTokenAmountSmall, challenger), nil
+}
+
+// runNetworkFilters periodically downloads the IP lists of the enabled network filters.
+func runNetworkFilters() {
+	for label, list := range networkFilterLists {
+		if err := Component.Daemon().BackgroundWorker("Faucet[NetworkFilter]["+label+"]", func(ctx context.Context) {
+			list.Run(ctx, ParamsFaucet.NetworkFilters.RefreshInterval, func(err error) {
+				Component.LogWarnf("failed to refresh the %s network filter: %s", label, err)
+			})
+		}, daemon.PriorityStopNetworkFilters); err != nil {
+			Component.LogPanicf("failed to start worker: %s", err)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package faucet
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/lo"
+	"github.com/iotaledger/inx-app/pkg/nodebridge"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+	"github.com/iotaledger/iota.go/v4/builder"
+	"github.com/iotaledger/iota.go/v4/nodeclient"
+)
+
+// inxNodeAdapter accesses the ledger of the node via INX and the indexer, and issues the blocks via the block issuer.
+type inxNodeAdapter struct {
+	nodeBridge        nodebridge.NodeBridge
+	blockIssuerClient nodeclient.BlockIssuerClient
+	indexer           nodeclient.IndexerClient
+	// the restricted address of the faucet.
+	faucetAddress iotago.Address
+	// the restricted addresses of the additional keys the payouts are funded from.
+	sourceAddresses []iotago.Address
+	// the restricted addresses of the retired faucet keys, their funds are swept to the faucet address.
+	retiredAddresses []iotago.Address
+}
+
+var _ faucet.NodeAdapter = &inxNodeAdapter{}
+
+func (n *inxNodeAdapter) IsNodeHealthy() bool {
+	return n.nodeBridge.IsNodeHealthy()
+}
+
+func (n *inxNodeAdapter) FetchTransactionMetadata(transactionID iotago.TransactionID) (*api.TransactionMetadataResponse, error) {
+	ctx, cancel := context.WithTimeout(Component.Daemon().ContextStopped(), 5*time.Second)
+	defer cancel()
+
+	metadata, err := n.nodeBridge.TransactionMetadata(ctx, transactionID)
+	if err != nil {
+		st, ok := status.FromError(err)
+		if ok && st.Code() == codes.NotFound {
+			// the block is either not found, or it was evicted
+			//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+func (n *inxNodeAdapter) CollectUnlockableFaucetOutputs() ([]faucet.UTXOBasicOutput, error) {
+	ctxRequest, cancelRequest := context.WithTimeout(Component.Daemon().ContextStopped(), inxRequestTimeout)
+	defer cancelRequest()
+
+	faucetOutputs, err := n.collectAddressOutputs(ctxRequest, n.faucetAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the outputs of the source and retired addresses are unlocked with their own keys.
+	additionalAddresses := append(append([]iotago.Address{}, n.sourceAddresses...), n.retiredAddresses...)
+	for _, additionalAddress := range additionalAddresses {
+		additionalOutputs, err := n.collectAddressOutputs(ctxRequest, additionalAddress, additionalAddress)
+		if err != nil {
+			return nil, err
+		}
+		faucetOutputs = append(faucetOutputs, additionalOutputs...)
+	}
+
+	if ParamsFaucet.ClaimExpiredOutputs {
+		// outputs sent by the faucet with an expiration, or sent by someone else with the faucet as return address,
+		// are consumed in the next faucet transaction after they expired.
+		returnAddresses := make([]iotago.Address, 0, 2*(len(additionalAddresses)+1))
+		for _, address := range append([]iotago.Address{n.faucetAddress}, additionalAddresses...) {
+			//nolint:forcetypeassert // we can safely assume that this is a RestrictedAddress
+			returnAddresses = append(returnAddresses, address, address.(*iotago.RestrictedAddress).Address)
+		}
+
+		expiredOutputs, err := collectExpiredFaucetOutputs(ctxRequest, n.indexer, returnAddresses...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, expiredOutput := range expiredOutputs {
+			Component.LogDebugf("claiming expired output %s with %d base tokens", expiredOutput.OutputID.ToHex(), expiredOutput.Output.Amount)
+		}
+		faucetOutputs = append(faucetOutputs, expiredOutputs...)
+	}
+
+	return faucetOutputs, nil
+}
+
+// collectAddressOutputs collects the basic outputs owned by the given restricted address.
+// the unlock target is set on the returned outputs, nil means the faucet address.
+func (n *inxNodeAdapter) collectAddressOutputs(ctx context.Context, address iotago.Address, unlockTarget iotago.Address) ([]faucet.UTXOBasicOutput, error) {
+	// the restricted address only returns simple outputs, which are basic outputs without timelocks,
+	// expiration, native tokens, storage deposit return unlocks conditions.
+	query := &api.BasicOutputsQuery{
+		AddressBech32: address.Bech32(n.nodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()),
+	}
+
+	result, err := n.indexer.Outputs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	addressOutputs := make([]faucet.UTXOBasicOutput, 0)
+	for result.Next() {
+		outputs, err := result.Outputs(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		outputIDs := result.Response.Items.MustOutputIDs()
+
+		for i := range outputs {
+			basicOutput, ok := outputs[i].(*iotago.BasicOutput)
+			if !ok {
+				Component.LogWarnf("invalid type: expected *iotago.BasicOutput, got %T", outputs[i])
+
+				continue
+			}
+
+			addressOutputs = append(addressOutputs, faucet.UTXOBasicOutput{
+				OutputID:     outputIDs[i],
+				Output:       basicOutput,
+				UnlockTarget: unlockTarget,
+			})
+		}
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return addressOutputs, nil
+}
+
+func (n *inxNodeAdapter) ComputeUnlockableAddressBalance(address iotago.Address) (iotago.BaseToken, iotago.Mana, error) {
+	ctxRequest, cancelRequest := context.WithTimeout(Component.Daemon().ContextStopped(), inxRequestTimeout)
+	defer cancelRequest()
+
+	// collect all possible outputs that are owned by that address and evaluate later if they are unlockable.
+	query := &api.OutputsQuery{
+		IndexerUnlockableByAddressParams: api.IndexerUnlockableByAddressParams{
+			UnlockableByAddressBech32: address.Bech32(n.nodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()),
+		},
+	}
+
+	result, err := n.indexer.Outputs(ctxRequest, query)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// the funds must be spendable in a transaction that references the latest commitment,
+	// otherwise users with only conditional funds would receive a smaller amount.
+	commitmentSlot := n.LatestCommittedSlot()
+	committedAPI := n.nodeBridge.APIProvider().CommittedAPI()
+
+	var unlockableBalance iotago.BaseToken
+	var unlockableMana iotago.Mana
+	for result.Next() {
+		outputs, err := result.Outputs(ctxRequest)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, output := range outputs {
+			balance, mana := faucet.UnlockableFunds(committedAPI, output, address, commitmentSlot)
+			unlockableBalance += balance
+			unlockableMana += mana
+		}
+	}
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+
+	return unlockableBalance, unlockableMana, nil
+}
+
+func (n *inxNodeAdapter) LatestSlot() iotago.SlotIndex {
+	return iotago.SlotIndex(n.nodeBridge.NodeStatus().GetLastAcceptedBlockSlot())
+}
+
+func (n *inxNodeAdapter) ReferenceManaCost() (iotago.Mana, error) {
+	latestCommitment := n.nodeBridge.LatestCommitment()
+	if latestCommitment == nil || latestCommitment.Commitment == nil {
+		return 0, ierrors.New("latest commitment is unknown")
+	}
+
+	return latestCommitment.Commitment.ReferenceManaCost, nil
+}
+
+func (n *inxNodeAdapter) LatestCommittedSlot() iotago.SlotIndex {
+	latestCommitment := n.nodeBridge.LatestCommitment()
+	if latestCommitment == nil || latestCommitment.Commitment == nil {
+		return 0
+	}
+
+	return latestCommitment.Commitment.Slot
+}
+
+func (n *inxNodeAdapter) CollectFaucetDelegationOutputs() ([]faucet.UTXODelegationOutput, error) {
+	ctxRequest, cancelRequest := context.WithTimeout(Component.Daemon().ContextStopped(), inxRequestTimeout)
+	defer cancelRequest()
+
+	// delegation outputs are owned by the faucet address without restrictions.
+	//nolint:forcetypeassert // the faucet address is always a restricted address
+	query := &api.DelegationOutputsQuery{
+		AddressBech32: n.faucetAddress.(*iotago.RestrictedAddress).Address.Bech32(n.nodeBridge.APIProvider().CommittedAPI().ProtocolParameters().Bech32HRP()),
+	}
+
+	result, err := n.indexer.Outputs(ctxRequest, query)
+	if err != nil {
+		return nil, err
+	}
+
+	delegationOutputs := make([]faucet.UTXODelegationOutput, 0)
+	for result.Next() {
+		outputs, err := result.Outputs(ctxRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		outputIDs := result.Response.Items.MustOutputIDs()
+
+		for i := range outputs {
+			delegationOutput, ok := outputs[i].(*iotago.DelegationOutput)
+			if !ok {
+				Component.LogWarnf("invalid type: expected *iotago.DelegationOutput, got %T", outputs[i])
+
+				continue
+			}
+
+			delegationOutputs = append(delegationOutputs, faucet.UTXODelegationOutput{
+				OutputID: outputIDs[i],
+				Output:   delegationOutput,
+			})
+		}
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return delegationOutputs, nil
+}
+
+func (n *inxNodeAdapter) DelegationRewards(outputID iotago.OutputID) (iotago.Mana, error) {
+	ctxRequest, cancelRequest := context.WithTimeout(Component.Daemon().ContextStopped(), inxRequestTimeout)
+	defer cancelRequest()
+
+	client, err := n.nodeBridge.INXNodeClient()
+	if err != nil {
+		return 0, err
+	}
+
+	rewardsResponse, err := client.Rewards(ctxRequest, outputID)
+	if err != nil {
+		return 0, err
+	}
+
+	return rewardsResponse.Rewards, nil
+}
+
+func (n *inxNodeAdapter) SubmitTransactionPayload(ctx context.Context, builder *builder.TransactionBuilder, storedManaOutputIndex int, numPoWWorkers ...int) (iotago.ApplicationPayload, iotago.BlockID, error) {
+	Component.LogDebug("sending transaction payload...")
+	signedTx, blockCreatedResponse, err := n.blockIssuerClient.SendPayloadWithTransactionBuilder(ctx, builder, storedManaOutputIndex, numPoWWorkers...)
+	if err != nil {
+		return nil, iotago.EmptyBlockID, err
+	}
+	//nolint:forcetypeassert // we can safely assume that this is a SignedTransaction
+	Component.LogDebugf("sent transaction payload, blockID: %s, txID: %s", blockCreatedResponse.BlockID, lo.Return1(signedTx.(*iotago.SignedTransaction).ID()))
+
+	return signedTx, blockCreatedResponse.BlockID, nil
+}
+
+func (n *inxNodeAdapter) ReissueTransactionPayload(ctx context.Context, signedTx *iotago.SignedTransaction, numPoWWorkers ...int) (iotago.BlockID, error) {
+	// the block needs to reference the same commitment as the transaction
+	commitmentInput := signedTx.Transaction.CommitmentInput()
+	if commitmentInput == nil {
+		return iotago.EmptyBlockID, ierrors.New("transaction does not contain a commitment input")
+	}
+
+	Component.LogDebug("resending transaction payload...")
+	blockCreatedResponse, err := n.blockIssuerClient.SendPayload(ctx, signedTx, commitmentInput.CommitmentID, numPoWWorkers...)
+	if err != nil {
+		return iotago.EmptyBlockID, err
+	}
+	Component.LogDebugf("resent transaction payload, blockID: %s, txID: %s", blockCreatedResponse.BlockID, lo.Return1(signedTx.ID()))
+
+	return blockCreatedResponse.BlockID, nil
+}
@@ -7,19 +7,287 @@ import (
 )
 
 type ParametersFaucet struct {
-	BaseTokenAmount          uint64        `default:"1000000000" usage:"the amount of funds the requester receives"`
-	BaseTokenAmountSmall     uint64        `default:"100000000" usage:"the amount of funds the requester receives if the target address has more funds than the faucet amount and less than maximum"`
-	BaseTokenAmountMaxTarget uint64        `default:"5000000000" usage:"the maximum allowed amount of funds on the target address"`
-	ManaAmount               uint64        `default:"1000000" usage:"the amount of mana the requester receives"`
-	ManaAmountMinFaucet      uint64        `default:"1000000000" usage:"the minimum amount of mana the faucet needs to hold before mana payouts become active"`
-	TagMessage               string        `default:"FAUCET" usage:"the faucet transaction tag payload"`
-	BatchTimeout             time.Duration `default:"2s" usage:"the maximum duration for collecting faucet batches"`
-	BindAddress              string        `default:"localhost:8091" usage:"the bind address on which the faucet website can be accessed from"`
-	RateLimit                struct {
+	BaseTokenAmount          uint64 `default:"1000000000" usage:"the amount of funds the requester receives"`
+	BaseTokenAmountSmall     uint64 `default:"100000000" usage:"the amount of funds the requester receives if the target address has more funds than the faucet amount and less than maximum"`
+	BaseTokenAmountMaxTarget uint64 `default:"5000000000" usage:"the maximum allowed amount of funds on the target address"`
+	ManaAmount               uint64 `default:"1000000" usage:"the amount of mana the requester receives"`
+	ManaAmountMinFaucet      uint64 `default:"1000000000" usage:"the minimum amount of mana the faucet needs to hold before mana payouts become active"`
+	ManaAmountMaxTarget      uint64 `default:"0" usage:"the maximum amount of mana on the target address, requests for addresses with more mana don't receive mana (0 to disable)"`
+	ManaOnlyPayouts          bool   `default:"false" usage:"whether addresses that hold the maximum amount of funds but less than the maximum amount of mana still receive mana with the minimum storage deposit"`
+	StorageDepositPolicy     string `default:"bump" usage:"how payouts below the minimum storage deposit of their output are handled (bump: the payout is raised to the minimum, reject: the request is rejected)"`
+	ClaimExpiredOutputs      bool   `default:"true" usage:"whether outputs that are returned to the faucet address after their expiration are claimed in the faucet transactions"`
+	TagMessage               string `default:"FAUCET" usage:"the faucet transaction tag payload"`
+	TransactionTags          struct {
+		Payout     string `default:"" usage:"the tag payload of the transactions that pay out regular requests (empty to use the tag message)"`
+		Airdrop    string `default:"" usage:"the tag payload of the transactions that pay out airdrop entries (empty to use the tag message)"`
+		ManaTopUp  string `default:"" usage:"the tag payload of the transactions that allot mana to accounts (empty to use the tag message)"`
+		Sweep      string `default:"" usage:"the tag payload of the transactions that consolidate the faucet outputs without payouts, e.g. sweeps of retired addresses and mana claims (empty to use the tag message)"`
+		Delegation string `default:"" usage:"the tag payload of the transactions that delegate the faucet funds (empty to use the tag message)"`
+	}
+	RequestableAmount struct {
+		Min uint64 `default:"0" usage:"the minimum amount of base tokens a requester can ask for, smaller amounts are raised to it"`
+		Max uint64 `default:"0" usage:"the maximum amount of base tokens a requester can ask for, larger amounts are lowered to it (0 to disable requesting an amount)"`
+	}
+	BaseTokenAmountJitter    float64       `default:"0" usage:"the fraction of the full and the small amount that is randomly added or subtracted, e.g. 0.1 pays out between 0.9 and 1.1 tokens instead of 1, the full amount is only lowered because the payout never exceeds the amount reserved on enqueue (0 to disable)"`
+	RequestTagMaxLength      int           `default:"32" usage:"the maximum length of the optional tag of a faucet request that is added to the transaction data (0 to disable)"`
+	BatchTimeout             time.Duration `default:"2s" usage:"the maximum duration for collecting faucet batches, it is used if the queue is short"`
+	BatchTimeoutMin          time.Duration `default:"200ms" usage:"the minimum duration for collecting faucet batches, the batch timeout shrinks towards it as the queue grows (0 to disable)"`
+	BatchMaxSize             int           `default:"128" usage:"the maximum amount of requests in a faucet batch, the batch is issued immediately if it is reached"`
+	MaxBlockReattachments    int           `default:"3" usage:"the maximum amount of times the transaction of an orphaned faucet block is reattached in a new block"`
+	MaxReferenceManaCost     uint64        `default:"0" usage:"the maximum reference mana cost of the network up to which faucet transactions are issued (0 to disable)"`
+	MaxTransactionsPerMinute int           `default:"0" usage:"the maximum amount of transactions the faucet issues per minute, regardless of the queue length (0 to disable)"`
+	BalanceCacheTTL          time.Duration `default:"5s" usage:"the duration the funds on a target address are cached, so duplicate requests don't query the indexer again (0 to disable)"`
+	MaxPendingRequestsPerIP  int           `default:"10" usage:"the maximum amount of unconfirmed requests per originating IP address (0 to disable)"`
+	BindAddress              string        `default:"localhost:8091" usage:"the bind address on which the faucet API and website can be accessed from"`
+	BasePath                 string        `default:"" usage:"the path prefix the faucet API and website are served under, e.g. \"/faucet\" behind a shared reverse proxy (empty to serve them at the root)"`
+	ExplorerURL              string        `default:"" usage:"the template of the explorer links in the API responses and webhook notifications, {kind} is replaced with block, transaction or addr and {id} with the ID, e.g. \"https://explorer.iota.org/testnet/{kind}/{id}\" (empty to disable)"`
+	IssueTransactions        bool          `default:"true" usage:"whether this instance issues the faucet transactions (only a single instance per faucet address may do so)"`
+	Signer                   struct {
+		Type          string        `default:"local" usage:"the signer of the faucet transactions (local: the private key in the \"FAUCET_PRV_KEY\" environment variable, mnemonic: the key derived from the BIP39 mnemonic in the \"FAUCET_MNEMONIC\" environment variable, keyfile: the key in an encrypted key file, remote: an external signing service that holds the key, pkcs11: a key in an HSM, only available in builds with the \"pkcs11\" tag)"`
+		CheckInterval time.Duration `default:"1h" usage:"the interval in which the signer is tested with a sign/verify round trip for the faucet addresses, it is always tested on startup (0 to disable the periodic test)"`
+		Mnemonic      struct {
+			DerivationPath string `default:"m/44'/4218'/0'/0'/0'" usage:"the BIP32 path the key is derived from the mnemonic with, only hardened steps are supported"`
+		}
+		KeyFile struct {
+			Path string `default:"" usage:"the path to the key file encrypted with the passphrase in the \"FAUCET_KEY_FILE_PASSPHRASE\" environment variable (the passphrase is prompted for if it is not set)"`
+		}
+		Remote struct {
+			URL        string        `name:"url" default:"" usage:"the URL of the signing service"`
+			Timeout    time.Duration `default:"10s" usage:"the timeout of a request to the signing service"`
+			CACertPath string        `name:"caCertPath" default:"" usage:"the path to the PEM encoded CA certificate the certificate of the signing service is verified with (empty to use the system CAs)"`
+			CertPath   string        `default:"" usage:"the path to the PEM encoded client certificate for mutual TLS (empty to disable)"`
+			KeyPath    string        `default:"" usage:"the path to the PEM encoded private key of the client certificate"`
+		}
+		PKCS11 struct {
+			KeyLabel string `default:"" usage:"the label of the Ed25519 key pair in the HSM, the module, slot and PIN are taken from the \"FAUCET_PKCS11_MODULE\", \"FAUCET_PKCS11_SLOT\" and \"FAUCET_PKCS11_PIN\" environment variables"`
+		} `name:"pkcs11"`
+	}
+	Queue struct {
+		Size           int           `default:"5000" usage:"the maximum amount of requests in the queue"`
+		OverflowPolicy string        `default:"reject" usage:"the policy for new requests if the queue is full (reject, drop-oldest, block)"`
+		BlockTimeout   time.Duration `default:"5s" usage:"the maximum duration a new request waits for room in the queue if the block policy is used"`
+		// the API keys of clients whose requests are processed before anonymous requests
+		PriorityAPIKeys []string `default:"" usage:"the API keys that grant a higher priority in the queue, sent in the \"X-API-Key\" header"`
+	}
+	BalanceCheck struct {
+		MaxPerSecond  int `default:"20" usage:"the maximum amount of lookups of the funds on target addresses per second when queued requests are processed (0 to disable the limit)"`
+		MaxConcurrent int `default:"4" usage:"the maximum amount of lookups of the funds on target addresses that run in parallel"`
+	}
+	InputSelection struct {
+		Strategy  string `default:"all" usage:"the strategy to select the unspent outputs that are consumed in a faucet transaction (all, largest-first, oldest-first, branch-and-bound)"`
+		MaxInputs int    `default:"0" usage:"the maximum amount of inputs per faucet transaction, the outputs are swept first if they don't fit with the \"all\" strategy (0 to use the protocol limit)"`
+	}
+	RateLimit struct {
 		Enabled     bool          `default:"true" usage:"whether the rate limiting should be enabled"`
 		Period      time.Duration `default:"5m" usage:"the period for rate limiting"`
 		MaxRequests int           `default:"10" usage:"the maximum number of requests per period"`
 		MaxBurst    int           `default:"20" usage:"additional requests allowed in the burst period"`
+		Global      struct {
+			Enabled     bool          `default:"false" usage:"whether the requests of all clients together should be rate limited"`
+			Period      time.Duration `default:"1m" usage:"the period for global rate limiting"`
+			MaxRequests int           `default:"600" usage:"the maximum number of requests of all clients per period"`
+			MaxBurst    int           `default:"100" usage:"additional requests allowed in the burst period"`
+		}
+	}
+	ManaClaim struct {
+		Interval         time.Duration `default:"1m" usage:"the interval in which it is checked if the potential mana of the faucet should be converted into stored mana (0 to disable)"`
+		MinPotentialMana uint64        `default:"1000000" usage:"the minimum amount of potential mana the faucet outputs need to hold before it is claimed"`
+	}
+	Delegation struct {
+		Amount uint64 `default:"0" usage:"the amount of base tokens that are delegated to a validator per delegation request (0 to disable)"`
+	}
+	Allotment struct {
+		ManaAmount uint64 `default:"0" usage:"the amount of mana that is allotted to the block issuance credits of an account per allotment request (0 to disable)"`
+		RateLimit  struct {
+			Enabled     bool          `default:"true" usage:"whether the rate limiting of allotment requests should be enabled"`
+			Period      time.Duration `default:"1h" usage:"the period for rate limiting of allotment requests"`
+			MaxRequests int           `default:"5" usage:"the maximum number of allotment requests per client and account per period"`
+			MaxBurst    int           `default:"5" usage:"additional allotment requests allowed in the burst period"`
+		}
+	}
+	TargetAddresses struct {
+		Ed25519                 bool     `default:"true" usage:"whether Ed25519 addresses are accepted as targets of basic requests"`
+		ImplicitAccountCreation bool     `default:"true" usage:"whether implicit account creation addresses are accepted as targets of basic requests"`
+		Account                 bool     `default:"false" usage:"whether account addresses are accepted as targets of basic requests"`
+		NFT                     bool     `name:"nft" default:"false" usage:"whether NFT addresses are accepted as targets of basic requests"`
+		Anchor                  bool     `default:"false" usage:"whether anchor addresses are accepted as targets of basic requests"`
+		Burn                    []string `default:"" usage:"the bech32 addresses whose requests are rejected because the funds sent to them are lost, null addresses and the addresses of the faucet are always rejected"`
+	}
+	Budget struct {
+		Daily struct {
+			BaseTokenAmount uint64 `default:"0" usage:"the maximum amount of base tokens the faucet distributes in a rolling 24h window (0 to disable)"`
+			ManaAmount      uint64 `default:"0" usage:"the maximum amount of mana the faucet distributes in a rolling 24h window (0 to disable)"`
+		}
+		Epoch struct {
+			BaseTokenAmount uint64 `default:"0" usage:"the maximum amount of base tokens the faucet distributes per protocol epoch (0 to disable)"`
+			ManaAmount      uint64 `default:"0" usage:"the maximum amount of mana the faucet distributes per protocol epoch (0 to disable)"`
+		}
+	}
+	Payouts struct {
+		StoragePath string `default:"faucet_payouts.jsonl" usage:"the file the ledger of all confirmed payouts is stored in (empty to disable)"`
+	}
+	AuditLog struct {
+		StoragePath   string        `default:"" usage:"the file the lifecycle of all requests is appended to for abuse forensics, it contains client IPs and user agents (empty to disable)"`
+		Retention     time.Duration `default:"720h" usage:"the duration after which entries are removed from the audit log (0 to keep them forever)"`
+		PruneInterval time.Duration `default:"1h" usage:"the interval in which expired entries are removed from the audit log"`
+	}
+	Privacy struct {
+		ClientMetadataRetention time.Duration `default:"0s" usage:"the duration after which the client IPs and user agents in the payout ledger and the audit log are anonymized (0 to keep them)"`
+		Anonymization           string        `default:"hash" usage:"how the client metadata is anonymized after the retention (hash, remove)"`
+		HashSalt                string        `default:"" usage:"the salt of the hashed client metadata, so hashes of the same client can be correlated across restarts (random per start if empty)"`
+	}
+	RecentPayouts struct {
+		MaxCount           int  `default:"20" usage:"the maximum amount of latest confirmed payouts in the public feed (0 to disable)"`
+		AnonymizeAddresses bool `default:"true" usage:"whether the addresses in the public feed of payouts are truncated"`
+	}
+	Donations struct {
+		MaxCount int `default:"50" usage:"the maximum amount of latest donations to the faucet address in the public feed (0 to only count them)"`
+	}
+	AddressLists struct {
+		Allow                []string `default:"" usage:"the addresses that bypass the maximum target balance check"`
+		Deny                 []string `default:"" usage:"the addresses whose requests are rejected"`
+		AllowBaseTokenAmount uint64   `default:"0" usage:"the amount of base tokens the configured allowlisted addresses receive (0 to use the regular amount)"`
+		StoragePath          string   `default:"faucet_address_lists.json" usage:"the file the lists that were changed via the admin API are persisted to, they replace the configured lists (empty to disable)"`
+	}
+	ShadowBans struct {
+		StoragePath string `default:"faucet_shadow_bans.json" usage:"the file the shadow-ban rules that are managed via the admin API are persisted to (empty to disable)"`
+	}
+	RuntimeParameters struct {
+		StoragePath string `default:"faucet_runtime_parameters.json" usage:"the file the parameters that were changed via the admin API are persisted to, they take precedence over the configured values (empty to disable)"`
+	}
+	Frontend struct {
+		Enabled     bool          `default:"true" usage:"whether the embedded faucet website is served"`
+		BindAddress string        `default:"" usage:"the bind address of the faucet website if it should be served separately from the API (empty to serve it on the bind address of the API)"`
+		CacheMaxAge time.Duration `default:"1h" usage:"the duration browsers may cache the assets of the website without revalidating them, the index.html is always revalidated (0 to always revalidate)"`
+	}
+	Server struct {
+		ReadHeaderTimeout   time.Duration `default:"5s" usage:"the maximum duration for reading the request headers (0 for no timeout)"`
+		ReadTimeout         time.Duration `default:"15s" usage:"the maximum duration for reading the entire request including the body (0 for no timeout)"`
+		WriteTimeout        time.Duration `default:"30s" usage:"the maximum duration before timing out writes of the response (0 for no timeout)"`
+		IdleTimeout         time.Duration `default:"60s" usage:"the maximum duration to wait for the next request on a keep-alive connection (0 to use the read timeout)"`
+		MaxHeaderBytes      int           `default:"65536" usage:"the maximum size of the request headers in bytes"`
+		MaxBodySize         string        `default:"1M" usage:"the maximum size of a request body, e.g. 512K or 1M (empty to disable)"`
+		MaxConnections      int           `default:"0" usage:"the maximum amount of concurrently open connections (0 to disable)"`
+		MaxConnectionsPerIP int           `default:"0" usage:"the maximum amount of concurrently open connections per client IP (0 to disable)"`
+	}
+	TLS struct {
+		CertPath       string        `default:"" usage:"the path to the PEM encoded TLS certificate (chain) the faucet servers are served with (empty to serve HTTP)"`
+		KeyPath        string        `default:"" usage:"the path to the PEM encoded private key of the TLS certificate"`
+		ReloadInterval time.Duration `default:"1m" usage:"the interval in which the certificate files are checked for changes, so rotated certificates are used without a restart (0 to disable)"`
+	}
+	Compression struct {
+		Enabled   bool `default:"true" usage:"whether the responses of the faucet website and API are compressed with gzip if the client supports it"`
+		Level     int  `default:"-1" usage:"the compression level from 1 (fastest) to 9 (smallest), -1 for the default level"`
+		MinLength int  `default:"1024" usage:"the minimum size of a response body in bytes before it is compressed"`
+	}
+	TrustedProxies struct {
+		Proxies []string `default:"" usage:"the IP addresses and CIDRs of the reverse proxies whose client IP header is honored (empty to use the address of the connection)"`
+		Header  string   `default:"X-Forwarded-For" usage:"the header the trusted proxies send the client IP in (X-Forwarded-For, X-Real-IP, CF-Connecting-IP)"`
+	}
+	AccessLog struct {
+		Enabled  bool   `default:"false" usage:"whether the requests to the faucet website and API are written to an access log"`
+		Format   string `default:"combined" usage:"the format of the access log (common, combined, json)"`
+		FilePath string `default:"" usage:"the file the access log is appended to (empty to write it to stdout)"`
+	}
+	Admin struct {
+		Enabled   bool   `default:"false" usage:"whether the admin API routes are enabled (only enable in trusted networks)"`
+		JWTSecret string `name:"jwtSecret" default:"" usage:"the secret the JWTs of the admin API are signed with, tokens are issued with tools/faucetjwt (required if the admin API routes are enabled)"`
+	}
+	Airdrop struct {
+		BatchSize  int `default:"100" usage:"the maximum amount of airdrop payouts per transaction, airdrop and public transactions take turns"`
+		MaxEntries int `default:"10000" usage:"the maximum amount of entries of an uploaded airdrop file"`
+	}
+	CancelRequests struct {
+		Enabled      bool `default:"true" usage:"whether clients can remove their queued requests that are not part of a transaction yet"`
+		RequireProof bool `default:"true" usage:"whether canceling a request requires the idempotency key it was enqueued with or a signed ownership challenge of the address"`
+	}
+	Subscriptions struct {
+		Enabled            bool          `default:"false" usage:"whether addresses can be registered to receive a payout in a regular interval, via the admin API or with a priority API key or an admin bearer token"`
+		StoragePath        string        `default:"faucet_subscriptions.json" usage:"the file the subscriptions and the time of their last payout are persisted to (empty to disable)"`
+		CheckInterval      time.Duration `default:"1m" usage:"the interval in which the subscriptions are checked for due payouts"`
+		MaxBaseTokenAmount uint64        `default:"0" usage:"the maximum amount of base tokens of a single payout of a subscription (0 to disable)"`
+	}
+	BatchEnqueue struct {
+		Enabled     bool `default:"false" usage:"whether clients with a priority API key or an admin bearer token can enqueue requests for many addresses in one call"`
+		MaxRequests int  `default:"100" usage:"the maximum amount of addresses per batch"`
+	}
+	GitHubOIDC struct {
+		Enabled      bool     `default:"false" usage:"whether GitHub Actions workflows can request funds with their OIDC token"`
+		Audience     string   `default:"inx-faucet" usage:"the audience the OIDC tokens of the workflows must be issued for"`
+		Repositories []string `default:"" usage:"the repositories whose workflows can request funds (\"owner/name\" or \"owner/*\" for all repositories of an owner)"`
+		RateLimit    struct {
+			Period      time.Duration `default:"1h" usage:"the period for rate limiting of the requests of a repository"`
+			MaxRequests int           `default:"60" usage:"the maximum number of requests per repository per period"`
+			MaxBurst    int           `default:"20" usage:"additional requests allowed in the burst period"`
+		}
+	} `name:"githubOIDC"`
+	Webhooks struct {
+		URLs           []string      `name:"urls" default:"" usage:"the URLs that receive JSON notifications about enqueued requests, confirmed or failed payouts and received donations (empty to disable)"`
+		Secret         string        `default:"" usage:"the secret the notifications are signed with, the HMAC-SHA256 of the timestamp and the body is sent in the \"X-Faucet-Signature\" header (empty to disable the signature)"`
+		Timeout        time.Duration `default:"5s" usage:"the timeout of a single delivery attempt"`
+		MaxRetries     int           `default:"5" usage:"the maximum amount of retries if a delivery failed"`
+		InitialBackoff time.Duration `default:"1s" usage:"the delay before the first retry of a failed delivery, it is doubled with every retry"`
+		MaxBackoff     time.Duration `default:"1m" usage:"the maximum delay between retries of a failed delivery"`
+		QueueSize      int           `default:"1000" usage:"the maximum amount of notifications waiting for delivery, further notifications are dropped"`
+	}
+	SubmitRetry struct {
+		Unavailable struct {
+			MaxRetries     int           `default:"3" usage:"the maximum amount of retries if the submission failed because the block issuer was unavailable"`
+			InitialBackoff time.Duration `default:"500ms" usage:"the delay before the first retry if the submission failed because the block issuer was unavailable"`
+			MaxBackoff     time.Duration `default:"5s" usage:"the maximum delay between retries if the submission failed because the block issuer was unavailable"`
+			Jitter         float64       `default:"0.2" usage:"the fraction of the delay that is randomly added or subtracted if the submission failed because the block issuer was unavailable"`
+		}
+	}
+	Redis struct {
+		Enabled   bool   `default:"false" usage:"whether the queue and the rate limiting state are stored in redis to share them between multiple faucet instances"`
+		Address   string `default:"localhost:6379" usage:"the address of the redis server"`
+		Password  string `default:"" usage:"the password of the redis server"`
+		Database  int    `default:"0" usage:"the index of the redis database"`
+		KeyPrefix string `default:"faucet" usage:"the prefix of all keys stored in redis"`
+	}
+	LeaderElection struct {
+		Enabled       bool          `default:"false" usage:"whether the instances that issue transactions elect a leader via a lease in redis, so only the leader issues transactions"`
+		LeaseDuration time.Duration `default:"15s" usage:"the duration of the leader lease, the leader renews it after a third of the duration"`
+	}
+	Shutdown struct {
+		PendingTransactionTimeout time.Duration `default:"30s" usage:"the maximum duration to wait for the pending transaction to be resolved on shutdown"`
+		QueueFilePath             string        `default:"faucet_queue.json" usage:"the file the remaining queued requests are persisted to on shutdown and restored from on startup (empty to disable)"`
+	}
+	PoWChallenge struct {
+		Enabled    bool          `default:"false" usage:"whether enqueue requests must include a solved proof-of-work challenge (the challenges are shared via redis if it is enabled)"`
+		Difficulty int           `default:"20" usage:"the required amount of leading zero bits of the proof-of-work hash"`
+		TTL        time.Duration `default:"5m" usage:"the duration after which an issued challenge expires"`
+	} `name:"powChallenge"`
+	Ownership struct {
+		Enabled      bool          `default:"false" usage:"whether requests for more than the small amount must include a signature that proves the ownership of the target address (otherwise only the small amount is paid out)"`
+		ChallengeTTL time.Duration `default:"5m" usage:"the duration after which an issued ownership challenge expires"`
+	}
+	GeoIP struct {
+		Enabled             bool     `default:"false" usage:"whether requests are restricted per country or autonomous system of the client"`
+		CountryDatabasePath string   `default:"" usage:"the path to the MaxMind country database (GeoIP2 or GeoLite2), empty to not restrict countries"`
+		ASNDatabasePath     string   `name:"asnDatabasePath" default:"" usage:"the path to the MaxMind ASN database (GeoLite2), empty to not restrict autonomous systems"`
+		Downgrade           []string `default:"" usage:"the countries (e.g. \"CU\") and autonomous systems (e.g. \"AS14061\") whose requests only receive the small amount"`
+		Challenge           []string `default:"" usage:"the countries and autonomous systems whose requests must include a solved proof-of-work challenge"`
+		Block               []string `default:"" usage:"the countries and autonomous systems whose requests are rejected"`
+	} `name:"geoIP"`
+	NetworkFilters struct {
+		RefreshInterval time.Duration `default:"6h" usage:"the interval in which the IP lists of the network filters are downloaded again"`
+		Tor             struct {
+			Enabled bool     `default:"false" usage:"whether a policy is applied to requests from exit nodes of the Tor network"`
+			Action  string   `default:"block" usage:"the action for requests from Tor exit nodes (\"downgrade\" to only pay out the small amount, \"challenge\" to require a solved proof-of-work challenge or \"block\")"`
+			URLs    []string `name:"urls" default:"https://check.torproject.org/torbulkexitlist" usage:"the URLs of the lists of Tor exit nodes (plain text, CSV or JSON)"`
+		}
+		Datacenter struct {
+			Enabled bool     `default:"false" usage:"whether a policy is applied to requests from IP ranges of cloud providers"`
+			Action  string   `default:"downgrade" usage:"the action for requests from cloud providers (\"downgrade\", \"challenge\" or \"block\")"`
+			URLs    []string `name:"urls" default:"https://ip-ranges.amazonaws.com/ip-ranges.json,https://www.gstatic.com/ipranges/cloud.json" usage:"the URLs of the lists of IP ranges of cloud providers (plain text, CSV or JSON)"`
+		}
+	}
+	RiskScoring struct {
+		Enabled        bool          `default:"false" usage:"whether anonymous requests are correlated by IP, subnet, user agent, timing and address reuse to detect Sybil attacks"`
+		Window         time.Duration `default:"1h" usage:"the duration in which previous requests are correlated with new requests"`
+		DowngradeScore int           `default:"50" usage:"the risk score (0-100) from which only the small amount is paid out (0 to disable)"`
+		RejectScore    int           `default:"80" usage:"the risk score (0-100) from which requests are rejected (0 to disable)"`
 	}
 	PoW struct {
 		// the amount of workers used for calculating PoW when sending payloads to the block issuer
@@ -35,5 +303,5 @@ var params = &app.ComponentParams{
 	Params: map[string]any{
 		"faucet": ParamsFaucet,
 	},
-	Masked: nil,
+	Masked: []string{"faucet.redis.password", "faucet.queue.priorityAPIKeys", "faucet.admin.jwtSecret", "faucet.webhooks.secret"},
 }
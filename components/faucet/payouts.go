@@ -0,0 +1,220 @@
+package faucet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/payouts"
+)
+
+const (
+	// the default amount of payouts per page.
+	payoutsPageSizeDefault = 100
+	// the maximum amount of payouts per page.
+	payoutsPageSizeMax = 1000
+
+	// the export format of the payouts.
+	payoutsFormatJSON = "json"
+	payoutsFormatCSV  = "csv"
+
+	// the maximum amount of confirmed transactions whose payouts wait to be written to the payout ledger.
+	payoutsQueueSize = 1000
+)
+
+// payoutsResponse defines the response of a GET RouteAdminPayouts REST API call.
+type payoutsResponse struct {
+	// The payouts of the requested page, newest first.
+	Payouts []*payouts.Record `json:"payouts"`
+	// The total amount of payouts matching the filter.
+	Total int `json:"total"`
+	// The amount of skipped payouts.
+	Offset int `json:"offset"`
+	// The maximum amount of payouts per page.
+	Limit int `json:"limit"`
+}
+
+// payoutRecords converts the payouts of a confirmed transaction into ledger records.
+func payoutRecords(confirmedTx *faucet.ConfirmedTransaction) []*payouts.Record {
+	now := time.Now()
+
+	records := make([]*payouts.Record, 0, len(confirmedTx.Payouts))
+	for _, payout := range confirmedTx.Payouts {
+		records = append(records, &payouts.Record{
+			Timestamp:       now,
+			Address:         payout.Bech32,
+			Type:            string(payout.Type),
+			BaseTokenAmount: payout.BaseTokenAmount,
+			ManaAmount:      payout.ManaAmount,
+			TransactionID:   confirmedTx.TransactionID.ToHex(),
+			BlockID:         confirmedTx.BlockID.ToHex(),
+			Tag:             payout.Tag,
+			RemoteIP:        payout.RemoteIP,
+			EnqueuedAt:      payout.EnqueuedAt,
+		})
+	}
+
+	return records
+}
+
+// setupPayoutLedger records the payouts of the confirmed transactions in the payout ledger.
+// The event is triggered while the faucet holds its lock, so the records are written by a background worker.
+func setupPayoutLedger() {
+	if deps.PayoutStore == nil {
+		return
+	}
+
+	queue := make(chan []*payouts.Record, payoutsQueueSize)
+
+	deps.Faucet.Events.TransactionConfirmed.Hook(func(confirmedTx *faucet.ConfirmedTransaction) {
+		if len(confirmedTx.Payouts) == 0 {
+			return
+		}
+
+		// the records are created here, so their timestamp is the time of the confirmation
+		select {
+		case queue <- payoutRecords(confirmedTx):
+		default:
+			Component.LogWarnf("failed to record payouts of transaction %s: the queue of the payout ledger is full", confirmedTx.TransactionID.ToHex())
+		}
+	})
+
+	addPayouts := func(records []*payouts.Record) {
+		if err := deps.PayoutStore.Add(records...); err != nil {
+			Component.LogWarnf("failed to record payouts of transaction %s: %s", records[0].TransactionID, err)
+		}
+	}
+
+	// create a background worker that writes the payouts to the ledger and closes it after the faucet was stopped
+	if err := Component.Daemon().BackgroundWorker("Faucet[Payouts]", func(ctx context.Context) {
+		for {
+			select {
+			case records := <-queue:
+				addPayouts(records)
+			case <-ctx.Done():
+				// the faucet was stopped before, so the remaining payouts are written before the ledger is closed
+				for len(queue) > 0 {
+					addPayouts(<-queue)
+				}
+
+				if err := deps.PayoutStore.Close(); err != nil {
+					Component.LogWarnf("failed to close payout ledger: %s", err)
+				}
+
+				return
+			}
+		}
+	}, daemon.PriorityClosePayouts); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
+
+// parseQueryTime parses a time query parameter given as RFC3339 or unix timestamp.
+func parseQueryTime(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if unixTime, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unixTime, 0), nil
+	}
+
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter \"%s\", expected RFC3339 or unix timestamp", name))
+	}
+
+	return parsedTime, nil
+}
+
+// parseQueryInt parses a non-negative integer query parameter.
+func parseQueryInt(c echo.Context, name string, defaultValue int) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsedValue, err := strconv.Atoi(value)
+	if err != nil || parsedValue < 0 {
+		return 0, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter \"%s\", expected a non-negative integer", name))
+	}
+
+	return parsedValue, nil
+}
+
+// queryPayouts returns the payouts of the ledger matching the query parameters as JSON or CSV.
+func queryPayouts(c echo.Context) error {
+	if deps.PayoutStore == nil {
+		return faucet.NewRequestError(faucet.ErrorCodeNotFound, http.StatusNotFound, "The payout ledger is disabled.")
+	}
+
+	format := c.QueryParam("format")
+	switch format {
+	case "":
+		format = payoutsFormatJSON
+	case payoutsFormatJSON, payoutsFormatCSV:
+	default:
+		return faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid format \"%s\", expected \"%s\" or \"%s\"", format, payoutsFormatJSON, payoutsFormatCSV))
+	}
+
+	from, err := parseQueryTime(c, "from")
+	if err != nil {
+		return err
+	}
+
+	to, err := parseQueryTime(c, "to")
+	if err != nil {
+		return err
+	}
+
+	offset, err := parseQueryInt(c, "offset", 0)
+	if err != nil {
+		return err
+	}
+
+	// the CSV export contains all matching payouts by default
+	limitDefault := payoutsPageSizeDefault
+	if format == payoutsFormatCSV {
+		limitDefault = 0
+	}
+
+	limit, err := parseQueryInt(c, "limit", limitDefault)
+	if err != nil {
+		return err
+	}
+	if format == payoutsFormatJSON && (limit == 0 || limit > payoutsPageSizeMax) {
+		limit = payoutsPageSizeMax
+	}
+
+	records, total := deps.PayoutStore.Query(&payouts.Filter{
+		Address:       c.QueryParam("address"),
+		TransactionID: c.QueryParam("transactionId"),
+		From:          from,
+		To:            to,
+		Offset:        offset,
+		Limit:         limit,
+	})
+
+	if format == payoutsFormatCSV {
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\"faucet_payouts.csv\"")
+		c.Response().WriteHeader(http.StatusOK)
+
+		return payouts.WriteCSV(c.Response(), records)
+	}
+
+	return httpserver.JSONResponse(c, http.StatusOK, &payoutsResponse{
+		Payouts: records,
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+	})
+}
@@ -0,0 +1,68 @@
+package faucet
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+// acceptsPlainText checks if the client prefers a plain text response over JSON, e.g. "Accept: text/plain".
+// JSON is returned if both are accepted with the same quality.
+func acceptsPlainText(c echo.Context) bool {
+	var plainTextQuality, jsonQuality float64
+	for _, mediaRange := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case echo.MIMETextPlain:
+			plainTextQuality = max(plainTextQuality, quality)
+		case echo.MIMEApplicationJSON, "application/*", "*/*":
+			jsonQuality = max(jsonQuality, quality)
+		}
+	}
+
+	return plainTextQuality > jsonQuality
+}
+
+// contentResponse returns the response as a single line of plain text if the client prefers it, and as JSON otherwise.
+func contentResponse(c echo.Context, statusCode int, response any, plainText func() string) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	if acceptsPlainText(c) {
+		return c.String(statusCode, plainText()+"\n")
+	}
+
+	return httpserver.JSONResponse(c, statusCode, response)
+}
+
+// infoPlainText returns the info of the faucet as space separated "key=value" pairs.
+func infoPlainText(info *faucet.InfoResponse) string {
+	return fmt.Sprintf("address=%s balance=%d tokenName=%s waitingRequests=%d healthy=%t paused=%t",
+		info.Address, info.Balance, info.TokenName, info.WaitingRequests, info.IsHealthy, info.Paused)
+}
+
+// enqueuePlainText returns the result of an enqueued request as space separated "key=value" pairs.
+func enqueuePlainText(response *faucet.EnqueueResponse) string {
+	return fmt.Sprintf("address=%s waitingRequests=%d", response.Address, response.WaitingRequests)
+}
+
+// errorPlainText returns an error response as a single line of plain text.
+func errorPlainText(response *faucet.ErrorResponse) string {
+	return fmt.Sprintf("error=%s message=%s", response.Code, strconv.Quote(response.Message))
+}
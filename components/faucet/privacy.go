@@ -0,0 +1,153 @@
+package faucet
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/runtime/timeutil"
+	"github.com/iotaledger/inx-faucet/pkg/audit"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/payouts"
+)
+
+const (
+	// the interval in which the client metadata in the histories is checked for expiration.
+	privacyCheckInterval = time.Hour
+
+	// the prefix of hashed client metadata, so values are not hashed twice.
+	hashedClientMetadataPrefix = "hash:"
+)
+
+// ClientMetadataAnonymization defines how the client metadata is anonymized after the retention.
+type ClientMetadataAnonymization string
+
+const (
+	// ClientMetadataAnonymizationHash replaces the client metadata with a salted hash,
+	// so requests of the same client can still be correlated.
+	ClientMetadataAnonymizationHash ClientMetadataAnonymization = "hash"
+	// ClientMetadataAnonymizationRemove removes the client metadata.
+	ClientMetadataAnonymizationRemove ClientMetadataAnonymization = "remove"
+)
+
+// ParseClientMetadataAnonymization parses the name of a client metadata anonymization.
+func ParseClientMetadataAnonymization(name string) (ClientMetadataAnonymization, error) {
+	switch anonymization := ClientMetadataAnonymization(name); anonymization {
+	case ClientMetadataAnonymizationHash, ClientMetadataAnonymizationRemove:
+		return anonymization, nil
+	default:
+		return "", ierrors.Errorf("unknown client metadata anonymization \"%s\", expected \"%s\" or \"%s\"", name, ClientMetadataAnonymizationHash, ClientMetadataAnonymizationRemove)
+	}
+}
+
+// clientMetadataAnonymizer hashes or removes client metadata.
+type clientMetadataAnonymizer struct {
+	anonymization ClientMetadataAnonymization
+	salt          []byte
+}
+
+func newClientMetadataAnonymizer(anonymization ClientMetadataAnonymization, salt string) (*clientMetadataAnonymizer, error) {
+	anonymizer := &clientMetadataAnonymizer{
+		anonymization: anonymization,
+		salt:          []byte(salt),
+	}
+
+	if anonymization == ClientMetadataAnonymizationHash && salt == "" {
+		// without a salt, the hashes of IP addresses could easily be reversed
+		anonymizer.salt = make([]byte, 32)
+		if _, err := rand.Read(anonymizer.salt); err != nil {
+			return nil, ierrors.Wrap(err, "failed to generate the salt of the hashed client metadata")
+		}
+	}
+
+	return anonymizer, nil
+}
+
+// anonymize returns the anonymized value and whether it was changed.
+func (a *clientMetadataAnonymizer) anonymize(value string) (string, bool) {
+	if value == "" || strings.HasPrefix(value, hashedClientMetadataPrefix) {
+		return value, false
+	}
+
+	if a.anonymization == ClientMetadataAnonymizationRemove {
+		return "", true
+	}
+
+	hash := sha256.Sum256(append(append([]byte{}, a.salt...), value...))
+
+	return hashedClientMetadataPrefix + hex.EncodeToString(hash[:16]), true
+}
+
+func (a *clientMetadataAnonymizer) anonymizePayoutRecord(record *payouts.Record) bool {
+	var changed bool
+	record.RemoteIP, changed = a.anonymize(record.RemoteIP)
+
+	return changed
+}
+
+func (a *clientMetadataAnonymizer) anonymizeAuditEntry(entry *audit.Entry) bool {
+	var remoteIPChanged, userAgentChanged bool
+	entry.RemoteIP, remoteIPChanged = a.anonymize(entry.RemoteIP)
+	entry.UserAgent, userAgentChanged = a.anonymize(entry.UserAgent)
+
+	return remoteIPChanged || userAgentChanged
+}
+
+// runClientMetadataAnonymization anonymizes the client metadata in the payout ledger and the audit log after the retention.
+func runClientMetadataAnonymization() {
+	if ParamsFaucet.Privacy.ClientMetadataRetention <= 0 || (deps.PayoutStore == nil && deps.AuditLog == nil) {
+		return
+	}
+
+	anonymization, err := ParseClientMetadataAnonymization(ParamsFaucet.Privacy.Anonymization)
+	if err != nil {
+		Component.LogPanic(err.Error())
+	}
+
+	anonymizer, err := newClientMetadataAnonymizer(anonymization, ParamsFaucet.Privacy.HashSalt)
+	if err != nil {
+		Component.LogPanic(err.Error())
+	}
+
+	anonymizeExpiredClientMetadata := func() {
+		before := time.Now().Add(-ParamsFaucet.Privacy.ClientMetadataRetention)
+
+		if deps.PayoutStore != nil {
+			if changed, err := deps.PayoutStore.Anonymize(before, anonymizer.anonymizePayoutRecord); err != nil {
+				Component.LogWarnf("failed to anonymize the client metadata in the payout ledger: %s", err)
+			} else if changed > 0 {
+				Component.LogInfof("anonymized the client metadata of %d payouts", changed)
+			}
+		}
+
+		if deps.AuditLog != nil {
+			if changed, err := deps.AuditLog.Anonymize(before, anonymizer.anonymizeAuditEntry); err != nil {
+				Component.LogWarnf("failed to anonymize the client metadata in the audit log: %s", err)
+			} else if changed > 0 {
+				Component.LogInfof("anonymized the client metadata of %d audit log entries", changed)
+			}
+		}
+	}
+
+	if err := Component.Daemon().BackgroundWorker("Faucet[Privacy]", func(ctx context.Context) {
+		anonymizeExpiredClientMetadata()
+
+		ticker := time.NewTicker(privacyCheckInterval)
+		defer timeutil.CleanupTicker(ticker)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				anonymizeExpiredClientMetadata()
+			}
+		}
+	}, daemon.PriorityStopPrivacy); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
@@ -0,0 +1,157 @@
+package faucet
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/payouts"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// the amount of characters of the data part of an anonymized address that are kept at the start and at the end.
+	anonymizedAddressKeepChars = 6
+)
+
+// recentPayout is a confirmed payout in the public feed.
+type recentPayout struct {
+	// The time the payout was confirmed.
+	Timestamp time.Time `json:"timestamp"`
+	// The bech32 address of the receiver, possibly anonymized.
+	Address string `json:"address"`
+	// The type of the request.
+	Type string `json:"type"`
+	// The amount of base tokens that were paid out.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of mana that was paid out.
+	ManaAmount iotago.Mana `json:"mana"`
+	// The ID of the faucet transaction.
+	TransactionID string `json:"transactionId"`
+}
+
+// recentPayoutsResponse defines the response of a GET RouteFaucetRecentPayouts REST API call.
+type recentPayoutsResponse struct {
+	// The latest confirmed payouts, newest first.
+	Payouts []*recentPayout `json:"payouts"`
+}
+
+// recentPayoutsFeed keeps the latest confirmed payouts in memory.
+type recentPayoutsFeed struct {
+	mutex sync.RWMutex
+	// the payouts, oldest first.
+	payouts  []*recentPayout
+	capacity int
+}
+
+func newRecentPayoutsFeed(capacity int) *recentPayoutsFeed {
+	return &recentPayoutsFeed{
+		payouts:  make([]*recentPayout, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// add adds the given payouts and removes the oldest ones if the capacity is exceeded.
+func (r *recentPayoutsFeed) add(records ...*payouts.Record) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, record := range records {
+		r.payouts = append(r.payouts, &recentPayout{
+			Timestamp:       record.Timestamp,
+			Address:         record.Address,
+			Type:            record.Type,
+			BaseTokenAmount: record.BaseTokenAmount,
+			ManaAmount:      record.ManaAmount,
+			TransactionID:   record.TransactionID,
+		})
+	}
+
+	if overflow := len(r.payouts) - r.capacity; overflow > 0 {
+		r.payouts = append(r.payouts[:0], r.payouts[overflow:]...)
+	}
+}
+
+// latest returns up to count of the latest payouts, newest first.
+func (r *recentPayoutsFeed) latest(count int, anonymize bool) []*recentPayout {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*recentPayout, 0, min(count, len(r.payouts)))
+	for i := len(r.payouts) - 1; i >= 0 && len(result) < count; i-- {
+		payout := *r.payouts[i]
+		if anonymize {
+			payout.Address = anonymizeAddress(payout.Address)
+		}
+		result = append(result, &payout)
+	}
+
+	return result
+}
+
+// anonymizeAddress truncates the data part of a bech32 address, e.g. "rms1qz3k4g...7ra9f2".
+func anonymizeAddress(bech32Addr string) string {
+	// the human readable part is separated by the last "1"
+	separatorIndex := -1
+	for i := len(bech32Addr) - 1; i >= 0; i-- {
+		if bech32Addr[i] == '1' {
+			separatorIndex = i
+			break
+		}
+	}
+
+	data := bech32Addr[separatorIndex+1:]
+	if len(data) <= 2*anonymizedAddressKeepChars {
+		return bech32Addr
+	}
+
+	return bech32Addr[:separatorIndex+1] + data[:anonymizedAddressKeepChars] + "..." + data[len(data)-anonymizedAddressKeepChars:]
+}
+
+// recentPayouts is the public feed of the latest confirmed payouts.
+// it is nil if the feed is disabled.
+var recentPayouts *recentPayoutsFeed
+
+// setupRecentPayoutsFeed creates the feed of the latest confirmed payouts and fills it from the payout ledger.
+func setupRecentPayoutsFeed() {
+	if ParamsFaucet.RecentPayouts.MaxCount <= 0 {
+		return
+	}
+
+	recentPayouts = newRecentPayoutsFeed(ParamsFaucet.RecentPayouts.MaxCount)
+
+	if deps.PayoutStore != nil {
+		records, _ := deps.PayoutStore.Query(&payouts.Filter{Limit: ParamsFaucet.RecentPayouts.MaxCount})
+
+		// the records are returned newest first
+		for i := len(records) - 1; i >= 0; i-- {
+			recentPayouts.add(records[i])
+		}
+	}
+
+	deps.Faucet.Events.TransactionConfirmed.Hook(func(confirmedTx *faucet.ConfirmedTransaction) {
+		recentPayouts.add(payoutRecords(confirmedTx)...)
+	})
+}
+
+// getRecentPayouts returns the latest confirmed payouts.
+func getRecentPayouts(c echo.Context) (*recentPayoutsResponse, error) {
+	count, err := parseQueryInt(c, "count", ParamsFaucet.RecentPayouts.MaxCount)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 || count > ParamsFaucet.RecentPayouts.MaxCount {
+		count = ParamsFaucet.RecentPayouts.MaxCount
+	}
+
+	if recentPayouts == nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeNotFound, http.StatusNotFound, "The recent payouts feed is disabled.")
+	}
+
+	return &recentPayoutsResponse{
+		Payouts: recentPayouts.latest(count, ParamsFaucet.RecentPayouts.AnonymizeAddresses),
+	}, nil
+}
@@ -0,0 +1,231 @@
+package faucet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+const (
+	// the marker of a queued address and the counter of the requests of an IP address expire after this duration,
+	// so addresses are released even if the issuing instance crashed.
+	redisQueuedRequestTTL = time.Hour
+)
+
+var (
+	// marks the address as queued with the originating IP address as value and counts the request for the IP address.
+	// returns -1 if the IP address has too many requests, 0 if the address is already queued and 1 if the request was added.
+	redisPushRequestScript = redis.NewScript(`if ARGV[1] ~= "" and tonumber(ARGV[3]) > 0 and tonumber(redis.call("GET", KEYS[2]) or "0") >= tonumber(ARGV[3]) then return -1 end
+if not redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return 0 end
+if ARGV[1] ~= "" then redis.call("INCR", KEYS[2]) redis.call("PEXPIRE", KEYS[2], ARGV[2]) end
+return 1`)
+	// removes the marker of the address and the request from the counter of its originating IP address.
+	redisReleaseRequestScript = redis.NewScript(`local remoteIP = redis.call("GET", KEYS[1])
+if not remoteIP then return 0 end
+redis.call("DEL", KEYS[1])
+if remoteIP ~= "" and redis.call("DECR", ARGV[1] .. remoteIP) <= 0 then redis.call("DEL", ARGV[1] .. remoteIP) end
+return 1`)
+)
+
+// redisSharedQueue is a faucet.SharedQueue that is stored in redis.
+type redisSharedQueue struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+var _ faucet.SharedQueue = &redisSharedQueue{}
+
+func newRedisSharedQueue(client *redis.Client, keyPrefix string) *redisSharedQueue {
+	return &redisSharedQueue{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (q *redisSharedQueue) queueKey() string {
+	return q.keyPrefix + ":queue"
+}
+
+func (q *redisSharedQueue) queuedKey(bech32Addr string) string {
+	return q.keyPrefix + ":queued:" + bech32Addr
+}
+
+func (q *redisSharedQueue) pendingPerIPKey(remoteIP string) string {
+	return q.keyPrefix + ":pendingPerIP:" + remoteIP
+}
+
+func (q *redisSharedQueue) Push(ctx context.Context, request *faucet.SharedRequest, maxPendingRequestsPerIP int) (bool, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return false, err
+	}
+
+	// the marker of the address is used to deduplicate requests across all instances,
+	// the counter of the IP address limits the unconfirmed requests per IP address across all instances
+	result, err := redisPushRequestScript.Run(ctx, q.client, []string{q.queuedKey(request.Bech32), q.pendingPerIPKey(request.RemoteIP)},
+		request.RemoteIP, redisQueuedRequestTTL.Milliseconds(), maxPendingRequestsPerIP).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	switch result {
+	case -1:
+		return false, faucet.ErrTooManyPendingRequestsPerIP
+	case 0:
+		// address is already in the queue
+		return false, nil
+	}
+
+	if err := q.client.RPush(ctx, q.queueKey(), data).Err(); err != nil {
+		_ = q.Release(ctx, request.Bech32)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (q *redisSharedQueue) Pop(ctx context.Context, timeout time.Duration) (*faucet.SharedRequest, error) {
+	// the timeout of BLPOP has a resolution of seconds
+	values, err := q.client.BLPop(ctx, max(timeout, time.Second), q.queueKey()).Result()
+	if err != nil {
+		if ierrors.Is(err, redis.Nil) {
+			// timeout reached
+			//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if len(values) != 2 {
+		return nil, ierrors.Errorf("unexpected reply of BLPOP: %v", values)
+	}
+
+	request := &faucet.SharedRequest{}
+	if err := json.Unmarshal([]byte(values[1]), request); err != nil {
+		return nil, ierrors.Wrap(err, "failed to parse request of the shared queue")
+	}
+
+	return request, nil
+}
+
+func (q *redisSharedQueue) Requeue(ctx context.Context, request *faucet.SharedRequest) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return q.client.LPush(ctx, q.queueKey(), data).Err()
+}
+
+func (q *redisSharedQueue) Release(ctx context.Context, bech32Addr string) error {
+	return redisReleaseRequestScript.Run(ctx, q.client, []string{q.queuedKey(bech32Addr)}, q.pendingPerIPKey("")).Err()
+}
+
+func (q *redisSharedQueue) Len(ctx context.Context) (int, error) {
+	length, err := q.client.LLen(ctx, q.queueKey()).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(length), nil
+}
+
+// redisLeaderLease is a faucet.LeaderLease that is stored in redis.
+type redisLeaderLease struct {
+	client     *redis.Client
+	key        string
+	instanceID string
+}
+
+var _ faucet.LeaderLease = &redisLeaderLease{}
+
+var (
+	// acquires the lease if it is free or renews it if it is held by this instance.
+	redisAcquireLeaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) and 1 or 0`)
+	// releases the lease if it is held by this instance.
+	redisReleaseLeaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+)
+
+func newRedisLeaderLease(client *redis.Client, keyPrefix string, instanceID string) *redisLeaderLease {
+	return &redisLeaderLease{
+		client:     client,
+		key:        keyPrefix + ":leader",
+		instanceID: instanceID,
+	}
+}
+
+func (l *redisLeaderLease) Acquire(ctx context.Context, duration time.Duration) (bool, error) {
+	acquired, err := redisAcquireLeaseScript.Run(ctx, l.client, []string{l.key}, l.instanceID, duration.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return acquired == 1, nil
+}
+
+func (l *redisLeaderLease) Release(ctx context.Context) error {
+	return redisReleaseLeaseScript.Run(ctx, l.client, []string{l.key}, l.instanceID).Err()
+}
+
+const (
+	// the secret of the challenges is created by the first faucet instance.
+	redisChallengeSecretLength = 32
+	// the used challenges are kept a bit longer than they are valid, so a clock skew between the instances doesn't allow to reuse them.
+	redisUsedChallengeSkew = time.Minute
+)
+
+// redisChallengeStore is a faucet.ChallengeStore that is stored in redis,
+// so the challenges issued by one faucet instance are accepted by all others, but only once.
+type redisChallengeStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+var _ faucet.ChallengeStore = &redisChallengeStore{}
+
+func newRedisChallengeStore(client *redis.Client, keyPrefix string) *redisChallengeStore {
+	return &redisChallengeStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *redisChallengeStore) secretKey() string {
+	return s.keyPrefix + ":secret"
+}
+
+func (s *redisChallengeStore) usedKey(challenge string) string {
+	return s.keyPrefix + ":used:" + challenge
+}
+
+func (s *redisChallengeStore) Secret(ctx context.Context) ([]byte, error) {
+	secret := make([]byte, redisChallengeSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, ierrors.Wrap(err, "failed to create challenge secret")
+	}
+
+	// the secret is only set if no other instance created it before
+	if err := s.client.SetNX(ctx, s.secretKey(), hex.EncodeToString(secret), 0).Err(); err != nil {
+		return nil, err
+	}
+
+	storedSecret, err := s.client.Get(ctx, s.secretKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(storedSecret)
+}
+
+func (s *redisChallengeStore) Use(ctx context.Context, challenge string, expiresAt time.Time) (bool, error) {
+	return s.client.SetNX(ctx, s.usedKey(challenge), "", max(time.Until(expiresAt), 0)+redisUsedChallengeSkew).Result()
+}
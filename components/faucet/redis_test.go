@@ -0,0 +1,46 @@
+package faucet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisChallengeStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	store1 := newRedisChallengeStore(client, "faucet:powChallenge")
+	store2 := newRedisChallengeStore(client, "faucet:powChallenge")
+
+	// the secret is created by the first instance and used by all others
+	secret1, err := store1.Secret(ctx)
+	require.NoError(t, err)
+	require.Len(t, secret1, redisChallengeSecretLength)
+
+	secret2, err := store2.Secret(ctx)
+	require.NoError(t, err)
+	require.Equal(t, secret1, secret2)
+
+	// a challenge can only be used once across all instances
+	expiresAt := time.Now().Add(time.Minute)
+	unused, err := store1.Use(ctx, "challenge", expiresAt)
+	require.NoError(t, err)
+	require.True(t, unused)
+
+	unused, err = store2.Use(ctx, "challenge", expiresAt)
+	require.NoError(t, err)
+	require.False(t, unused)
+
+	// the used challenge is kept until it expires
+	server.FastForward(time.Minute)
+	require.True(t, server.Exists("faucet:powChallenge:used:challenge"))
+	server.FastForward(redisUsedChallengeSkew + time.Second)
+	require.False(t, server.Exists("faucet:powChallenge:used:challenge"))
+}
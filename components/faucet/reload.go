@@ -0,0 +1,170 @@
+package faucet
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/ratelimit"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// reloadMutex prevents concurrent reloads of the parameters.
+var reloadMutex sync.Mutex
+
+// rateLimitResponse are the limits of a rate limiter.
+type rateLimitResponse struct {
+	// The period for rate limiting.
+	Period string `json:"period"`
+	// The maximum number of requests per period.
+	MaxRequests int `json:"maxRequests"`
+}
+
+// reloadResponse defines the response of a POST RouteAdminReload REST API call.
+type reloadResponse struct {
+	// The runtime parameters after the reload.
+	Parameters *runtimeParameters `json:"parameters"`
+	// The limits of the rate limiters by their name.
+	RateLimits map[string]*rateLimitResponse `json:"rateLimits,omitempty"`
+	// The amount of API keys that grant a higher priority in the queue.
+	PriorityAPIKeys int `json:"priorityAPIKeys"`
+}
+
+// runReloadOnSignal reloads the parameters every time the process receives a SIGHUP.
+func runReloadOnSignal(ctx context.Context) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGHUP)
+	defer signal.Stop(signalChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signalChan:
+			Component.LogInfo("received SIGHUP, reloading the faucet parameters")
+			if _, err := reloadParameters(); err != nil {
+				Component.LogWarnf("failed to reload the faucet parameters: %s", err)
+			}
+		}
+	}
+}
+
+// reloadParameters loads the config file, the environment variables and the command line flags again
+// and applies the amounts, the rate limits and the priority API keys without restarting the faucet.
+// the bind addresses and the features that are set up on startup are not reloaded.
+func reloadParameters() (*reloadResponse, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	config := Component.App().Config()
+
+	if err := config.LoadFile(*deps.AppConfigFilePath); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, ierrors.Wrapf(err, "failed to load config file %s", *deps.AppConfigFilePath).Error())
+		}
+		Component.LogInfof("config file %s not found, only reloading the environment variables", *deps.AppConfigFilePath)
+	}
+	if err := config.LoadEnvironmentVars(""); err != nil {
+		return nil, ierrors.Wrap(err, "failed to load environment variables")
+	}
+	// the flags take precedence over the config file and the environment variables
+	if err := config.LoadFlagSet(Component.App().FlagSet()); err != nil {
+		return nil, ierrors.Wrap(err, "failed to load command line flags")
+	}
+
+	durationParam := func(parameter *time.Duration) time.Duration {
+		return config.Duration(config.GetParameterPath(parameter))
+	}
+	intParam := func(parameter *int) int {
+		return config.Int(config.GetParameterPath(parameter))
+	}
+	uint64Param := func(parameter *uint64) uint64 {
+		return uint64(config.Int64(config.GetParameterPath(parameter)))
+	}
+
+	params := &faucet.RuntimeParameters{
+		BaseTokenAmount:          iotago.BaseToken(uint64Param(&ParamsFaucet.BaseTokenAmount)),
+		BaseTokenAmountSmall:     iotago.BaseToken(uint64Param(&ParamsFaucet.BaseTokenAmountSmall)),
+		BaseTokenAmountMaxTarget: iotago.BaseToken(uint64Param(&ParamsFaucet.BaseTokenAmountMaxTarget)),
+		ManaAmount:               iotago.Mana(uint64Param(&ParamsFaucet.ManaAmount)),
+		BatchTimeout:             durationParam(&ParamsFaucet.BatchTimeout),
+	}
+	if err := deps.Faucet.SetRuntimeParameters(params); err != nil {
+		return nil, err
+	}
+
+	// the parameters that were changed via the admin API keep their precedence
+	if err := loadRuntimeParameters(deps.Faucet); err != nil {
+		Component.LogWarnf("failed to apply the persisted faucet runtime parameters: %s", err)
+	}
+
+	// the same applies to the address lists
+	if err := loadAddressLists(deps.Faucet, configuredAddressLists(
+		config.Strings(config.GetParameterPath(&ParamsFaucet.AddressLists.Allow)),
+		config.Strings(config.GetParameterPath(&ParamsFaucet.AddressLists.Deny)),
+		iotago.BaseToken(uint64Param(&ParamsFaucet.AddressLists.AllowBaseTokenAmount)),
+	)); err != nil {
+		Component.LogWarnf("failed to reload the address lists: %s", err)
+	}
+
+	response := &reloadResponse{
+		Parameters: getRuntimeParameters(),
+		RateLimits: make(map[string]*rateLimitResponse),
+	}
+
+	reloadRateLimits := func(name string, limiter ratelimit.Limiter, period time.Duration, maxRequests int, maxBurst int) {
+		if limiter == nil {
+			// the rate limiter is disabled
+			return
+		}
+
+		if err := limiter.SetLimits(period, maxRequests, maxBurst); err != nil {
+			Component.LogWarnf("ignoring invalid %s rate limit: %s", name, err)
+		}
+
+		currentPeriod, currentMaxRequests := limiter.Limits()
+		response.RateLimits[name] = &rateLimitResponse{
+			Period:      currentPeriod.String(),
+			MaxRequests: currentMaxRequests,
+		}
+	}
+
+	reloadRateLimits("api", apiRateLimiter,
+		durationParam(&ParamsFaucet.RateLimit.Period),
+		intParam(&ParamsFaucet.RateLimit.MaxRequests),
+		intParam(&ParamsFaucet.RateLimit.MaxBurst),
+	)
+	reloadRateLimits("global", globalRateLimiter,
+		durationParam(&ParamsFaucet.RateLimit.Global.Period),
+		intParam(&ParamsFaucet.RateLimit.Global.MaxRequests),
+		intParam(&ParamsFaucet.RateLimit.Global.MaxBurst),
+	)
+	reloadRateLimits("allotment", allotmentRateLimiter,
+		durationParam(&ParamsFaucet.Allotment.RateLimit.Period),
+		intParam(&ParamsFaucet.Allotment.RateLimit.MaxRequests),
+		intParam(&ParamsFaucet.Allotment.RateLimit.MaxBurst),
+	)
+	reloadRateLimits("github", githubRateLimiter,
+		durationParam(&ParamsFaucet.GitHubOIDC.RateLimit.Period),
+		intParam(&ParamsFaucet.GitHubOIDC.RateLimit.MaxRequests),
+		intParam(&ParamsFaucet.GitHubOIDC.RateLimit.MaxBurst),
+	)
+
+	keys := config.Strings(config.GetParameterPath(&ParamsFaucet.Queue.PriorityAPIKeys))
+	priorityAPIKeys.Store(&keys)
+	for _, key := range keys {
+		if key != "" {
+			response.PriorityAPIKeys++
+		}
+	}
+
+	Component.LogInfof("reloaded the faucet parameters, baseTokenAmount: %d, manaAmount: %d, priority API keys: %d", response.Parameters.BaseTokenAmount, response.Parameters.ManaAmount, response.PriorityAPIKeys)
+
+	return response, nil
+}
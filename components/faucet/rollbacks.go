@@ -0,0 +1,34 @@
+package faucet
+
+import (
+	"context"
+
+	"github.com/iotaledger/inx-app/pkg/nodebridge"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+)
+
+// runRollbackDetection checks the accepted faucet transactions for rollbacks whenever the latest commitment changes.
+func runRollbackDetection() {
+	if err := Component.Daemon().BackgroundWorker("Faucet[Rollbacks]", func(ctx context.Context) {
+		// the checks are done in the worker, so the commitment events of the node bridge are not blocked
+		commitmentChanged := make(chan struct{}, 1)
+		hook := deps.NodeBridge.Events().LatestCommitmentChanged.Hook(func(_ *nodebridge.Commitment) {
+			select {
+			case commitmentChanged <- struct{}{}:
+			default:
+			}
+		})
+		defer hook.Unhook()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-commitmentChanged:
+				deps.Faucet.CheckUnfinalizedTransactions(ctx)
+			}
+		}
+	}, daemon.PriorityStopFaucetRollbacks); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
@@ -1,51 +1,271 @@
 package faucet
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"golang.org/x/time/rate"
 
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/oidc"
+	"github.com/iotaledger/inx-faucet/pkg/ratelimit"
+)
+
+const (
+	// headerAPIKey is the header that contains the API key of the client.
+	headerAPIKey = "X-API-Key"
+	// headerIdempotencyKey is the header that contains the optional key the client identifies its request with.
+	headerIdempotencyKey = "Idempotency-Key"
 )
 
 const (
 	// RouteFaucetHealth is the route to get the health info of the faucet.
 	RouteFaucetHealth = "/health"
 
+	// RouteFaucetLive is the liveness probe of the faucet.
+	// GET returns 200 as long as the process is able to serve HTTP requests.
+	RouteFaucetLive = "/healthz"
+
+	// RouteFaucetReady is the readiness probe of the faucet.
+	// GET returns 200 if the faucet is ready to serve requests, 503 otherwise, together with the result of every check.
+	RouteFaucetReady = "/readyz"
+
 	// RouteFaucetInfo is the route to give info about the faucet address.
 	// GET returns address, balance, bech32Hrp and tokenName of the faucet.
 	RouteFaucetInfo = "/info"
 
+	// RouteFaucetAddressQRCode is the route to get the deposit address of the faucet as QR code.
+	// GET returns a PNG image, the optional "scale" query parameter defines the amount of pixels per module.
+	RouteFaucetAddressQRCode = "/address/qr.png"
+
 	// RouteFaucetEnqueue is the route to tell the faucet to pay out some funds to the given address.
-	// POST enqueues a new request.
+	// POST enqueues a new request, it is sent as JSON, form-encoded or in the "address" query parameter.
 	RouteFaucetEnqueue = "/enqueue"
+
+	// RouteFaucetEnqueueGitHub is the route for GitHub Actions workflows to request funds.
+	// POST enqueues a new request, the OIDC token of the workflow is sent as bearer token.
+	RouteFaucetEnqueueGitHub = "/enqueue/github"
+
+	// RouteFaucetEnqueueBatch is the route to pay out funds to many addresses in one call.
+	// POST enqueues all requests and returns the result per address, it requires a priority API key or an admin bearer token.
+	RouteFaucetEnqueueBatch = "/enqueue/batch"
+
+	// RouteFaucetPreview is the route to preview what a request for an address would receive.
+	// GET returns the amounts and the reasons for the "address" query parameter without enqueueing a request.
+	RouteFaucetPreview = "/preview"
+
+	// RouteFaucetRequest is the route to manage the queued request of an address.
+	// GET returns the processing state of the request until its transaction is accepted.
+	// DELETE removes the request from the queue if it is not part of a transaction yet,
+	// the idempotency key of the request or a signed ownership challenge may be required.
+	RouteFaucetRequest = "/requests/:" + ParameterAddress
+
+	// RouteFaucetSubscriptions is the route to register an address for recurring payouts.
+	// POST adds a subscription and returns its ID, it requires a priority API key or an admin bearer token.
+	RouteFaucetSubscriptions = "/subscriptions"
+
+	// RouteFaucetSubscription is the route to manage a single subscription.
+	// DELETE removes the subscription, it requires a priority API key or an admin bearer token.
+	RouteFaucetSubscription = "/subscriptions/:" + ParameterSubscriptionID
+
+	// RouteFaucetChallenge is the route to get a new proof-of-work challenge.
+	// GET returns the challenge, the difficulty and the expiry time.
+	RouteFaucetChallenge = "/challenge"
+
+	// RouteFaucetOwnershipChallenge is the route to get a new challenge that proves the ownership of the target address.
+	// GET returns the challenge and the expiry time.
+	RouteFaucetOwnershipChallenge = "/challenge/ownership"
+
+	// RouteFaucetRecentPayouts is the route to get the latest confirmed payouts.
+	// GET returns up to "count" payouts, newest first.
+	RouteFaucetRecentPayouts = "/payouts/recent"
+
+	// RouteFaucetDonations is the route to get the outputs that were sent to the faucet address by others.
+	// GET returns the totals and the latest donations, newest first.
+	RouteFaucetDonations = "/donations"
+
+	// RouteFaucetStats is the route to get the statistics of the confirmed payouts.
+	// GET returns the totals and the time series of the last 24 hours and 7 days.
+	RouteFaucetStats = "/stats"
 )
 
+// rateLimiters creates all rate limiters of the faucet.
+// if redis is enabled, their state is shared between all faucet instances,
+// otherwise it is kept in memory and is part of the faucet snapshot.
+var rateLimiters *ratelimit.Manager
+
+// allotmentRateLimiter limits the allotment requests per client and per account.
+// it is nil if the rate limiting of allotment requests is disabled.
+var allotmentRateLimiter ratelimit.Limiter
+
+// apiRateLimiter limits the requests to the API per IP or per API key of the client.
+// it is nil if the rate limiting is disabled.
+var apiRateLimiter ratelimit.Limiter
+
+// globalRateLimiter limits the requests to the API of all clients together.
+// it is nil if the global rate limiting is disabled.
+var globalRateLimiter ratelimit.Limiter
+
+func newRateLimitedError(period time.Duration, maxRequests int) error {
+	return faucet.NewRequestError(faucet.ErrorCodeRateLimited, http.StatusTooManyRequests, "Too many requests. Please try again later!").
+		WithRetryAfter(time.Duration(float64(period) / float64(maxRequests)))
+}
+
+// checkAllotmentRateLimit checks the separate rate limit of allotment requests for the client and the requested account.
+func checkAllotmentRateLimit(c echo.Context, request *faucet.EnqueueRequest) error {
+	if allotmentRateLimiter == nil || request.Type != faucet.RequestTypeAllotment {
+		return nil
+	}
+
+	// bech32 addresses are case-insensitive, so the account is limited by its lower case encoding
+	for _, identifier := range []string{ratelimit.Identifier(ratelimit.ScopeIP, c.RealIP()), "account:" + strings.ToLower(request.Address)} {
+		allowed, err := allotmentRateLimiter.Allow(identifier)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return newRateLimitedError(allotmentRateLimiter.Limits())
+		}
+	}
+
+	return nil
+}
+
 func enforceMaxOneDotPerURL(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		if strings.Count(c.Request().RequestURI, "..") != 0 {
-			return c.String(http.StatusForbidden, "path not allowed")
+			return faucet.NewRequestError(faucet.ErrorCodeForbidden, http.StatusForbidden, "path not allowed")
 		}
 
 		return next(c)
 	}
 }
 
+// errorHandler returns all errors to the client in the faucet error response format.
+// clients that prefer plain text receive the error as a single line.
+func errorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	reqErr := faucet.AsRequestError(err)
+	logRequestError(c, err, reqErr)
+
+	response := faucet.ErrorResponseEnvelope{
+		Error: faucet.ErrorResponse{
+			Code:    reqErr.Code,
+			Message: reqErr.Message,
+			Details: reqErr.Details,
+		},
+	}
+
+	if reqErr.RetryAfter > 0 {
+		retryAfterSeconds := int(math.Ceil(reqErr.RetryAfter.Seconds()))
+		response.Error.RetryAfter = retryAfterSeconds
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds))
+	}
+
+	switch {
+	case c.Request().Method == http.MethodHead:
+		err = c.NoContent(reqErr.StatusCode)
+	case acceptsPlainText(c):
+		err = c.String(reqErr.StatusCode, errorPlainText(&response.Error)+"\n")
+	default:
+		err = c.JSON(reqErr.StatusCode, response)
+	}
+	if err != nil {
+		Component.LogWarnf("failed to send error response: %s", err)
+	}
+}
+
+// logRequestError logs the details of an error that are not passed to the client.
+func logRequestError(c echo.Context, err error, reqErr *faucet.RequestError) {
+	if reqErr.Code == faucet.ErrorCodeInternal {
+		Component.LogWarnf("request to %s failed: %s", c.Request().URL.Path, err)
+
+		return
+	}
+
+	var httpErr *echo.HTTPError
+	if ierrors.As(err, &httpErr) && httpErr.Internal != nil {
+		Component.LogDebugf("request to %s failed: %s", c.Request().URL.Path, httpErr.Internal)
+	}
+}
+
+// priorityAPIKeys are the API keys that grant a higher priority in the queue.
+// they are replaced if the parameters are reloaded.
+var priorityAPIKeys atomic.Pointer[[]string]
+
+// apiRateLimitIdentifier returns the identifier the requests of a client are rate limited by.
+// clients with a priority API key are limited per key, so they don't share the limit with other clients behind the same IP.
+func apiRateLimitIdentifier(c echo.Context) string {
+	if hasPriorityAPIKey(c) {
+		// the key itself is not stored in the rate limiter state
+		keyHash := sha256.Sum256([]byte(c.Request().Header.Get(headerAPIKey)))
+
+		return ratelimit.Identifier(ratelimit.ScopeKey, hex.EncodeToString(keyHash[:8]))
+	}
+
+	return ratelimit.Identifier(ratelimit.ScopeIP, c.RealIP())
+}
+
+// rateLimiterMiddleware applies the given rate limiter to the requests that are not skipped.
+func rateLimiterMiddleware(limiter ratelimit.Limiter, skipper middleware.Skipper, identifierExtractor middleware.Extractor) echo.MiddlewareFunc {
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Skipper:             skipper,
+		Store:               limiter,
+		IdentifierExtractor: identifierExtractor,
+		DenyHandler: func(_ echo.Context, _ string, _ error) error {
+			return newRateLimitedError(limiter.Limits())
+		},
+	})
+}
+
+// hasPriorityAPIKey checks if the client sent one of the API keys that grant a higher priority in the queue.
+func hasPriorityAPIKey(c echo.Context) bool {
+	apiKey := c.Request().Header.Get(headerAPIKey)
+	if apiKey == "" {
+		return false
+	}
+
+	for _, priorityAPIKey := range *priorityAPIKeys.Load() {
+		if priorityAPIKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(priorityAPIKey)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
 func addFaucetOutputToQueue(c echo.Context) (*faucet.EnqueueResponse, error) {
-	request := &faucet.EnqueueRequest{}
-	if err := c.Bind(request); err != nil {
-		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "Invalid Request! Error: %s", err)
+	request, err := bindEnqueueRequest(c)
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := deps.Faucet.Enqueue(request.Address)
+	if err := checkAllotmentRateLimit(c, request); err != nil {
+		return nil, err
+	}
+
+	response, err := deps.Faucet.Enqueue(request, &faucet.ClientMetadata{
+		RemoteIP:       c.RealIP(),
+		UserAgent:      c.Request().UserAgent(),
+		Header:         c.Request().Header,
+		Authenticated:  hasPriorityAPIKey(c),
+		APIKey:         c.Request().Header.Get(headerAPIKey),
+		IdempotencyKey: c.Request().Header.Get(headerIdempotencyKey),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -53,12 +273,39 @@ func addFaucetOutputToQueue(c echo.Context) (*faucet.EnqueueResponse, error) {
 	return response, nil
 }
 
-func setupRoutes(e *echo.Echo) {
+// bindEnqueueRequest binds the enqueue request from the query parameters and the JSON or form-encoded body,
+// so simple HTML forms and "curl -X POST .../enqueue?address=..." work as well. The values of the body take precedence.
+func bindEnqueueRequest(c echo.Context) (*faucet.EnqueueRequest, error) {
+	binder := &echo.DefaultBinder{}
+
+	request := &faucet.EnqueueRequest{}
+	if err := binder.BindQueryParams(c, request); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	if err := binder.BindBody(c, request); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	return request, nil
+}
+
+// setupFrontendRoutes serves the embedded faucet website.
+func setupFrontendRoutes(e *echo.Echo) {
 	e.Pre(enforceMaxOneDotPerURL)
 
-	e.Group("/*").Use(frontendMiddleware())
+	e.Group(basePath()).Use(frontendMiddleware())
+}
+
+func setupRoutes(e *echo.Echo) error {
+	e.Pre(enforceMaxOneDotPerURL)
+
+	priorityAPIKeys.Store(&ParamsFaucet.Queue.PriorityAPIKeys)
+
+	// all routes are served under the configured path prefix
+	baseGroup := e.Group(basePath())
 
-	e.GET(RouteFaucetHealth, func(c echo.Context) error {
+	baseGroup.GET(RouteFaucetHealth, func(c echo.Context) error {
 		if !deps.Faucet.IsHealthy() {
 			return c.NoContent(http.StatusServiceUnavailable)
 		}
@@ -66,15 +313,60 @@ func setupRoutes(e *echo.Echo) {
 		return c.NoContent(http.StatusOK)
 	})
 
+	baseGroup.GET(RouteFaucetLive, func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	baseGroup.GET(RouteFaucetReady, func(c echo.Context) error {
+		readiness := deps.Faucet.Readiness()
+		if !readiness.Ready {
+			return httpserver.JSONResponse(c, http.StatusServiceUnavailable, readiness)
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, readiness)
+	})
+
 	// Pass all the requests through to the local rest API
-	apiGroup := e.Group("/api")
+	apiGroup := baseGroup.Group("/api")
+
+	var err error
+	rateLimiters = ratelimit.NewManager(deps.RedisClient, ParamsFaucet.Redis.KeyPrefix+":ratelimit", inxRequestTimeout)
+
+	if ParamsFaucet.Allotment.RateLimit.Enabled {
+		if allotmentRateLimiter, err = rateLimiters.New("allotment", ParamsFaucet.Allotment.RateLimit.Period, ParamsFaucet.Allotment.RateLimit.MaxRequests, ParamsFaucet.Allotment.RateLimit.MaxBurst); err != nil {
+			return ierrors.Wrap(err, "failed to create the allotment rate limiter")
+		}
+	}
 
-	if ParamsFaucet.RateLimit.Enabled {
+	if ParamsFaucet.GitHubOIDC.Enabled {
+		if len(ParamsFaucet.GitHubOIDC.Repositories) == 0 {
+			Component.LogWarn("GitHub OIDC endpoint is enabled, but no repositories are allowed to request funds")
+		}
+
+		githubOIDCVerifier = oidc.NewVerifier(oidc.GitHubActionsIssuer, ParamsFaucet.GitHubOIDC.Audience, githubOIDCTimeout)
+		if githubRateLimiter, err = rateLimiters.New("github", ParamsFaucet.GitHubOIDC.RateLimit.Period, ParamsFaucet.GitHubOIDC.RateLimit.MaxRequests, ParamsFaucet.GitHubOIDC.RateLimit.MaxBurst); err != nil {
+			return ierrors.Wrap(err, "failed to create the github rate limiter")
+		}
+	}
+
+	if ParamsFaucet.RateLimit.Enabled || ParamsFaucet.RateLimit.Global.Enabled {
+		apiPath := basePath() + "/api"
 		allowedRoutes := map[string][]string{
 			http.MethodGet: {
-				"/api/info",
+				apiPath + RouteFaucetInfo,
+				apiPath + RouteFaucetAddressQRCode,
+				apiPath + RouteFaucetChallenge,
+				apiPath + RouteFaucetRecentPayouts,
+				apiPath + RouteFaucetStats,
+				apiPath + RouteFaucetDonations,
+				// the status of a request is polled until it is paid out
+				apiPath + strings.TrimSuffix(RouteFaucetRequest, ":"+ParameterAddress),
 			},
 		}
+		if githubOIDCVerifier != nil {
+			// the requests of GitHub Actions workflows are rate limited per repository
+			allowedRoutes[http.MethodPost] = []string{apiPath + RouteFaucetEnqueueGitHub}
+		}
 
 		rateLimiterSkipper := func(context echo.Context) bool {
 			// Check for which route we will skip the rate limiter
@@ -93,22 +385,23 @@ func setupRoutes(e *echo.Echo) {
 			return false
 		}
 
-		rateLimiterConfig := middleware.RateLimiterConfig{
-			Skipper: rateLimiterSkipper,
-			Store: middleware.NewRateLimiterMemoryStoreWithConfig(
-				middleware.RateLimiterMemoryStoreConfig{
-					Rate:      rate.Limit(float64(ParamsFaucet.RateLimit.MaxRequests) / ParamsFaucet.RateLimit.Period.Seconds()),
-					Burst:     ParamsFaucet.RateLimit.MaxBurst,
-					ExpiresIn: 5 * time.Minute,
-				},
-			),
-			IdentifierExtractor: func(ctx echo.Context) (string, error) {
-				id := ctx.RealIP()
+		if ParamsFaucet.RateLimit.Global.Enabled {
+			if globalRateLimiter, err = rateLimiters.New("global", ParamsFaucet.RateLimit.Global.Period, ParamsFaucet.RateLimit.Global.MaxRequests, ParamsFaucet.RateLimit.Global.MaxBurst); err != nil {
+				return ierrors.Wrap(err, "failed to create the global rate limiter")
+			}
+			apiGroup.Use(rateLimiterMiddleware(globalRateLimiter, rateLimiterSkipper, func(_ echo.Context) (string, error) {
+				return ratelimit.Identifier(ratelimit.ScopeGlobal, ""), nil
+			}))
+		}
 
-				return id, nil
-			},
+		if ParamsFaucet.RateLimit.Enabled {
+			if apiRateLimiter, err = rateLimiters.New("api", ParamsFaucet.RateLimit.Period, ParamsFaucet.RateLimit.MaxRequests, ParamsFaucet.RateLimit.MaxBurst); err != nil {
+				return ierrors.Wrap(err, "failed to create the api rate limiter")
+			}
+			apiGroup.Use(rateLimiterMiddleware(apiRateLimiter, rateLimiterSkipper, func(c echo.Context) (string, error) {
+				return apiRateLimitIdentifier(c), nil
+			}))
 		}
-		apiGroup.Use(middleware.RateLimiterWithConfig(rateLimiterConfig))
 	}
 
 	apiGroup.GET(RouteFaucetInfo, func(c echo.Context) error {
@@ -117,32 +410,143 @@ func setupRoutes(e *echo.Echo) {
 			return err
 		}
 
-		return httpserver.JSONResponse(c, http.StatusOK, resp)
+		return contentResponse(c, http.StatusOK, resp, func() string { return infoPlainText(resp) })
 	})
 
+	apiGroup.GET(RouteFaucetAddressQRCode, getAddressQRCode)
+
 	apiGroup.POST(RouteFaucetEnqueue, func(c echo.Context) error {
 		resp, err := addFaucetOutputToQueue(c)
 		if err != nil {
-			// own error handler to have nicer user facing error messages.
-			var statusCode int
-			var message string
-
-			var e *echo.HTTPError
-			if ierrors.As(err, &e) {
-				statusCode = e.Code
-				if ierrors.Is(err, httpserver.ErrInvalidParameter) {
-					message = strings.Replace(err.Error(), ": "+ierrors.Unwrap(err).Error(), "", 1)
-				} else {
-					message = err.Error()
-				}
-			} else {
-				statusCode = http.StatusInternalServerError
-				message = fmt.Sprintf("internal server error. error: %s", err.Error())
+			return err
+		}
+
+		return contentResponse(c, http.StatusAccepted, resp, func() string { return enqueuePlainText(resp) })
+	})
+
+	if githubOIDCVerifier != nil {
+		apiGroup.POST(RouteFaucetEnqueueGitHub, func(c echo.Context) error {
+			resp, err := addGitHubFaucetOutputToQueue(c)
+			if err != nil {
+				return err
+			}
+
+			return contentResponse(c, http.StatusAccepted, resp, func() string { return enqueuePlainText(resp) })
+		})
+	}
+
+	if ParamsFaucet.BatchEnqueue.Enabled {
+		apiGroup.POST(RouteFaucetEnqueueBatch, func(c echo.Context) error {
+			resp, err := addFaucetOutputsToQueue(c)
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusOK, resp)
+		})
+	}
+
+	apiGroup.GET(RouteFaucetPreview, func(c echo.Context) error {
+		resp, err := deps.Faucet.Preview(c.QueryParam("address"))
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	apiGroup.GET(RouteFaucetRequest, func(c echo.Context) error {
+		resp, err := deps.Faucet.RequestStatus(c.Param(ParameterAddress))
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	if ParamsFaucet.CancelRequests.Enabled {
+		apiGroup.DELETE(RouteFaucetRequest, func(c echo.Context) error {
+			resp, err := cancelFaucetRequest(c)
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusOK, resp)
+		})
+	}
+
+	if ParamsFaucet.Subscriptions.Enabled {
+		apiGroup.POST(RouteFaucetSubscriptions, func(c echo.Context) error {
+			if err := authorizeTrustedClient(c, "add subscription"); err != nil {
+				return err
+			}
+
+			resp, err := addSubscription(c)
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusCreated, resp)
+		})
+
+		apiGroup.DELETE(RouteFaucetSubscription, func(c echo.Context) error {
+			if err := authorizeTrustedClient(c, "remove subscription"); err != nil {
+				return err
 			}
 
-			return c.JSON(statusCode, httpserver.HTTPErrorResponseEnvelope{Error: httpserver.HTTPErrorResponse{Code: strconv.Itoa(statusCode), Message: message}})
+			if err := removeSubscription(c); err != nil {
+				return err
+			}
+
+			return c.NoContent(http.StatusNoContent)
+		})
+	}
+
+	apiGroup.GET(RouteFaucetDonations, func(c echo.Context) error {
+		return httpserver.JSONResponse(c, http.StatusOK, getDonations())
+	})
+
+	apiGroup.GET(RouteFaucetRecentPayouts, func(c echo.Context) error {
+		resp, err := getRecentPayouts(c)
+		if err != nil {
+			return err
 		}
 
-		return httpserver.JSONResponse(c, http.StatusAccepted, resp)
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	apiGroup.GET(RouteFaucetStats, func(c echo.Context) error {
+		resp, err := getStats()
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	})
+
+	if deps.PoWChallenger != nil {
+		apiGroup.GET(RouteFaucetChallenge, func(c echo.Context) error {
+			resp, err := deps.PoWChallenger.NewChallenge()
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusOK, resp)
+		})
+	}
+
+	if deps.OwnershipVerifier != nil {
+		apiGroup.GET(RouteFaucetOwnershipChallenge, func(c echo.Context) error {
+			resp, err := deps.OwnershipVerifier.NewChallenge()
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusOK, resp)
+		})
+	}
+
+	setupAdminRoutes(apiGroup)
+
+	return nil
 }
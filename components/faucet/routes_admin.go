@@ -0,0 +1,379 @@
+package faucet
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+const (
+	// headerAuthorization is the header that contains the bearer token of the admin API requests.
+	headerAuthorization = "Authorization"
+	// bearerPrefix is the prefix of the bearer token in the authorization header.
+	bearerPrefix = "Bearer "
+)
+
+const (
+	// RouteAdminDelegations is the route to list the delegation outputs of the faucet.
+	// GET returns the delegation outputs owned by the faucet.
+	RouteAdminDelegations = "/delegations"
+
+	// RouteAdminDelegationsUndelegate is the route to stop all active delegations of the faucet.
+	// POST issues a transaction that sets the end epoch of all active delegation outputs.
+	RouteAdminDelegationsUndelegate = "/delegations/undelegate"
+
+	// RouteAdminDelegationsClaim is the route to claim the funds and rewards of all ended delegations of the faucet.
+	// POST issues a transaction that destroys all ended delegation outputs.
+	RouteAdminDelegationsClaim = "/delegations/claim"
+
+	// RouteAdminSnapshot is the route to export the state of the faucet.
+	// POST returns the queued requests, the pending transaction and the rate limiter state.
+	RouteAdminSnapshot = "/snapshot"
+
+	// RouteAdminRestore is the route to import the state of the faucet.
+	// POST adds the requests of a snapshot to the queue and restores the rate limiter state.
+	RouteAdminRestore = "/restore"
+
+	// RouteAdminPayouts is the route to query the ledger of confirmed payouts.
+	// GET returns the payouts filtered by address, transactionId, from and to, paginated by offset and limit.
+	// The format query parameter selects between "json" and "csv".
+	RouteAdminPayouts = "/payouts"
+
+	// RouteAdminParameters is the route to manage the faucet parameters that can be changed at runtime.
+	// GET returns the current parameters.
+	// PUT changes the given parameters and persists them.
+	RouteAdminParameters = "/parameters"
+
+	// RouteAdminReload is the route to reload the parameters from the config file.
+	// POST applies the reloadable parameters and returns their new values.
+	RouteAdminReload = "/reload"
+
+	// RouteAdminFlush is the route to issue the currently collected requests immediately.
+	// POST stops the current batching of faucet requests.
+	RouteAdminFlush = "/flush"
+
+	// RouteAdminPause is the route to pause the faucet.
+	// POST stops accepting new requests and issuing transactions, the queue is kept.
+	RouteAdminPause = "/pause"
+
+	// RouteAdminResume is the route to resume the faucet after it was paused.
+	// POST accepts new requests and issues transactions again.
+	RouteAdminResume = "/resume"
+
+	// RouteAdminAddressLists is the route to get the allow and deny lists of addresses.
+	// GET returns both lists.
+	RouteAdminAddressLists = "/addresslists"
+
+	// RouteAdminAddressList is the route to manage a list of addresses ("allow" or "deny").
+	// PUT adds an address to the list or replaces its entry and persists the lists.
+	RouteAdminAddressList = "/addresslists/:" + ParameterAddressList
+
+	// RouteAdminAddressListEntry is the route to manage an address on a list.
+	// DELETE removes the address from the list and persists the lists.
+	RouteAdminAddressListEntry = "/addresslists/:" + ParameterAddressList + "/:" + ParameterAddress
+
+	// RouteAdminShadowBans is the route to manage the rules whose matching requests are accepted, but never processed.
+	// GET returns all rules.
+	// POST adds a rule by IP address or CIDR range ("ip"), API key ("apiKey") or address pattern ("address") and persists the rules.
+	RouteAdminShadowBans = "/shadowbans"
+
+	// RouteAdminShadowBan is the route to manage a single shadow-ban rule.
+	// DELETE removes the rule and persists the rules.
+	RouteAdminShadowBan = "/shadowbans/:" + ParameterShadowBanID
+
+	// RouteAdminAirdrops is the route to manage the airdrops that are processed separately from the public queue.
+	// GET returns the progress of all airdrops.
+	// POST schedules the "address,amount" lines of an uploaded CSV file (raw body or "file" form field), "name" and "tag" are optional query parameters.
+	RouteAdminAirdrops = "/airdrops"
+
+	// RouteAdminAirdrop is the route to manage a single airdrop.
+	// GET returns the progress of the airdrop including its single payouts.
+	// DELETE cancels the payouts that were not sent yet.
+	RouteAdminAirdrop = "/airdrops/:" + ParameterAirdropID
+
+	// RouteAdminSubscriptions is the route to manage the addresses that receive a payout in a regular interval.
+	// GET returns all subscriptions, ordered by their next payout.
+	// POST adds a subscription and persists the subscriptions.
+	RouteAdminSubscriptions = "/subscriptions"
+
+	// RouteAdminSubscription is the route to manage a single subscription.
+	// DELETE removes the subscription and persists the subscriptions.
+	RouteAdminSubscription = "/subscriptions/:" + ParameterSubscriptionID
+
+	// RouteAdminRiskAssessments is the route to get the risk scores of the most recent anonymous requests.
+	// GET returns the assessments, the newest first.
+	RouteAdminRiskAssessments = "/risk"
+)
+
+const (
+	// ParameterAddressList is used to identify a list of addresses.
+	ParameterAddressList = "list"
+
+	// ParameterAddress is used to identify an address.
+	ParameterAddress = "address"
+
+	// ParameterShadowBanID is used to identify a shadow-ban rule.
+	ParameterShadowBanID = "id"
+
+	// ParameterAirdropID is used to identify an airdrop.
+	ParameterAirdropID = "id"
+
+	// ParameterSubscriptionID is used to identify a subscription.
+	ParameterSubscriptionID = "id"
+)
+
+// pauseResponse defines the response of a POST RouteAdminPause or RouteAdminResume REST API call.
+type pauseResponse struct {
+	// Whether the faucet is paused.
+	Paused bool `json:"paused"`
+}
+
+const (
+	// flushTimeout is the maximum duration to wait for the faucet to pick up a flush.
+	flushTimeout = 5 * time.Second
+)
+
+func setupAdminRoutes(apiGroup *echo.Group) {
+	if !ParamsFaucet.Admin.Enabled {
+		return
+	}
+
+	adminGroup := apiGroup.Group("/admin")
+
+	// the secret is checked when the component is provided
+	adminGroup.Use(adminAuthMiddleware([]byte(ParamsFaucet.Admin.JWTSecret)))
+
+	adminGroup.GET(RouteAdminDelegations, func(c echo.Context) error {
+		resp, err := deps.Faucet.Delegations()
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.POST(RouteAdminDelegationsUndelegate, func(c echo.Context) error {
+		resp, err := deps.Faucet.Undelegate(c.Request().Context())
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.POST(RouteAdminDelegationsClaim, func(c echo.Context) error {
+		resp, err := deps.Faucet.ClaimDelegations(c.Request().Context())
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.POST(RouteAdminSnapshot, func(c echo.Context) error {
+		return httpserver.JSONResponse(c, http.StatusOK, createStateSnapshot())
+	})
+
+	adminGroup.POST(RouteAdminRestore, func(c echo.Context) error {
+		resp, err := restoreStateSnapshot(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.GET(RouteAdminPayouts, queryPayouts)
+
+	adminGroup.GET(RouteAdminParameters, func(c echo.Context) error {
+		return httpserver.JSONResponse(c, http.StatusOK, getRuntimeParameters())
+	})
+
+	adminGroup.POST(RouteAdminFlush, func(c echo.Context) error {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), flushTimeout)
+		defer cancel()
+
+		if err := deps.Faucet.FlushRequestsWithContext(ctx); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	adminGroup.POST(RouteAdminPause, func(c echo.Context) error {
+		deps.Faucet.Pause()
+
+		return httpserver.JSONResponse(c, http.StatusOK, &pauseResponse{Paused: deps.Faucet.IsPaused()})
+	})
+
+	adminGroup.POST(RouteAdminResume, func(c echo.Context) error {
+		deps.Faucet.Resume()
+
+		return httpserver.JSONResponse(c, http.StatusOK, &pauseResponse{Paused: deps.Faucet.IsPaused()})
+	})
+
+	adminGroup.POST(RouteAdminReload, func(c echo.Context) error {
+		resp, err := reloadParameters()
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.PUT(RouteAdminParameters, func(c echo.Context) error {
+		resp, err := updateRuntimeParameters(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.GET(RouteAdminAddressLists, func(c echo.Context) error {
+		return httpserver.JSONResponse(c, http.StatusOK, deps.Faucet.AddressLists())
+	})
+
+	adminGroup.PUT(RouteAdminAddressList, func(c echo.Context) error {
+		resp, err := addAddressListEntry(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.DELETE(RouteAdminAddressListEntry, func(c echo.Context) error {
+		if err := removeAddressListEntry(c); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	adminGroup.GET(RouteAdminShadowBans, func(c echo.Context) error {
+		return httpserver.JSONResponse(c, http.StatusOK, deps.Faucet.ShadowBanRules())
+	})
+
+	adminGroup.POST(RouteAdminShadowBans, func(c echo.Context) error {
+		resp, err := addShadowBanRule(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusCreated, resp)
+	})
+
+	adminGroup.DELETE(RouteAdminShadowBan, func(c echo.Context) error {
+		if err := removeShadowBanRule(c); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	adminGroup.GET(RouteAdminAirdrops, func(c echo.Context) error {
+		return httpserver.JSONResponse(c, http.StatusOK, deps.Faucet.Airdrops())
+	})
+
+	adminGroup.POST(RouteAdminAirdrops, func(c echo.Context) error {
+		resp, err := startAirdrop(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusCreated, resp)
+	})
+
+	adminGroup.GET(RouteAdminAirdrop, func(c echo.Context) error {
+		resp, err := deps.Faucet.Airdrop(c.Param(ParameterAirdropID))
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	adminGroup.DELETE(RouteAdminAirdrop, func(c echo.Context) error {
+		resp, err := deps.Faucet.CancelAirdrop(c.Param(ParameterAirdropID))
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	if ParamsFaucet.Subscriptions.Enabled {
+		adminGroup.GET(RouteAdminSubscriptions, func(c echo.Context) error {
+			return httpserver.JSONResponse(c, http.StatusOK, deps.Faucet.Subscriptions())
+		})
+
+		adminGroup.POST(RouteAdminSubscriptions, func(c echo.Context) error {
+			resp, err := addSubscription(c)
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusCreated, resp)
+		})
+
+		adminGroup.DELETE(RouteAdminSubscription, func(c echo.Context) error {
+			if err := removeSubscription(c); err != nil {
+				return err
+			}
+
+			return c.NoContent(http.StatusNoContent)
+		})
+	}
+
+	if deps.RiskScorer != nil {
+		adminGroup.GET(RouteAdminRiskAssessments, func(c echo.Context) error {
+			return httpserver.JSONResponse(c, http.StatusOK, deps.RiskScorer.Assessments())
+		})
+	}
+}
+
+// verifyAdminToken verifies the bearer token of the request with the given secret and returns its subject.
+func verifyAdminToken(c echo.Context, secret []byte) (string, error) {
+	authorization := c.Request().Header.Get(headerAuthorization)
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		return "", faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "Missing bearer token.")
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(strings.TrimPrefix(authorization, bearerPrefix), claims, func(_ *jwt.Token) (any, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})); err != nil {
+		if ierrors.Is(err, jwt.ErrTokenExpired) {
+			return "", faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "Bearer token expired.")
+		}
+
+		return "", faucet.NewRequestError(faucet.ErrorCodeUnauthorized, http.StatusUnauthorized, "Invalid bearer token.")
+	}
+
+	return claims.Subject, nil
+}
+
+// adminAuthMiddleware only allows requests with a valid JWT that is signed with the given secret.
+func adminAuthMiddleware(secret []byte) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			subject, err := verifyAdminToken(c, secret)
+			if err != nil {
+				return err
+			}
+
+			Component.LogInfof("admin API request %s %s by %s", c.Request().Method, c.Request().URL.Path, subject)
+
+			return next(c)
+		}
+	}
+}
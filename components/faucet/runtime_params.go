@@ -0,0 +1,188 @@
+package faucet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// runtimeParameters are the faucet parameters that can be changed at runtime in their serialized form.
+type runtimeParameters struct {
+	// The amount of funds the requester receives.
+	BaseTokenAmount iotago.BaseToken `json:"baseTokenAmount"`
+	// The amount of funds the requester receives if the target address has more funds than the faucet amount and less than maximum.
+	BaseTokenAmountSmall iotago.BaseToken `json:"baseTokenAmountSmall"`
+	// The maximum allowed amount of funds on the target address.
+	BaseTokenAmountMaxTarget iotago.BaseToken `json:"baseTokenAmountMaxTarget"`
+	// The amount of mana the requester receives.
+	ManaAmount iotago.Mana `json:"manaAmount"`
+	// The maximum duration for collecting faucet batches, e.g. "2s".
+	BatchTimeout string `json:"batchTimeout"`
+	// The base token amounts in token units, e.g. "10.5".
+	BaseTokenAmountFormatted          string `json:"baseTokenAmountFormatted"`
+	BaseTokenAmountSmallFormatted     string `json:"baseTokenAmountSmallFormatted"`
+	BaseTokenAmountMaxTargetFormatted string `json:"baseTokenAmountMaxTargetFormatted"`
+}
+
+// runtimeParametersUpdate defines the request of a PUT RouteAdminParameters REST API call.
+// Only the given parameters are changed.
+// The base token amounts are either integers of the smallest unit or token units with a decimal point, e.g. "10.5".
+type runtimeParametersUpdate struct {
+	BaseTokenAmount          *json.Number `json:"baseTokenAmount,omitempty"`
+	BaseTokenAmountSmall     *json.Number `json:"baseTokenAmountSmall,omitempty"`
+	BaseTokenAmountMaxTarget *json.Number `json:"baseTokenAmountMaxTarget,omitempty"`
+	ManaAmount               *iotago.Mana `json:"manaAmount,omitempty"`
+	BatchTimeout             *string      `json:"batchTimeout,omitempty"`
+}
+
+func toRuntimeParameters(f *faucet.Faucet, params *faucet.RuntimeParameters) *runtimeParameters {
+	return &runtimeParameters{
+		BaseTokenAmount:                   params.BaseTokenAmount,
+		BaseTokenAmountSmall:              params.BaseTokenAmountSmall,
+		BaseTokenAmountMaxTarget:          params.BaseTokenAmountMaxTarget,
+		ManaAmount:                        params.ManaAmount,
+		BatchTimeout:                      params.BatchTimeout.String(),
+		BaseTokenAmountFormatted:          f.FormatBaseTokens(params.BaseTokenAmount),
+		BaseTokenAmountSmallFormatted:     f.FormatBaseTokens(params.BaseTokenAmountSmall),
+		BaseTokenAmountMaxTargetFormatted: f.FormatBaseTokens(params.BaseTokenAmountMaxTarget),
+	}
+}
+
+// apply applies the changed parameters to the given parameters.
+func (u *runtimeParametersUpdate) apply(f *faucet.Faucet, params *faucet.RuntimeParameters) error {
+	for _, amount := range []struct {
+		name   string
+		update *json.Number
+		param  *iotago.BaseToken
+	}{
+		{"baseTokenAmount", u.BaseTokenAmount, &params.BaseTokenAmount},
+		{"baseTokenAmountSmall", u.BaseTokenAmountSmall, &params.BaseTokenAmountSmall},
+		{"baseTokenAmountMaxTarget", u.BaseTokenAmountMaxTarget, &params.BaseTokenAmountMaxTarget},
+	} {
+		if amount.update == nil {
+			continue
+		}
+
+		value, err := f.ParseBaseTokens(amount.update.String())
+		if err != nil {
+			return faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid %s provided! Error: %s", amount.name, err))
+		}
+		*amount.param = value
+	}
+	if u.ManaAmount != nil {
+		params.ManaAmount = *u.ManaAmount
+	}
+	if u.BatchTimeout != nil {
+		batchTimeout, err := time.ParseDuration(*u.BatchTimeout)
+		if err != nil {
+			return faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid batchTimeout provided! Error: %s", err))
+		}
+		params.BatchTimeout = batchTimeout
+	}
+
+	return nil
+}
+
+func getRuntimeParameters() *runtimeParameters {
+	return toRuntimeParameters(deps.Faucet, deps.Faucet.RuntimeParameters())
+}
+
+func updateRuntimeParameters(c echo.Context) (*runtimeParameters, error) {
+	update := &runtimeParametersUpdate{}
+	if err := c.Bind(update); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	params := deps.Faucet.RuntimeParameters()
+	if err := update.apply(deps.Faucet, params); err != nil {
+		return nil, err
+	}
+
+	if err := deps.Faucet.SetRuntimeParameters(params); err != nil {
+		return nil, err
+	}
+
+	if err := writeRuntimeParametersFile(ParamsFaucet.RuntimeParameters.StoragePath, params); err != nil {
+		// the parameters are applied anyway, but they are lost after a restart
+		Component.LogWarnf("failed to persist the faucet runtime parameters: %s", err)
+	}
+
+	Component.LogInfof("faucet runtime parameters changed, baseTokenAmount: %d, baseTokenAmountSmall: %d, baseTokenAmountMaxTarget: %d, manaAmount: %d, batchTimeout: %s",
+		params.BaseTokenAmount, params.BaseTokenAmountSmall, params.BaseTokenAmountMaxTarget, params.ManaAmount, params.BatchTimeout)
+
+	return toRuntimeParameters(deps.Faucet, params), nil
+}
+
+// loadRuntimeParameters applies the runtime parameters that were persisted in a previous run.
+// they take precedence over the configured values.
+func loadRuntimeParameters(f *faucet.Faucet) error {
+	filePath := ParamsFaucet.RuntimeParameters.StoragePath
+	if filePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return ierrors.Wrap(err, "failed to read the runtime parameters file")
+	}
+
+	persisted := &runtimeParametersUpdate{}
+	if err := json.Unmarshal(data, persisted); err != nil {
+		return ierrors.Wrap(err, "failed to parse the runtime parameters file")
+	}
+
+	params := f.RuntimeParameters()
+	if err := persisted.apply(f, params); err != nil {
+		return ierrors.Wrap(err, "invalid runtime parameters file")
+	}
+
+	if err := f.SetRuntimeParameters(params); err != nil {
+		return ierrors.Wrap(err, "invalid runtime parameters file")
+	}
+
+	Component.LogInfof("applied the persisted faucet runtime parameters from %s", filePath)
+
+	return nil
+}
+
+// writeRuntimeParametersFile persists the given runtime parameters.
+func writeRuntimeParametersFile(filePath string, params *faucet.RuntimeParameters) error {
+	if filePath == "" {
+		return nil
+	}
+
+	return writeJSONFile(filePath, toRuntimeParameters(deps.Faucet, params))
+}
+
+// writeJSONFile writes the given value as JSON to the given file.
+// the file is replaced atomically, so a crash while writing doesn't corrupt it.
+func writeJSONFile(filePath string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return err
+	}
+
+	tmpFilePath := filePath + ".tmp"
+	if err := os.WriteFile(tmpFilePath, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilePath, filePath)
+}
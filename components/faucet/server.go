@@ -0,0 +1,125 @@
+package faucet
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// limitListener limits the amount of concurrently open connections in total and per remote IP.
+// connections that exceed a limit are closed immediately.
+type limitListener struct {
+	net.Listener
+
+	// the maximum amount of open connections (0 to disable).
+	maxConnections int
+	// the maximum amount of open connections per remote IP (0 to disable).
+	maxConnectionsPerIP int
+
+	mutex           sync.Mutex
+	connections     int
+	connectionsByIP map[string]int
+}
+
+func newLimitListener(listener net.Listener, maxConnections int, maxConnectionsPerIP int) *limitListener {
+	return &limitListener{
+		Listener:            listener,
+		maxConnections:      maxConnections,
+		maxConnectionsPerIP: maxConnectionsPerIP,
+		connectionsByIP:     make(map[string]int),
+	}
+}
+
+// Accept waits for the next connection that doesn't exceed the limits.
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		remoteIP, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			remoteIP = conn.RemoteAddr().String()
+		}
+
+		if !l.acquire(remoteIP) {
+			_ = conn.Close()
+
+			continue
+		}
+
+		return &limitedConn{Conn: conn, release: func() { l.release(remoteIP) }}, nil
+	}
+}
+
+func (l *limitListener) acquire(remoteIP string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.maxConnections > 0 && l.connections >= l.maxConnections {
+		return false
+	}
+	if l.maxConnectionsPerIP > 0 && l.connectionsByIP[remoteIP] >= l.maxConnectionsPerIP {
+		return false
+	}
+
+	l.connections++
+	l.connectionsByIP[remoteIP]++
+
+	return true
+}
+
+func (l *limitListener) release(remoteIP string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.connections--
+	if l.connectionsByIP[remoteIP]--; l.connectionsByIP[remoteIP] <= 0 {
+		delete(l.connectionsByIP, remoteIP)
+	}
+}
+
+// limitedConn releases its slot in the limit listener when it is closed.
+type limitedConn struct {
+	net.Conn
+
+	releaseOnce sync.Once
+	release     func()
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+
+	return err
+}
+
+// configureServer applies the timeouts, the connection limits and TLS to the server of the given echo instance.
+func configureServer(e *echo.Echo, bindAddress string) error {
+	e.Server.ReadHeaderTimeout = ParamsFaucet.Server.ReadHeaderTimeout
+	e.Server.ReadTimeout = ParamsFaucet.Server.ReadTimeout
+	e.Server.WriteTimeout = ParamsFaucet.Server.WriteTimeout
+	e.Server.IdleTimeout = ParamsFaucet.Server.IdleTimeout
+	e.Server.MaxHeaderBytes = ParamsFaucet.Server.MaxHeaderBytes
+
+	listener, err := net.Listen("tcp", bindAddress)
+	if err != nil {
+		return err
+	}
+
+	if ParamsFaucet.Server.MaxConnections > 0 || ParamsFaucet.Server.MaxConnectionsPerIP > 0 {
+		listener = newLimitListener(listener, ParamsFaucet.Server.MaxConnections, ParamsFaucet.Server.MaxConnectionsPerIP)
+	}
+
+	if certificates != nil {
+		// the TLS connections are terminated by the listener, the limits apply before the handshake
+		listener = tls.NewListener(listener, certificates.tlsConfig())
+	}
+
+	e.Listener = listener
+
+	return nil
+}
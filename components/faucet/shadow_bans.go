@@ -0,0 +1,87 @@
+package faucet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+// loadShadowBanRules applies the shadow-ban rules that were persisted after they were changed via the admin API.
+func loadShadowBanRules(f *faucet.Faucet) error {
+	filePath := ParamsFaucet.ShadowBans.StoragePath
+	if filePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return ierrors.Wrap(err, "failed to read the shadow-ban rules file")
+	}
+
+	rules := []*faucet.ShadowBanRule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return ierrors.Wrap(err, "failed to parse the shadow-ban rules file")
+	}
+
+	if err := f.SetShadowBanRules(rules); err != nil {
+		return ierrors.Wrap(err, "invalid shadow-ban rules file")
+	}
+
+	Component.LogInfof("applied %d persisted shadow-ban rules from %s", len(rules), filePath)
+
+	return nil
+}
+
+// persistShadowBanRules persists the current shadow-ban rules, so the changes of the admin API survive a restart.
+func persistShadowBanRules() {
+	if ParamsFaucet.ShadowBans.StoragePath == "" {
+		return
+	}
+
+	if err := writeJSONFile(ParamsFaucet.ShadowBans.StoragePath, deps.Faucet.ShadowBanRules()); err != nil {
+		// the rules are applied anyway, but the changes are lost after a restart
+		Component.LogWarnf("failed to persist the shadow-ban rules: %s", err)
+	}
+}
+
+// addShadowBanRule adds the shadow-ban rule of the request body.
+func addShadowBanRule(c echo.Context) (*faucet.ShadowBanRule, error) {
+	rule := &faucet.ShadowBanRule{}
+	if err := c.Bind(rule); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	addedRule, err := deps.Faucet.AddShadowBanRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	persistShadowBanRules()
+
+	Component.LogInfof("added shadow-ban rule %s (%s)", addedRule.ID, addedRule.Type)
+
+	return addedRule, nil
+}
+
+// removeShadowBanRule removes the shadow-ban rule given in the path.
+func removeShadowBanRule(c echo.Context) error {
+	id := c.Param(ParameterShadowBanID)
+
+	if !deps.Faucet.RemoveShadowBanRule(id) {
+		return faucet.NewRequestError(faucet.ErrorCodeNotFound, http.StatusNotFound, fmt.Sprintf("Shadow-ban rule %s not found.", id))
+	}
+	persistShadowBanRules()
+
+	Component.LogInfof("removed shadow-ban rule %s", id)
+
+	return nil
+}
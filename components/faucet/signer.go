@@ -0,0 +1,157 @@
+package faucet
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/keys"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// signerTypeLocal signs the transactions with the private key in the environment.
+	signerTypeLocal = "local"
+	// signerTypeMnemonic signs the transactions with the private key derived from the mnemonic in the environment.
+	signerTypeMnemonic = "mnemonic"
+	// signerTypeKeyFile signs the transactions with the private key in an encrypted key file.
+	signerTypeKeyFile = "keyfile"
+	// signerTypeRemote delegates the signing to an external signing service.
+	signerTypeRemote = "remote"
+	// signerTypePKCS11 signs the transactions with a key in an HSM, accessed via PKCS#11.
+	signerTypePKCS11 = "pkcs11"
+)
+
+// loadFaucetAddressAndSigner loads the signer of the configured type and returns the address of its key.
+func loadFaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
+	switch ParamsFaucet.Signer.Type {
+	case signerTypeLocal:
+		return loadLocalFaucetAddressAndSigner()
+	case signerTypeMnemonic:
+		return loadMnemonicFaucetAddressAndSigner()
+	case signerTypeKeyFile:
+		return loadKeyFileFaucetAddressAndSigner()
+	case signerTypeRemote:
+		return loadRemoteFaucetAddressAndSigner()
+	case signerTypePKCS11:
+		return loadPKCS11FaucetAddressAndSigner()
+	default:
+		return nil, nil, ierrors.Errorf("unknown signer type \"%s\" (local, mnemonic, keyfile, remote, pkcs11)", ParamsFaucet.Signer.Type)
+	}
+}
+
+// loadMnemonicFaucetAddressAndSigner derives the private key of the faucet from the mnemonic and the optional passphrase in the environment.
+func loadMnemonicFaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
+	mnemonic, exists := os.LookupEnv("FAUCET_MNEMONIC")
+	if !exists || mnemonic == "" {
+		return nil, nil, ierrors.New("loading faucet mnemonic failed, err: environment variable 'FAUCET_MNEMONIC' not set")
+	}
+
+	privateKey, err := keys.Ed25519PrivateKeyFromMnemonic(mnemonic, os.Getenv("FAUCET_MNEMONIC_PASSPHRASE"), ParamsFaucet.Signer.Mnemonic.DerivationPath)
+	if err != nil {
+		// the error never contains the mnemonic
+		return nil, nil, ierrors.Errorf("deriving faucet private key failed, err: %w", err)
+	}
+
+	//nolint:forcetypeassert // we can safely assume that this is an ed25519.PublicKey
+	faucetAddress := iotago.Ed25519AddressFromPubKey(privateKey.Public().(ed25519.PublicKey))
+
+	return faucetAddress, iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(faucetAddress, privateKey)), nil
+}
+
+// loadKeyFileFaucetAddressAndSigner decrypts the private key of the faucet from the key file.
+// The passphrase is taken from the environment, or prompted for if the faucet runs in a terminal.
+func loadKeyFileFaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
+	keyFilePath := ParamsFaucet.Signer.KeyFile.Path
+	if keyFilePath == "" {
+		return nil, nil, ierrors.New("loading faucet key file failed, err: the path of the key file is not set")
+	}
+
+	data, err := os.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, nil, ierrors.Errorf("loading faucet key file failed, err: %w", err)
+	}
+
+	passphrase, exists := os.LookupEnv("FAUCET_KEY_FILE_PASSPHRASE")
+	if !exists {
+		passphrase, err = keys.ReadPassphrase(fmt.Sprintf("Passphrase of the faucet key file %s: ", keyFilePath))
+		if err != nil {
+			return nil, nil, ierrors.Errorf("loading faucet key file failed, err: environment variable 'FAUCET_KEY_FILE_PASSPHRASE' not set: %w", err)
+		}
+	}
+
+	privateKey, err := keys.DecryptEd25519PrivateKey(data, passphrase)
+	if err != nil {
+		return nil, nil, ierrors.Errorf("decrypting faucet key file failed, err: %w", err)
+	}
+
+	//nolint:forcetypeassert // we can safely assume that this is an ed25519.PublicKey
+	faucetAddress := iotago.Ed25519AddressFromPubKey(privateKey.Public().(ed25519.PublicKey))
+
+	return faucetAddress, iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(faucetAddress, privateKey)), nil
+}
+
+// loadRemoteFaucetAddressAndSigner connects to the signing service and queries the public key of the faucet.
+func loadRemoteFaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
+	if ParamsFaucet.Signer.Remote.URL == "" {
+		return nil, nil, ierrors.New("loading remote signer failed, err: the URL of the signing service is not set")
+	}
+
+	httpClient, err := newRemoteSignerHTTPClient()
+	if err != nil {
+		return nil, nil, ierrors.Errorf("loading remote signer failed, err: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ParamsFaucet.Signer.Remote.Timeout)
+	defer cancel()
+
+	remoteSigner, err := faucet.NewRemoteAddressSigner(ctx, ParamsFaucet.Signer.Remote.URL, httpClient)
+	if err != nil {
+		return nil, nil, ierrors.Errorf("loading remote signer failed, err: %w", err)
+	}
+
+	return remoteSigner.Address(), remoteSigner, nil
+}
+
+// newRemoteSignerHTTPClient creates the HTTP client for the signing service, with mutual TLS if a client certificate is configured.
+func newRemoteSignerHTTPClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caCertPath := ParamsFaucet.Signer.Remote.CACertPath; caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "failed to read CA certificate %s", caCertPath)
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, ierrors.Errorf("no valid certificate found in %s", caCertPath)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if certPath := ParamsFaucet.Signer.Remote.CertPath; certPath != "" {
+		certificate, err := tls.LoadX509KeyPair(certPath, ParamsFaucet.Signer.Remote.KeyPath)
+		if err != nil {
+			return nil, ierrors.Wrap(err, "failed to load the client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	//nolint:forcetypeassert // we can safely assume that the default transport is a *http.Transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   ParamsFaucet.Signer.Remote.Timeout,
+	}, nil
+}
@@ -0,0 +1,13 @@
+//go:build !pkcs11
+
+package faucet
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// loadPKCS11FaucetAddressAndSigner fails, because the PKCS#11 signer needs cgo and is only built with the "pkcs11" tag.
+func loadPKCS11FaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
+	return nil, nil, ierrors.New("loading PKCS#11 signer failed, err: the faucet was built without the \"pkcs11\" tag")
+}
@@ -0,0 +1,57 @@
+//go:build pkcs11
+
+package faucet
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// loadPKCS11FaucetAddressAndSigner logs in to the HSM with the module, slot and PIN in the environment
+// and looks up the key pair of the faucet.
+// The signer is tested with a signature of a random message on startup, like all signers.
+func loadPKCS11FaucetAddressAndSigner() (*iotago.Ed25519Address, iotago.AddressSigner, error) {
+	modulePath, exists := os.LookupEnv("FAUCET_PKCS11_MODULE")
+	if !exists || modulePath == "" {
+		return nil, nil, ierrors.New("loading PKCS#11 signer failed, err: environment variable 'FAUCET_PKCS11_MODULE' not set")
+	}
+
+	slot, err := strconv.ParseUint(os.Getenv("FAUCET_PKCS11_SLOT"), 10, 0)
+	if err != nil {
+		return nil, nil, ierrors.Errorf("loading PKCS#11 signer failed, err: environment variable 'FAUCET_PKCS11_SLOT' is not a valid slot ID: %w", err)
+	}
+
+	pin, exists := os.LookupEnv("FAUCET_PKCS11_PIN")
+	if !exists {
+		return nil, nil, ierrors.New("loading PKCS#11 signer failed, err: environment variable 'FAUCET_PKCS11_PIN' not set")
+	}
+
+	if ParamsFaucet.Signer.PKCS11.KeyLabel == "" {
+		return nil, nil, ierrors.New("loading PKCS#11 signer failed, err: the label of the key is not set")
+	}
+
+	pkcs11Signer, err := faucet.NewPKCS11AddressSigner(modulePath, uint(slot), pin, ParamsFaucet.Signer.PKCS11.KeyLabel)
+	if err != nil {
+		// the error never contains the PIN
+		return nil, nil, ierrors.Errorf("loading PKCS#11 signer failed, err: %w", err)
+	}
+
+	// the session is closed after everything that signs transactions has stopped
+	if err := Component.Daemon().BackgroundWorker("Faucet[PKCS11]", func(ctx context.Context) {
+		<-ctx.Done()
+
+		if err := pkcs11Signer.Close(); err != nil {
+			Component.LogWarnf("failed to close PKCS#11 session: %s", err)
+		}
+	}, daemon.PriorityCloseSigner); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+
+	return pkcs11Signer.Address(), pkcs11Signer, nil
+}
@@ -0,0 +1,68 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+const (
+	// stateSnapshotVersion is the version of the state snapshot format.
+	stateSnapshotVersion = 1
+)
+
+// stateSnapshot is the exported state of the faucet.
+type stateSnapshot struct {
+	// The version of the snapshot format.
+	Version int `json:"version"`
+	// The time the snapshot was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// The state of the faucet queue.
+	Faucet *faucet.Snapshot `json:"faucet"`
+	// The remaining tokens per identifier of the rate limiters by their name.
+	RateLimits map[string]map[string]float64 `json:"rateLimits,omitempty"`
+}
+
+// restoreResponse defines the response of a POST RouteAdminRestore REST API call.
+type restoreResponse struct {
+	// The amount of restored requests.
+	Requests int `json:"requests"`
+	// The amount of restored rate limiter entries.
+	RateLimits int `json:"rateLimits"`
+}
+
+func createStateSnapshot() *stateSnapshot {
+	return &stateSnapshot{
+		Version:    stateSnapshotVersion,
+		CreatedAt:  time.Now(),
+		Faucet:     deps.Faucet.Snapshot(),
+		RateLimits: rateLimiters.Snapshot(),
+	}
+}
+
+func restoreStateSnapshot(c echo.Context) (*restoreResponse, error) {
+	snapshot := &stateSnapshot{}
+	if err := c.Bind(snapshot); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	if snapshot.Version != stateSnapshotVersion {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Unsupported snapshot version %d, expected %d", snapshot.Version, stateSnapshotVersion))
+	}
+
+	response := &restoreResponse{}
+	if snapshot.Faucet != nil {
+		response.Requests = deps.Faucet.Restore(snapshot.Faucet)
+	}
+
+	// the rate limiters that are disabled or shared via redis are skipped
+	response.RateLimits = rateLimiters.Restore(snapshot.RateLimits)
+
+	Component.LogInfof("restored faucet state snapshot from %s, requests: %d, rate limits: %d", snapshot.CreatedAt.Format(time.RFC3339), response.Requests, response.RateLimits)
+
+	return response, nil
+}
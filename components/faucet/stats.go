@@ -0,0 +1,34 @@
+package faucet
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/payouts"
+)
+
+// statsResponse defines the response of a GET RouteFaucetStats REST API call.
+type statsResponse struct {
+	// The aggregated statistics of all payouts.
+	Totals *payouts.Totals `json:"totals"`
+	// The hourly statistics of the last 24 hours, oldest first.
+	Last24h []*payouts.Bucket `json:"last24h"`
+	// The daily statistics of the last 7 days, oldest first.
+	Last7d []*payouts.Bucket `json:"last7d"`
+}
+
+// getStats returns the statistics of the confirmed payouts.
+func getStats() (*statsResponse, error) {
+	if deps.PayoutStore == nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeNotFound, http.StatusNotFound, "The statistics are not available because the payout ledger is disabled.")
+	}
+
+	now := time.Now()
+
+	return &statsResponse{
+		Totals:  deps.PayoutStore.Totals(),
+		Last24h: deps.PayoutStore.TimeSeries(now, 24*time.Hour, time.Hour),
+		Last7d:  deps.PayoutStore.TimeSeries(now, 7*24*time.Hour, 24*time.Hour),
+	}, nil
+}
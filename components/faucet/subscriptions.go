@@ -0,0 +1,121 @@
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+// loadSubscriptions applies the subscriptions that were persisted after they were changed via the API.
+func loadSubscriptions(f *faucet.Faucet) error {
+	filePath := ParamsFaucet.Subscriptions.StoragePath
+	if !ParamsFaucet.Subscriptions.Enabled || filePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return ierrors.Wrap(err, "failed to read the subscriptions file")
+	}
+
+	subscriptions := []*faucet.Subscription{}
+	if err := json.Unmarshal(data, &subscriptions); err != nil {
+		return ierrors.Wrap(err, "failed to parse the subscriptions file")
+	}
+
+	if err := f.SetSubscriptions(subscriptions); err != nil {
+		return ierrors.Wrap(err, "invalid subscriptions file")
+	}
+
+	Component.LogInfof("applied %d persisted subscriptions from %s", len(subscriptions), filePath)
+
+	return nil
+}
+
+// persistSubscriptions persists the current subscriptions, so the changes and the payout times survive a restart.
+func persistSubscriptions() {
+	if ParamsFaucet.Subscriptions.StoragePath == "" {
+		return
+	}
+
+	if err := writeJSONFile(ParamsFaucet.Subscriptions.StoragePath, deps.Faucet.Subscriptions()); err != nil {
+		// the subscriptions are applied anyway, but the changes are lost after a restart
+		Component.LogWarnf("failed to persist the subscriptions: %s", err)
+	}
+}
+
+// addSubscription registers the subscription of the request body.
+func addSubscription(c echo.Context) (*faucet.Subscription, error) {
+	subscription := &faucet.Subscription{}
+	if err := c.Bind(subscription); err != nil {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid Request! Error: %s", err))
+	}
+
+	if maxAmount := ParamsFaucet.Subscriptions.MaxBaseTokenAmount; maxAmount > 0 && uint64(subscription.BaseTokenAmount) > maxAmount {
+		return nil, faucet.NewRequestError(faucet.ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("The amount of a subscription must not exceed %d.", maxAmount)).
+			WithDetail("maxAmount", maxAmount)
+	}
+
+	addedSubscription, err := deps.Faucet.AddSubscription(subscription)
+	if err != nil {
+		return nil, err
+	}
+	persistSubscriptions()
+
+	Component.LogInfof("added subscription %s for %s, every %d hours", addedSubscription.ID, addedSubscription.Address, addedSubscription.IntervalHours)
+
+	return addedSubscription, nil
+}
+
+// removeSubscription removes the subscription given in the path.
+func removeSubscription(c echo.Context) error {
+	id := c.Param(ParameterSubscriptionID)
+
+	if !deps.Faucet.RemoveSubscription(id) {
+		return faucet.NewRequestError(faucet.ErrorCodeNotFound, http.StatusNotFound, fmt.Sprintf("Subscription %s not found.", id))
+	}
+	persistSubscriptions()
+
+	Component.LogInfof("removed subscription %s", id)
+
+	return nil
+}
+
+// runSubscriptions periodically enqueues the payouts of the subscriptions that are due.
+func runSubscriptions() {
+	if !ParamsFaucet.Subscriptions.Enabled {
+		return
+	}
+
+	if err := Component.Daemon().BackgroundWorker("Faucet[Subscriptions]", func(ctx context.Context) {
+		ticker := time.NewTicker(ParamsFaucet.Subscriptions.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			if deps.Faucet.ProcessDueSubscriptions() {
+				persistSubscriptions()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}, daemon.PriorityStopFaucetSubscriptions); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
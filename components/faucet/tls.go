@@ -0,0 +1,138 @@
+package faucet
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/runtime/timeutil"
+)
+
+// certificateReloader serves the TLS certificate of the faucet servers and loads it again if the files changed,
+// so rotated certificates are used without a restart.
+type certificateReloader struct {
+	certPath string
+	keyPath  string
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	// the latest modification time of the certificate and the key file that were loaded.
+	modTime time.Time
+}
+
+// certificates is the TLS certificate of the faucet servers, it is nil if TLS is disabled.
+var certificates *certificateReloader
+
+func newCertificateReloader(certPath string, keyPath string) (*certificateReloader, error) {
+	reloader := &certificateReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+
+	if _, err := reloader.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// latestModTime returns the latest modification time of the certificate and the key file.
+func (r *certificateReloader) latestModTime() (time.Time, error) {
+	var latestModTime time.Time
+	for _, filePath := range []string{r.certPath, r.keyPath} {
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			return time.Time{}, ierrors.Wrapf(err, "failed to read TLS file %s", filePath)
+		}
+
+		if fileInfo.ModTime().After(latestModTime) {
+			latestModTime = fileInfo.ModTime()
+		}
+	}
+
+	return latestModTime, nil
+}
+
+// reloadIfChanged loads the certificate again if the files were modified since they were loaded.
+// It returns true if the certificate was reloaded.
+func (r *certificateReloader) reloadIfChanged() (bool, error) {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return false, err
+	}
+
+	r.mutex.RLock()
+	unchanged := r.certificate != nil && modTime.Equal(r.modTime)
+	r.mutex.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return false, ierrors.Wrap(err, "failed to load TLS certificate")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.certificate = &certificate
+	r.modTime = modTime
+
+	return true, nil
+}
+
+// GetCertificate returns the current certificate for new TLS connections.
+func (r *certificateReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.certificate, nil
+}
+
+// tlsConfig returns the TLS configuration of the faucet servers.
+func (r *certificateReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+		NextProtos:     []string{"http/1.1"},
+	}
+}
+
+// waitAndReloadCertificates loads rotated certificates in the configured interval until the context is done.
+func waitAndReloadCertificates(ctx context.Context) {
+	if certificates == nil || ParamsFaucet.TLS.ReloadInterval <= 0 {
+		<-ctx.Done()
+
+		return
+	}
+
+	ticker := time.NewTicker(ParamsFaucet.TLS.ReloadInterval)
+	defer timeutil.CleanupTicker(ticker)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reloaded, err := certificates.reloadIfChanged(); err != nil {
+				Component.LogWarnf("failed to reload the TLS certificate, the previous one is still used: %s", err)
+			} else if reloaded {
+				Component.LogInfo("reloaded the TLS certificate")
+			}
+		}
+	}
+}
+
+// serverScheme returns the URL scheme of the faucet servers.
+func serverScheme() string {
+	if certificates != nil {
+		return "https"
+	}
+
+	return "http"
+}
@@ -0,0 +1,121 @@
+package faucet
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	// headerCFConnectingIP is the header that contains the client IP if the faucet is served behind Cloudflare.
+	headerCFConnectingIP = "CF-Connecting-IP"
+)
+
+// ipExtractor extracts the client IP from the requests to the faucet servers.
+var ipExtractor echo.IPExtractor
+
+// parseTrustedProxies parses the configured IP addresses and CIDRs of the trusted proxies.
+func parseTrustedProxies(trustedProxies []string) ([]*net.IPNet, error) {
+	ipRanges := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, trustedProxy := range trustedProxies {
+		trustedProxy = strings.TrimSpace(trustedProxy)
+		if trustedProxy == "" {
+			continue
+		}
+
+		if !strings.Contains(trustedProxy, "/") {
+			// a single IP address
+			ip := net.ParseIP(trustedProxy)
+			if ip == nil {
+				return nil, ierrors.Errorf("invalid trusted proxy \"%s\"", trustedProxy)
+			}
+
+			bits := net.IPv6len * 8
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = net.IPv4len * 8
+			}
+			ipRanges = append(ipRanges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, ipRange, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "invalid trusted proxy \"%s\"", trustedProxy)
+		}
+		ipRanges = append(ipRanges, ipRange)
+	}
+
+	return ipRanges, nil
+}
+
+// newIPExtractor returns how the client IP is extracted from the requests, it is used for rate limiting,
+// the audit log and the abuse detection. The configured header is only honored if the request was sent by a trusted proxy,
+// otherwise the address of the connection is used, so clients can't fake their IP.
+func newIPExtractor() (echo.IPExtractor, error) {
+	ipRanges, err := parseTrustedProxies(ParamsFaucet.TrustedProxies.Proxies)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ipRanges) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// only the configured proxies are trusted, not the whole private network
+	trustOptions := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, ipRange := range ipRanges {
+		trustOptions = append(trustOptions, echo.TrustIPRange(ipRange))
+	}
+
+	switch http.CanonicalHeaderKey(ParamsFaucet.TrustedProxies.Header) {
+	case echo.HeaderXForwardedFor:
+		return echo.ExtractIPFromXFFHeader(trustOptions...), nil
+
+	case echo.HeaderXRealIP:
+		return echo.ExtractIPFromRealIPHeader(trustOptions...), nil
+
+	case http.CanonicalHeaderKey(headerCFConnectingIP):
+		return extractIPFromSingleIPHeader(headerCFConnectingIP, ipRanges), nil
+
+	default:
+		return nil, ierrors.Errorf("unknown client IP header \"%s\", expected \"%s\", \"%s\" or \"%s\"", ParamsFaucet.TrustedProxies.Header, echo.HeaderXForwardedFor, echo.HeaderXRealIP, headerCFConnectingIP)
+	}
+}
+
+// extractIPFromSingleIPHeader extracts the client IP from a header that contains a single IP address,
+// if the request was sent by one of the trusted proxies.
+func extractIPFromSingleIPHeader(header string, trustedProxies []*net.IPNet) echo.IPExtractor {
+	directIPExtractor := echo.ExtractIPDirect()
+
+	return func(req *http.Request) string {
+		directIP := directIPExtractor(req)
+
+		headerIP := net.ParseIP(strings.TrimSpace(req.Header.Get(header)))
+		if headerIP == nil {
+			return directIP
+		}
+
+		proxyIP := net.ParseIP(directIP)
+		if proxyIP == nil {
+			return directIP
+		}
+
+		for _, trustedProxy := range trustedProxies {
+			if trustedProxy.Contains(proxyIP) {
+				return headerIP.String()
+			}
+		}
+
+		return directIP
+	}
+}
@@ -0,0 +1,164 @@
+package faucet
+
+import (
+	"context"
+	"time"
+
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/webhook"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// webhookEventRequestEnqueued is sent when a request was added to the queue.
+	webhookEventRequestEnqueued = "request.enqueued"
+	// webhookEventPayoutConfirmed is sent when the transaction of a payout was confirmed.
+	webhookEventPayoutConfirmed = "payout.confirmed"
+	// webhookEventPayoutFailed is sent when the transaction of a payout failed and the payout is retried.
+	webhookEventPayoutFailed = "payout.failed"
+	// webhookEventRequestFailed is sent when a queued request was dropped without a payout.
+	webhookEventRequestFailed = "request.failed"
+	// webhookEventDonationReceived is sent when someone else sent an output to the faucet address.
+	webhookEventDonationReceived = "donation.received"
+)
+
+// webhookPayout is a payout in a webhook notification.
+type webhookPayout struct {
+	// The bech32 address of the receiver.
+	Address string `json:"address"`
+	// The explorer link of the receiver, it is omitted if no explorer URL is configured.
+	AddressURL string `json:"addressUrl,omitempty"`
+	// The type of the request.
+	Type string `json:"type"`
+	// The amount of base tokens of the payout.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of mana of the payout, it is not known before the transaction was created.
+	ManaAmount iotago.Mana `json:"mana,omitempty"`
+	// The sanitized tag of the request.
+	Tag string `json:"tag,omitempty"`
+	// The time the request was enqueued.
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+}
+
+// webhookTransaction is a confirmed or failed faucet transaction in a webhook notification.
+type webhookTransaction struct {
+	// The ID of the block that contained the transaction.
+	BlockID string `json:"blockId"`
+	// The ID of the transaction.
+	TransactionID string `json:"transactionId"`
+	// The explorer link of the block, it is omitted if no explorer URL is configured.
+	BlockURL string `json:"blockUrl,omitempty"`
+	// The explorer link of the transaction, it is omitted if no explorer URL is configured.
+	TransactionURL string `json:"transactionUrl,omitempty"`
+	// The payouts of the transaction.
+	Payouts []*webhookPayout `json:"payouts"`
+	// The reason why the transaction failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// webhookFailedRequest is a queued request that was dropped without a payout in a webhook notification.
+type webhookFailedRequest struct {
+	*webhookPayout
+	// The reason why the request failed.
+	Reason string `json:"reason"`
+}
+
+func newWebhookPayout(payout *faucet.Payout) *webhookPayout {
+	return &webhookPayout{
+		Address:         payout.Bech32,
+		AddressURL:      deps.Faucet.ExplorerURL(faucet.ExplorerLinkKindAddress, payout.Bech32),
+		Type:            string(payout.Type),
+		BaseTokenAmount: payout.BaseTokenAmount,
+		ManaAmount:      payout.ManaAmount,
+		Tag:             payout.Tag,
+		EnqueuedAt:      payout.EnqueuedAt,
+	}
+}
+
+func newWebhookPayouts(payouts []*faucet.Payout) []*webhookPayout {
+	webhookPayouts := make([]*webhookPayout, 0, len(payouts))
+	for _, payout := range payouts {
+		webhookPayouts = append(webhookPayouts, newWebhookPayout(payout))
+	}
+
+	return webhookPayouts
+}
+
+func newWebhookTransaction(blockID iotago.BlockID, transactionID iotago.TransactionID, payouts []*faucet.Payout) *webhookTransaction {
+	return &webhookTransaction{
+		BlockID:        blockID.ToHex(),
+		TransactionID:  transactionID.ToHex(),
+		BlockURL:       deps.Faucet.ExplorerURL(faucet.ExplorerLinkKindBlock, blockID.ToHex()),
+		TransactionURL: deps.Faucet.ExplorerURL(faucet.ExplorerLinkKindTransaction, transactionID.ToHex()),
+		Payouts:        newWebhookPayouts(payouts),
+	}
+}
+
+// setupWebhooks sends notifications about the requests and payouts of the faucet to the configured URLs.
+func setupWebhooks() {
+	if len(ParamsFaucet.Webhooks.URLs) == 0 {
+		return
+	}
+
+	if ParamsFaucet.Webhooks.Secret == "" {
+		Component.LogWarn("webhook notifications are not signed, no secret is configured")
+	}
+
+	dispatcher := webhook.NewDispatcher(&webhook.Options{
+		URLs:           ParamsFaucet.Webhooks.URLs,
+		Secret:         ParamsFaucet.Webhooks.Secret,
+		Timeout:        ParamsFaucet.Webhooks.Timeout,
+		MaxRetries:     ParamsFaucet.Webhooks.MaxRetries,
+		InitialBackoff: ParamsFaucet.Webhooks.InitialBackoff,
+		MaxBackoff:     ParamsFaucet.Webhooks.MaxBackoff,
+		QueueSize:      ParamsFaucet.Webhooks.QueueSize,
+	}, func(err error) {
+		Component.LogWarn(err.Error())
+	})
+
+	notify := func(event string, data any) {
+		if err := dispatcher.Notify(event, data); err != nil {
+			Component.LogWarnf("failed to send webhook notification: %s", err)
+		}
+	}
+
+	deps.Faucet.Events.RequestEnqueued.Hook(func(payout *faucet.Payout) {
+		notify(webhookEventRequestEnqueued, newWebhookPayout(payout))
+	})
+
+	deps.Faucet.Events.TransactionConfirmed.Hook(func(confirmedTx *faucet.ConfirmedTransaction) {
+		notify(webhookEventPayoutConfirmed, newWebhookTransaction(confirmedTx.BlockID, confirmedTx.TransactionID, confirmedTx.Payouts))
+	})
+
+	deps.Faucet.Events.TransactionFailed.Hook(func(failedTx *faucet.FailedTransaction) {
+		var reason string
+		if failedTx.Reason != nil {
+			reason = failedTx.Reason.Error()
+		}
+
+		webhookTx := newWebhookTransaction(failedTx.BlockID, failedTx.TransactionID, failedTx.Payouts)
+		webhookTx.Reason = reason
+
+		notify(webhookEventPayoutFailed, webhookTx)
+	})
+
+	deps.Faucet.Events.RequestFailed.Hook(func(failedRequest *faucet.FailedRequest) {
+		notify(webhookEventRequestFailed, &webhookFailedRequest{
+			webhookPayout: newWebhookPayout(failedRequest.Payout),
+			Reason:        failedRequest.Reason.Error(),
+		})
+	})
+
+	deps.Faucet.Events.DonationReceived.Hook(func(d *faucet.Donation) {
+		notify(webhookEventDonationReceived, newDonation(d))
+	})
+
+	// create a background worker that delivers the webhook notifications.
+	// it is stopped after the faucet, so the notifications of the last transactions are still sent.
+	if err := Component.Daemon().BackgroundWorker("Faucet[Webhooks]", func(ctx context.Context) {
+		dispatcher.Run(ctx)
+	}, daemon.PriorityStopWebhooks); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/dig"
+
+	"github.com/iotaledger/hive.go/app"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/daemon"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+func init() {
+	Component = &app.Component{
+		Name:      "Prometheus",
+		DepsFunc:  func(cDeps dependencies) { deps = cDeps },
+		Params:    params,
+		IsEnabled: func(_ *dig.Container) bool { return ParamsPrometheus.Enabled },
+		Configure: configure,
+		Run:       run,
+	}
+}
+
+var (
+	Component *app.Component
+	deps      dependencies
+
+	server   *http.Server
+	registry = prometheus.NewRegistry()
+)
+
+type dependencies struct {
+	dig.In
+	Faucet *faucet.Faucet
+}
+
+func configure() error {
+	if ParamsPrometheus.FaucetMetrics {
+		configureFaucetMetrics()
+	}
+	if ParamsPrometheus.GoMetrics {
+		registry.MustRegister(collectors.NewGoCollector())
+	}
+	if ParamsPrometheus.ProcessMetrics {
+		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+
+	return nil
+}
+
+func run() error {
+	return Component.Daemon().BackgroundWorker("Prometheus exporter", func(ctx context.Context) {
+		Component.LogInfo("Starting Prometheus exporter ... done")
+
+		e := echo.New()
+		e.HideBanner = true
+		e.Use(middleware.Recover())
+
+		e.GET("/metrics", func(c echo.Context) error {
+			handler := promhttp.HandlerFor(
+				registry,
+				promhttp.HandlerOpts{
+					EnableOpenMetrics: true,
+				},
+			)
+
+			if ParamsPrometheus.PromhttpMetrics {
+				handler = promhttp.InstrumentMetricHandler(registry, handler)
+			}
+
+			handler.ServeHTTP(c.Response().Writer, c.Request())
+
+			return nil
+		})
+
+		bindAddr := ParamsPrometheus.BindAddress
+		server = &http.Server{Addr: bindAddr, Handler: e, ReadHeaderTimeout: 5 * time.Second}
+
+		go func() {
+			Component.LogInfof("You can now access the Prometheus exporter using: http://%s/metrics", bindAddr)
+			if err := server.ListenAndServe(); err != nil && !ierrors.Is(err, http.ErrServerClosed) {
+				Component.LogWarnf("Stopping Prometheus exporter due to an error (%s)", err)
+			}
+		}()
+
+		<-ctx.Done()
+		Component.LogInfo("Stopping Prometheus exporter ...")
+
+		if server != nil {
+			shutdownCtx, shutdownCtxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCtxCancel()
+
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				Component.LogWarn(err.Error())
+			}
+		}
+
+		Component.LogInfo("Stopping Prometheus exporter ... done")
+	}, daemon.PriorityStopPrometheus)
+}
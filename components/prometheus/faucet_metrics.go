@@ -0,0 +1,178 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+var (
+	faucetSubmitRetries   *prometheus.CounterVec
+	faucetSoftErrors      *prometheus.CounterVec
+	faucetNodeCallLatency *prometheus.HistogramVec
+	faucetNodeCallErrors  *prometheus.CounterVec
+)
+
+func configureFaucetMetrics() {
+	faucetSubmitRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "submit_retries_total",
+			Help:      "The total number of retried faucet transaction submissions per error class.",
+		},
+		[]string{"class"},
+	)
+
+	registry.MustRegister(faucetSubmitRetries)
+
+	deps.Faucet.Events.SubmitRetried.Hook(func(errorClass faucet.SubmitErrorClass) {
+		faucetSubmitRetries.WithLabelValues(string(errorClass)).Inc()
+	})
+
+	faucetSoftErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "soft_errors_total",
+			Help:      "The total number of soft errors of the faucet per category.",
+		},
+		[]string{"category"},
+	)
+
+	registry.MustRegister(faucetSoftErrors)
+
+	deps.Faucet.Events.SoftError.Hook(func(softErr *faucet.SoftError) {
+		faucetSoftErrors.WithLabelValues(string(softErr.Category)).Inc()
+	})
+
+	faucetNodeCallLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "node_call_duration_seconds",
+			Help:      "The round-trip time of the calls to the node, the indexer and the block issuer per call type.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"call"},
+	)
+
+	faucetNodeCallErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "node_call_errors_total",
+			Help:      "The total number of failed calls to the node, the indexer and the block issuer per call type.",
+		},
+		[]string{"call"},
+	)
+
+	registry.MustRegister(faucetNodeCallLatency)
+	registry.MustRegister(faucetNodeCallErrors)
+
+	deps.Faucet.Events.NodeCallCompleted.Hook(func(call *faucet.NodeCall) {
+		faucetNodeCallLatency.WithLabelValues(string(call.Type)).Observe(call.Duration.Seconds())
+		if call.Err != nil {
+			faucetNodeCallErrors.WithLabelValues(string(call.Type)).Inc()
+		}
+	})
+
+	registerReservationGauge := func(name string, help string, value func(stats *faucet.ReservationStats) float64) {
+		registry.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "iota",
+				Subsystem: "faucet",
+				Name:      name,
+				Help:      help,
+			},
+			func() float64 {
+				return value(deps.Faucet.ReservationStats())
+			},
+		))
+	}
+
+	registerReservationGauge("base_tokens_total", "The base tokens of the faucet that can be used for payouts.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.TotalBaseTokens)
+	})
+	registerReservationGauge("base_tokens_reserved", "The base tokens that are reserved for queued and pending requests.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.ReservedBaseTokens)
+	})
+	registerReservationGauge("base_tokens_available", "The base tokens that can be reserved for new requests.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.AvailableBaseTokens)
+	})
+	registerReservationGauge("mana_total", "The stored mana of the faucet.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.TotalMana)
+	})
+	registerReservationGauge("mana_reserved", "The mana that is reserved for queued and pending requests.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.ReservedMana)
+	})
+	registerReservationGauge("mana_available", "The mana that can be reserved for new requests.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.AvailableMana)
+	})
+	registerReservationGauge("reservations", "The amount of requests with reserved funds.", func(stats *faucet.ReservationStats) float64 {
+		return float64(stats.Reservations)
+	})
+
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "paused",
+			Help:      "Whether the faucet was paused by the operator (1) or not (0).",
+		},
+		func() float64 {
+			if deps.Faucet.IsPaused() {
+				return 1
+			}
+
+			return 0
+		},
+	))
+
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "inx_connected",
+			Help:      "Whether all INX streams of the faucet are connected (1) or at least one is reconnecting (0).",
+		},
+		func() float64 {
+			if deps.Faucet.INXConnectionState().Connected {
+				return 1
+			}
+
+			return 0
+		},
+	))
+
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "inx_reconnecting_seconds",
+			Help:      "The seconds since when at least one INX stream of the faucet is reconnecting.",
+		},
+		func() float64 {
+			state := deps.Faucet.INXConnectionState()
+			if state.ReconnectingSince == nil {
+				return 0
+			}
+
+			return time.Since(*state.ReconnectingSince).Seconds()
+		},
+	))
+
+	registry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: "iota",
+			Subsystem: "faucet",
+			Name:      "inx_reconnects_total",
+			Help:      "The total number of reconnects of the INX streams of the faucet.",
+		},
+		func() float64 {
+			return float64(deps.Faucet.INXConnectionState().Reconnects)
+		},
+	))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iotaledger/hive.go/crypto"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/keys"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// encryptKeyCommand is the name of the subcommand that writes the private key of the faucet to an encrypted key file.
+	encryptKeyCommand = "encrypt-key"
+)
+
+// runEncryptKeyCommand encrypts the private key in the environment, or a new one, with a passphrase and writes the key file.
+// It returns the exit code of the command.
+func runEncryptKeyCommand(args []string) int {
+	flagSet := flag.NewFlagSet(encryptKeyCommand, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), "Usage: inx-faucet %s --out <key file> [options]\n\n", encryptKeyCommand)
+		fmt.Fprintln(flagSet.Output(), "Encrypts the private key in the \"FAUCET_PRV_KEY\" environment variable with a passphrase and writes the key file.")
+		fmt.Fprintln(flagSet.Output(), "The passphrase is taken from the \"FAUCET_KEY_FILE_PASSPHRASE\" environment variable, or prompted for.")
+		fmt.Fprintln(flagSet.Output())
+		flagSet.PrintDefaults()
+	}
+
+	outPath := flagSet.String("out", "", "the path of the key file")
+	generate := flagSet.Bool("generate", false, "whether to generate a new private key instead of using \"FAUCET_PRV_KEY\"")
+
+	if err := flagSet.Parse(args); err != nil {
+		return exitCodeUsage
+	}
+
+	if *outPath == "" {
+		flagSet.Usage()
+
+		return exitCodeUsage
+	}
+
+	address, err := encryptKey(*outPath, *generate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encrypting the key failed: %s\n", err)
+
+		return exitCodeFailure
+	}
+	fmt.Printf("wrote key file %s for address %s\n", *outPath, address)
+
+	return exitCodeSuccess
+}
+
+// encryptKey writes the encrypted key file and returns the Ed25519 address of the key.
+func encryptKey(outPath string, generate bool) (*iotago.Ed25519Address, error) {
+	var privateKey ed25519.PrivateKey
+	if generate {
+		var err error
+		if _, privateKey, err = ed25519.GenerateKey(nil); err != nil {
+			return nil, ierrors.Wrap(err, "failed to generate the private key")
+		}
+	} else {
+		var err error
+		if privateKey, err = crypto.ParseEd25519PrivateKeyFromString(os.Getenv("FAUCET_PRV_KEY")); err != nil {
+			return nil, ierrors.New("environment variable 'FAUCET_PRV_KEY' doesn't contain a valid private key")
+		}
+	}
+
+	passphrase, exists := os.LookupEnv("FAUCET_KEY_FILE_PASSPHRASE")
+	if !exists {
+		var err error
+		if passphrase, err = keys.ReadPassphrase("Passphrase: "); err != nil {
+			return nil, err
+		}
+
+		confirmation, err := keys.ReadPassphrase("Repeat the passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+
+		if passphrase != confirmation {
+			return nil, ierrors.New("the passphrases don't match")
+		}
+	}
+
+	data, err := keys.EncryptEd25519PrivateKey(privateKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// the file must not exist yet, so an existing key is never overwritten
+	file, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert // we can safely assume that this is an ed25519.PublicKey
+	return iotago.Ed25519AddressFromPubKey(privateKey.Public().(ed25519.PublicKey)), nil
+}
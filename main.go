@@ -1,9 +1,20 @@
 package main
 
 import (
+	"os"
+
 	"github.com/iotaledger/inx-faucet/components/app"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case requestCommand:
+			os.Exit(runRequestCommand(os.Args[2:]))
+		case encryptKeyCommand:
+			os.Exit(runEncryptKeyCommand(os.Args[2:]))
+		}
+	}
+
 	app.App().Run()
 }
@@ -0,0 +1,253 @@
+// Package audit contains an append-only log of the lifecycle of all faucet requests for abuse forensics.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// EntryKind is the lifecycle step of a request an entry was written for.
+type EntryKind string
+
+const (
+	// EntryKindEnqueued is written when a request was added to the queue.
+	EntryKindEnqueued EntryKind = "enqueued"
+	// EntryKindRejected is written when a request was rejected.
+	EntryKindRejected EntryKind = "rejected"
+	// EntryKindConfirmed is written when the payout of a request was confirmed.
+	EntryKindConfirmed EntryKind = "confirmed"
+	// EntryKindFailed is written when the transaction of a request failed and the request is retried.
+	EntryKindFailed EntryKind = "failed"
+)
+
+// Entry is a single step in the lifecycle of a faucet request.
+type Entry struct {
+	// The time the entry was written.
+	Timestamp time.Time `json:"timestamp"`
+	// The lifecycle step of the request.
+	Kind EntryKind `json:"kind"`
+	// The bech32 address of the request.
+	Address string `json:"address"`
+	// The type of the request.
+	Type string `json:"type,omitempty"`
+	// The tag of the request.
+	Tag string `json:"tag,omitempty"`
+	// The originating IP address of the request.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	// The user agent of the client.
+	UserAgent string `json:"userAgent,omitempty"`
+	// Which of the configured amounts the request receives, e.g. the small amount.
+	Decision string `json:"decision,omitempty"`
+	// The amount of base tokens of the payout.
+	BaseTokenAmount iotago.BaseToken `json:"amount,omitempty"`
+	// The amount of mana of the payout.
+	ManaAmount iotago.Mana `json:"mana,omitempty"`
+	// The machine-readable code of the error the request was rejected with.
+	ErrorCode string `json:"errorCode,omitempty"`
+	// The reason why the request was rejected or the transaction failed.
+	Reason string `json:"reason,omitempty"`
+	// The ID of the faucet transaction.
+	TransactionID string `json:"transactionId,omitempty"`
+	// The ID of the block that contained the faucet transaction.
+	BlockID string `json:"blockId,omitempty"`
+	// The time the request was enqueued.
+	EnqueuedAt *time.Time `json:"enqueuedAt,omitempty"`
+}
+
+// Log is an append-only log of request lifecycle entries that is persisted as JSON lines in a file.
+// Entries older than the retention are removed by Prune.
+// It is safe for concurrent use.
+type Log struct {
+	mutex     sync.Mutex
+	filePath  string
+	file      *os.File
+	retention time.Duration
+}
+
+// NewLog opens the audit log in the given file.
+// A retention of 0 keeps the entries forever.
+func NewLog(filePath string, retention time.Duration) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return nil, ierrors.Wrap(err, "failed to create the audit log directory")
+	}
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to open the audit log")
+	}
+
+	return &Log{
+		filePath:  filePath,
+		file:      file,
+		retention: retention,
+	}, nil
+}
+
+// Append writes the given entries to the log.
+func (l *Log) Append(entries ...*Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, err := l.file.Write(data); err != nil {
+		return ierrors.Wrap(err, "failed to write to the audit log")
+	}
+
+	return nil
+}
+
+// Prune removes the entries that are older than the retention.
+// It returns the amount of removed entries.
+func (l *Log) Prune(now time.Time) (int, error) {
+	if l.retention <= 0 {
+		return 0, nil
+	}
+	threshold := now.Add(-l.retention)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.rewriteWithoutLocking(func(entry *Entry) (bool, bool) {
+		return !entry.Timestamp.Before(threshold), false
+	})
+}
+
+// Anonymize applies the given function to the entries that were written before the given time.
+// The function returns true if it changed the entry, e.g. because it removed or hashed the client metadata.
+// It returns the amount of changed entries.
+func (l *Log) Anonymize(before time.Time, anonymize func(entry *Entry) bool) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.rewriteWithoutLocking(func(entry *Entry) (bool, bool) {
+		if !entry.Timestamp.Before(before) {
+			return true, false
+		}
+
+		return true, anonymize(entry)
+	})
+}
+
+// rewriteWithoutLocking rewrites the log file with the entries the process function keeps, changed entries are written again.
+// Lines that can't be parsed, e.g. a partially written last line after a crash, are dropped.
+// It returns the amount of removed or changed entries.
+// write lock must be acquired outside.
+func (l *Log) rewriteWithoutLocking(process func(entry *Entry) (keep bool, changed bool)) (int, error) {
+	source, err := os.Open(l.filePath)
+	if err != nil {
+		return 0, ierrors.Wrap(err, "failed to open the audit log")
+	}
+	defer source.Close()
+
+	tempFilePath := l.filePath + ".tmp"
+	target, err := os.OpenFile(tempFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, ierrors.Wrap(err, "failed to create the pruned audit log")
+	}
+
+	var affected int
+	writer := bufio.NewWriter(target)
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := &Entry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			affected++
+
+			continue
+		}
+
+		keep, changed := process(entry)
+		if !keep {
+			affected++
+
+			continue
+		}
+
+		if changed {
+			affected++
+
+			if line, err = json.Marshal(entry); err != nil {
+				_ = target.Close()
+
+				return 0, err
+			}
+		}
+
+		if _, err := writer.Write(line); err != nil {
+			_ = target.Close()
+
+			return 0, ierrors.Wrap(err, "failed to write the pruned audit log")
+		}
+		if err := writer.WriteByte('\n'); err != nil {
+			_ = target.Close()
+
+			return 0, ierrors.Wrap(err, "failed to write the pruned audit log")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = target.Close()
+
+		return 0, ierrors.Wrap(err, "failed to read the audit log")
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = target.Close()
+
+		return 0, ierrors.Wrap(err, "failed to write the pruned audit log")
+	}
+	if err := target.Close(); err != nil {
+		return 0, ierrors.Wrap(err, "failed to write the pruned audit log")
+	}
+
+	if affected == 0 {
+		return 0, os.Remove(tempFilePath)
+	}
+
+	if err := os.Rename(tempFilePath, l.filePath); err != nil {
+		return 0, ierrors.Wrap(err, "failed to replace the audit log")
+	}
+
+	// the old file was replaced, so new entries must be appended to the new one
+	file, err := os.OpenFile(l.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, ierrors.Wrap(err, "failed to open the audit log")
+	}
+	_ = l.file.Close()
+	l.file = file
+
+	return affected, nil
+}
+
+// Close closes the file of the log.
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.file.Close()
+}
@@ -0,0 +1,182 @@
+// Package client implements a client for the public REST API of the faucet.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+const (
+	// the header that contains the priority API key.
+	headerAPIKey = "X-API-Key"
+)
+
+// APIError is returned if the faucet answered a request with an error.
+type APIError struct {
+	// The HTTP status code of the response.
+	StatusCode int
+	// The error that was returned by the faucet, it only contains the message if the response could not be decoded.
+	Response faucet.ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	if e.Response.Code != "" {
+		return fmt.Sprintf("faucet returned %d %s: %s", e.StatusCode, e.Response.Code, e.Response.Message)
+	}
+
+	return fmt.Sprintf("faucet returned %d: %s", e.StatusCode, e.Response.Message)
+}
+
+// IsNotFound returns whether the error is an APIError of a resource that does not exist.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !ierrors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client is a client for the public REST API of the faucet.
+type Client struct {
+	// the URL of the faucet, including the base path.
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+}
+
+// Option is a function setting a client option.
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client that is used for the requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sets the priority API key that is sent with the requests.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// New creates a new client for the faucet with the given URL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// do sends a request to the API of the faucet and decodes the response into result.
+func (c *Client) do(ctx context.Context, method string, route string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return ierrors.Wrap(err, "unable to marshal request")
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api"+route, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(headerAPIKey, c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ierrors.Wrap(err, "unable to read response")
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+
+		envelope := &faucet.ErrorResponseEnvelope{}
+		if err := json.Unmarshal(responseBytes, envelope); err == nil && envelope.Error.Code != "" {
+			apiErr.Response = envelope.Error
+		} else {
+			apiErr.Response.Message = strings.TrimSpace(string(responseBytes))
+		}
+
+		return apiErr
+	}
+
+	if err := json.Unmarshal(responseBytes, result); err != nil {
+		return ierrors.Wrap(err, "unable to unmarshal response")
+	}
+
+	return nil
+}
+
+// Info returns the info of the faucet.
+func (c *Client) Info(ctx context.Context) (*faucet.InfoResponse, error) {
+	info := &faucet.InfoResponse{}
+	if err := c.do(ctx, http.MethodGet, "/info", nil, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// Preview returns what a request for the given address would receive.
+func (c *Client) Preview(ctx context.Context, bech32Addr string) (*faucet.PreviewResponse, error) {
+	preview := &faucet.PreviewResponse{}
+	if err := c.do(ctx, http.MethodGet, "/preview?address="+url.QueryEscape(bech32Addr), nil, preview); err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+// Enqueue enqueues a request for funds.
+func (c *Client) Enqueue(ctx context.Context, request *faucet.EnqueueRequest) (*faucet.EnqueueResponse, error) {
+	response := &faucet.EnqueueResponse{}
+	if err := c.do(ctx, http.MethodPost, "/enqueue", request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// RequestStatus returns the processing state of the request of the given address.
+// It returns an APIError for which IsNotFound is true if the faucet doesn't know the request (anymore).
+func (c *Client) RequestStatus(ctx context.Context, bech32Addr string) (*faucet.RequestStatusResponse, error) {
+	status := &faucet.RequestStatusResponse{}
+	if err := c.do(ctx, http.MethodGet, "/requests/"+url.PathEscape(bech32Addr), nil, status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
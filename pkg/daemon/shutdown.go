@@ -2,6 +2,20 @@ package daemon
 
 const (
 	PriorityDisconnectINX = iota // no dependencies
+	PriorityCloseSigner
+	PriorityCloseRedis
+	PriorityClosePayouts
+	PriorityCloseAuditLog
+	PriorityStopPrivacy
+	PriorityStopWebhooks
+	PriorityStopNetworkFilters
 	PriorityStopFaucetAcceptedTransactions
+	PriorityStopFaucetRollbacks
+	PriorityStopFaucetLeaderElection
 	PriorityStopFaucet
+	PriorityStopFaucetSubscriptions
+	PriorityStopFaucetSharedQueue
+	PriorityStopFaucetAPI
+	PriorityStopFaucetReload
+	PriorityStopPrometheus
 )
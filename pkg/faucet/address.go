@@ -0,0 +1,40 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithTargetAddressTypes defines the address types that are accepted as targets of basic requests.
+// Restricted addresses are accepted if the type of the underlying address is accepted.
+func WithTargetAddressTypes(addressTypes ...iotago.AddressType) Option {
+	return func(opts *Options) {
+		opts.targetAddressTypes = make(map[iotago.AddressType]struct{}, len(addressTypes))
+		for _, addressType := range addressTypes {
+			opts.targetAddressTypes[addressType] = struct{}{}
+		}
+	}
+}
+
+// checkTargetAddressType checks if basic outputs of the faucet can be sent to the given address.
+func (f *Faucet) checkTargetAddressType(addr iotago.Address) error {
+	underlyingAddr := addr
+	if restrictedAddress, ok := addr.(*iotago.RestrictedAddress); ok {
+		underlyingAddr = restrictedAddress.Address
+	}
+
+	addressType := underlyingAddr.Type()
+	if addressType == iotago.AddressMulti {
+		// the bech32 encoding of a multi address only contains its hash, which can't be used as the address of an output
+		return NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, "Invalid address provided! Multi addresses are not supported as target.")
+	}
+
+	if _, allowed := f.opts.targetAddressTypes[addressType]; !allowed {
+		return NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, fmt.Sprintf("Invalid address provided! %s is not supported as target by this faucet.", addressType)).
+			WithDetail("addressType", addressType.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// AddressList is an operator-managed list of addresses.
+type AddressList string
+
+const (
+	// AddressListAllow contains the addresses that bypass the maximum target balance check.
+	AddressListAllow AddressList = "allow"
+	// AddressListDeny contains the addresses whose requests are rejected.
+	AddressListDeny AddressList = "deny"
+)
+
+// AddressListEntry is an address on an operator-managed list.
+type AddressListEntry struct {
+	// The bech32 address.
+	Address string `json:"address"`
+	// The amount of base tokens an allowlisted address receives (0 to use the regular amount).
+	BaseTokenAmount iotago.BaseToken `json:"amount,omitempty"`
+	// An optional note of the operator, e.g. why the address was added.
+	Note string `json:"note,omitempty"`
+}
+
+// AddressLists are the operator-managed lists of addresses.
+type AddressLists struct {
+	// The addresses that bypass the maximum target balance check.
+	Allow []*AddressListEntry `json:"allow"`
+	// The addresses whose requests are rejected.
+	Deny []*AddressListEntry `json:"deny"`
+}
+
+// addressListStore holds the operator-managed lists of addresses.
+type addressListStore struct {
+	mutex sync.RWMutex
+	lists map[AddressList]map[string]*AddressListEntry
+}
+
+func newAddressListStore() *addressListStore {
+	return &addressListStore{
+		lists: map[AddressList]map[string]*AddressListEntry{
+			AddressListAllow: make(map[string]*AddressListEntry),
+			AddressListDeny:  make(map[string]*AddressListEntry),
+		},
+	}
+}
+
+// addressListKey returns the key of an address in the lists.
+// bech32 addresses are case-insensitive.
+func addressListKey(bech32Addr string) string {
+	return strings.ToLower(bech32Addr)
+}
+
+// get returns the entry of the given address in the given list or nil if it is not part of the list.
+func (s *addressListStore) get(list AddressList, bech32Addr string) *AddressListEntry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.lists[list][addressListKey(bech32Addr)]
+}
+
+// checkAddressListEntry validates the given list and entry.
+func (f *Faucet) checkAddressListEntry(list AddressList, entry *AddressListEntry) error {
+	switch list {
+	case AddressListAllow, AddressListDeny:
+	default:
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid address list \"%s\" provided!", list))
+	}
+
+	if _, err := f.parseBech32Address(entry.Address); err != nil {
+		return err
+	}
+
+	if list == AddressListDeny && entry.BaseTokenAmount != 0 {
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "Entries of the denylist must not have an amount.")
+	}
+
+	return nil
+}
+
+// AddressLists returns the operator-managed lists of addresses, sorted by address.
+func (f *Faucet) AddressLists() *AddressLists {
+	f.addressLists.mutex.RLock()
+	defer f.addressLists.mutex.RUnlock()
+
+	sortedEntries := func(entries map[string]*AddressListEntry) []*AddressListEntry {
+		result := make([]*AddressListEntry, 0, len(entries))
+		for _, entry := range entries {
+			entryCopy := *entry
+			result = append(result, &entryCopy)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return addressListKey(result[i].Address) < addressListKey(result[j].Address)
+		})
+
+		return result
+	}
+
+	return &AddressLists{
+		Allow: sortedEntries(f.addressLists.lists[AddressListAllow]),
+		Deny:  sortedEntries(f.addressLists.lists[AddressListDeny]),
+	}
+}
+
+// SetAddressLists validates the given lists and replaces the current lists.
+func (f *Faucet) SetAddressLists(lists *AddressLists) error {
+	newLists := newAddressListStore().lists
+
+	for list, entries := range map[AddressList][]*AddressListEntry{
+		AddressListAllow: lists.Allow,
+		AddressListDeny:  lists.Deny,
+	} {
+		for _, entry := range entries {
+			if err := f.checkAddressListEntry(list, entry); err != nil {
+				return err
+			}
+
+			entryCopy := *entry
+			newLists[list][addressListKey(entry.Address)] = &entryCopy
+		}
+	}
+
+	for key := range newLists[AddressListAllow] {
+		if _, exists := newLists[AddressListDeny][key]; exists {
+			return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Address %s is on the allowlist and the denylist.", key))
+		}
+	}
+
+	f.addressLists.mutex.Lock()
+	defer f.addressLists.mutex.Unlock()
+
+	f.addressLists.lists = newLists
+
+	return nil
+}
+
+// AddAddressListEntry adds an address to the given list or replaces its entry.
+// An address can only be on one of the lists, so it is removed from the other list.
+func (f *Faucet) AddAddressListEntry(list AddressList, entry *AddressListEntry) error {
+	if err := f.checkAddressListEntry(list, entry); err != nil {
+		return err
+	}
+
+	f.addressLists.mutex.Lock()
+	defer f.addressLists.mutex.Unlock()
+
+	key := addressListKey(entry.Address)
+	for otherList, entries := range f.addressLists.lists {
+		if otherList != list {
+			delete(entries, key)
+		}
+	}
+
+	entryCopy := *entry
+	f.addressLists.lists[list][key] = &entryCopy
+
+	return nil
+}
+
+// RemoveAddressListEntry removes an address from the given list.
+// It returns false if the address was not part of the list.
+func (f *Faucet) RemoveAddressListEntry(list AddressList, bech32Addr string) bool {
+	f.addressLists.mutex.Lock()
+	defer f.addressLists.mutex.Unlock()
+
+	entries, exists := f.addressLists.lists[list]
+	if !exists {
+		return false
+	}
+
+	key := addressListKey(bech32Addr)
+	if _, exists := entries[key]; !exists {
+		return false
+	}
+	delete(entries, key)
+
+	return true
+}
+
+// checkDenylist returns an error if the given address is on the denylist.
+func (f *Faucet) checkDenylist(bech32Addr string) error {
+	if f.addressLists.get(AddressListDeny, bech32Addr) != nil {
+		return NewRequestError(ErrorCodeAddressDenied, http.StatusForbidden, "Requests for this address are not allowed.")
+	}
+
+	return nil
+}
+
+// allowlistPayoutAmounts returns the amount of base tokens an allowlisted address receives
+// and whether the mana payout is skipped. The balance of the address is not checked.
+func (f *Faucet) allowlistPayoutAmounts(addr iotago.Address, entry *AddressListEntry) (iotago.BaseToken, bool) {
+	baseTokenAmount := entry.BaseTokenAmount
+	if baseTokenAmount == 0 {
+		baseTokenAmount = f.RuntimeParameters().BaseTokenAmount
+	}
+
+	return baseTokenAmount, !canReceiveMana(addr)
+}
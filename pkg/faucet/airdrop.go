@@ -0,0 +1,473 @@
+package faucet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// AirdropState is the state of an airdrop.
+type AirdropState string
+
+const (
+	// AirdropStateRunning means that the airdrop still has entries that are not paid out.
+	AirdropStateRunning AirdropState = "running"
+	// AirdropStateCompleted means that all entries of the airdrop were processed.
+	AirdropStateCompleted AirdropState = "completed"
+	// AirdropStateCanceled means that the airdrop was canceled by the operator.
+	AirdropStateCanceled AirdropState = "canceled"
+)
+
+// AirdropEntryState is the state of a single payout of an airdrop.
+type AirdropEntryState string
+
+const (
+	// AirdropEntryStatePending means that the entry waits to be added to a transaction.
+	AirdropEntryStatePending AirdropEntryState = "pending"
+	// AirdropEntryStateProcessing means that the entry is part of a transaction that is not confirmed yet.
+	AirdropEntryStateProcessing AirdropEntryState = "processing"
+	// AirdropEntryStatePaid means that the transaction of the entry was confirmed.
+	AirdropEntryStatePaid AirdropEntryState = "paid"
+	// AirdropEntryStateFailed means that the entry couldn't be paid out.
+	AirdropEntryStateFailed AirdropEntryState = "failed"
+	// AirdropEntryStateCanceled means that the airdrop was canceled before the entry was paid out.
+	AirdropEntryStateCanceled AirdropEntryState = "canceled"
+)
+
+// WithAirdropBatchSize defines the maximum amount of airdrop payouts per transaction.
+func WithAirdropBatchSize(airdropBatchSize int) Option {
+	return func(opts *Options) {
+		opts.airdropBatchSize = airdropBatchSize
+	}
+}
+
+// AirdropPayout is a single payout of a new airdrop.
+type AirdropPayout struct {
+	// The bech32 address.
+	Address string
+	// The amount of base tokens that will be paid out.
+	BaseTokenAmount iotago.BaseToken
+}
+
+// AirdropEntry is the state of a single payout of an airdrop.
+type AirdropEntry struct {
+	// The bech32 address.
+	Address string `json:"address"`
+	// The amount of base tokens that will be paid out.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of base tokens that will be paid out in token units, e.g. "10.5".
+	BaseTokenAmountFormatted string `json:"amountFormatted"`
+	// The state of the payout.
+	State AirdropEntryState `json:"state"`
+	// The ID of the transaction of the payout.
+	TransactionID string `json:"transactionId,omitempty"`
+	// The reason why the payout failed.
+	Error string `json:"error,omitempty"`
+
+	address iotago.Address
+	airdrop *airdrop
+}
+
+// AirdropStatus is the progress of an airdrop.
+type AirdropStatus struct {
+	// The unique ID of the airdrop.
+	ID string `json:"id"`
+	// The name of the airdrop.
+	Name string `json:"name,omitempty"`
+	// The tag that is added to the data of the transactions of the airdrop.
+	Tag string `json:"tag,omitempty"`
+	// The state of the airdrop.
+	State AirdropState `json:"state"`
+	// The time the airdrop was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// The amount of payouts per state.
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Paid       int `json:"paid"`
+	Failed     int `json:"failed"`
+	Canceled   int `json:"canceled"`
+	// The base tokens of all payouts.
+	TotalBaseTokens iotago.BaseToken `json:"totalAmount"`
+	// The base tokens of the confirmed payouts.
+	PaidBaseTokens iotago.BaseToken `json:"paidAmount"`
+	// The base tokens of all payouts in token units, e.g. "10.5".
+	TotalBaseTokensFormatted string `json:"totalAmountFormatted"`
+	// The base tokens of the confirmed payouts in token units, e.g. "10.5".
+	PaidBaseTokensFormatted string `json:"paidAmountFormatted"`
+	// The single payouts, they are only part of the details of a single airdrop.
+	Entries []*AirdropEntry `json:"entries,omitempty"`
+}
+
+// airdrop is a list of payouts that is processed separately from the public request queue.
+type airdrop struct {
+	id        string
+	name      string
+	tag       string
+	createdAt time.Time
+	canceled  bool
+	entries   []*AirdropEntry
+	// the entries that wait to be added to a transaction, in order.
+	pending []*AirdropEntry
+}
+
+// status returns the progress of the airdrop, the amounts are formatted with the given number of decimals.
+func (a *airdrop) status(withEntries bool, tokenDecimals uint32) *AirdropStatus {
+	status := &AirdropStatus{
+		ID:        a.id,
+		Name:      a.name,
+		Tag:       a.tag,
+		State:     AirdropStateRunning,
+		CreatedAt: a.createdAt,
+		Total:     len(a.entries),
+	}
+
+	for _, entry := range a.entries {
+		status.TotalBaseTokens += entry.BaseTokenAmount
+
+		switch entry.State {
+		case AirdropEntryStatePending:
+			status.Pending++
+		case AirdropEntryStateProcessing:
+			status.Processing++
+		case AirdropEntryStatePaid:
+			status.Paid++
+			status.PaidBaseTokens += entry.BaseTokenAmount
+		case AirdropEntryStateFailed:
+			status.Failed++
+		case AirdropEntryStateCanceled:
+			status.Canceled++
+		}
+
+		if withEntries {
+			entryCopy := *entry
+			entryCopy.BaseTokenAmountFormatted = FormatBaseTokenAmount(entry.BaseTokenAmount, tokenDecimals)
+			status.Entries = append(status.Entries, &entryCopy)
+		}
+	}
+
+	switch {
+	case a.canceled:
+		status.State = AirdropStateCanceled
+	case status.Pending == 0 && status.Processing == 0:
+		status.State = AirdropStateCompleted
+	}
+
+	status.TotalBaseTokensFormatted = FormatBaseTokenAmount(status.TotalBaseTokens, tokenDecimals)
+	status.PaidBaseTokensFormatted = FormatBaseTokenAmount(status.PaidBaseTokens, tokenDecimals)
+
+	return status
+}
+
+// airdropQueue holds the airdrops in the order they were created.
+// It is safe for concurrent use.
+type airdropQueue struct {
+	mutex    sync.Mutex
+	airdrops []*airdrop
+	// lastBatch is true if the last transaction contained airdrop payouts, so the public queue is served next.
+	lastBatch bool
+}
+
+func newAirdropQueue() *airdropQueue {
+	return &airdropQueue{
+		airdrops: make([]*airdrop, 0),
+	}
+}
+
+// pendingBaseTokens returns the base tokens of all entries that are not paid out yet.
+func (q *airdropQueue) pendingBaseTokens() iotago.BaseToken {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var pending iotago.BaseToken
+	for _, airdrop := range q.airdrops {
+		for _, entry := range airdrop.pending {
+			pending += entry.BaseTokenAmount
+		}
+	}
+
+	return pending
+}
+
+// nextBatch takes up to batchSize pending entries of the oldest running airdrop.
+// the airdrops and the public queue take turns, unless the public queue is empty.
+func (q *airdropQueue) nextBatch(batchSize int, publicQueueEmpty bool) []*queueItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.lastBatch && !publicQueueEmpty {
+		q.lastBatch = false
+
+		return nil
+	}
+
+	for _, airdrop := range q.airdrops {
+		if len(airdrop.pending) == 0 {
+			continue
+		}
+
+		count := min(batchSize, len(airdrop.pending))
+		batch := make([]*queueItem, 0, count)
+		for _, entry := range airdrop.pending[:count] {
+			entry.State = AirdropEntryStateProcessing
+			batch = append(batch, &queueItem{
+				Bech32:          entry.Address,
+				BaseTokenAmount: entry.BaseTokenAmount,
+				Address:         entry.address,
+				Tag:             airdrop.tag,
+				Type:            RequestTypeBasic,
+				EnqueuedAt:      airdrop.createdAt,
+				Priority:        RequestPriorityRetry,
+				airdropEntry:    entry,
+			})
+		}
+		airdrop.pending = airdrop.pending[count:]
+		q.lastBatch = true
+
+		return batch
+	}
+
+	q.lastBatch = false
+
+	return nil
+}
+
+// requeue adds the entries of the given airdrop requests back to the front of their airdrops.
+func (q *airdropQueue) requeue(requests []*queueItem) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i := len(requests) - 1; i >= 0; i-- {
+		entry := requests[i].airdropEntry
+		if entry.State != AirdropEntryStateProcessing {
+			continue
+		}
+
+		if entry.airdrop.canceled {
+			entry.State = AirdropEntryStateCanceled
+
+			continue
+		}
+
+		entry.State = AirdropEntryStatePending
+		entry.airdrop.pending = append([]*AirdropEntry{entry}, entry.airdrop.pending...)
+	}
+}
+
+// reopen marks the paid entries of the given airdrop requests as processing again, e.g. if their transaction was rolled back.
+func (q *airdropQueue) reopen(requests []*queueItem) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, request := range requests {
+		entry := request.airdropEntry
+		if entry.State != AirdropEntryStatePaid {
+			continue
+		}
+
+		entry.State = AirdropEntryStateProcessing
+		entry.TransactionID = ""
+	}
+}
+
+// finish sets the final state of the entry of the given airdrop request.
+func (q *airdropQueue) finish(request *queueItem, state AirdropEntryState, transactionID string, reason string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entry := request.airdropEntry
+	if entry.State != AirdropEntryStateProcessing {
+		return
+	}
+
+	entry.State = state
+	entry.TransactionID = transactionID
+	entry.Error = reason
+}
+
+// splitAirdropRequests separates the requests of airdrops from the public requests.
+func splitAirdropRequests(requests []*queueItem) ([]*queueItem, []*queueItem) {
+	var publicRequests, airdropRequests []*queueItem
+	for _, request := range requests {
+		if request.airdropEntry != nil {
+			airdropRequests = append(airdropRequests, request)
+
+			continue
+		}
+		publicRequests = append(publicRequests, request)
+	}
+
+	return publicRequests, airdropRequests
+}
+
+// newAirdropID creates a random ID for an airdrop.
+func newAirdropID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", ierrors.Wrap(err, "failed to create airdrop ID")
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// StartAirdrop validates the given payouts and schedules them in a new airdrop.
+// The payouts are sent in dedicated transactions that take turns with the transactions of the public queue.
+// Airdrops are kept in memory, the pending payouts are lost on restart.
+func (f *Faucet) StartAirdrop(name string, tag string, payouts []*AirdropPayout) (*AirdropStatus, error) {
+	if !f.IsLeader() {
+		return nil, NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "Airdrops can only be started on the instance that issues the faucet transactions.")
+	}
+
+	if len(payouts) == 0 {
+		return nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "The airdrop contains no payouts.")
+	}
+
+	tag, err := f.sanitizeRequestTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newAirdropID()
+	if err != nil {
+		return nil, err
+	}
+
+	newAirdrop := &airdrop{
+		id:        id,
+		name:      name,
+		tag:       tag,
+		createdAt: f.opts.clock.Now(),
+		entries:   make([]*AirdropEntry, 0, len(payouts)),
+	}
+
+	var totalBaseTokens iotago.BaseToken
+	seen := make(map[string]struct{}, len(payouts))
+	for i, payout := range payouts {
+		// the line numbers of the uploaded file start at 1
+		line := i + 1
+
+		addr, bech32Addr, err := f.canonicalBech32Address(payout.Address)
+		if err != nil {
+			return nil, NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, fmt.Sprintf("Invalid address in entry %d: %s", line, payout.Address)).WithDetail("entry", line)
+		}
+
+		if err := f.checkTargetAddressType(addr); err != nil {
+			return nil, NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, fmt.Sprintf("Unsupported address in entry %d: %s", line, payout.Address)).WithDetail("entry", line)
+		}
+
+		if err := f.checkPayoutTarget(addr); err != nil {
+			reqErr := AsRequestError(err)
+
+			return nil, NewRequestError(reqErr.Code, reqErr.StatusCode, fmt.Sprintf("%s Entry %d: %s", reqErr.Message, line, payout.Address)).WithDetail("entry", line)
+		}
+
+		if payout.BaseTokenAmount == 0 {
+			return nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid amount in entry %d.", line)).WithDetail("entry", line)
+		}
+
+		baseTokenAmount, err := f.applyMinStorageDeposit(RequestTypeBasic, addr, payout.BaseTokenAmount)
+		if err != nil {
+			reqErr := AsRequestError(err)
+
+			return nil, NewRequestError(reqErr.Code, reqErr.StatusCode, fmt.Sprintf("%s Entry %d: %s", reqErr.Message, line, payout.Address)).WithDetail("entry", line)
+		}
+
+		if _, exists := seen[bech32Addr]; exists {
+			return nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Duplicate address in entry %d: %s", line, payout.Address)).WithDetail("entry", line)
+		}
+		seen[bech32Addr] = struct{}{}
+
+		totalBaseTokens += baseTokenAmount
+		newAirdrop.entries = append(newAirdrop.entries, &AirdropEntry{
+			Address:         bech32Addr,
+			BaseTokenAmount: baseTokenAmount,
+			State:           AirdropEntryStatePending,
+			address:         addr,
+			airdrop:         newAirdrop,
+		})
+	}
+	newAirdrop.pending = append([]*AirdropEntry{}, newAirdrop.entries...)
+
+	f.RLock()
+	availableBaseTokens := f.reservations.availableBaseTokens()
+	f.RUnlock()
+
+	// the public requests keep their reservations, so the airdrops must fit into the remaining funds
+	if required := totalBaseTokens + f.airdrops.pendingBaseTokens(); required > availableBaseTokens {
+		return nil, NewRequestError(ErrorCodeFaucetNotEnoughFunds, http.StatusServiceUnavailable, fmt.Sprintf("Faucet does not have enough funds for the airdrop (%d required, %d available).", required, availableBaseTokens))
+	}
+
+	f.airdrops.mutex.Lock()
+	f.airdrops.airdrops = append(f.airdrops.airdrops, newAirdrop)
+	status := newAirdrop.status(false, f.opts.tokenDecimals)
+	f.airdrops.mutex.Unlock()
+
+	f.LogInfof("started airdrop %s with %d payouts (%d base tokens)", id, len(newAirdrop.entries), totalBaseTokens)
+
+	return status, nil
+}
+
+// Airdrops returns the progress of all airdrops, the newest first.
+func (f *Faucet) Airdrops() []*AirdropStatus {
+	f.airdrops.mutex.Lock()
+	defer f.airdrops.mutex.Unlock()
+
+	statuses := make([]*AirdropStatus, 0, len(f.airdrops.airdrops))
+	for i := len(f.airdrops.airdrops) - 1; i >= 0; i-- {
+		statuses = append(statuses, f.airdrops.airdrops[i].status(false, f.opts.tokenDecimals))
+	}
+
+	return statuses
+}
+
+// Airdrop returns the progress of the airdrop with the given ID including its single payouts.
+func (f *Faucet) Airdrop(id string) (*AirdropStatus, error) {
+	f.airdrops.mutex.Lock()
+	defer f.airdrops.mutex.Unlock()
+
+	for _, airdrop := range f.airdrops.airdrops {
+		if airdrop.id == id {
+			return airdrop.status(true, f.opts.tokenDecimals), nil
+		}
+	}
+
+	return nil, NewRequestError(ErrorCodeNotFound, http.StatusNotFound, "Airdrop not found.")
+}
+
+// CancelAirdrop cancels the payouts of the airdrop with the given ID that were not added to a transaction yet.
+func (f *Faucet) CancelAirdrop(id string) (*AirdropStatus, error) {
+	f.airdrops.mutex.Lock()
+	defer f.airdrops.mutex.Unlock()
+
+	for _, airdrop := range f.airdrops.airdrops {
+		if airdrop.id != id {
+			continue
+		}
+
+		airdrop.canceled = true
+		for _, entry := range airdrop.pending {
+			entry.State = AirdropEntryStateCanceled
+		}
+		airdrop.pending = nil
+
+		return airdrop.status(false, f.opts.tokenDecimals), nil
+	}
+
+	return nil, NewRequestError(ErrorCodeNotFound, http.StatusNotFound, "Airdrop not found.")
+}
+
+// collectAirdropRequests returns the next batch of airdrop payouts if it is the turn of the airdrops.
+func (f *Faucet) collectAirdropRequests() []*queueItem {
+	batchSize := f.opts.airdropBatchSize
+	if batchSize <= 0 || batchSize > iotago.MaxOutputsCount-1 {
+		batchSize = iotago.MaxOutputsCount - 1
+	}
+
+	return f.airdrops.nextBatch(batchSize, f.queue.len() == 0)
+}
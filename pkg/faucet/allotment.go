@@ -0,0 +1,57 @@
+package faucet
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/hive.go/core/safemath"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithAllotmentManaAmount defines the amount of mana that is allotted to an account per allotment request.
+// A value of 0 disables allotment requests.
+func WithAllotmentManaAmount(allotmentManaAmount iotago.Mana) Option {
+	return func(opts *Options) {
+		opts.allotmentManaAmount = allotmentManaAmount
+	}
+}
+
+// checkAllotmentRequest checks if an allotment request to the given address can be processed.
+func (f *Faucet) checkAllotmentRequest(addr iotago.Address) error {
+	if f.opts.allotmentManaAmount == 0 {
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "Allotment requests are not supported by this faucet.")
+	}
+
+	if _, ok := addr.(*iotago.AccountAddress); !ok {
+		return NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, "Invalid address provided! Allotment requests require an account address.")
+	}
+
+	return nil
+}
+
+// requiredManaPayouts returns the amount of mana that is needed to pay out the given requests.
+func (f *Faucet) requiredManaPayouts(batchedRequests []*queueItem) (iotago.Mana, error) {
+	var totalManaPayouts iotago.Mana
+	for _, req := range batchedRequests {
+		manaAmount := f.opts.manaAmount
+		switch req.Type {
+		case RequestTypeDelegation:
+			// delegation outputs can't hold mana
+			continue
+		default:
+			if req.SkipMana || !canReceiveMana(req.Address) {
+				// the target address already holds enough mana or can't receive mana
+				continue
+			}
+		case RequestTypeAllotment:
+			manaAmount = f.opts.allotmentManaAmount
+		}
+
+		var err error
+		totalManaPayouts, err = safemath.SafeAdd(totalManaPayouts, manaAmount)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return totalManaPayouts, nil
+}
@@ -0,0 +1,88 @@
+package faucet
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// ErrInvalidAmount is returned if an amount can't be parsed.
+var ErrInvalidAmount = ierrors.New("invalid amount")
+
+// WithTokenDecimals defines the number of decimals of the base token of the network.
+// It is used to parse and format amounts in token units, e.g. "10.5".
+func WithTokenDecimals(tokenDecimals uint32) Option {
+	return func(opts *Options) {
+		opts.tokenDecimals = tokenDecimals
+	}
+}
+
+// ParseBaseTokenAmount parses an amount of base tokens.
+// Amounts with a decimal point are in token units with the given number of decimals, e.g. "10.5",
+// amounts without one are integers of the smallest unit, so existing integrations keep working.
+func ParseBaseTokenAmount(amount string, decimals uint32) (iotago.BaseToken, error) {
+	amount = strings.TrimSpace(amount)
+
+	integerPart, fractionalPart, isTokenUnits := strings.Cut(amount, ".")
+	if !isTokenUnits {
+		value, err := strconv.ParseUint(amount, 10, 64)
+		if err != nil {
+			return 0, ierrors.WithMessagef(ErrInvalidAmount, "\"%s\" is not an integer amount", amount)
+		}
+
+		return iotago.BaseToken(value), nil
+	}
+
+	if integerPart == "" && fractionalPart == "" {
+		return 0, ierrors.WithMessagef(ErrInvalidAmount, "\"%s\" has no digits", amount)
+	}
+
+	if len(fractionalPart) > int(decimals) {
+		return 0, ierrors.WithMessagef(ErrInvalidAmount, "\"%s\" has more than %d decimal places", amount, decimals)
+	}
+
+	if integerPart == "" {
+		integerPart = "0"
+	}
+
+	// the amount is shifted by the decimals, so it is parsed as an integer of the smallest unit
+	value, err := strconv.ParseUint(integerPart+fractionalPart+strings.Repeat("0", int(decimals)-len(fractionalPart)), 10, 64)
+	if err != nil {
+		return 0, ierrors.WithMessagef(ErrInvalidAmount, "\"%s\" is not a valid amount", amount)
+	}
+
+	return iotago.BaseToken(value), nil
+}
+
+// FormatBaseTokenAmount formats an amount of base tokens in token units with the given number of decimals, e.g. "10.5".
+// Trailing zeros of the decimal places are omitted.
+func FormatBaseTokenAmount(amount iotago.BaseToken, decimals uint32) string {
+	digits := strconv.FormatUint(uint64(amount), 10)
+	if decimals == 0 {
+		return digits
+	}
+
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+
+	integerPart := digits[:len(digits)-int(decimals)]
+	fractionalPart := strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fractionalPart == "" {
+		return integerPart
+	}
+
+	return integerPart + "." + fractionalPart
+}
+
+// ParseBaseTokens parses an amount of base tokens with the decimals of the network, see ParseBaseTokenAmount.
+func (f *Faucet) ParseBaseTokens(amount string) (iotago.BaseToken, error) {
+	return ParseBaseTokenAmount(amount, f.opts.tokenDecimals)
+}
+
+// FormatBaseTokens formats an amount of base tokens in token units with the decimals of the network.
+func (f *Faucet) FormatBaseTokens(amount iotago.BaseToken) string {
+	return FormatBaseTokenAmount(amount, f.opts.tokenDecimals)
+}
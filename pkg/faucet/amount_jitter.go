@@ -0,0 +1,37 @@
+package faucet
+
+import (
+	"math/rand/v2"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithBaseTokenAmountJitter defines the fraction of the full and the small amount that is randomly added or subtracted,
+// e.g. 0.1 pays out between 0.9 and 1.1 tokens instead of 1, so the payouts are less predictable. Zero disables it.
+// The payout never exceeds the amount that was reserved on enqueue, so the full amount is only lowered.
+func WithBaseTokenAmountJitter(jitter float64) Option {
+	return func(opts *Options) {
+		opts.baseTokenAmountJitter = jitter
+	}
+}
+
+// isJitteredAmountKind checks if payouts of the given kind are randomized.
+// allowlisted and requested amounts are paid out exactly.
+func isJitteredAmountKind(amountKind PayoutAmountKind) bool {
+	return amountKind == PayoutAmountFull || amountKind == PayoutAmountSmall
+}
+
+// jitterBaseTokenAmount randomly adds or subtracts up to the configured fraction of the given amount.
+func (f *Faucet) jitterBaseTokenAmount(baseTokenAmount iotago.BaseToken) iotago.BaseToken {
+	if f.opts.baseTokenAmountJitter <= 0 {
+		return baseTokenAmount
+	}
+
+	//nolint:gosec // we don't need crypto secure randomness for the jitter
+	jitteredAmount := int64(baseTokenAmount) + int64((rand.Float64()*2-1)*f.opts.baseTokenAmountJitter*float64(baseTokenAmount))
+	if jitteredAmount < 0 {
+		return 0
+	}
+
+	return iotago.BaseToken(jitteredAmount)
+}
@@ -0,0 +1,134 @@
+package faucet
+
+import (
+	"sync"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// balanceCacheEntry is the cached funds of a target address.
+type balanceCacheEntry struct {
+	// closed if the funds were loaded.
+	loaded chan struct{}
+
+	balance   iotago.BaseToken
+	mana      iotago.Mana
+	err       error
+	expiresAt time.Time
+}
+
+// balanceCache caches the unlockable funds of target addresses for a short time,
+// so bursts of duplicate requests don't query the indexer for the same address again.
+// Concurrent lookups of the same address share a single query.
+type balanceCache struct {
+	mutex sync.Mutex
+	// the duration the funds are cached, 0 disables the cache.
+	ttl   time.Duration
+	clock Clock
+	// the cached funds per address key.
+	entries map[string]*balanceCacheEntry
+	// the time the expired entries were removed the last time.
+	lastPrune time.Time
+}
+
+func newBalanceCache(ttl time.Duration, clock Clock) *balanceCache {
+	return &balanceCache{
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]*balanceCacheEntry),
+	}
+}
+
+// WithBalanceCacheTTL defines the duration the funds on a target address are cached.
+// A value of 0 disables the cache.
+func WithBalanceCacheTTL(ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.balanceCacheTTL = ttl
+	}
+}
+
+// addressBalance returns the unlockable funds on the given address, they are cached for a short time.
+func (f *Faucet) addressBalance(addr iotago.Address) (iotago.BaseToken, iotago.Mana, error) {
+	return f.balanceCache.get(addr, func() (iotago.BaseToken, iotago.Mana, error) {
+		return f.node.ComputeUnlockableAddressBalance(addr)
+	})
+}
+
+// get returns the cached funds of the given address, or loads them if they are unknown or expired.
+// failed lookups are not cached.
+func (c *balanceCache) get(addr iotago.Address, load func() (iotago.BaseToken, iotago.Mana, error)) (iotago.BaseToken, iotago.Mana, error) {
+	if c.ttl <= 0 {
+		return load()
+	}
+
+	key := addr.Key()
+
+	c.mutex.Lock()
+	if entry, exists := c.entries[key]; exists {
+		select {
+		case <-entry.loaded:
+			if c.clock.Now().Before(entry.expiresAt) {
+				c.mutex.Unlock()
+
+				return entry.balance, entry.mana, nil
+			}
+		default:
+			// another lookup of the same address is in progress
+			c.mutex.Unlock()
+			<-entry.loaded
+
+			return entry.balance, entry.mana, entry.err
+		}
+	}
+
+	entry := &balanceCacheEntry{loaded: make(chan struct{})}
+	c.entries[key] = entry
+	c.pruneWithoutLocking()
+	c.mutex.Unlock()
+
+	balance, mana, err := load()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry.balance, entry.mana, entry.err = balance, mana, err
+	entry.expiresAt = c.clock.Now().Add(c.ttl)
+	close(entry.loaded)
+
+	if err != nil && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+
+	return balance, mana, err
+}
+
+// invalidate removes the cached funds of the given addresses, e.g. because the faucet sent funds to them.
+func (c *balanceCache) invalidate(addrs ...iotago.Address) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, addr := range addrs {
+		delete(c.entries, addr.Key())
+	}
+}
+
+// pruneWithoutLocking removes the expired entries, at most once per TTL.
+// write lock must be acquired outside.
+func (c *balanceCache) pruneWithoutLocking() {
+	now := c.clock.Now()
+	if now.Sub(c.lastPrune) < c.ttl {
+		return
+	}
+	c.lastPrune = now
+
+	for key, entry := range c.entries {
+		select {
+		case <-entry.loaded:
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		default:
+		}
+	}
+}
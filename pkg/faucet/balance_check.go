@@ -0,0 +1,201 @@
+package faucet
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithBalanceCheckRateLimit defines how many lookups of the funds on target addresses
+// are started per second and how many of them run in parallel when a batch is processed.
+// A maxPerSecond of 0 disables the rate limit.
+func WithBalanceCheckRateLimit(maxPerSecond int, maxConcurrent int) Option {
+	return func(opts *Options) {
+		opts.balanceCheckMaxPerSecond = maxPerSecond
+		opts.balanceCheckMaxConcurrent = maxConcurrent
+	}
+}
+
+// balanceCheckLimiter paces the lookups of the funds on target addresses, so a full queue doesn't flood the indexer.
+// it is only used by the faucet loop, so no locking is needed.
+type balanceCheckLimiter struct {
+	// the minimum duration between two lookups, 0 disables the limit.
+	interval time.Duration
+	clock    Clock
+	// the earliest time the next lookup may be started.
+	next time.Time
+}
+
+func newBalanceCheckLimiter(maxPerSecond int, clock Clock) *balanceCheckLimiter {
+	limiter := &balanceCheckLimiter{
+		clock: clock,
+	}
+	if maxPerSecond > 0 {
+		limiter.interval = time.Second / time.Duration(maxPerSecond)
+	}
+
+	return limiter
+}
+
+// wait blocks until the next lookup may be started.
+func (l *balanceCheckLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+
+	now := l.clock.Now()
+	if wait := l.next.Sub(now); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ErrOperationAborted
+		case <-l.clock.After(wait):
+		}
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+
+	return nil
+}
+
+// balanceCheckResult is the payout of a request that was determined from the funds on its target address.
+type balanceCheckResult struct {
+	baseTokenAmount iotago.BaseToken
+	skipMana        bool
+	amountKind      PayoutAmountKind
+	err             error
+}
+
+// checkTargetBalances checks the funds on the target addresses of the collected requests that were not checked yet.
+// The payout amounts of the requests are adapted to the funds, requests to addresses that already hold enough funds are dropped.
+// The lookups run in parallel without holding the lock of the faucet, so slow responses of the indexer don't block the API.
+// If the context is done, the requests that were not checked yet are returned unchanged together with ErrOperationAborted.
+// locking not required.
+func (f *Faucet) checkTargetBalances(ctx context.Context, batchedRequests []*queueItem) ([]*queueItem, error) {
+	results := make([]*balanceCheckResult, len(batchedRequests))
+
+	maxConcurrent := f.opts.balanceCheckMaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	semaphore := make(chan struct{}, maxConcurrent)
+
+	var wg sync.WaitGroup
+	var abortErr error
+
+CheckRequests:
+	for i, request := range batchedRequests {
+		if !request.BalanceUnchecked {
+			continue
+		}
+
+		if err := f.balanceCheckLimiter.wait(ctx); err != nil {
+			abortErr = err
+
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			abortErr = ErrOperationAborted
+
+			break CheckRequests
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			baseTokenAmount, skipMana, err := f.targetPayoutAmounts(request.Address)
+			if err != nil {
+				results[i] = &balanceCheckResult{err: err}
+
+				return
+			}
+
+			if request.AmountRequested {
+				baseTokenAmount = f.requestedPayoutAmount(request.BaseTokenAmount, baseTokenAmount)
+			}
+
+			amountKind := f.payoutAmountKind(request.Type, min(baseTokenAmount, request.BaseTokenAmount), false)
+			if request.AmountRequested && baseTokenAmount >= request.BaseTokenAmount {
+				amountKind = PayoutAmountRequested
+			}
+
+			// the amount is randomized after the funds are checked, so the randomization is not undone by the checks
+			if isJitteredAmountKind(amountKind) {
+				baseTokenAmount = f.jitterBaseTokenAmount(baseTokenAmount)
+			}
+
+			// the request validators may have lowered the amount already and it was reserved on enqueue,
+			// so it is never raised, not even by the randomization
+			baseTokenAmount = min(baseTokenAmount, request.BaseTokenAmount)
+
+			// the lowered amount must still cover the storage deposit of the output
+			baseTokenAmount, err = f.applyMinStorageDeposit(request.Type, request.Address, baseTokenAmount)
+			if err != nil {
+				results[i] = &balanceCheckResult{err: err}
+
+				return
+			}
+
+			results[i] = &balanceCheckResult{
+				baseTokenAmount: baseTokenAmount,
+				skipMana:        request.SkipMana || skipMana,
+				amountKind:      amountKind,
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkedRequests := make([]*queueItem, 0, len(batchedRequests))
+	rejectedRequests := make([]*RejectedRequest, 0)
+
+	f.Lock()
+	for i, request := range batchedRequests {
+		result := results[i]
+		if result == nil {
+			// the request was checked already or the check was aborted
+			checkedRequests = append(checkedRequests, request)
+
+			continue
+		}
+
+		if result.err != nil {
+			// the address holds enough funds already or the payout doesn't cover the storage deposit => drop the request
+			f.clearRequestWithoutLocking(request)
+			rejectedRequests = append(rejectedRequests, &RejectedRequest{
+				Bech32: request.Bech32,
+				Type:   request.Type,
+				Tag:    request.Tag,
+				Client: &ClientMetadata{
+					RemoteIP:  request.RemoteIP,
+					UserAgent: request.UserAgent,
+				},
+				Error: AsRequestError(result.err),
+			})
+
+			continue
+		}
+
+		request.BalanceUnchecked = false
+		request.BaseTokenAmount = result.baseTokenAmount
+		request.SkipMana = result.skipMana
+		request.AmountKind = result.amountKind
+
+		// the reservation is replaced with the adapted amounts
+		f.reserveRequestWithoutLocking(request)
+		checkedRequests = append(checkedRequests, request)
+	}
+	f.Unlock()
+
+	for _, rejectedRequest := range rejectedRequests {
+		f.LogDebugf("dropped request of %s: %s", rejectedRequest.Bech32, rejectedRequest.Error.Message)
+		f.Events.RequestRejected.Trigger(rejectedRequest)
+	}
+
+	return checkedRequests, abortErr
+}
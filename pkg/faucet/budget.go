@@ -0,0 +1,159 @@
+package faucet
+
+import (
+	"net/http"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// the duration of the rolling window of the daily budget.
+	dailyBudgetWindow = 24 * time.Hour
+)
+
+// Budget is the maximum amount of base tokens and mana the faucet may distribute in a period.
+// A value of 0 disables the respective limit.
+type Budget struct {
+	BaseTokens iotago.BaseToken
+	Mana       iotago.Mana
+}
+
+// distribution is the amount of funds that were distributed by a confirmed faucet transaction.
+type distribution struct {
+	Time       time.Time
+	Epoch      iotago.EpochIndex
+	BaseTokens iotago.BaseToken
+	Mana       iotago.Mana
+}
+
+// WithDailyBudget defines the maximum amount of funds the faucet may distribute in a rolling 24h window.
+func WithDailyBudget(budget Budget) Option {
+	return func(opts *Options) {
+		opts.dailyBudget = budget
+	}
+}
+
+// WithEpochBudget defines the maximum amount of funds the faucet may distribute per protocol epoch.
+func WithEpochBudget(budget Budget) Option {
+	return func(opts *Options) {
+		opts.epochBudget = budget
+	}
+}
+
+func (b Budget) isEnabled() bool {
+	return b.BaseTokens > 0 || b.Mana > 0
+}
+
+// exceeded returns true if the given amounts exceed the budget.
+func (b Budget) exceeded(baseTokens iotago.BaseToken, mana iotago.Mana) bool {
+	return (b.BaseTokens > 0 && baseTokens > b.BaseTokens) || (b.Mana > 0 && mana > b.Mana)
+}
+
+// currentEpoch returns the epoch of the latest known slot.
+func (f *Faucet) currentEpoch() iotago.EpochIndex {
+	return f.apiProvider.CommittedAPI().TimeProvider().EpochFromSlot(f.node.LatestSlot())
+}
+
+// checkBudgetWithoutLocking checks if the given request would exceed the daily or the epoch budget of the faucet.
+// queued and pending requests are counted as distributed.
+// write lock must be acquired outside.
+func (f *Faucet) checkBudgetWithoutLocking(request *queueItem) error {
+	if !f.opts.dailyBudget.isEnabled() && !f.opts.epochBudget.isEnabled() {
+		return nil
+	}
+
+	now := f.opts.clock.Now()
+	epoch := f.currentEpoch()
+	f.pruneDistributionsWithoutLocking(now, epoch)
+
+	queuedBaseTokens, queuedMana := request.BaseTokenAmount, f.requestManaAmount(request)
+	for _, queuedRequest := range f.queueMap {
+		queuedBaseTokens += queuedRequest.BaseTokenAmount
+		queuedMana += f.requestManaAmount(queuedRequest)
+	}
+
+	dailyBaseTokens, dailyMana := queuedBaseTokens, queuedMana
+	epochBaseTokens, epochMana := queuedBaseTokens, queuedMana
+	for _, entry := range f.distributions {
+		if now.Sub(entry.Time) < dailyBudgetWindow {
+			dailyBaseTokens += entry.BaseTokens
+			dailyMana += entry.Mana
+		}
+		if entry.Epoch == epoch {
+			epochBaseTokens += entry.BaseTokens
+			epochMana += entry.Mana
+		}
+	}
+
+	if f.opts.dailyBudget.exceeded(dailyBaseTokens, dailyMana) {
+		// the budget is available again when the oldest distribution leaves the window
+		retryAfter := dailyBudgetWindow
+		for _, entry := range f.distributions {
+			if age := now.Sub(entry.Time); age < dailyBudgetWindow {
+				retryAfter = dailyBudgetWindow - age
+				break
+			}
+		}
+
+		return NewRequestError(ErrorCodeBudgetExhausted, http.StatusServiceUnavailable, "The daily budget of the faucet is exhausted. Please try again later!").
+			WithRetryAfter(retryAfter).
+			WithDetail("budgetBaseTokens", f.opts.dailyBudget.BaseTokens).
+			WithDetail("budgetMana", f.opts.dailyBudget.Mana)
+	}
+
+	if f.opts.epochBudget.exceeded(epochBaseTokens, epochMana) {
+		timeProvider := f.apiProvider.CommittedAPI().TimeProvider()
+
+		return NewRequestError(ErrorCodeBudgetExhausted, http.StatusServiceUnavailable, "The budget of the faucet for the current epoch is exhausted. Please try again later!").
+			WithRetryAfter(timeProvider.SlotStartTime(timeProvider.EpochStart(epoch+1)).Sub(f.opts.clock.Now())).
+			WithDetail("epoch", epoch).
+			WithDetail("budgetBaseTokens", f.opts.epochBudget.BaseTokens).
+			WithDetail("budgetMana", f.opts.epochBudget.Mana)
+	}
+
+	return nil
+}
+
+// requestManaAmount returns the amount of mana that is paid out for the given request.
+func (f *Faucet) requestManaAmount(request *queueItem) iotago.Mana {
+	manaAmount, err := f.requiredManaPayouts([]*queueItem{request})
+	if err != nil {
+		// can't happen for a single request
+		return 0
+	}
+
+	return manaAmount
+}
+
+// recordDistributionWithoutLocking adds the funds that were distributed by the given confirmed requests to the budget.
+// write lock must be acquired outside.
+func (f *Faucet) recordDistributionWithoutLocking(requests []*queueItem) {
+	if !f.opts.dailyBudget.isEnabled() && !f.opts.epochBudget.isEnabled() {
+		return
+	}
+
+	entry := &distribution{
+		Time:  f.opts.clock.Now(),
+		Epoch: f.currentEpoch(),
+	}
+	for _, request := range requests {
+		entry.BaseTokens += request.BaseTokenAmount
+		entry.Mana += request.ManaAmount
+	}
+
+	f.distributions = append(f.distributions, entry)
+}
+
+// pruneDistributionsWithoutLocking removes the distributions that neither count for the daily nor for the epoch budget anymore.
+// write lock must be acquired outside.
+func (f *Faucet) pruneDistributionsWithoutLocking(now time.Time, epoch iotago.EpochIndex) {
+	for len(f.distributions) > 0 {
+		oldest := f.distributions[0]
+		if now.Sub(oldest.Time) < dailyBudgetWindow || oldest.Epoch >= epoch {
+			return
+		}
+
+		f.distributions = f.distributions[1:]
+	}
+}
@@ -0,0 +1,76 @@
+package faucet
+
+import (
+	"net/http"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithBurnAddresses defines additional addresses whose requests are rejected, because the funds sent to them are lost.
+// Addresses with an all-zero key hash or ID are always rejected.
+func WithBurnAddresses(burnAddresses ...iotago.Address) Option {
+	return func(opts *Options) {
+		opts.burnAddresses = burnAddresses
+	}
+}
+
+// isNullAddress checks if the key hash or ID of the given address consists of zeros only, nobody can unlock such an address.
+func isNullAddress(addr iotago.Address) bool {
+	switch address := addr.(type) {
+	case *iotago.Ed25519Address:
+		return *address == iotago.Ed25519Address{}
+	case *iotago.ImplicitAccountCreationAddress:
+		return *address == iotago.ImplicitAccountCreationAddress{}
+	case *iotago.AccountAddress:
+		return *address == iotago.AccountAddress{}
+	case *iotago.NFTAddress:
+		return *address == iotago.NFTAddress{}
+	case *iotago.AnchorAddress:
+		return *address == iotago.AnchorAddress{}
+	default:
+		return false
+	}
+}
+
+// isOwnAddress checks if the given address, or the address it restricts, belongs to the faucet.
+func (f *Faucet) isOwnAddress(addr iotago.Address) bool {
+	addressKey := underlyingAddress(addr).Key()
+	for _, faucetAddress := range append(f.sourceAddresses(), f.opts.retiredAddresses...) {
+		if underlyingAddress(faucetAddress).Key() == addressKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBurnAddress checks if the given address, or the address it restricts, is a null address or a configured burn address.
+func (f *Faucet) isBurnAddress(addr iotago.Address) bool {
+	addr = underlyingAddress(addr)
+	if isNullAddress(addr) {
+		return true
+	}
+
+	addressKey := addr.Key()
+	for _, burnAddress := range f.opts.burnAddresses {
+		if underlyingAddress(burnAddress).Key() == addressKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkPayoutTarget returns an error if the given address belongs to the faucet itself or is a burn address.
+// payouts to the faucet would only churn its funds, payouts to burn addresses would destroy them.
+func (f *Faucet) checkPayoutTarget(addr iotago.Address) error {
+	if f.isOwnAddress(addr) {
+		return NewRequestError(ErrorCodeFaucetAddress, http.StatusBadRequest, "Invalid address provided! Requests for the addresses of the faucet are not allowed.")
+	}
+
+	if f.isBurnAddress(addr) {
+		return NewRequestError(ErrorCodeBurnAddress, http.StatusBadRequest, "Invalid address provided! Funds sent to this address are lost.")
+	}
+
+	return nil
+}
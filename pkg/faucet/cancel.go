@@ -0,0 +1,71 @@
+package faucet
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+)
+
+// CancelResponse defines the response of a canceled faucet request.
+type CancelResponse struct {
+	// The bech32 address of the canceled request.
+	Address string `json:"address"`
+	// The number of waiting requests in the queue.
+	WaitingRequests int `json:"waitingRequests"`
+}
+
+// hashIdempotencyKey returns the hash of the given idempotency key, so the key itself is not kept in the queue.
+// it returns an empty string if no key is given.
+func hashIdempotencyKey(idempotencyKey string) string {
+	if idempotencyKey == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(idempotencyKey))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// CancelRequest removes the queued request of the given address and releases its reservation.
+// If the client is not authorized otherwise, e.g. by a proof of ownership of the address,
+// the request can only be canceled with the idempotency key it was enqueued with.
+// Requests that are already part of a transaction can't be canceled anymore.
+func (f *Faucet) CancelRequest(bech32Addr string, idempotencyKey string, authorized bool) (*CancelResponse, error) {
+	if err := f.checkStopping(); err != nil {
+		return nil, err
+	}
+
+	bech32Addr = f.canonicalBech32(bech32Addr)
+
+	f.Lock()
+	defer f.Unlock()
+
+	// requests in the shared queue are only known to the instance that issues the transactions
+	request, exists := f.queueMap[bech32Addr]
+	if !exists {
+		return nil, NewRequestError(ErrorCodeNotFound, http.StatusNotFound, "No queued request found for the given address.")
+	}
+
+	if !authorized {
+		if request.IdempotencyKeyHash == "" {
+			return nil, NewRequestError(ErrorCodeForbidden, http.StatusForbidden, "The request was enqueued without an idempotency key, a proof of ownership of the address is required to cancel it.")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(hashIdempotencyKey(idempotencyKey)), []byte(request.IdempotencyKeyHash)) != 1 {
+			return nil, NewRequestError(ErrorCodeForbidden, http.StatusForbidden, "The idempotency key does not match the key of the queued request.")
+		}
+	}
+
+	if !f.queue.remove(request) {
+		return nil, NewRequestError(ErrorCodeRequestAlreadyProcessed, http.StatusConflict, "The request is already part of a faucet transaction and can't be canceled anymore.")
+	}
+
+	f.clearRequestWithoutLocking(request)
+	f.LogDebugf("canceled queued request of %s", bech32Addr)
+
+	return &CancelResponse{
+		Address:         bech32Addr,
+		WaitingRequests: len(f.queueMap),
+	}, nil
+}
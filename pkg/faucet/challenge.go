@@ -0,0 +1,186 @@
+package faucet
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	challengeExpiryLength = 8
+	challengeRandomLength = 16
+	challengeMACLength    = 16
+	challengeLength       = challengeExpiryLength + challengeRandomLength + challengeMACLength
+
+	// challengeStoreTimeout is the maximum duration of a request to the challenge store.
+	challengeStoreTimeout = 5 * time.Second
+)
+
+// ChallengeStore keeps the secret the challenges are authenticated with and the used challenges.
+// A shared store lets all faucet instances accept the challenges issued by any of them, but only once.
+type ChallengeStore interface {
+	// Secret returns the secret key the challenges are authenticated with, it is the same for all users of the store.
+	Secret(ctx context.Context) ([]byte, error)
+	// Use marks the given challenge as used until it expires, it returns false if the challenge was already used.
+	Use(ctx context.Context, challenge string, expiresAt time.Time) (bool, error)
+}
+
+// memoryChallengeStore is a ChallengeStore that is kept in memory, the challenges are only valid on a single faucet instance.
+type memoryChallengeStore struct {
+	secret []byte
+	clock  Clock
+
+	mutex sync.Mutex
+	// the used challenges and their expiry time.
+	used map[string]time.Time
+}
+
+var _ ChallengeStore = &memoryChallengeStore{}
+
+func newMemoryChallengeStore(clock Clock) (*memoryChallengeStore, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, ierrors.Wrap(err, "failed to create challenge secret")
+	}
+
+	return &memoryChallengeStore{
+		secret: secret,
+		clock:  clock,
+		used:   make(map[string]time.Time),
+	}, nil
+}
+
+func (s *memoryChallengeStore) Secret(_ context.Context) ([]byte, error) {
+	return s.secret, nil
+}
+
+func (s *memoryChallengeStore) Use(_ context.Context, challenge string, expiresAt time.Time) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// remove expired challenges, they are rejected anyway
+	now := s.clock.Now()
+	for usedChallenge, usedExpiresAt := range s.used {
+		if now.After(usedExpiresAt) {
+			delete(s.used, usedChallenge)
+		}
+	}
+
+	if _, used := s.used[challenge]; used {
+		return false, nil
+	}
+	s.used[challenge] = expiresAt
+
+	return true, nil
+}
+
+// ChallengeResponse defines the response of a GET RouteFaucetChallenge REST API call.
+type ChallengeResponse struct {
+	// The hex encoded challenge.
+	Challenge string `json:"challenge"`
+	// The required amount of leading zero bits of the proof-of-work hash.
+	Difficulty int `json:"difficulty,omitempty"`
+	// The unix timestamp after which the challenge is no longer accepted.
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// challengeIssuer issues stateless challenges that are authenticated with a secret key.
+// every challenge can only be used once until it expires.
+type challengeIssuer struct {
+	secret []byte
+	ttl    time.Duration
+	clock  Clock
+	store  ChallengeStore
+}
+
+// newChallengeIssuer creates a new challenge issuer, the challenges are kept in memory if no store is given.
+func newChallengeIssuer(ttl time.Duration, clock Clock, store ChallengeStore) (*challengeIssuer, error) {
+	if store == nil {
+		memoryStore, err := newMemoryChallengeStore(clock)
+		if err != nil {
+			return nil, err
+		}
+		store = memoryStore
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), challengeStoreTimeout)
+	defer cancel()
+
+	secret, err := store.Secret(ctx)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to load challenge secret")
+	}
+
+	return &challengeIssuer{
+		secret: secret,
+		ttl:    ttl,
+		clock:  clock,
+		store:  store,
+	}, nil
+}
+
+func (i *challengeIssuer) mac(data []byte) []byte {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write(data)
+
+	return mac.Sum(nil)[:challengeMACLength]
+}
+
+// issue creates a new challenge.
+func (i *challengeIssuer) issue() (string, time.Time, error) {
+	expiresAt := i.clock.Now().Add(i.ttl)
+
+	challenge := make([]byte, challengeExpiryLength+challengeRandomLength, challengeLength)
+	//nolint:gosec // the unix timestamp is always positive
+	binary.BigEndian.PutUint64(challenge[:challengeExpiryLength], uint64(expiresAt.Unix()))
+	if _, err := rand.Read(challenge[challengeExpiryLength:]); err != nil {
+		return "", time.Time{}, ierrors.Wrap(err, "failed to create challenge")
+	}
+	challenge = append(challenge, i.mac(challenge)...)
+
+	return hex.EncodeToString(challenge), expiresAt, nil
+}
+
+// consume checks if the given challenge was issued by this issuer, is not expired and was not used before.
+// the challenge is marked as used afterwards.
+func (i *challengeIssuer) consume(challenge string) error {
+	invalidChallengeErr := NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "Invalid or expired challenge provided!")
+
+	challengeBytes, err := hex.DecodeString(challenge)
+	if err != nil || len(challengeBytes) != challengeLength {
+		return invalidChallengeErr
+	}
+
+	data := challengeBytes[:challengeExpiryLength+challengeRandomLength]
+	if !hmac.Equal(i.mac(data), challengeBytes[challengeExpiryLength+challengeRandomLength:]) {
+		return invalidChallengeErr
+	}
+
+	//nolint:gosec // the unix timestamp was created by us
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(challengeBytes[:challengeExpiryLength])), 0)
+
+	if i.clock.Now().After(expiresAt) {
+		return invalidChallengeErr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), challengeStoreTimeout)
+	defer cancel()
+
+	unused, err := i.store.Use(ctx, challenge, expiresAt)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to mark the challenge as used")
+	}
+	if !unused {
+		return invalidChallengeErr
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package faucet
+
+import (
+	"time"
+)
+
+// Clock provides the time to the faucet, so the batching, the tickers and the cooldowns can be driven deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns a new Ticker that sends the current time on its channel after each tick.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks of a Clock at intervals.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// SystemClock is the Clock based on the system time, it is used by default.
+var SystemClock Clock = &systemClock{}
+
+type systemClock struct{}
+
+func (c *systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (c *systemClock) NewTicker(d time.Duration) Ticker {
+	return &systemTicker{ticker: time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t *systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// WithClock sets the clock used for the batching, the tickers and the cooldowns of the faucet.
+func WithClock(clock Clock) Option {
+	return func(opts *Options) {
+		opts.clock = clock
+	}
+}
@@ -0,0 +1,343 @@
+package faucet
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/builder"
+)
+
+// ErrPendingTransaction is returned when an operation can't be executed because there is a pending faucet transaction.
+var ErrPendingTransaction = ierrors.New("faucet has a pending transaction")
+
+// RequestType is the type of a faucet request.
+type RequestType string
+
+const (
+	// RequestTypeBasic requests base tokens and mana in a basic output.
+	RequestTypeBasic RequestType = "basic"
+	// RequestTypeDelegation requests a delegation of the faucet to the validator account of the given account address.
+	RequestTypeDelegation RequestType = "delegation"
+	// RequestTypeAllotment requests mana that is allotted to the block issuance credits of the account of the given account address.
+	RequestTypeAllotment RequestType = "allotment"
+)
+
+type UTXODelegationOutput struct {
+	OutputID iotago.OutputID
+	Output   *iotago.DelegationOutput
+}
+
+// DelegationOutputInfo holds the info about a delegation output of the faucet.
+type DelegationOutputInfo struct {
+	// The output ID of the delegation output.
+	OutputID string `json:"outputId"`
+	// The delegation ID of the delegation output.
+	DelegationID string `json:"delegationId"`
+	// The bech32 address of the validator.
+	ValidatorAddress string `json:"validatorAddress"`
+	// The amount of base tokens of the delegation output.
+	Amount iotago.BaseToken `json:"amount"`
+	// The delegated amount of base tokens.
+	DelegatedAmount iotago.BaseToken `json:"delegatedAmount"`
+	// The epoch in which the delegation starts.
+	StartEpoch iotago.EpochIndex `json:"startEpoch"`
+	// The epoch in which the delegation ends, 0 if the delegation is still active.
+	EndEpoch iotago.EpochIndex `json:"endEpoch"`
+}
+
+// DelegationsResponse defines the response of a GET RouteAdminDelegations REST API call.
+type DelegationsResponse struct {
+	// The delegation outputs owned by the faucet.
+	Delegations []*DelegationOutputInfo `json:"delegations"`
+}
+
+// DelegationTransactionResponse defines the response of a POST RouteAdminDelegationsUndelegate or RouteAdminDelegationsClaim REST API call.
+type DelegationTransactionResponse struct {
+	// The ID of the issued block.
+	BlockID string `json:"blockId"`
+	// The ID of the issued transaction.
+	TransactionID string `json:"transactionId"`
+	// The number of affected delegation outputs.
+	Delegations int `json:"delegations"`
+}
+
+// WithDelegationAmount defines the amount of base tokens that are delegated to a validator per delegation request.
+// A value of 0 disables delegation requests.
+func WithDelegationAmount(delegationAmount iotago.BaseToken) Option {
+	return func(opts *Options) {
+		opts.delegationAmount = delegationAmount
+	}
+}
+
+// ownerAddress returns the address of the faucet without restrictions.
+// It is used as the owner of the delegation outputs, because the restricted faucet address can't receive delegation outputs.
+func (f *Faucet) ownerAddress() iotago.Address {
+	if restrictedAddress, ok := f.address.(*iotago.RestrictedAddress); ok {
+		return restrictedAddress.Address
+	}
+
+	return f.address
+}
+
+// delegationAmount returns the amount of base tokens that is delegated for a delegation request to the given address.
+func (f *Faucet) delegationAmount(addr iotago.Address) (iotago.BaseToken, error) {
+	if f.opts.delegationAmount == 0 {
+		return 0, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "Delegation requests are not supported by this faucet.")
+	}
+
+	if _, ok := addr.(*iotago.AccountAddress); !ok {
+		return 0, NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, "Invalid address provided! Delegation requests require the account address of a validator.")
+	}
+
+	return f.opts.delegationAmount, nil
+}
+
+// delegationStartEpoch returns the start epoch of a delegation that is created in a transaction referencing the given commitment slot.
+func delegationStartEpoch(api iotago.API, commitmentSlot iotago.SlotIndex) iotago.EpochIndex {
+	timeProvider := api.TimeProvider()
+
+	pastBoundedSlot := commitmentSlot + api.ProtocolParameters().MaxCommittableAge()
+	pastBoundedEpoch := timeProvider.EpochFromSlot(pastBoundedSlot)
+	registrationSlot := timeProvider.EpochStart(pastBoundedEpoch+1) - api.ProtocolParameters().EpochNearingThreshold()
+
+	if pastBoundedSlot <= registrationSlot {
+		return pastBoundedEpoch + 1
+	}
+
+	return pastBoundedEpoch + 2
+}
+
+// delegationEndEpoch returns the end epoch of a delegation that is stopped in a transaction referencing the given commitment slot.
+func delegationEndEpoch(api iotago.API, commitmentSlot iotago.SlotIndex) iotago.EpochIndex {
+	timeProvider := api.TimeProvider()
+
+	futureBoundedSlot := commitmentSlot + api.ProtocolParameters().MinCommittableAge()
+	futureBoundedEpoch := timeProvider.EpochFromSlot(futureBoundedSlot)
+	registrationSlot := timeProvider.EpochStart(futureBoundedEpoch+1) - api.ProtocolParameters().EpochNearingThreshold()
+
+	if futureBoundedSlot <= registrationSlot {
+		return futureBoundedEpoch
+	}
+
+	return futureBoundedEpoch + 1
+}
+
+// newDelegationOutput creates a new delegation output of the faucet for the given validator.
+func (f *Faucet) newDelegationOutput(api iotago.API, validatorAddress *iotago.AccountAddress, amount iotago.BaseToken) *iotago.DelegationOutput {
+	return &iotago.DelegationOutput{
+		Amount:           amount,
+		DelegatedAmount:  amount,
+		DelegationID:     iotago.DelegationID{},
+		ValidatorAddress: validatorAddress,
+		StartEpoch:       delegationStartEpoch(api, f.node.LatestCommittedSlot()),
+		EndEpoch:         0,
+		UnlockConditions: iotago.DelegationOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: f.ownerAddress()},
+		},
+	}
+}
+
+// Delegations returns the delegation outputs owned by the faucet.
+func (f *Faucet) Delegations() (*DelegationsResponse, error) {
+	delegationOutputs, err := f.node.CollectFaucetDelegationOutputs()
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to collect delegation outputs of the faucet")
+	}
+
+	bech32HRP := f.apiProvider.CommittedAPI().ProtocolParameters().Bech32HRP()
+
+	delegations := make([]*DelegationOutputInfo, 0, len(delegationOutputs))
+	for _, delegationOutput := range delegationOutputs {
+		delegationID := delegationOutput.Output.DelegationID
+		if delegationID.Empty() {
+			delegationID = iotago.DelegationIDFromOutputID(delegationOutput.OutputID)
+		}
+
+		delegations = append(delegations, &DelegationOutputInfo{
+			OutputID:         delegationOutput.OutputID.ToHex(),
+			DelegationID:     delegationID.ToHex(),
+			ValidatorAddress: delegationOutput.Output.ValidatorAddress.Bech32(bech32HRP),
+			Amount:           delegationOutput.Output.Amount,
+			DelegatedAmount:  delegationOutput.Output.DelegatedAmount,
+			StartEpoch:       delegationOutput.Output.StartEpoch,
+			EndEpoch:         delegationOutput.Output.EndEpoch,
+		})
+	}
+
+	return &DelegationsResponse{
+		Delegations: delegations,
+	}, nil
+}
+
+// delegationInputs holds the delegation outputs that were added to a delegation transaction.
+type delegationInputs struct {
+	// the output IDs of the consumed delegation outputs.
+	consumedOutputIDs iotago.OutputIDs
+	// the amount of outputs that were created for the delegation outputs.
+	createdOutputs int
+	// the amount of base tokens of destroyed delegation outputs.
+	claimedAmount iotago.BaseToken
+}
+
+// Undelegate stops all active delegations of the faucet.
+// The funds of the delegation outputs can be claimed after the delegations ended.
+func (f *Faucet) Undelegate(ctx context.Context) (*DelegationTransactionResponse, error) {
+	api := f.apiProvider.CommittedAPI()
+	endEpoch := delegationEndEpoch(api, f.node.LatestCommittedSlot())
+
+	return f.issueDelegationTransaction(ctx, func(txBuilder *builder.TransactionBuilder, delegationOutputs []UTXODelegationOutput, _ int) (*delegationInputs, error) {
+		inputs := &delegationInputs{}
+		for _, delegationOutput := range delegationOutputs {
+			if delegationOutput.Output.EndEpoch != 0 {
+				// delegation was already stopped
+				continue
+			}
+
+			txBuilder.AddInput(&builder.TxInput{UnlockTarget: f.ownerAddress(), InputID: delegationOutput.OutputID, Input: delegationOutput.Output})
+			inputs.consumedOutputIDs = append(inputs.consumedOutputIDs, delegationOutput.OutputID)
+
+			//nolint:forcetypeassert // we can safely assume that this is a DelegationOutput
+			transitionedOutput := delegationOutput.Output.Clone().(*iotago.DelegationOutput)
+			if transitionedOutput.DelegationID.Empty() {
+				transitionedOutput.DelegationID = iotago.DelegationIDFromOutputID(delegationOutput.OutputID)
+			}
+			transitionedOutput.EndEpoch = endEpoch
+			txBuilder.AddOutput(transitionedOutput)
+			inputs.createdOutputs++
+		}
+
+		return inputs, nil
+	})
+}
+
+// ClaimDelegations destroys all delegation outputs of the faucet whose delegation ended
+// and claims their funds and mana rewards.
+func (f *Faucet) ClaimDelegations(ctx context.Context) (*DelegationTransactionResponse, error) {
+	api := f.apiProvider.CommittedAPI()
+	currentEpoch := api.TimeProvider().EpochFromSlot(f.node.LatestCommittedSlot())
+
+	return f.issueDelegationTransaction(ctx, func(txBuilder *builder.TransactionBuilder, delegationOutputs []UTXODelegationOutput, inputIndexOffset int) (*delegationInputs, error) {
+		inputs := &delegationInputs{}
+		for _, delegationOutput := range delegationOutputs {
+			if delegationOutput.Output.EndEpoch == 0 || delegationOutput.Output.EndEpoch >= currentEpoch {
+				// delegation is still active
+				continue
+			}
+
+			rewards, err := f.node.DelegationRewards(delegationOutput.OutputID)
+			if err != nil {
+				return nil, ierrors.Wrapf(err, "failed to get rewards of delegation output %s", delegationOutput.OutputID.ToHex())
+			}
+
+			//nolint:gosec // there are never more than iotago.MaxInputsCount inputs
+			inputIndex := uint16(inputIndexOffset + len(inputs.consumedOutputIDs))
+
+			txBuilder.AddInput(&builder.TxInput{UnlockTarget: f.ownerAddress(), InputID: delegationOutput.OutputID, Input: delegationOutput.Output})
+			txBuilder.AddRewardInput(&iotago.RewardInput{Index: inputIndex}, rewards)
+			inputs.consumedOutputIDs = append(inputs.consumedOutputIDs, delegationOutput.OutputID)
+			inputs.claimedAmount += delegationOutput.Output.Amount
+		}
+
+		return inputs, nil
+	})
+}
+
+// issueDelegationTransaction issues a transaction that consumes all faucet outputs and the delegation outputs added by the given function.
+// The faucet outputs are needed to provide the mana for the block issuance.
+// The transaction is tracked as the pending transaction of the faucet, so the faucet loop waits for its confirmation.
+func (f *Faucet) issueDelegationTransaction(ctx context.Context, addDelegationsFunc func(txBuilder *builder.TransactionBuilder, delegationOutputs []UTXODelegationOutput, inputIndexOffset int) (*delegationInputs, error)) (*DelegationTransactionResponse, error) {
+	delegationOutputs, err := f.node.CollectFaucetDelegationOutputs()
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to collect delegation outputs of the faucet")
+	}
+
+	// we need to acquire a write lock here, because we consume the faucet outputs.
+	f.Lock()
+	defer f.Unlock()
+
+	// the remainder is sent to the faucet address, so the faucet address must not have a pending transaction
+	if f.isSourceAddressPendingWithoutLocking(0) || f.submitRetryPending {
+		return nil, NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, ErrPendingTransaction.Error()).WithRetryAfter(f.opts.batchTimeout)
+	}
+
+	if wait := f.issuanceRate.waitDuration(); wait > 0 {
+		return nil, NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "The maximum amount of faucet transactions per minute was reached.").WithRetryAfter(wait)
+	}
+
+	unspentOutputs, balance, err := f.collectUnlockableFaucetOutputsAndBalanceWithoutLocking()
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to collect faucet outputs")
+	}
+	f.setFaucetFundsWithoutLocking(unspentOutputs, balance)
+	unspentOutputs = f.faucetAddressOutputs(f.filterPendingOutputsWithoutLocking(unspentOutputs))
+
+	api := f.apiProvider.CommittedAPI()
+	txBuilder := builder.NewTransactionBuilder(api, f.addressSigner)
+	txBuilder.AddTaggedDataPayload(&iotago.TaggedData{Tag: f.transactionTag(TransactionTypeDelegation), Data: nil})
+
+	consumedInputs := iotago.OutputIDs{}
+	var remainderAmount iotago.BaseToken
+	for _, unspentOutput := range unspentOutputs {
+		remainderAmount += unspentOutput.Output.Amount
+		txBuilder.AddInput(&builder.TxInput{UnlockTarget: f.unlockTarget(unspentOutput), InputID: unspentOutput.OutputID, Input: unspentOutput.Output})
+		consumedInputs = append(consumedInputs, unspentOutput.OutputID)
+	}
+
+	inputs, err := addDelegationsFunc(txBuilder, delegationOutputs, len(consumedInputs))
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs.consumedOutputIDs) == 0 {
+		return nil, NewRequestError(ErrorCodeNotFound, http.StatusNotFound, "No delegation outputs found that can be processed.")
+	}
+	consumedInputs = append(consumedInputs, inputs.consumedOutputIDs...)
+	remainderAmount += inputs.claimedAmount
+
+	// the remainder output is added last and receives the remaining mana
+	remainderOutputIndex := inputs.createdOutputs
+	txBuilder.AddOutput(&iotago.BasicOutput{
+		Amount: remainderAmount,
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: f.address},
+		},
+	})
+
+	blockPayload, blockID, err := f.node.SubmitTransactionPayload(ctx, txBuilder, remainderOutputIndex, f.opts.powWorkerCount)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "submit delegation transaction payload failed")
+	}
+
+	signedTx, ok := blockPayload.(*iotago.SignedTransaction)
+	if !ok {
+		return nil, ierrors.Errorf("submitted delegation transaction payload is not a SignedTransaction, got instead: %T", blockPayload)
+	}
+
+	transactionID, err := signedTx.Transaction.ID()
+	if err != nil {
+		return nil, ierrors.Wrap(err, "send delegation block failed")
+	}
+
+	f.issuanceRate.record()
+
+	f.setPendingTransactionWithoutLocking(&pendingTransaction{
+		BlockID:           blockID,
+		TransactionID:     transactionID,
+		SignedTransaction: signedTx,
+		QueuedItems:       nil,
+		ConsumedInputs:    consumedInputs,
+		// the delegation transaction consumes the outputs of the faucet address
+		SourceAddressIndex: 0,
+	})
+
+	f.Events.IssuedBlock.Trigger(blockID)
+
+	f.LogInfof("issued delegation transaction, blockID: %s, txID: %s, delegations: %d", blockID, transactionID, len(inputs.consumedOutputIDs))
+
+	return &DelegationTransactionResponse{
+		BlockID:       blockID.ToHex(),
+		TransactionID: transactionID.ToHex(),
+		Delegations:   len(inputs.consumedOutputIDs),
+	}, nil
+}
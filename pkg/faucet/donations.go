@@ -0,0 +1,160 @@
+package faucet
+
+import (
+	"sync"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// LedgerOutput is an output that was consumed or created by an accepted transaction.
+type LedgerOutput struct {
+	OutputID iotago.OutputID
+	Output   iotago.Output
+}
+
+// Donation is an output that was sent to the faucet address by someone else.
+type Donation struct {
+	// The ID of the received output.
+	OutputID iotago.OutputID
+	// The ID of the transaction that created the output.
+	TransactionID iotago.TransactionID
+	// The bech32 address of the sender, it is empty if the inputs of the transaction belong to different addresses.
+	Sender string
+	// The amount of base tokens of the output.
+	BaseTokenAmount iotago.BaseToken
+	// The stored mana of the output.
+	ManaAmount iotago.Mana
+	// The time the donation was detected.
+	Timestamp time.Time
+}
+
+// DonationStats are the totals of all donations since the faucet was started.
+type DonationStats struct {
+	// The amount of received donations.
+	Count int
+	// The base tokens of all donations.
+	TotalBaseTokens iotago.BaseToken
+	// The stored mana of all donations.
+	TotalMana iotago.Mana
+}
+
+// donationStore keeps the latest donations and the totals in memory.
+type donationStore struct {
+	mutex sync.RWMutex
+	// the donations, oldest first.
+	donations []*Donation
+	stats     DonationStats
+}
+
+func newDonationStore() *donationStore {
+	return &donationStore{
+		donations: make([]*Donation, 0),
+	}
+}
+
+// WithDonationsMaxCount defines the maximum amount of latest donations that are kept in memory.
+func WithDonationsMaxCount(donationsMaxCount int) Option {
+	return func(opts *Options) {
+		opts.donationsMaxCount = donationsMaxCount
+	}
+}
+
+// Donations returns the latest donations, newest first, and the totals of all donations.
+func (f *Faucet) Donations() ([]*Donation, *DonationStats) {
+	f.donations.mutex.RLock()
+	defer f.donations.mutex.RUnlock()
+
+	donations := make([]*Donation, 0, len(f.donations.donations))
+	for i := len(f.donations.donations) - 1; i >= 0; i-- {
+		donation := *f.donations.donations[i]
+		donations = append(donations, &donation)
+	}
+	stats := f.donations.stats
+
+	return donations, &stats
+}
+
+// isFaucetAddress checks if the given output is owned by the faucet address.
+func (f *Faucet) isFaucetAddress(output iotago.Output) bool {
+	addressUnlockCondition := output.UnlockConditionSet().Address()
+
+	return addressUnlockCondition != nil && addressUnlockCondition.Address.Equal(f.address)
+}
+
+// donationSender returns the bech32 address of the sender of a transaction with the given inputs.
+// it returns an empty string if the inputs belong to different addresses.
+func (f *Faucet) donationSender(consumed []*LedgerOutput) string {
+	var sender iotago.Address
+	for _, input := range consumed {
+		addressUnlockCondition := input.Output.UnlockConditionSet().Address()
+		if addressUnlockCondition == nil {
+			return ""
+		}
+
+		if sender != nil && !sender.Equal(addressUnlockCondition.Address) {
+			return ""
+		}
+		sender = addressUnlockCondition.Address
+	}
+
+	if sender == nil {
+		return ""
+	}
+
+	return sender.Bech32(f.apiProvider.CommittedAPI().ProtocolParameters().Bech32HRP())
+}
+
+// ApplyDonations records the outputs of an accepted transaction that were sent to the faucet address by someone else.
+// Transactions that consume outputs of the faucet are issued by the faucet itself, so their outputs are remainders.
+func (f *Faucet) ApplyDonations(transactionID iotago.TransactionID, consumed []*LedgerOutput, created []*LedgerOutput) {
+	for _, input := range consumed {
+		if f.isFaucetAddress(input.Output) {
+			return
+		}
+	}
+
+	var donations []*Donation
+	for _, output := range created {
+		if !f.isFaucetAddress(output.Output) {
+			continue
+		}
+
+		donations = append(donations, &Donation{
+			OutputID:        output.OutputID,
+			TransactionID:   transactionID,
+			BaseTokenAmount: output.Output.BaseTokenAmount(),
+			ManaAmount:      output.Output.StoredMana(),
+			Timestamp:       f.opts.clock.Now(),
+		})
+	}
+
+	if len(donations) == 0 {
+		return
+	}
+
+	// the sender is only derived if the transaction contains donations
+	sender := f.donationSender(consumed)
+
+	f.donations.mutex.Lock()
+	for _, donation := range donations {
+		donation.Sender = sender
+
+		f.donations.stats.Count++
+		f.donations.stats.TotalBaseTokens += donation.BaseTokenAmount
+		f.donations.stats.TotalMana += donation.ManaAmount
+
+		if f.opts.donationsMaxCount > 0 {
+			f.donations.donations = append(f.donations.donations, donation)
+			if overflow := len(f.donations.donations) - f.opts.donationsMaxCount; overflow > 0 {
+				f.donations.donations = append(f.donations.donations[:0], f.donations.donations[overflow:]...)
+			}
+		}
+	}
+	f.donations.mutex.Unlock()
+
+	for _, donation := range donations {
+		f.LogInfof("received donation of %d base tokens, sender: %s, outputID: %s", donation.BaseTokenAmount, donation.Sender, donation.OutputID.ToHex())
+		f.Events.DonationReceived.Trigger(donation)
+	}
+}
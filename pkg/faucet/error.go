@@ -1,6 +1,14 @@
 package faucet
 
-import "github.com/iotaledger/hive.go/ierrors"
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
 
 // CriticalError wraps the given error as a critical error.
 func CriticalError(err error) error {
@@ -25,3 +33,143 @@ type criticalError struct {
 
 func (ce criticalError) Error() string { return ce.err.Error() }
 func (ce criticalError) Unwrap() error { return ce.err }
+
+// ErrorCode is a machine-readable code that identifies the cause of a failed faucet API call.
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidRequest is returned if the request could not be parsed.
+	ErrorCodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+	// ErrorCodeInvalidAddress is returned if the given address is not a valid bech32 address of the network.
+	ErrorCodeInvalidAddress ErrorCode = "INVALID_ADDRESS"
+	// ErrorCodeInvalidTag is returned if the optional tag of the request is invalid.
+	ErrorCodeInvalidTag ErrorCode = "INVALID_TAG"
+	// ErrorCodeAddressAlreadyInQueue is returned if there is already a pending request for the given address.
+	ErrorCodeAddressAlreadyInQueue ErrorCode = "ADDRESS_ALREADY_IN_QUEUE"
+	// ErrorCodeRequestAlreadyProcessed is returned if a request can't be canceled anymore, because it is already part of a transaction.
+	ErrorCodeRequestAlreadyProcessed ErrorCode = "REQUEST_ALREADY_PROCESSED"
+	// ErrorCodeAddressHasEnoughFunds is returned if the given address already holds the maximum allowed funds.
+	ErrorCodeAddressHasEnoughFunds ErrorCode = "ADDRESS_HAS_ENOUGH_FUNDS"
+	// ErrorCodeNodeUnhealthy is returned if the node used by the faucet is not synchronized/healthy.
+	ErrorCodeNodeUnhealthy ErrorCode = "NODE_UNHEALTHY"
+	// ErrorCodeFaucetNotEnoughFunds is returned if the faucet does not hold enough funds to process the request.
+	ErrorCodeFaucetNotEnoughFunds ErrorCode = "FAUCET_NOT_ENOUGH_FUNDS"
+	// ErrorCodeQueueFull is returned if the request queue of the faucet is full.
+	ErrorCodeQueueFull ErrorCode = "QUEUE_FULL"
+	// ErrorCodeBudgetExhausted is returned if the distribution budget of the faucet for the current period is exhausted.
+	ErrorCodeBudgetExhausted ErrorCode = "BUDGET_EXHAUSTED"
+	// ErrorCodeAddressDenied is returned if the address is on the denylist of the faucet.
+	ErrorCodeAddressDenied ErrorCode = "ADDRESS_DENIED"
+	// ErrorCodeFaucetAddress is returned if the address belongs to the faucet itself.
+	ErrorCodeFaucetAddress ErrorCode = "FAUCET_ADDRESS"
+	// ErrorCodeBurnAddress is returned if the funds sent to the address would be lost, e.g. because it is a null address.
+	ErrorCodeBurnAddress ErrorCode = "BURN_ADDRESS"
+	// ErrorCodeAmountBelowStorageDeposit is returned if the payout doesn't cover the minimum storage deposit of its output.
+	ErrorCodeAmountBelowStorageDeposit ErrorCode = "AMOUNT_BELOW_STORAGE_DEPOSIT"
+	// ErrorCodeFaucetPaused is returned if the faucet was paused by the operator.
+	ErrorCodeFaucetPaused ErrorCode = "FAUCET_PAUSED"
+	// ErrorCodeRateLimited is returned if the client sent too many requests.
+	ErrorCodeRateLimited ErrorCode = "RATE_LIMITED"
+	// ErrorCodeUnauthorized is returned if the client is not authenticated for the requested resource.
+	ErrorCodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	// ErrorCodeForbidden is returned if the access to the requested resource is not allowed.
+	ErrorCodeForbidden ErrorCode = "FORBIDDEN"
+	// ErrorCodeNotFound is returned if the requested resource does not exist.
+	ErrorCodeNotFound ErrorCode = "NOT_FOUND"
+	// ErrorCodeMethodNotAllowed is returned if the requested resource does not support the HTTP method of the request.
+	ErrorCodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	// ErrorCodeRequestTooLarge is returned if the body of the request exceeds the maximum size.
+	ErrorCodeRequestTooLarge ErrorCode = "REQUEST_TOO_LARGE"
+	// ErrorCodeServiceUnavailable is returned if the service is temporarily unavailable.
+	ErrorCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	// ErrorCodeInternal is returned if an unexpected error occurred.
+	ErrorCodeInternal ErrorCode = "INTERNAL_ERROR"
+)
+
+// RequestError is an error that is reported to the client of the faucet API.
+type RequestError struct {
+	// Code is the machine-readable error code.
+	Code ErrorCode
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Message is the human-readable error message.
+	Message string
+	// RetryAfter is the duration after which the client may retry the request.
+	RetryAfter time.Duration
+	// Details holds additional information about the error.
+	Details map[string]any
+}
+
+// NewRequestError creates a new RequestError.
+func NewRequestError(code ErrorCode, statusCode int, message string) *RequestError {
+	return &RequestError{
+		Code:       code,
+		StatusCode: statusCode,
+		Message:    message,
+	}
+}
+
+// WithRetryAfter sets the duration after which the client may retry the request.
+func (e *RequestError) WithRetryAfter(retryAfter time.Duration) *RequestError {
+	e.RetryAfter = retryAfter
+
+	return e
+}
+
+// WithDetail adds additional information about the error.
+func (e *RequestError) WithDetail(key string, value any) *RequestError {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+
+	return e
+}
+
+func (e *RequestError) Error() string { return e.Message }
+
+// AsRequestError converts the given error to a RequestError.
+// Errors that are neither a RequestError nor an echo.HTTPError are reported as internal errors.
+// The messages of internal errors are not passed to the client, since they may contain details of the faucet,
+// the caller needs to log the original error instead.
+func AsRequestError(err error) *RequestError {
+	var reqErr *RequestError
+	if ierrors.As(err, &reqErr) {
+		return reqErr
+	}
+
+	var httpErr *echo.HTTPError
+	if ierrors.As(err, &httpErr) {
+		var code ErrorCode
+		switch httpErr.Code {
+		case http.StatusBadRequest:
+			code = ErrorCodeInvalidRequest
+		case http.StatusUnauthorized:
+			code = ErrorCodeUnauthorized
+		case http.StatusForbidden:
+			code = ErrorCodeForbidden
+		case http.StatusNotFound:
+			code = ErrorCodeNotFound
+		case http.StatusMethodNotAllowed:
+			code = ErrorCodeMethodNotAllowed
+		case http.StatusRequestEntityTooLarge:
+			code = ErrorCodeRequestTooLarge
+		case http.StatusTooManyRequests:
+			code = ErrorCodeRateLimited
+		case http.StatusServiceUnavailable:
+			code = ErrorCodeServiceUnavailable
+		default:
+			code = ErrorCodeInternal
+		}
+
+		// the internal error of an echo.HTTPError is never passed to the client
+		message := http.StatusText(httpErr.Code)
+		if httpErr.Message != nil && code != ErrorCodeInternal {
+			message = fmt.Sprintf("%v", httpErr.Message)
+		}
+
+		return NewRequestError(code, httpErr.Code, message)
+	}
+
+	return NewRequestError(ErrorCodeInternal, http.StatusInternalServerError, "Internal server error.")
+}
@@ -0,0 +1,51 @@
+package faucet_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+func TestAsRequestError(t *testing.T) {
+	requestErr := faucet.NewRequestError(faucet.ErrorCodeQueueFull, http.StatusServiceUnavailable, "Faucet queue is full.")
+	require.Same(t, requestErr, faucet.AsRequestError(ierrors.Wrap(requestErr, "enqueue failed")))
+
+	for statusCode, expectedCode := range map[int]faucet.ErrorCode{
+		http.StatusBadRequest:            faucet.ErrorCodeInvalidRequest,
+		http.StatusUnauthorized:          faucet.ErrorCodeUnauthorized,
+		http.StatusForbidden:             faucet.ErrorCodeForbidden,
+		http.StatusNotFound:              faucet.ErrorCodeNotFound,
+		http.StatusMethodNotAllowed:      faucet.ErrorCodeMethodNotAllowed,
+		http.StatusRequestEntityTooLarge: faucet.ErrorCodeRequestTooLarge,
+		http.StatusTooManyRequests:       faucet.ErrorCodeRateLimited,
+		http.StatusServiceUnavailable:    faucet.ErrorCodeServiceUnavailable,
+		http.StatusInternalServerError:   faucet.ErrorCodeInternal,
+	} {
+		reqErr := faucet.AsRequestError(echo.NewHTTPError(statusCode))
+		require.Equal(t, expectedCode, reqErr.Code, statusCode)
+		require.Equal(t, statusCode, reqErr.StatusCode, statusCode)
+		require.Equal(t, http.StatusText(statusCode), reqErr.Message, statusCode)
+	}
+}
+
+func TestAsRequestErrorHidesInternalErrors(t *testing.T) {
+	internalErr := ierrors.New("dial tcp 10.0.0.1:9029: connection refused")
+
+	reqErr := faucet.AsRequestError(echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON.").SetInternal(internalErr))
+	require.Equal(t, faucet.ErrorCodeInvalidRequest, reqErr.Code)
+	require.Equal(t, "Invalid JSON.", reqErr.Message)
+
+	reqErr = faucet.AsRequestError(echo.NewHTTPError(http.StatusInternalServerError, internalErr.Error()))
+	require.Equal(t, faucet.ErrorCodeInternal, reqErr.Code)
+	require.NotContains(t, reqErr.Message, "10.0.0.1")
+
+	reqErr = faucet.AsRequestError(internalErr)
+	require.Equal(t, faucet.ErrorCodeInternal, reqErr.Code)
+	require.Equal(t, http.StatusInternalServerError, reqErr.StatusCode)
+	require.NotContains(t, reqErr.Message, "10.0.0.1")
+}
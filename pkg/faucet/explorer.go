@@ -0,0 +1,72 @@
+package faucet
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	// ExplorerURLPlaceholderKind is replaced with the kind of the linked object in the explorer URL template.
+	ExplorerURLPlaceholderKind = "{kind}"
+	// ExplorerURLPlaceholderID is replaced with the ID of the linked object in the explorer URL template.
+	ExplorerURLPlaceholderID = "{id}"
+)
+
+// ExplorerLinkKind is the kind of object an explorer link points to.
+type ExplorerLinkKind string
+
+const (
+	// ExplorerLinkKindBlock links a block by its hex encoded ID.
+	ExplorerLinkKindBlock ExplorerLinkKind = "block"
+	// ExplorerLinkKindTransaction links a transaction by its hex encoded ID.
+	ExplorerLinkKindTransaction ExplorerLinkKind = "transaction"
+	// ExplorerLinkKindAddress links an address by its bech32 encoding.
+	ExplorerLinkKindAddress ExplorerLinkKind = "addr"
+)
+
+// ParseExplorerURL checks that the given explorer URL template is an absolute URL that contains the ID placeholder.
+// An empty template disables the explorer links.
+func ParseExplorerURL(template string) (string, error) {
+	if template == "" {
+		return "", nil
+	}
+
+	if !strings.Contains(template, ExplorerURLPlaceholderID) {
+		return "", ierrors.Errorf("invalid explorer URL \"%s\": the placeholder %s is missing", template, ExplorerURLPlaceholderID)
+	}
+
+	parsedURL, err := url.Parse(template)
+	if err != nil {
+		return "", ierrors.Wrapf(err, "invalid explorer URL \"%s\"", template)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "", ierrors.Errorf("invalid explorer URL \"%s\": only http and https are supported", template)
+	}
+
+	return template, nil
+}
+
+// WithExplorerURL defines the template of the explorer links in the API responses and notifications of the faucet.
+// The placeholders {kind} and {id} are replaced with the kind and the ID of the linked object,
+// e.g. "https://explorer.iota.org/testnet/{kind}/{id}". An empty template disables the links.
+func WithExplorerURL(template string) Option {
+	return func(opts *Options) {
+		opts.explorerURL = template
+	}
+}
+
+// ExplorerURL returns the explorer link of the object with the given kind and ID.
+// It returns an empty string if no explorer URL is configured or the ID is empty.
+func (f *Faucet) ExplorerURL(kind ExplorerLinkKind, id string) string {
+	if f.opts.explorerURL == "" || id == "" {
+		return ""
+	}
+
+	return strings.NewReplacer(
+		ExplorerURLPlaceholderKind, string(kind),
+		ExplorerURLPlaceholderID, url.PathEscape(id),
+	).Replace(f.opts.explorerURL)
+}
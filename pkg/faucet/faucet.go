@@ -3,28 +3,36 @@ package faucet
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/labstack/echo/v4"
-
 	"github.com/iotaledger/hive.go/app/daemon"
 	"github.com/iotaledger/hive.go/core/safemath"
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/hive.go/log"
 	"github.com/iotaledger/hive.go/runtime/event"
 	"github.com/iotaledger/hive.go/runtime/syncutils"
-	"github.com/iotaledger/hive.go/runtime/timeutil"
-	"github.com/iotaledger/inx-app/pkg/httpserver"
 	iotago "github.com/iotaledger/iota.go/v4"
 	"github.com/iotaledger/iota.go/v4/api"
 	"github.com/iotaledger/iota.go/v4/builder"
 )
 
+const (
+	// retryAfterNodeUnhealthy is the suggested retry duration for clients if the node is not healthy.
+	retryAfterNodeUnhealthy = 30 * time.Second
+	// retryAfterNotEnoughFunds is the suggested retry duration for clients if the faucet does not have enough funds.
+	retryAfterNotEnoughFunds = 5 * time.Minute
+)
+
 var (
 	// ErrOperationAborted is returned when the operation was aborted e.g. by a shutdown signal.
 	ErrOperationAborted = ierrors.New("operation was aborted")
 	// ErrNothingToProcess is returned when there is no need to sweep or send funds.
 	ErrNothingToProcess = ierrors.New("nothing to process")
+	// ErrLeadershipLost is returned when the leader lease was lost while the faucet was issuing a transaction.
+	ErrLeadershipLost = ierrors.New("leadership was lost")
 
 	// EmptyBasicOutput is used to calculate the storage deposit of the faucet remainder output.
 	EmptyBasicOutput = &iotago.BasicOutput{
@@ -42,35 +50,49 @@ var (
 	}
 )
 
-type (
-	// IsNodeHealthyFunc is a function to query if the used node is synced.
-	IsNodeHealthyFunc func() bool
-	// FetchTransactionMetadataFunc is a function to fetch the required metadata of a transaction.
-	// This returns nil if the transaction is not found.
-	FetchTransactionMetadataFunc func(transactionID iotago.TransactionID) (*api.TransactionMetadataResponse, error)
-	// CollectUnlockableFaucetOutputsFunc is a function to collect the unlockable outputs of the faucet.
-	CollectUnlockableFaucetOutputsFunc func() ([]UTXOBasicOutput, error)
-	// CollectUnlockableFaucetOutputsAndBalanceFunc is a function to collect the unlockable outputs and the balance of the faucet.
-	CollectUnlockableFaucetOutputsAndBalanceFunc func() ([]UTXOBasicOutput, iotago.BaseToken, error)
-	// ComputeUnlockableAddressBalanceFunc is a function to compute the unlockable balance of an address.
-	ComputeUnlockableAddressBalanceFunc func(address iotago.Address) (iotago.BaseToken, error)
-	// GetLatestSlotFunc is a function to get the latest known slot in the network.
-	GetLatestSlotFunc func() iotago.SlotIndex
-	// SubmitTransactionPayloadFunc is a function which creates a signed transaction payload and sends it to a block issuer.
-	SubmitTransactionPayloadFunc func(ctx context.Context, builder *builder.TransactionBuilder, storedManaOutputIndex int, numPoWWorkers ...int) (iotago.ApplicationPayload, iotago.BlockID, error)
-)
-
 type UTXOBasicOutput struct {
 	OutputID iotago.OutputID
 	Output   *iotago.BasicOutput
+	// UnlockTarget is the address that unlocks the output, the faucet address is used if it is nil.
+	// it is set for expired outputs that are returned to the faucet and for outputs of the source and retired addresses.
+	UnlockTarget iotago.Address
+}
+
+// unlockTarget returns the address that unlocks the given output.
+func (f *Faucet) unlockTarget(output UTXOBasicOutput) iotago.Address {
+	if output.UnlockTarget != nil {
+		return output.UnlockTarget
+	}
+
+	return f.address
 }
 
 // Events are the events issued by the faucet.
 type Events struct {
 	// Fired when a faucet block is issued.
 	IssuedBlock *event.Event1[iotago.BlockID]
-	// SoftError is triggered when a soft error is encountered.
-	SoftError *event.Event1[error]
+	// SoftError is triggered when a soft error is encountered, the payload carries the category of the error.
+	SoftError *event.Event1[*SoftError]
+	// SubmitRetried is triggered when the submission of a faucet transaction is retried.
+	SubmitRetried *event.Event1[SubmitErrorClass]
+	// TransactionConfirmed is triggered when a faucet transaction was confirmed.
+	TransactionConfirmed *event.Event1[*ConfirmedTransaction]
+	// TransactionFailed is triggered when a faucet transaction failed and its requests are added back to the queue.
+	TransactionFailed *event.Event1[*FailedTransaction]
+	// RequestEnqueued is triggered when a request was added to the queue.
+	RequestEnqueued *event.Event1[*Payout]
+	// RequestRejected is triggered when a request was rejected.
+	RequestRejected *event.Event1[*RejectedRequest]
+	// RequestProcessed is triggered for every request whose payout was confirmed.
+	RequestProcessed *event.Event1[*ProcessedRequest]
+	// RequestFailed is triggered when a queued request was dropped without a payout.
+	RequestFailed *event.Event1[*FailedRequest]
+	// BalanceChanged is triggered when the funds of the faucet changed.
+	BalanceChanged *event.Event1[*BalanceChange]
+	// DonationReceived is triggered when someone else sent an output to the faucet address.
+	DonationReceived *event.Event1[*Donation]
+	// NodeCallCompleted is triggered when a call to the node, the indexer or the block issuer completed.
+	NodeCallCompleted *event.Event1[*NodeCall]
 }
 
 // queueItem is an item for the faucet requests queue.
@@ -78,14 +100,100 @@ type queueItem struct {
 	Bech32          string
 	BaseTokenAmount iotago.BaseToken
 	Address         iotago.Address
+	Tag             string
+	Type            RequestType
+	RemoteIP        string
+	UserAgent       string
+	EnqueuedAt      time.Time
+	Priority        RequestPriority
+	// AmountKind describes which of the configured amounts the request receives.
+	AmountKind PayoutAmountKind
+	// SkipMana is true if the target address already holds enough mana.
+	SkipMana bool
+	// BalanceUnchecked is true if the funds on the target address were not checked yet,
+	// they are checked when the request is processed.
+	BalanceUnchecked bool
+	// AmountRequested is true if the amount of base tokens was requested by the client.
+	AmountRequested bool
+	// the amount of mana that is paid out, it is set when the transaction is created.
+	ManaAmount iotago.Mana
+	// the hash of the idempotency key the client sent, it is needed to cancel the request without a proof of ownership.
+	IdempotencyKeyHash string
+	// the entry of the airdrop the request belongs to, it is nil for public requests.
+	airdropEntry *AirdropEntry
 }
 
 // pendingTransaction holds info about a sent transaction that is pending.
 type pendingTransaction struct {
-	BlockID        iotago.BlockID
-	TransactionID  iotago.TransactionID
-	QueuedItems    []*queueItem
-	ConsumedInputs iotago.OutputIDs
+	BlockID           iotago.BlockID
+	TransactionID     iotago.TransactionID
+	SignedTransaction *iotago.SignedTransaction
+	QueuedItems       []*queueItem
+	ConsumedInputs    iotago.OutputIDs
+	// the amount of times the transaction was reattached in a new block.
+	Reattachments int
+	// whether the transaction was reissued after it was rolled back, the payouts were already recorded on its first acceptance.
+	Reissued bool
+	// the index of the source address whose outputs are consumed by the transaction.
+	SourceAddressIndex int
+}
+
+// Payout is a single payout of a confirmed faucet transaction.
+type Payout struct {
+	// The bech32 address of the receiver.
+	Bech32 string
+	// The type of the request.
+	Type RequestType
+	// The amount of base tokens that were paid out.
+	BaseTokenAmount iotago.BaseToken
+	// The amount of mana that was paid out.
+	ManaAmount iotago.Mana
+	// The sanitized tag of the request.
+	Tag string
+	// The originating IP address of the request.
+	RemoteIP string
+	// The user agent of the client, it is empty for requests restored after a restart.
+	UserAgent string
+	// Which of the configured amounts the request receives.
+	AmountKind PayoutAmountKind
+	// The time the request was enqueued.
+	EnqueuedAt time.Time
+}
+
+// RejectedRequest holds info about a rejected faucet request.
+type RejectedRequest struct {
+	// The bech32 address of the request.
+	Bech32 string
+	// The type of the request.
+	Type RequestType
+	// The tag of the request.
+	Tag string
+	// The metadata of the client, it may be nil.
+	Client *ClientMetadata
+	// The error the request was rejected with.
+	Error *RequestError
+}
+
+// ConfirmedTransaction holds info about a confirmed faucet transaction.
+type ConfirmedTransaction struct {
+	// The ID of the block that contained the transaction.
+	BlockID iotago.BlockID
+	// The ID of the transaction.
+	TransactionID iotago.TransactionID
+	// The payouts of the transaction.
+	Payouts []*Payout
+}
+
+// FailedTransaction holds info about a failed faucet transaction.
+type FailedTransaction struct {
+	// The ID of the block that contained the transaction.
+	BlockID iotago.BlockID
+	// The ID of the transaction.
+	TransactionID iotago.TransactionID
+	// The payouts of the transaction, they are retried in a new transaction.
+	Payouts []*Payout
+	// The reason why the transaction failed.
+	Reason error
 }
 
 // InfoResponse defines the response of a GET RouteFaucetInfo REST API call.
@@ -94,18 +202,60 @@ type InfoResponse struct {
 	IsHealthy bool `json:"isHealthy"`
 	// The bech32 address of the faucet.
 	Address string `json:"address"`
+	// The explorer link of the faucet address, it is omitted if no explorer URL is configured.
+	AddressURL string `json:"addressUrl,omitempty"`
 	// The remaining balance of faucet.
 	Balance iotago.BaseToken `json:"balance"`
+	// The remaining balance of faucet in token units, e.g. "10.5".
+	BalanceFormatted string `json:"balanceFormatted"`
 	// The name of the token of the faucet.
 	TokenName string `json:"tokenName"`
+	// The number of decimals of the token of the faucet.
+	TokenDecimals uint32 `json:"tokenDecimals"`
 	// The Bech32 human readable part of the faucet.
 	Bech32HRP iotago.NetworkPrefix `json:"bech32Hrp"`
+	// The number of waiting requests in the queue.
+	WaitingRequests int `json:"waitingRequests"`
+	// The maximum amount of requests in the queue.
+	QueueSize int `json:"queueSize"`
+	// The policy that is applied to new requests if the queue is full.
+	QueueOverflowPolicy QueueOverflowPolicy `json:"queueOverflowPolicy"`
+	// The stored mana of the faucet that is not reserved for queued requests.
+	StoredMana iotago.Mana `json:"storedMana"`
+	// The potential mana that was generated by the outputs of the faucet.
+	PotentialMana iotago.Mana `json:"potentialMana"`
+	// Whether the faucet has enough stored mana left to pay out mana.
+	// If this is false, requests only receive base tokens.
+	ManaPayoutsActive bool `json:"manaPayoutsActive"`
+	// The amount of mana that is paid out per request.
+	ManaAmount iotago.Mana `json:"manaAmount"`
+	// Whether the faucet was paused by the operator.
+	Paused bool `json:"paused"`
+	// The connection state of the INX streams of the faucet.
+	INXConnection *INXConnectionState `json:"inxConnection"`
 }
 
 // EnqueueRequest defines the request for a POST RouteFaucetEnqueue REST API call.
+// It is either sent as JSON or form-encoded, the address, the tag and the type may also be given as query parameters.
 type EnqueueRequest struct {
 	// The bech32 address.
-	Address string `json:"address"`
+	Address string `json:"address" form:"address" query:"address"`
+	// The optional tag that is added to the data of the faucet transaction.
+	Tag string `json:"tag,omitempty" form:"tag" query:"tag"`
+	// The optional type of the request, defaults to "basic".
+	Type RequestType `json:"type,omitempty" form:"type" query:"type"`
+	// The optional amount of base tokens the request asks for, it is clamped to the bounds of the faucet.
+	Amount string `json:"amount,omitempty" form:"amount" query:"amount"`
+	// The optional challenge that was provided by the faucet.
+	Challenge string `json:"challenge,omitempty" form:"challenge"`
+	// The optional solution of the challenge.
+	Nonce string `json:"nonce,omitempty" form:"nonce"`
+	// The optional challenge that was signed to prove the ownership of the address.
+	OwnershipChallenge string `json:"ownershipChallenge,omitempty" form:"ownershipChallenge"`
+	// The optional hex encoded public key of the address.
+	PublicKey string `json:"publicKey,omitempty" form:"publicKey"`
+	// The optional hex encoded signature of the ownership challenge.
+	Signature string `json:"signature,omitempty" form:"signature"`
 }
 
 // EnqueueResponse defines the response of a POST RouteFaucetEnqueue REST API call.
@@ -114,6 +264,25 @@ type EnqueueResponse struct {
 	Address string `json:"address"`
 	// The number of waiting requests in the queue.
 	WaitingRequests int `json:"waitingRequests"`
+	// The tag that is added to the data of the faucet transaction.
+	Tag string `json:"tag,omitempty"`
+}
+
+// ErrorResponse defines the error of a failed REST API call.
+type ErrorResponse struct {
+	// The machine-readable error code.
+	Code ErrorCode `json:"code"`
+	// The human-readable error message.
+	Message string `json:"message"`
+	// The number of seconds after which the request may be retried.
+	RetryAfter int `json:"retryAfter,omitempty"`
+	// Additional information about the error.
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// ErrorResponseEnvelope defines the response of a failed REST API call.
+type ErrorResponseEnvelope struct {
+	Error ErrorResponse `json:"error"`
 }
 
 // Faucet is used to issue transaction to users that requested funds via a REST endpoint.
@@ -125,19 +294,8 @@ type Faucet struct {
 	// used to access the global daemon.
 	daemon daemon.Daemon
 
-	// used to determine the health status of the node.
-	isNodeHealthyFunc IsNodeHealthyFunc
-	// used to fetch metadata of a transaction from the node.
-	fetchTransactionMetadataFunc FetchTransactionMetadataFunc
-	// used to collect the unlockable outputs and the balance of the faucet.
-	// write lock must be acquired outside because we read from queueMap and we want to set the faucet balance without modifications to the map.
-	collectUnlockableFaucetOutputsAndBalanceFuncWithoutLocking CollectUnlockableFaucetOutputsAndBalanceFunc
-	// used to compute the unlockable balance of an address.
-	computeUnlockableAddressBalanceFunc ComputeUnlockableAddressBalanceFunc
-	// used to get the latest known slot in the network.
-	getLatestSlotFunc GetLatestSlotFunc
-	// used to create a signed transaction payload and send it to a block issuer.
-	submitTransactionPayloadFunc SubmitTransactionPayloadFunc
+	// used to access the ledger of the node and to issue blocks.
+	node NodeAdapter
 
 	// the api Provider.
 	apiProvider iotago.APIProvider
@@ -151,16 +309,62 @@ type Faucet struct {
 	// events of the faucet.
 	Events *Events
 
-	// faucetBalance is the remaining balance of the faucet if all requests would be processed.
-	faucetBalance iotago.BaseToken
-	// queue of new requests.
-	queue chan *queueItem
+	// reservations track the funds of the faucet that are reserved for queued and pending requests.
+	reservations *reservationLedger
+	// addressLists are the operator-managed allow and deny lists of addresses.
+	addressLists *addressListStore
+	// shadowBans are the rules whose matching requests are accepted, but never processed.
+	shadowBans *shadowBanStore
+	// airdrops are the operator-scheduled payouts that are processed separately from the public queue.
+	airdrops *airdropQueue
+	// subscriptions are the addresses that receive a payout in a regular interval.
+	subscriptions *subscriptionStore
+	// donations are the latest outputs that were sent to the faucet address by someone else.
+	donations *donationStore
+	// issuanceRate limits the amount of transactions the faucet issues per minute.
+	issuanceRate *issuanceRateLimiter
+	// balanceCache caches the funds on target addresses for a short time.
+	balanceCache *balanceCache
+	// balanceCheckLimiter paces the lookups of the funds on target addresses of queued requests.
+	balanceCheckLimiter *balanceCheckLimiter
+	// unfinalizedTransactions are the accepted faucet transactions that are not finalized yet.
+	unfinalizedTransactions map[iotago.TransactionID]*pendingTransaction
+	// inxConnection tracks the connection state of the INX streams.
+	inxConnection *inxConnection
+	// queue of new requests, ordered by priority.
+	queue *requestQueue
 	// map with all queued requests per address (bech32).
 	queueMap map[string]*queueItem
+	// map with the amount of unconfirmed requests per originating IP address.
+	pendingRequestsPerIP map[string]int
 	// flushQueue is used to signal to stop an ongoing batching of faucet requests.
 	flushQueue chan struct{}
-	// pendingTransaction is the currently sent transaction that is still pending.
-	pendingTransaction *pendingTransaction
+	// pendingTransactions are the sent transactions that are still pending, at most one per source address,
+	// so their input sets are disjoint and they are confirmed independently of each other.
+	pendingTransactions map[iotago.TransactionID]*pendingTransaction
+	// submitRetryPending is true while the lock is released during the backoff of a submission retry,
+	// the outputs of the batch must not be consumed by other transactions in the meantime.
+	submitRetryPending bool
+	// potentialMana is the potential mana of the faucet outputs at the time the funds were last collected.
+	potentialMana iotago.Mana
+	// lastManaClaimCheck is the time of the last check if the potential mana of the faucet should be claimed.
+	lastManaClaimCheck time.Time
+	// congested is true if the issuance of faucet transactions is paused because of network congestion.
+	congested atomic.Bool
+	// leaderUntil is the time in unix nanoseconds until this instance holds the leader lease.
+	leaderUntil atomic.Int64
+	// stopping is true if the faucet is shutting down and doesn't accept new requests.
+	stopping atomic.Bool
+	// paused is true if the faucet was paused by the operator and neither accepts new requests nor issues transactions.
+	paused atomic.Bool
+	// balanceInitialized is true if the initial balance of the faucet was computed.
+	balanceInitialized atomic.Bool
+	// distributions are the confirmed payouts that still count for the distribution budgets, ordered by time.
+	distributions []*distribution
+	// nextSourceAddressIndex is the index of the source address whose outputs are consumed in the next faucet transaction.
+	nextSourceAddressIndex int
+	// retiredAddressesSwept is true per retired address key if all its funds were swept.
+	retiredAddressesSwept map[string]bool
 }
 
 // the default options applied to the faucet.
@@ -171,23 +375,99 @@ var defaultOptions = []Option{
 	WithBaseTokenAmountMaxTarget(20_000_000), // 20 IOTA
 	WithManaAmount(1000),
 	WithManaAmountMinFaucet(1000000),
+	WithManaAmountMaxTarget(0, false),
 	WithTagMessage("FAUCET"),
 	WithBatchTimeout(2 * time.Second),
+	WithBatchTimeoutMin(200 * time.Millisecond),
+	WithBatchMaxSize(iotago.MaxOutputsCount),
+	WithMaxBlockReattachments(3),
+	WithDelegationAmount(0),
+	WithAllotmentManaAmount(0),
+	WithIssuanceEnabled(true),
+	WithShutdownTimeout(30 * time.Second),
+	WithQueueFilePath(""),
+	WithMaxPendingRequestsPerIP(0),
+	WithQueueSize(5000),
+	WithQueueOverflowPolicy(QueueOverflowPolicyReject, 5*time.Second),
+	WithDailyBudget(Budget{}),
+	WithEpochBudget(Budget{}),
+	WithManaClaimInterval(time.Minute),
+	WithManaClaimMinPotentialMana(1000000),
+	WithMaxReferenceManaCost(0),
+	WithSubmitErrorClassifier(DefaultSubmitErrorClassifier),
+	WithSubmitRetryPolicy(SubmitErrorClassUnavailable, &RetryPolicy{MaxRetries: 3, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 5 * time.Second, Jitter: 0.2}),
+	WithRequestTagMaxLength(32),
+	WithTargetAddressTypes(iotago.AddressEd25519, iotago.AddressImplicitAccountCreation),
+	WithAirdropBatchSize(100),
+	WithDonationsMaxCount(50),
+	WithInputSelection(InputSelectionAll, 0),
+	WithBalanceCacheTTL(5 * time.Second),
+	WithClock(SystemClock),
+	WithBalanceCheckRateLimit(20, 4),
+	WithSignerCheckInterval(time.Hour),
+	WithStorageDepositPolicy(StorageDepositPolicyBump),
 }
 
 // Options define options for the faucet.
 type Options struct {
 	// the logger used to log events.
-	logger                   log.Logger
-	tokenName                string
-	baseTokenAmount          iotago.BaseToken
-	baseTokenAmountSmall     iotago.BaseToken
-	baseTokenAmountMaxTarget iotago.BaseToken
-	manaAmount               iotago.Mana
-	manaAmountMinFaucet      iotago.Mana
-	tagMessage               []byte
-	batchTimeout             time.Duration
-	powWorkerCount           int
+	logger                    log.Logger
+	tokenName                 string
+	tokenDecimals             uint32
+	baseTokenAmount           iotago.BaseToken
+	baseTokenAmountSmall      iotago.BaseToken
+	baseTokenAmountMaxTarget  iotago.BaseToken
+	manaAmount                iotago.Mana
+	manaAmountMinFaucet       iotago.Mana
+	manaAmountMaxTarget       iotago.Mana
+	manaOnlyPayouts           bool
+	tagMessage                []byte
+	transactionTags           map[TransactionType][]byte
+	explorerURL               string
+	requestTagMaxLength       int
+	targetAddressTypes        map[iotago.AddressType]struct{}
+	batchTimeout              time.Duration
+	batchTimeoutMin           time.Duration
+	maxTransactionsPerMinute  int
+	batchMaxSize              int
+	maxBlockReattachments     int
+	maxReferenceManaCost      iotago.Mana
+	delegationAmount          iotago.BaseToken
+	allotmentManaAmount       iotago.Mana
+	manaClaimInterval         time.Duration
+	manaClaimMinPotentialMana iotago.Mana
+	submitErrorClassifier     SubmitErrorClassifierFunc
+	submitRetryPolicies       map[SubmitErrorClass]*RetryPolicy
+	powWorkerCount            int
+	requestValidators         []RequestValidator
+	sharedQueue               SharedQueue
+	issuanceEnabled           bool
+	leaderLease               LeaderLease
+	leaderLeaseDuration       time.Duration
+	shutdownTimeout           time.Duration
+	queueFilePath             string
+	maxPendingRequestsPerIP   int
+	dailyBudget               Budget
+	epochBudget               Budget
+	queueSize                 int
+	queueOverflowPolicy       QueueOverflowPolicy
+	queueBlockTimeout         time.Duration
+	airdropBatchSize          int
+	donationsMaxCount         int
+	inputSelectionStrategy    InputSelectionStrategy
+	maxInputsPerTransaction   int
+	clock                     Clock
+	balanceCacheTTL           time.Duration
+	balanceCheckMaxPerSecond  int
+	balanceCheckMaxConcurrent int
+	sourceAddresses           []iotago.Address
+	retiredAddresses          []iotago.Address
+	burnAddresses             []iotago.Address
+	storageDepositPolicy      StorageDepositPolicy
+	requestableAmountMin      iotago.BaseToken
+	requestableAmountMax      iotago.BaseToken
+	baseTokenAmountJitter     float64
+	signerCheckInterval       time.Duration
 }
 
 // applies the given Option.
@@ -249,6 +529,33 @@ func WithManaAmountMinFaucet(manaAmountMinFaucet iotago.Mana) Option {
 	}
 }
 
+// WithManaAmountMaxTarget defines the maximum amount of mana on the target address.
+// If there is more mana already, the request receives no mana. A value of 0 disables the check.
+// If manaOnlyPayouts is true, addresses that hold the maximum amount of funds but less than the maximum amount of mana
+// are not rejected, but receive mana with the minimum storage deposit.
+func WithManaAmountMaxTarget(manaAmountMaxTarget iotago.Mana, manaOnlyPayouts bool) Option {
+	return func(opts *Options) {
+		opts.manaAmountMaxTarget = manaAmountMaxTarget
+		opts.manaOnlyPayouts = manaOnlyPayouts
+	}
+}
+
+// WithManaClaimInterval defines the interval in which it is checked if the potential mana of the faucet
+// should be converted into stored mana by sweeping the faucet outputs. A value of 0 disables mana claiming.
+func WithManaClaimInterval(manaClaimInterval time.Duration) Option {
+	return func(opts *Options) {
+		opts.manaClaimInterval = manaClaimInterval
+	}
+}
+
+// WithManaClaimMinPotentialMana defines the minimum amount of potential mana
+// the faucet outputs need to hold before it is claimed.
+func WithManaClaimMinPotentialMana(manaClaimMinPotentialMana iotago.Mana) Option {
+	return func(opts *Options) {
+		opts.manaClaimMinPotentialMana = manaClaimMinPotentialMana
+	}
+}
+
 // WithTagMessage defines the faucet transaction tag payload.
 func WithTagMessage(tagMessage string) Option {
 	return func(opts *Options) {
@@ -256,6 +563,14 @@ func WithTagMessage(tagMessage string) Option {
 	}
 }
 
+// WithRequestTagMaxLength defines the maximum length of the optional tag of a faucet request.
+// A value of 0 disables request tags.
+func WithRequestTagMaxLength(requestTagMaxLength int) Option {
+	return func(opts *Options) {
+		opts.requestTagMaxLength = requestTagMaxLength
+	}
+}
+
 // WithBatchTimeout sets the maximum duration for collecting faucet batches.
 func WithBatchTimeout(timeout time.Duration) Option {
 	return func(opts *Options) {
@@ -263,6 +578,58 @@ func WithBatchTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithBatchTimeoutMin sets the minimum duration for collecting faucet batches.
+// The batch timeout shrinks from the maximum towards the minimum as the queue grows.
+// A value of 0 disables the adaptive batch timeout.
+func WithBatchTimeoutMin(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.batchTimeoutMin = timeout
+	}
+}
+
+// WithBatchMaxSize sets the maximum amount of requests that are collected in a batch.
+// The batch is issued as soon as this amount is reached, without waiting for the batch timeout.
+func WithBatchMaxSize(batchMaxSize int) Option {
+	return func(opts *Options) {
+		opts.batchMaxSize = batchMaxSize
+	}
+}
+
+// WithMaxBlockReattachments sets the maximum amount of times the transaction of an orphaned faucet block
+// is reattached in a new block before the requests are processed again in a new transaction.
+func WithMaxBlockReattachments(maxBlockReattachments int) Option {
+	return func(opts *Options) {
+		opts.maxBlockReattachments = maxBlockReattachments
+	}
+}
+
+// WithMaxReferenceManaCost sets the maximum reference mana cost of the network up to which faucet transactions are issued.
+// If the reference mana cost is higher, the issuance is paused until the congestion drops. A value of 0 disables the throttling.
+func WithMaxReferenceManaCost(maxReferenceManaCost iotago.Mana) Option {
+	return func(opts *Options) {
+		opts.maxReferenceManaCost = maxReferenceManaCost
+	}
+}
+
+// WithSubmitErrorClassifier sets the function that classifies errors of failed faucet transaction submissions.
+func WithSubmitErrorClassifier(submitErrorClassifier SubmitErrorClassifierFunc) Option {
+	return func(opts *Options) {
+		opts.submitErrorClassifier = submitErrorClassifier
+	}
+}
+
+// WithSubmitRetryPolicy sets the retry policy for failed faucet transaction submissions of the given error class.
+// Errors of classes without a retry policy are not retried. Timeouts and unknown errors are never retried,
+// because the block issuer may have accepted the transaction anyway.
+func WithSubmitRetryPolicy(errorClass SubmitErrorClass, retryPolicy *RetryPolicy) Option {
+	return func(opts *Options) {
+		if opts.submitRetryPolicies == nil {
+			opts.submitRetryPolicies = make(map[SubmitErrorClass]*RetryPolicy)
+		}
+		opts.submitRetryPolicies[errorClass] = retryPolicy
+	}
+}
+
 // WithPoWWorkerCount sets the amount of workers used for calculating PoW when sending payloads to the block issuer.
 func WithPoWWorkerCount(powWorkerCount int) Option {
 	return func(opts *Options) {
@@ -276,12 +643,7 @@ type Option func(opts *Options)
 // New creates a new faucet instance.
 func New(
 	daemon daemon.Daemon,
-	isNodeHealthyFunc IsNodeHealthyFunc,
-	fetchTransactionMetadataFunc FetchTransactionMetadataFunc,
-	collectUnlockableFaucetOutputsFunc CollectUnlockableFaucetOutputsFunc,
-	computeUnlockableAddressBalanceFunc ComputeUnlockableAddressBalanceFunc,
-	getLatestSlotFunc GetLatestSlotFunc,
-	submitTransactionPayloadFunc SubmitTransactionPayloadFunc,
+	node NodeAdapter,
 	apiProvider iotago.APIProvider,
 	address iotago.Address,
 	addressSigner iotago.AddressSigner,
@@ -291,81 +653,93 @@ func New(
 	options.apply(opts...)
 
 	faucet := &Faucet{
-		daemon:                              daemon,
-		isNodeHealthyFunc:                   isNodeHealthyFunc,
-		fetchTransactionMetadataFunc:        fetchTransactionMetadataFunc,
-		computeUnlockableAddressBalanceFunc: computeUnlockableAddressBalanceFunc,
-		getLatestSlotFunc:                   getLatestSlotFunc,
-		submitTransactionPayloadFunc:        submitTransactionPayloadFunc,
-		apiProvider:                         apiProvider,
-		address:                             address,
-		addressSigner:                       addressSigner,
-		opts:                                options,
+		daemon:                  daemon,
+		node:                    node,
+		apiProvider:             apiProvider,
+		address:                 address,
+		addressSigner:           addressSigner,
+		opts:                    options,
+		addressLists:            newAddressListStore(),
+		shadowBans:              newShadowBanStore(),
+		airdrops:                newAirdropQueue(),
+		subscriptions:           newSubscriptionStore(),
+		donations:               newDonationStore(),
+		issuanceRate:            newIssuanceRateLimiter(options.maxTransactionsPerMinute, options.clock),
+		balanceCache:            newBalanceCache(options.balanceCacheTTL, options.clock),
+		balanceCheckLimiter:     newBalanceCheckLimiter(options.balanceCheckMaxPerSecond, options.clock),
+		unfinalizedTransactions: make(map[iotago.TransactionID]*pendingTransaction),
+		retiredAddressesSwept:   make(map[string]bool),
+		inxConnection:           newINXConnection(),
 
 		Events: &Events{
-			IssuedBlock: event.New1[iotago.BlockID](),
-			SoftError:   event.New1[error](),
+			IssuedBlock:          event.New1[iotago.BlockID](),
+			SoftError:            event.New1[*SoftError](),
+			SubmitRetried:        event.New1[SubmitErrorClass](),
+			TransactionConfirmed: event.New1[*ConfirmedTransaction](),
+			TransactionFailed:    event.New1[*FailedTransaction](),
+			RequestEnqueued:      event.New1[*Payout](),
+			RequestRejected:      event.New1[*RejectedRequest](),
+			RequestProcessed:     event.New1[*ProcessedRequest](),
+			RequestFailed:        event.New1[*FailedRequest](),
+			BalanceChanged:       event.New1[*BalanceChange](),
+			DonationReceived:     event.New1[*Donation](),
+			NodeCallCompleted:    event.New1[*NodeCall](),
 		},
 	}
 
-	// write lock must be acquired outside because we read from queueMap and we want to set the faucet balance without modifications to the map
-	faucet.collectUnlockableFaucetOutputsAndBalanceFuncWithoutLocking = func() ([]UTXOBasicOutput, iotago.BaseToken, error) {
-		// get all outputs of the faucet
-		unspentOutputs, err := collectUnlockableFaucetOutputsFunc()
-		if err != nil {
-			return nil, 0, err
-		}
-
-		// get the total faucet balance
-		var balance iotago.BaseToken
-		for _, output := range unspentOutputs {
-			balance += output.Output.BaseTokenAmount()
-		}
+	// the round-trip times of the calls are measured, so slow indexer queries that stall the batching become visible
+	faucet.node = newInstrumentedNodeAdapter(node, options.clock, faucet.Events.NodeCallCompleted)
 
-		// calculate total balance of all pending requests
-		var pendingRequestsBalance iotago.BaseToken
-		for _, pendingRequest := range faucet.queueMap {
-			pendingRequestsBalance += pendingRequest.BaseTokenAmount
-		}
+	faucet.Logger = options.logger
+	faucet.init()
 
-		// subtract the storage deposit for a simple basic output, so we can simplify our logic for remainder handling
-		minStorageDeposit, err := faucet.apiProvider.CommittedAPI().StorageScoreStructure().MinDeposit(EmptyBasicOutput)
-		if err != nil {
-			return nil, 0, err
-		}
+	return faucet
+}
 
-		if balance >= minStorageDeposit {
-			balance -= minStorageDeposit
-		} else {
-			balance = 0
-		}
+// collectUnlockableFaucetOutputsAndBalanceWithoutLocking collects the unlockable outputs and the balance of the faucet.
+// write lock must be acquired outside because the outputs must not change until the faucet funds are set.
+func (f *Faucet) collectUnlockableFaucetOutputsAndBalanceWithoutLocking() ([]UTXOBasicOutput, iotago.BaseToken, error) {
+	// get all outputs of the faucet
+	unspentOutputs, err := f.node.CollectUnlockableFaucetOutputs()
+	if err != nil {
+		return nil, 0, newSoftError(SoftErrorCategoryIndexer, err)
+	}
+	unspentOutputs = f.filterSpendableOutputs(unspentOutputs)
 
-		if balance >= pendingRequestsBalance {
-			balance -= pendingRequestsBalance
-		} else {
-			balance = 0
-		}
+	// get the total faucet balance
+	var balance iotago.BaseToken
+	for _, output := range unspentOutputs {
+		balance += output.Output.BaseTokenAmount()
+	}
 
-		return unspentOutputs, balance, nil
+	// subtract the storage deposit for a simple basic output, so we can simplify our logic for remainder handling
+	minStorageDeposit, err := f.apiProvider.CommittedAPI().StorageScoreStructure().MinDeposit(EmptyBasicOutput)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	faucet.Logger = options.logger
-	faucet.init()
+	if balance >= minStorageDeposit {
+		balance -= minStorageDeposit
+	} else {
+		balance = 0
+	}
 
-	return faucet
+	return unspentOutputs, balance, nil
 }
 
 func (f *Faucet) init() {
-	f.faucetBalance = 0
-	f.queue = make(chan *queueItem, 5000)
+	f.reservations = newReservationLedger()
+	f.potentialMana = 0
+	f.queue = newRequestQueue(f.opts.queueSize)
 	f.queueMap = make(map[string]*queueItem)
+	f.pendingRequestsPerIP = make(map[string]int)
 	f.flushQueue = make(chan struct{})
-	f.pendingTransaction = nil
+	f.pendingTransactions = make(map[iotago.TransactionID]*pendingTransaction)
 }
 
 // IsHealthy returns the health status of the faucet.
 func (f *Faucet) IsHealthy() bool {
-	return f.isNodeHealthyFunc()
+	return f.node.IsNodeHealthy()
 }
 
 // Address returns the deposit address of the faucet.
@@ -377,70 +751,240 @@ func (f *Faucet) Address() iotago.Address {
 func (f *Faucet) Info() (*InfoResponse, error) {
 	protocolParams := f.apiProvider.CommittedAPI().ProtocolParameters()
 
+	f.RLock()
+	waitingRequests := len(f.queueMap)
+	balance := f.reservations.availableBaseTokens()
+	storedMana := f.reservations.availableMana()
+	potentialMana := f.potentialMana
+	manaAmount := f.opts.manaAmount
+	f.RUnlock()
+
+	bech32Addr := f.address.Bech32(protocolParams.Bech32HRP())
+
 	return &InfoResponse{
-		IsHealthy: f.isNodeHealthyFunc(),
-		Address:   f.address.Bech32(protocolParams.Bech32HRP()),
-		Balance:   f.faucetBalance,
-		TokenName: f.opts.tokenName,
-		Bech32HRP: protocolParams.Bech32HRP(),
+		IsHealthy:           f.node.IsNodeHealthy(),
+		Address:             bech32Addr,
+		AddressURL:          f.ExplorerURL(ExplorerLinkKindAddress, bech32Addr),
+		Balance:             balance,
+		BalanceFormatted:    f.FormatBaseTokens(balance),
+		TokenName:           f.opts.tokenName,
+		TokenDecimals:       f.opts.tokenDecimals,
+		Bech32HRP:           protocolParams.Bech32HRP(),
+		WaitingRequests:     waitingRequests,
+		QueueSize:           f.opts.queueSize,
+		QueueOverflowPolicy: f.opts.queueOverflowPolicy,
+		StoredMana:          storedMana,
+		PotentialMana:       potentialMana,
+		ManaPayoutsActive:   manaAmount > 0 && storedMana > f.opts.manaAmountMinFaucet,
+		ManaAmount:          manaAmount,
+		Paused:              f.IsPaused(),
+		INXConnection:       f.INXConnectionState(),
 	}, nil
 }
 
 // Enqueue adds a new faucet request to the queue.
-func (f *Faucet) Enqueue(bech32Addr string) (*EnqueueResponse, error) {
-	addr, err := f.parseBech32Address(bech32Addr)
+// The client metadata is passed to the request validators and may be nil.
+func (f *Faucet) Enqueue(enqueueRequest *EnqueueRequest, clientMetadata *ClientMetadata) (*EnqueueResponse, error) {
+	response, err := f.enqueue(enqueueRequest, clientMetadata)
 	if err != nil {
+		f.Events.RequestRejected.Trigger(&RejectedRequest{
+			Bech32: enqueueRequest.Address,
+			Type:   enqueueRequest.Type,
+			Tag:    enqueueRequest.Tag,
+			Client: clientMetadata,
+			Error:  AsRequestError(err),
+		})
+	}
+
+	return response, err
+}
+
+// enqueue validates a new faucet request and adds it to the queue.
+func (f *Faucet) enqueue(enqueueRequest *EnqueueRequest, clientMetadata *ClientMetadata) (*EnqueueResponse, error) {
+	if err := f.checkStopping(); err != nil {
+		return nil, err
+	}
+
+	if err := f.checkPaused(); err != nil {
 		return nil, err
 	}
 
-	if !f.isNodeHealthyFunc() {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, "Faucet node is not synchronized/healthy. Please try again later!")
+	if err := f.checkBalanceInitialized(); err != nil {
+		return nil, err
+	}
+
+	addr, bech32Addr, err := f.canonicalBech32Address(enqueueRequest.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.checkDenylist(bech32Addr); err != nil {
+		return nil, err
+	}
+
+	if err := f.checkPayoutTarget(addr); err != nil {
+		return nil, err
+	}
+
+	tag, err := f.sanitizeRequestTag(enqueueRequest.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	requestType := enqueueRequest.Type
+	switch requestType {
+	case "":
+		requestType = RequestTypeBasic
+	case RequestTypeBasic, RequestTypeDelegation, RequestTypeAllotment:
+	default:
+		return nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid request type \"%s\" provided!", requestType))
+	}
+
+	if !f.node.IsNodeHealthy() {
+		return nil, NewRequestError(ErrorCodeNodeUnhealthy, http.StatusServiceUnavailable, "Faucet node is not synchronized/healthy. Please try again later!").WithRetryAfter(retryAfterNodeUnhealthy)
 	}
 
 	if exists := f.isAlreadyinQueue(bech32Addr); exists {
-		return nil, ierrors.Wrap(httpserver.ErrInvalidParameter, "Address is already in the queue.")
+		return nil, NewRequestError(ErrorCodeAddressAlreadyInQueue, http.StatusBadRequest, "Address is already in the queue.")
+	}
+
+	var baseTokenAmount iotago.BaseToken
+	var skipMana, allowlisted, balanceUnchecked bool
+	switch requestType {
+	case RequestTypeDelegation:
+		baseTokenAmount, err = f.delegationAmount(addr)
+		if err != nil {
+			return nil, err
+		}
+
+	case RequestTypeAllotment:
+		// allotment requests don't create outputs, so no base tokens are needed
+		if err := f.checkAllotmentRequest(addr); err != nil {
+			return nil, err
+		}
+
+	default:
+		if err := f.checkTargetAddressType(addr); err != nil {
+			return nil, err
+		}
+
+		if allowEntry := f.addressLists.get(AddressListAllow, bech32Addr); allowEntry != nil {
+			baseTokenAmount, skipMana = f.allowlistPayoutAmounts(addr, allowEntry)
+			allowlisted = true
+
+			break
+		}
+
+		// the funds on the target address are checked when the request is processed,
+		// so slow responses of the indexer don't delay the API
+		baseTokenAmount = f.RuntimeParameters().BaseTokenAmount
+		skipMana = !canReceiveMana(addr)
+		balanceUnchecked = true
+	}
+
+	var amountRequested bool
+	if enqueueRequest.Amount != "" {
+		// the requested amount replaces the regular amount, it is still subject to the checks of the funds
+		baseTokenAmount, err = f.requestedAmount(requestType, enqueueRequest.Amount)
+		if err != nil {
+			return nil, err
+		}
+		amountRequested = true
+	}
+
+	baseTokenAmount, err = f.validateRequest(&ValidationRequest{
+		Bech32:             bech32Addr,
+		Address:            addr,
+		Type:               requestType,
+		Tag:                tag,
+		Challenge:          enqueueRequest.Challenge,
+		Nonce:              enqueueRequest.Nonce,
+		OwnershipChallenge: enqueueRequest.OwnershipChallenge,
+		PublicKey:          enqueueRequest.PublicKey,
+		Signature:          enqueueRequest.Signature,
+		BaseTokenAmount:    baseTokenAmount,
+		Client:             clientMetadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseTokenAmount, err = f.applyMinStorageDeposit(requestType, addr, baseTokenAmount)
+	if err != nil {
+		return nil, err
 	}
 
-	baseTokenAmount := f.opts.baseTokenAmount
-	balance, err := f.computeUnlockableAddressBalanceFunc(addr)
-	if err == nil && balance >= f.opts.baseTokenAmount {
-		baseTokenAmount = f.opts.baseTokenAmountSmall
+	if response := f.shadowBannedResponse(bech32Addr, tag, clientMetadata); response != nil {
+		return response, nil
+	}
 
-		if balance >= f.opts.baseTokenAmountMaxTarget {
-			return nil, ierrors.Wrap(httpserver.ErrInvalidParameter, "You already have enough funds on your address.")
+	request := &queueItem{
+		Bech32:           bech32Addr,
+		BaseTokenAmount:  baseTokenAmount,
+		Address:          addr,
+		Tag:              tag,
+		Type:             requestType,
+		EnqueuedAt:       f.opts.clock.Now(),
+		Priority:         RequestPriorityAnonymous,
+		SkipMana:         skipMana,
+		BalanceUnchecked: balanceUnchecked,
+		AmountRequested:  amountRequested,
+		AmountKind:       f.payoutAmountKind(requestType, baseTokenAmount, allowlisted),
+	}
+	if amountRequested {
+		request.AmountKind = PayoutAmountRequested
+	}
+	if clientMetadata != nil {
+		request.RemoteIP = clientMetadata.RemoteIP
+		request.UserAgent = clientMetadata.UserAgent
+		request.IdempotencyKeyHash = hashIdempotencyKey(clientMetadata.IdempotencyKey)
+		if clientMetadata.Authenticated {
+			request.Priority = RequestPriorityAuthenticated
 		}
 	}
 
+	return f.enqueueItem(request)
+}
+
+// enqueueItem adds a validated request to the shared or the local queue.
+func (f *Faucet) enqueueItem(request *queueItem) (*EnqueueResponse, error) {
+	if f.opts.sharedQueue != nil {
+		response, err := f.enqueueShared(request)
+		if err != nil {
+			return nil, err
+		}
+		f.Events.RequestEnqueued.Trigger(newPayout(request))
+
+		return response, nil
+	}
+
 	// we already need to lock here to have the correct faucet balance
 	// and we need to add the request to the queueMap
 	f.Lock()
 	defer f.Unlock()
 
-	if baseTokenAmount > f.faucetBalance {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, "Faucet does not have enough funds to process your request. Please try again later!")
+	if request.BaseTokenAmount > f.reservations.availableBaseTokens() {
+		return nil, NewRequestError(ErrorCodeFaucetNotEnoughFunds, http.StatusServiceUnavailable, "Faucet does not have enough funds to process your request. Please try again later!").WithRetryAfter(retryAfterNotEnoughFunds)
 	}
 
-	request := &queueItem{
-		Bech32:          bech32Addr,
-		BaseTokenAmount: baseTokenAmount,
-		Address:         addr,
+	if err := f.checkPendingRequestsPerIPWithoutLocking(request.RemoteIP); err != nil {
+		return nil, err
 	}
 
-	select {
-	case f.queue <- request:
-		f.faucetBalance -= baseTokenAmount
-		f.queueMap[bech32Addr] = request
-
-		return &EnqueueResponse{
-			Address:         bech32Addr,
-			WaitingRequests: len(f.queueMap),
-		}, nil
-
-	default:
-		// queue is full
+	if err := f.checkBudgetWithoutLocking(request); err != nil {
+		return nil, err
+	}
 
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, "Faucet queue is full. Please try again later!")
+	if err := f.pushRequestWithoutLocking(request); err != nil {
+		return nil, err
 	}
+	f.Events.RequestEnqueued.Trigger(newPayout(request))
+
+	return &EnqueueResponse{
+		Address:         request.Bech32,
+		WaitingRequests: len(f.queueMap),
+		Tag:             request.Tag,
+	}, nil
 }
 
 // FlushRequests stops current batching of faucet requests.
@@ -448,27 +992,92 @@ func (f *Faucet) FlushRequests() {
 	f.flushQueue <- struct{}{}
 }
 
-// logSoftError logs a soft error and triggers the event.
-func (f *Faucet) logSoftError(err error) {
-	f.LogWarn(err.Error())
-	f.Events.SoftError.Trigger(err)
+// FlushRequestsWithContext stops current batching of faucet requests.
+// It returns an error if the faucet didn't collect requests before the context was done,
+// e.g. because it waits for a pending transaction.
+func (f *Faucet) FlushRequestsWithContext(ctx context.Context) error {
+	select {
+	case f.flushQueue <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "Faucet is not collecting requests at the moment. Please try again later!").WithRetryAfter(f.RuntimeParameters().BatchTimeout)
+	}
 }
 
 // parseBech32Address parses a bech32 address.
 func (f *Faucet) parseBech32Address(bech32Addr string) (iotago.Address, error) {
 	hrp, bech32Address, err := iotago.ParseBech32(bech32Addr)
 	if err != nil {
-		return nil, ierrors.Wrap(httpserver.ErrInvalidParameter, "Invalid bech32 address provided!")
+		return nil, NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, "Invalid bech32 address provided!")
 	}
 
 	protocolParams := f.apiProvider.CommittedAPI().ProtocolParameters()
 	if hrp != protocolParams.Bech32HRP() {
-		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "Invalid bech32 address provided! Address does not start with \"%s\".", protocolParams.Bech32HRP())
+		return nil, NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, fmt.Sprintf("Invalid bech32 address provided! Address does not start with \"%s\".", protocolParams.Bech32HRP()))
 	}
 
 	return bech32Address, nil
 }
 
+// canonicalBech32Address parses a bech32 address and returns it together with its canonical encoding.
+// Requests are tracked by the canonical encoding of the decoded address, so different representations
+// of the same address, e.g. in upper case, can't bypass the check for requests that are already queued.
+func (f *Faucet) canonicalBech32Address(bech32Addr string) (iotago.Address, string, error) {
+	addr, err := f.parseBech32Address(bech32Addr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return addr, addr.Bech32(f.apiProvider.CommittedAPI().ProtocolParameters().Bech32HRP()), nil
+}
+
+// canonicalBech32 returns the canonical encoding of the given bech32 address to look up its requests.
+// invalid addresses are returned unchanged, they are never part of the queue.
+func (f *Faucet) canonicalBech32(bech32Addr string) string {
+	_, canonicalAddr, err := f.canonicalBech32Address(bech32Addr)
+	if err != nil {
+		return bech32Addr
+	}
+
+	return canonicalAddr
+}
+
+// sanitizeRequestTag checks the optional tag of a faucet request.
+func (f *Faucet) sanitizeRequestTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", nil
+	}
+
+	if f.opts.requestTagMaxLength == 0 {
+		return "", NewRequestError(ErrorCodeInvalidTag, http.StatusBadRequest, "Request tags are not supported by this faucet.")
+	}
+
+	if len(tag) > f.opts.requestTagMaxLength {
+		return "", NewRequestError(ErrorCodeInvalidTag, http.StatusBadRequest, fmt.Sprintf("Invalid tag provided! Tag must not be longer than %d characters.", f.opts.requestTagMaxLength))
+	}
+
+	for _, r := range tag {
+		if !isAllowedRequestTagRune(r) {
+			return "", NewRequestError(ErrorCodeInvalidTag, http.StatusBadRequest, "Invalid tag provided! Only letters, digits and \"-_.:/#\" are allowed.")
+		}
+	}
+
+	return tag, nil
+}
+
+// isAllowedRequestTagRune checks if the given rune is allowed in the tag of a faucet request.
+func isAllowedRequestTagRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case strings.ContainsRune("-_.:/#", r):
+		return true
+	default:
+		return false
+	}
+}
+
 // isAlreadyinQueue checks if the given address is already in the queue.
 func (f *Faucet) isAlreadyinQueue(bech32Addr string) bool {
 	f.RLock()
@@ -483,7 +1092,17 @@ func (f *Faucet) isAlreadyinQueue(bech32Addr string) bool {
 // this is necessary to be able to send a new request to the same address.
 // write lock must be acquired outside.
 func (f *Faucet) clearRequestWithoutLocking(request *queueItem) {
+	if request.airdropEntry != nil {
+		// airdrop payouts are not part of the public queue
+		f.airdrops.finish(request, AirdropEntryStateFailed, "", "the payout was dropped")
+
+		return
+	}
+
 	delete(f.queueMap, request.Bech32)
+	f.reservations.release(request.Bech32)
+	f.untrackPendingRequestWithoutLocking(request)
+	f.releaseSharedRequest(request.Bech32)
 }
 
 // clearRequestsWithoutLocking clears the old requests from the map.
@@ -496,80 +1115,180 @@ func (f *Faucet) clearRequestsWithoutLocking(batchedRequests []*queueItem) {
 }
 
 // readdRequestsWithoutLocking adds old requests back to the queue.
+// they are processed before new requests, so they don't starve.
 // write lock must be acquired outside.
 func (f *Faucet) readdRequestsWithoutLocking(batchedRequests []*queueItem) {
-	for _, request := range batchedRequests {
-		select {
-		case f.queue <- request:
-		default:
-			// queue full => no way to readd it, delete it from the map as well so user are able to send a new request
-			f.clearRequestWithoutLocking(request)
-		}
+	publicRequests, airdropRequests := splitAirdropRequests(batchedRequests)
+	f.airdrops.requeue(airdropRequests)
+
+	for _, request := range publicRequests {
+		request.Priority = RequestPriorityRetry
+		f.queue.forcePush(request)
 	}
 }
 
-// setPendingTransactionWithoutLocking sets the pending transaction.
+// setPendingTransactionWithoutLocking tracks the given transaction until it is confirmed or conflicting.
+// A reattached transaction replaces the pending transaction with the same ID.
 // write lock must be acquired outside.
 func (f *Faucet) setPendingTransactionWithoutLocking(pending *pendingTransaction) {
-	f.pendingTransaction = pending
+	f.pendingTransactions[pending.TransactionID] = pending
 }
 
 // clearPendingTransactionWithoutLocking removes tracking of a pending transaction.
 // write lock must be acquired outside.
-func (f *Faucet) clearPendingTransactionWithoutLocking() {
-	f.pendingTransaction = nil
+func (f *Faucet) clearPendingTransactionWithoutLocking(pending *pendingTransaction) {
+	delete(f.pendingTransactions, pending.TransactionID)
+}
+
+// pendingTransactionsWithoutLocking returns the pending transactions.
+// read lock must be acquired outside.
+func (f *Faucet) pendingTransactionsWithoutLocking() []*pendingTransaction {
+	pendingTransactions := make([]*pendingTransaction, 0, len(f.pendingTransactions))
+	for _, pendingTx := range f.pendingTransactions {
+		pendingTransactions = append(pendingTransactions, pendingTx)
+	}
+
+	return pendingTransactions
 }
 
-// clearPendingRequestsWithoutLocking clears the old requests from the map
-// and removes tracking of a pending transaction.
+// clearPendingRequestsWithoutLocking clears the old requests of a confirmed pending transaction from the map
+// and removes tracking of the pending transaction.
 // write lock must be acquired outside.
-func (f *Faucet) clearPendingRequestsWithoutLocking() {
-	f.clearRequestsWithoutLocking(f.pendingTransaction.QueuedItems)
-	f.clearPendingTransactionWithoutLocking()
+func (f *Faucet) clearPendingRequestsWithoutLocking(pendingTx *pendingTransaction) {
+	if !pendingTx.Reissued {
+		f.recordDistributionWithoutLocking(pendingTx.QueuedItems)
+		confirmedTx := newConfirmedTransaction(pendingTx)
+		f.Events.TransactionConfirmed.Trigger(confirmedTx)
+		f.triggerProcessedRequests(confirmedTx)
+	}
+
+	// the transaction is tracked until it is finalized, so a rollback can be detected
+	f.unfinalizedTransactions[pendingTx.TransactionID] = pendingTx
+
+	_, airdropRequests := splitAirdropRequests(pendingTx.QueuedItems)
+	for _, request := range airdropRequests {
+		f.airdrops.finish(request, AirdropEntryStatePaid, pendingTx.TransactionID.ToHex(), "")
+	}
+
+	// the funds on the target addresses changed
+	for _, request := range pendingTx.QueuedItems {
+		f.balanceCache.invalidate(request.Address)
+	}
+
+	f.clearRequestsWithoutLocking(pendingTx.QueuedItems)
+	f.clearPendingTransactionWithoutLocking(pendingTx)
 }
 
-// readdPendingRequestsWithoutLocking adds old requests back to the queue
-// and removes tracking of a pending transaction.
+// newPayout creates the info about the payout of a request.
+// the mana amount is only known after the transaction was created.
+func newPayout(request *queueItem) *Payout {
+	return &Payout{
+		Bech32:          request.Bech32,
+		Type:            request.Type,
+		BaseTokenAmount: request.BaseTokenAmount,
+		ManaAmount:      request.ManaAmount,
+		Tag:             request.Tag,
+		RemoteIP:        request.RemoteIP,
+		UserAgent:       request.UserAgent,
+		AmountKind:      request.AmountKind,
+		EnqueuedAt:      request.EnqueuedAt,
+	}
+}
+
+// pendingTransactionPayouts returns the payouts of the requests of a pending transaction.
+func pendingTransactionPayouts(pendingTx *pendingTransaction) []*Payout {
+	payouts := make([]*Payout, 0, len(pendingTx.QueuedItems))
+	for _, request := range pendingTx.QueuedItems {
+		payouts = append(payouts, newPayout(request))
+	}
+
+	return payouts
+}
+
+// newConfirmedTransaction creates the info about the payouts of a confirmed pending transaction.
+func newConfirmedTransaction(pendingTx *pendingTransaction) *ConfirmedTransaction {
+	return &ConfirmedTransaction{
+		BlockID:       pendingTx.BlockID,
+		TransactionID: pendingTx.TransactionID,
+		Payouts:       pendingTransactionPayouts(pendingTx),
+	}
+}
+
+// readdPendingRequestsWithoutLocking adds the old requests of a failed pending transaction back to the queue
+// and removes tracking of the pending transaction.
 // write lock must be acquired outside.
-func (f *Faucet) readdPendingRequestsWithoutLocking() {
-	f.readdRequestsWithoutLocking(f.pendingTransaction.QueuedItems)
-	f.clearPendingTransactionWithoutLocking()
+func (f *Faucet) readdPendingRequestsWithoutLocking(pendingTx *pendingTransaction, reason error) {
+	f.Events.TransactionFailed.Trigger(&FailedTransaction{
+		BlockID:       pendingTx.BlockID,
+		TransactionID: pendingTx.TransactionID,
+		Payouts:       pendingTransactionPayouts(pendingTx),
+		Reason:        reason,
+	})
+	f.readdRequestsWithoutLocking(pendingTx.QueuedItems)
+	f.clearPendingTransactionWithoutLocking(pendingTx)
 }
 
-// collectRequests collects faucet requests until the maximum amount or a timeout is reached.
+// adaptiveBatchTimeout returns the batch timeout for the given amount of waiting requests.
+// The timeout shrinks linearly from the maximum towards the minimum until a full batch is waiting,
+// so the latency stays bounded under load, while requests are still batched if the faucet is idle.
+func (f *Faucet) adaptiveBatchTimeout(batchTimeout time.Duration, waitingRequests int, batchMaxSize int) time.Duration {
+	batchTimeoutMin := f.opts.batchTimeoutMin
+	if batchTimeoutMin <= 0 || batchTimeoutMin >= batchTimeout || batchMaxSize <= 0 {
+		return batchTimeout
+	}
+
+	if waitingRequests >= batchMaxSize {
+		return batchTimeoutMin
+	}
+
+	return batchTimeout - (batchTimeout-batchTimeoutMin)*time.Duration(waitingRequests)/time.Duration(batchMaxSize)
+}
+
+// collectRequests collects faucet requests until the maximum batch size or a timeout is reached.
 // locking not required.
 func (f *Faucet) collectRequests(ctx context.Context) ([]*queueItem, error) {
 	batchedRequests := []*queueItem{}
 
+	batchMaxSize := f.opts.batchMaxSize
+	if batchMaxSize <= 0 || batchMaxSize > iotago.MaxOutputsCount {
+		batchMaxSize = iotago.MaxOutputsCount
+	}
+	batchTimeout := f.RuntimeParameters().BatchTimeout
+
 CollectValues:
-	for len(batchedRequests) < iotago.MaxOutputsCount {
+	for len(batchedRequests) < batchMaxSize {
+		// the waiting requests are taken by priority
+		if request := f.queue.tryPop(); request != nil {
+			batchedRequests = append(batchedRequests, request)
+
+			continue
+		}
+
 		select {
 		case <-ctx.Done():
-			// faucet was stopped
-			return nil, ErrOperationAborted
+			// faucet was stopped => the collected requests are returned, so they can be readded to the queue
+			return batchedRequests, ErrOperationAborted
 
-		case <-time.After(f.opts.batchTimeout):
+		case <-f.opts.clock.After(f.adaptiveBatchTimeout(batchTimeout, len(batchedRequests)+f.queue.len(), batchMaxSize)):
 			// timeout was reached => stop collecting requests
 			break CollectValues
 
 		case <-f.flushQueue:
 			// flush signal => stop collecting requests
-			for len(batchedRequests) < iotago.MaxOutputsCount {
+			for len(batchedRequests) < batchMaxSize {
 				// collect all pending requests
-				select {
-				case request := <-f.queue:
-					batchedRequests = append(batchedRequests, request)
-
-				default:
+				request := f.queue.tryPop()
+				if request == nil {
 					// no pending requests
 					break CollectValues
 				}
+				batchedRequests = append(batchedRequests, request)
 			}
 
 			break CollectValues
 
-		case request := <-f.queue:
-			batchedRequests = append(batchedRequests, request)
+		case <-f.queue.added:
+			// a new request was added
 		}
 	}
 
@@ -577,11 +1296,12 @@ CollectValues:
 }
 
 // processRequestsWithoutLocking processes all possible requests considering the maximum transaction size and the remaining funds of the faucet.
+// requests that are not covered by the balance of the selected inputs, but by the total balance of the faucet, are re-added to the queue.
 // write lock must be acquired outside.
-func (f *Faucet) processRequestsWithoutLocking(collectedRequestsCounter int, balance iotago.BaseToken, batchedRequests []*queueItem) []*queueItem {
+func (f *Faucet) processRequestsWithoutLocking(collectedRequestsCounter int, balance iotago.BaseToken, totalBalance iotago.BaseToken, batchedRequests []*queueItem) []*queueItem {
 	processedBatchedRequests := []*queueItem{}
 	unprocessedBatchedRequests := []*queueItem{}
-	nodeHealthy := f.isNodeHealthyFunc()
+	nodeHealthy := f.node.IsNodeHealthy()
 
 	for i := range batchedRequests {
 		request := batchedRequests[i]
@@ -601,14 +1321,22 @@ func (f *Faucet) processRequestsWithoutLocking(collectedRequestsCounter int, bal
 		}
 
 		if balance < request.BaseTokenAmount {
+			if totalBalance >= request.BaseTokenAmount {
+				// the selected inputs don't cover the request, but the faucet has enough funds => re-add it to the queue
+				unprocessedBatchedRequests = append(unprocessedBatchedRequests, request)
+
+				continue
+			}
+
 			// not enough funds to process this request => ignore the request
-			f.clearRequestWithoutLocking(request)
+			f.failRequestWithoutLocking(request, ErrFaucetNotEnoughFunds)
 
 			continue
 		}
 
 		// request can be processed in this transaction
 		balance -= request.BaseTokenAmount
+		totalBalance -= request.BaseTokenAmount
 		collectedRequestsCounter++
 		processedBatchedRequests = append(processedBatchedRequests, request)
 	}
@@ -619,9 +1347,11 @@ func (f *Faucet) processRequestsWithoutLocking(collectedRequestsCounter int, bal
 }
 
 // createTransactionBuilder creates a transaction builder with all inputs and batched requests.
-func (f *Faucet) createTransactionBuilder(api iotago.API, unspentOutputs []UTXOBasicOutput, batchedRequests []*queueItem) (*builder.TransactionBuilder, iotago.OutputIDs, int) {
+// It returns the requests that were not added because the transaction would exceed the protocol limits,
+// or because the faucet doesn't hold enough funds or mana for them.
+func (f *Faucet) createTransactionBuilder(api iotago.API, unspentOutputs []UTXOBasicOutput, batchedRequests []*queueItem) (*builder.TransactionBuilder, iotago.OutputIDs, int, []*queueItem) {
 	txBuilder := builder.NewTransactionBuilder(api, f.addressSigner)
-	txBuilder.AddTaggedDataPayload(&iotago.TaggedData{Tag: f.opts.tagMessage, Data: nil})
+	transactionTag := f.transactionTag(transactionTypeOfRequests(batchedRequests))
 
 	var outputCount int
 	var remainderAmount int64
@@ -632,60 +1362,139 @@ func (f *Faucet) createTransactionBuilder(api iotago.API, unspentOutputs []UTXOB
 	for _, unspentOutput := range unspentOutputs {
 		outputCount++
 		remainderAmount += int64(unspentOutput.Output.Amount)
-		txBuilder.AddInput(&builder.TxInput{UnlockTarget: f.address, InputID: unspentOutput.OutputID, Input: unspentOutput.Output})
+		txBuilder.AddInput(&builder.TxInput{UnlockTarget: f.unlockTarget(unspentOutput), InputID: unspentOutput.OutputID, Input: unspentOutput.Output})
 		consumedInputs = append(consumedInputs, unspentOutput.OutputID)
 	}
 
-	manaPayoutPerOutput := func() iotago.Mana {
+	manaPayoutsPossible := func() bool {
 		// we don't know the exact slot for the transaction yet, but we use the latest slot for the estimation.
 		// this is no problem, because we issue the transaction immediately afterwards, so the commitment for block issuance should be older anyway.
 		// also we only use the stored mana in the calculation, so we don't have the influence of mana generation.
 		// because of the bigger "manaAmountMinFaucet" threshold, there is also a lot of wiggle room.
-		availableManaInputs, err := txBuilder.CalculateAvailableManaInputs(f.getLatestSlotFunc())
+		availableManaInputs, err := txBuilder.CalculateAvailableManaInputs(f.node.LatestSlot())
 		if err != nil {
 			f.logSoftError(ierrors.Wrap(err, "failed to calculate available mana balance"))
 
-			return 0
+			return false
 		}
 
-		totalManaPayouts, err := safemath.SafeMul(iotago.Mana(len(batchedRequests)), f.opts.manaAmount)
+		totalManaPayouts, err := f.requiredManaPayouts(batchedRequests)
 		if err != nil {
 			f.logSoftError(ierrors.Wrap(err, "failed to calculate required total mana for payouts"))
 
-			return 0
+			return false
 		}
 
 		unboundStoredManaRemainder, err := safemath.SafeSub(availableManaInputs.UnboundStoredMana, totalManaPayouts)
 		if err != nil {
-			f.logSoftError(ierrors.Wrapf(err, "not enough mana left in the faucet to do the payouts: %d < %d", availableManaInputs.UnboundStoredMana, totalManaPayouts))
+			f.logSoftError(newSoftError(SoftErrorCategoryInsufficientMana, ierrors.Wrapf(err, "not enough mana left in the faucet to do the payouts: %d < %d", availableManaInputs.UnboundStoredMana, totalManaPayouts)))
 
 			// underflow => not enough mana left in the faucet
-			return 0
+			return false
 		}
 
 		if unboundStoredManaRemainder <= f.opts.manaAmountMinFaucet {
-			f.logSoftError(ierrors.Errorf("not enough mana left in the faucet: %d < %d", unboundStoredManaRemainder, f.opts.manaAmountMinFaucet))
+			f.logSoftError(newSoftError(SoftErrorCategoryInsufficientMana, ierrors.Errorf("not enough mana left in the faucet: %d < %d", unboundStoredManaRemainder, f.opts.manaAmountMinFaucet)))
 
 			// not enough mana left in the faucet
-			return 0
+			return false
 		}
 
-		return f.opts.manaAmount
+		return true
 	}()
 
-	// add all requests as outputs
+	var manaPayoutPerOutput iotago.Mana
+	if manaPayoutsPossible {
+		manaPayoutPerOutput = f.opts.manaAmount
+	}
+
+	// the size and the work score of the transaction are tracked, so the block doesn't exceed the protocol limits
+	limits, err := f.newTransactionLimits(api, txBuilder, transactionTag)
+	if err != nil {
+		// only the amount of outputs is checked
+		f.logSoftError(err)
+	}
+
+	// the remainder output must cover its storage deposit as well
+	remainderMinStorageDeposit, err := api.StorageScoreStructure().MinDeposit(&iotago.BasicOutput{
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: f.remainderAddress(unspentOutputs)},
+		},
+	})
+	if err != nil {
+		f.logSoftError(ierrors.Wrap(err, "failed to calculate the minimum storage deposit of the remainder"))
+	}
+
+	// the tags of the requests are added to the data of the tagged data payload
+	requestTags := make([]string, 0)
+
+	// requests that don't fit into the transaction are processed in the next one
+	unprocessedRequests := make([]*queueItem, 0)
+
+	// add all requests as outputs or allotments
+	var allotmentCount int
+	var limitsReached bool
 	for _, req := range batchedRequests {
-		outputCount++
+		req.ManaAmount = 0
+
+		if limitsReached {
+			unprocessedRequests = append(unprocessedRequests, req)
+
+			continue
+		}
+
+		if req.Type == RequestTypeAllotment {
+			if !manaPayoutsPossible {
+				// not enough mana left in the faucet, the request is processed once the faucet has generated enough mana
+				f.logSoftError(newSoftError(SoftErrorCategoryInsufficientMana, ierrors.Errorf("skipping allotment request for %s, not enough mana left in the faucet", req.Bech32)))
+				unprocessedRequests = append(unprocessedRequests, req)
+
+				continue
+			}
+
+			if allotmentCount >= iotago.MaxAllotmentCount {
+				// do not collect further allotments
+				unprocessedRequests = append(unprocessedRequests, req)
+
+				continue
+			}
+
+			if !limits.tryAddAllotment(req.Tag) {
+				// do not collect further requests
+				limitsReached = true
+				unprocessedRequests = append(unprocessedRequests, req)
+
+				continue
+			}
+
+			allotmentCount++
+			req.ManaAmount = f.opts.allotmentManaAmount
+
+			//nolint:forcetypeassert // the address type is checked when the request is enqueued
+			txBuilder.IncreaseAllotment(req.Address.(*iotago.AccountAddress).AccountID(), f.opts.allotmentManaAmount)
+
+			if req.Tag != "" {
+				requestTags = append(requestTags, req.Tag)
+			}
 
-		if outputCount >= iotago.MaxOutputsCount-1 {
+			continue
+		}
+
+		if outputCount+1 >= iotago.MaxOutputsCount-1 {
 			// do not collect further requests
 			// the last slot is for the remainder
-			break
+			limitsReached = true
+			unprocessedRequests = append(unprocessedRequests, req)
+
+			continue
 		}
 
 		if remainderAmount == 0 {
 			// do not collect further requests
-			break
+			limitsReached = true
+			unprocessedRequests = append(unprocessedRequests, req)
+
+			continue
 		}
 
 		baseTokenAmount := req.BaseTokenAmount
@@ -693,40 +1502,109 @@ func (f *Faucet) createTransactionBuilder(api iotago.API, unspentOutputs []UTXOB
 			// not enough funds left
 			baseTokenAmount = iotago.BaseToken(remainderAmount)
 		}
-		remainderAmount -= int64(baseTokenAmount)
 
-		txBuilder.AddOutput(&iotago.BasicOutput{
-			Amount: baseTokenAmount,
-			Mana:   manaPayoutPerOutput,
-			UnlockConditions: iotago.BasicOutputUnlockConditions{
-				&iotago.AddressUnlockCondition{Address: req.Address},
-			},
-		})
+		// the capabilities of the address are checked again, because requests from the shared queue
+		// or a persisted snapshot may have been enqueued without the check.
+		if req.Type != RequestTypeDelegation && !req.SkipMana && canReceiveMana(req.Address) {
+			req.ManaAmount = manaPayoutPerOutput
+		}
+		output := f.newPayoutOutput(api, req.Type, req.Address, baseTokenAmount, req.ManaAmount)
+
+		// outputs below the minimum storage deposit would make the node reject the whole transaction,
+		// so requests that can't be covered by the remaining funds are processed in the next one
+		minStorageDeposit, err := api.StorageScoreStructure().MinDeposit(output)
+		if err != nil || baseTokenAmount < minStorageDeposit {
+			req.ManaAmount = 0
+			unprocessedRequests = append(unprocessedRequests, req)
+
+			continue
+		}
+		if leftover := remainderAmount - int64(baseTokenAmount); leftover > 0 && leftover < int64(remainderMinStorageDeposit) {
+			req.ManaAmount = 0
+			unprocessedRequests = append(unprocessedRequests, req)
+
+			continue
+		}
+
+		if !limits.tryAddOutput(output, req.Tag) {
+			// do not collect further requests
+			req.ManaAmount = 0
+			limitsReached = true
+			unprocessedRequests = append(unprocessedRequests, req)
+
+			continue
+		}
+
+		outputCount++
+		remainderAmount -= int64(baseTokenAmount)
+		txBuilder.AddOutput(output)
 		remainderOutputIndex++
+
+		if req.Tag != "" {
+			requestTags = append(requestTags, req.Tag)
+		}
+	}
+
+	var taggedData []byte
+	if len(requestTags) > 0 {
+		taggedData = []byte(strings.Join(requestTags, "\n"))
 	}
+	txBuilder.AddTaggedDataPayload(&iotago.TaggedData{Tag: transactionTag, Data: taggedData})
 
 	if remainderAmount > 0 {
 		txBuilder.AddOutput(&iotago.BasicOutput{
 			Amount: iotago.BaseToken(remainderAmount),
 			UnlockConditions: iotago.BasicOutputUnlockConditions{
-				&iotago.AddressUnlockCondition{Address: f.address},
+				&iotago.AddressUnlockCondition{Address: f.remainderAddress(unspentOutputs)},
 			},
 		})
 	}
 
-	return txBuilder, consumedInputs, remainderOutputIndex
+	return txBuilder, consumedInputs, remainderOutputIndex, unprocessedRequests
 }
 
 // sendFaucetBlockWithoutLocking creates a faucet transaction payload and sends it to the block issuer.
-// write lock must be acquired outside.
+// write lock must be acquired outside, it is released while waiting for the backoff of a retry.
 func (f *Faucet) sendFaucetBlockWithoutLocking(ctx context.Context, unspentOutputs []UTXOBasicOutput, batchedRequests []*queueItem) error {
-	api := f.apiProvider.CommittedAPI()
+	var blockPayload iotago.ApplicationPayload
+	var blockID iotago.BlockID
+	var consumedInputs iotago.OutputIDs
+	var unprocessedRequests []*queueItem
+
+	for attempt := 0; ; attempt++ {
+		api := f.apiProvider.CommittedAPI()
+
+		// the transaction builder is modified during submission, so we need to create a new one for every attempt.
+		var txBuilder *builder.TransactionBuilder
+		var remainderOutputIndex int
+		txBuilder, consumedInputs, remainderOutputIndex, unprocessedRequests = f.createTransactionBuilder(api, unspentOutputs, batchedRequests)
+
+		var err error
+		blockPayload, blockID, err = f.node.SubmitTransactionPayload(ctx, txBuilder, remainderOutputIndex, f.opts.powWorkerCount)
+		if err == nil {
+			break
+		}
 
-	txBuilder, consumedInputs, remainderOutputIndex := f.createTransactionBuilder(api, unspentOutputs, batchedRequests)
+		errorClass := f.opts.submitErrorClassifier(err)
 
-	blockPayload, blockID, err := f.submitTransactionPayloadFunc(ctx, txBuilder, remainderOutputIndex, f.opts.powWorkerCount)
-	if err != nil {
-		return ierrors.Errorf("submit faucet transaction payload failed, error: %w", err)
+		retryPolicy, exists := f.opts.submitRetryPolicies[errorClass]
+		if !exists || !errorClass.isRetryable() || attempt >= retryPolicy.MaxRetries {
+			return newSoftError(SoftErrorCategorySubmit, ierrors.Errorf("submit faucet transaction payload failed, class: %s, attempts: %d, error: %w", errorClass, attempt+1, err))
+		}
+
+		backoff := retryPolicy.Backoff(attempt)
+		f.LogDebugf("submit faucet transaction payload failed, retrying in %v, class: %s, attempt: %d, error: %s", backoff, errorClass, attempt+1, err)
+		f.Events.SubmitRetried.Trigger(errorClass)
+
+		if !f.waitForSubmitRetryWithoutLocking(ctx, backoff) {
+			// faucet was stopped
+			return ierrors.Wrapf(ErrOperationAborted, "submit faucet transaction payload failed, error: %s", err)
+		}
+
+		// the lock was released during the backoff, so the faucet state may have changed in the meantime
+		if err := f.checkSubmitRetryWithoutLocking(unspentOutputs); err != nil {
+			return ierrors.Wrap(err, "retry of the faucet transaction payload submission aborted")
+		}
 	}
 
 	signedTx, ok := blockPayload.(*iotago.SignedTransaction)
@@ -739,11 +1617,34 @@ func (f *Faucet) sendFaucetBlockWithoutLocking(ctx context.Context, unspentOutpu
 		return ierrors.Errorf("send faucet block failed, error: %w", err)
 	}
 
+	f.issuanceRate.record()
+
+	if len(unprocessedRequests) > 0 {
+		// the requests that didn't fit into the transaction are processed in the next one
+		f.LogDebugf("%d requests didn't fit into the faucet transaction, txID: %s", len(unprocessedRequests), transactionID)
+		f.readdRequestsWithoutLocking(unprocessedRequests)
+
+		unprocessed := make(map[*queueItem]struct{}, len(unprocessedRequests))
+		for _, request := range unprocessedRequests {
+			unprocessed[request] = struct{}{}
+		}
+
+		includedRequests := make([]*queueItem, 0, len(batchedRequests)-len(unprocessedRequests))
+		for _, request := range batchedRequests {
+			if _, exists := unprocessed[request]; !exists {
+				includedRequests = append(includedRequests, request)
+			}
+		}
+		batchedRequests = includedRequests
+	}
+
 	f.setPendingTransactionWithoutLocking(&pendingTransaction{
-		BlockID:        blockID,
-		QueuedItems:    batchedRequests,
-		ConsumedInputs: consumedInputs,
-		TransactionID:  transactionID,
+		BlockID:            blockID,
+		QueuedItems:        batchedRequests,
+		ConsumedInputs:     consumedInputs,
+		TransactionID:      transactionID,
+		SignedTransaction:  signedTx,
+		SourceAddressIndex: f.remainderAddressIndex(unspentOutputs),
 	})
 
 	f.Events.IssuedBlock.Trigger(blockID)
@@ -751,78 +1652,249 @@ func (f *Faucet) sendFaucetBlockWithoutLocking(ctx context.Context, unspentOutpu
 	return nil
 }
 
+// waitForSubmitRetryWithoutLocking waits for the backoff before a submission is retried.
+// The lock is released while waiting, so the API and the confirmation hooks are not blocked by the backoff.
+// It returns false if the context was done before the backoff elapsed.
+// write lock must be acquired outside.
+func (f *Faucet) waitForSubmitRetryWithoutLocking(ctx context.Context, backoff time.Duration) bool {
+	f.submitRetryPending = true
+	f.Unlock()
+
+	defer func() {
+		f.Lock()
+		f.submitRetryPending = false
+	}()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-f.opts.clock.After(backoff):
+		return true
+	}
+}
+
+// checkSubmitRetryWithoutLocking checks if the submission of a transaction that consumes the given outputs can be retried.
+// It fails if the leader lease was lost or if any of the outputs was spent or consumed by another pending transaction
+// while the lock was released.
+// write lock must be acquired outside.
+func (f *Faucet) checkSubmitRetryWithoutLocking(consumedOutputs []UTXOBasicOutput) error {
+	if !f.IsLeader() {
+		return ErrLeadershipLost
+	}
+
+	unspentOutputs, _, err := f.collectUnlockableFaucetOutputsAndBalanceWithoutLocking()
+	if err != nil {
+		return err
+	}
+
+	availableOutputIDs := make(map[iotago.OutputID]struct{}, len(unspentOutputs))
+	for _, output := range f.filterPendingOutputsWithoutLocking(unspentOutputs) {
+		availableOutputIDs[output.OutputID] = struct{}{}
+	}
+
+	for _, output := range consumedOutputs {
+		if _, available := availableOutputIDs[output.OutputID]; !available {
+			return newSoftError(SoftErrorCategorySubmit, ierrors.Errorf("input of the faucet transaction is not available anymore, outputID: %s", output.OutputID.ToHex()))
+		}
+	}
+
+	return nil
+}
+
+// reattachPendingTransactionWithoutLocking issues the already signed pending transaction in a new block.
+// This keeps the transaction ID, so the requests don't need to be processed again.
+// write lock must be acquired outside.
+func (f *Faucet) reattachPendingTransactionWithoutLocking(ctx context.Context, pendingTx *pendingTransaction) error {
+	blockID, err := f.node.ReissueTransactionPayload(ctx, pendingTx.SignedTransaction, f.opts.powWorkerCount)
+	if err != nil {
+		return newSoftError(SoftErrorCategorySubmit, ierrors.Errorf("reattach faucet transaction failed, blockID: %s, txID: %s, error: %w", pendingTx.BlockID, pendingTx.TransactionID, err))
+	}
+
+	f.LogInfof("reattached pending transaction, txID: %s, old blockID: %s, new blockID: %s", pendingTx.TransactionID, pendingTx.BlockID, blockID)
+
+	reattachedTx := *pendingTx
+	reattachedTx.BlockID = blockID
+	reattachedTx.Reattachments++
+	f.setPendingTransactionWithoutLocking(&reattachedTx)
+
+	f.Events.IssuedBlock.Trigger(blockID)
+
+	return nil
+}
+
 // computeAndSetInitialFaucetBalance computes the faucet balance minus the storage deposit for a single basic output.
 func (f *Faucet) computeAndSetInitialFaucetBalance() error {
 	f.Lock()
 	defer f.Unlock()
 
-	_, balance, err := f.collectUnlockableFaucetOutputsAndBalanceFuncWithoutLocking()
+	unspentOutputs, balance, err := f.collectUnlockableFaucetOutputsAndBalanceWithoutLocking()
 	if err != nil {
 		return err
 	}
 
-	f.faucetBalance = balance
+	f.setFaucetFundsWithoutLocking(unspentOutputs, balance)
+	f.balanceInitialized.Store(true)
 
 	return nil
 }
 
+// isCongested checks if the reference mana cost of the network exceeds the configured maximum.
+// locking not required.
+func (f *Faucet) isCongested() bool {
+	if f.opts.maxReferenceManaCost == 0 {
+		// throttling is disabled
+		return false
+	}
+
+	referenceManaCost, err := f.node.ReferenceManaCost()
+	if err != nil {
+		// we don't throttle if the reference mana cost is unknown
+		f.logSoftError(newSoftError(SoftErrorCategoryIndexer, ierrors.Wrap(err, "failed to get the reference mana cost")))
+
+		return false
+	}
+
+	congested := referenceManaCost > f.opts.maxReferenceManaCost
+	if congested != f.congested.Swap(congested) {
+		if congested {
+			f.LogInfof("network is congested, pausing issuance of faucet transactions, reference mana cost: %d > %d", referenceManaCost, f.opts.maxReferenceManaCost)
+		} else {
+			f.LogInfof("network is no longer congested, resuming issuance of faucet transactions, reference mana cost: %d <= %d", referenceManaCost, f.opts.maxReferenceManaCost)
+		}
+	}
+
+	return congested
+}
+
 // collectRequestsAndSendFaucetBlock collects the requests and sends a faucet block.
 func (f *Faucet) collectRequestsAndSendFaucetBlock(ctx context.Context) error {
 	f.LogDebug("entering collectRequestsAndSendFaucetBlock...")
 	defer f.LogDebug("leaving collectRequestsAndSendFaucetBlock...")
 
 	f.RLock()
-	pendingTx := f.pendingTransaction
+	allSourceAddressesPending := f.allSourceAddressesPendingWithoutLocking()
+	pendingTransactionsCount := len(f.pendingTransactions)
 	f.RUnlock()
 
-	// check if there is a pending transaction before issuing the next one
-	if pendingTx != nil {
-		f.LogDebugf("skip processing of new requests because a pending tx was found, blockID: %s, txID: %s", f.pendingTransaction.BlockID, f.pendingTransaction.TransactionID)
+	// check if there is a source address without a pending transaction before issuing the next one
+	if allSourceAddressesPending {
+		f.LogDebugf("skip processing of new requests because every source address has a pending tx, pending txs: %d", pendingTransactionsCount)
 
 		select {
 		case <-ctx.Done():
 			// faucet was stopped
 			return nil
-		case <-time.After(time.Second):
+		case <-f.opts.clock.After(time.Second):
 			// cooldown
 			return nil
 		}
 	}
 
-	// first collect requests
-	batchedRequests, err := f.collectRequests(ctx)
-	if err != nil {
-		if ierrors.Is(err, ErrOperationAborted) {
+	// check if the network is congested before issuing the next transaction
+	if f.isCongested() {
+		select {
+		case <-ctx.Done():
+			// faucet was stopped
+			return nil
+		case <-f.opts.clock.After(time.Second):
+			// cooldown
 			return nil
 		}
-		if IsCriticalError(err) != nil {
-			// error is a critical error
-			// => stop the faucet
-			return err
+	}
+
+	// check if the maximum amount of transactions per minute was reached before issuing the next transaction
+	if wait := f.issuanceRate.waitDuration(); wait > 0 {
+		f.LogDebugf("maximum amount of transactions per minute reached, waiting %v before issuing the next transaction", wait)
+
+		select {
+		case <-ctx.Done():
+			// faucet was stopped
+			return nil
+		case <-f.opts.clock.After(wait):
+			return nil
 		}
-		f.logSoftError(err)
+	}
 
-		return nil
+	// first collect requests, the airdrops and the public queue take turns
+	batchedRequests := f.collectAirdropRequests()
+	if len(batchedRequests) == 0 {
+		var err error
+		batchedRequests, err = f.collectRequests(ctx)
+		if err != nil {
+			if ierrors.Is(err, ErrOperationAborted) {
+				// readd the collected requests, so they are persisted on shutdown
+				f.Lock()
+				f.readdRequestsWithoutLocking(batchedRequests)
+				f.Unlock()
+
+				return nil
+			}
+			if IsCriticalError(err) != nil {
+				// error is a critical error
+				// => stop the faucet
+				return err
+			}
+			f.logSoftError(err)
+
+			return nil
+		}
 	}
 
 	f.LogDebugf("collected %d requests", len(batchedRequests))
 
+	if !f.IsLeader() {
+		// the leader lease was lost while collecting => hand over the requests to the new leader
+		f.Lock()
+		f.handOverRequestsWithoutLocking(batchedRequests)
+		f.Unlock()
+
+		return nil
+	}
+
+	// the funds on the target addresses are checked before the lock is acquired, because the lookups can be slow
+	batchedRequests, err := f.checkTargetBalances(ctx, batchedRequests)
+	if err != nil {
+		// faucet was stopped => readd the collected requests, so they are persisted on shutdown
+		f.Lock()
+		f.readdRequestsWithoutLocking(batchedRequests)
+		f.Unlock()
+
+		return nil
+	}
+
 	// write lock must be acquired outside
 	processRequestsWithoutLocking := func() ([]UTXOBasicOutput, []*queueItem, error) {
-		unspentOutputs, balance, err := f.collectUnlockableFaucetOutputsAndBalanceFuncWithoutLocking()
+		unspentOutputs, balance, err := f.collectUnlockableFaucetOutputsAndBalanceWithoutLocking()
 		if err != nil {
 			return nil, nil, err
 		}
-		f.faucetBalance = balance
+		f.setFaucetFundsWithoutLocking(unspentOutputs, balance)
+		f.logRetiredAddressesWithoutLocking(unspentOutputs)
+
+		// the source addresses take turns, so only the outputs of one of them are consumed,
+		// the outputs of source addresses with a pending transaction are skipped
+		unspentOutputs = f.selectSourceOutputsWithoutLocking(f.filterPendingOutputsWithoutLocking(unspentOutputs))
 
-		if len(unspentOutputs) < 2 && len(batchedRequests) == 0 {
-			// no need to sweep or send funds
+		if len(unspentOutputs) < 2 && len(batchedRequests) == 0 && !f.hasRetiredOutputs(unspentOutputs) && !f.isManaClaimDueWithoutLocking(unspentOutputs) {
+			// no need to sweep, claim mana or send funds
 			return nil, nil, ErrNothingToProcess
 		}
 
-		processableRequests := f.processRequestsWithoutLocking(len(unspentOutputs), balance, batchedRequests)
+		if sweepOutputs := f.planSweep(unspentOutputs); sweepOutputs != nil {
+			// the requests are processed after the outputs were consolidated
+			f.readdRequestsWithoutLocking(batchedRequests)
+
+			return sweepOutputs, nil, nil
+		}
+
+		selectedOutputs, selectedBalance, err := f.selectInputs(unspentOutputs, batchedRequests)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		return unspentOutputs, processableRequests, nil
+		processableRequests := f.processRequestsWithoutLocking(len(selectedOutputs), selectedBalance, balance, batchedRequests)
+
+		return selectedOutputs, processableRequests, nil
 	}
 
 	// we need to acquire a write lock here to be able to modify the requests in the queue
@@ -859,6 +1931,13 @@ func (f *Faucet) collectRequestsAndSendFaucetBlock(ctx context.Context) error {
 			// => stop the faucet
 			return err
 		}
+		if ierrors.Is(err, ErrLeadershipLost) {
+			// the leader lease was lost during the backoff of a retry => hand over the requests to the new leader
+			f.LogInfof("%s, handing over %d requests to the new leader", err, len(processableRequests))
+			f.handOverRequestsWithoutLocking(processableRequests)
+
+			return nil
+		}
 		// readd the non-processed requests back to the queue
 		f.readdRequestsWithoutLocking(processableRequests)
 		f.logSoftError(err)
@@ -874,20 +1953,60 @@ func (f *Faucet) RunFaucetLoop(ctx context.Context) error {
 		return CriticalError(ierrors.Errorf("reading faucet address balance failed: %s, error: %w", f.address.Bech32(f.apiProvider.CommittedAPI().ProtocolParameters().Bech32HRP()), err))
 	}
 
-	checkPendingTxTicker := time.NewTicker(5 * time.Second)
-	defer timeutil.CleanupTicker(checkPendingTxTicker)
+	// restore the requests that were persisted on the last shutdown
+	if err := f.restorePersistedQueue(); err != nil {
+		f.logSoftError(ierrors.Wrap(err, "restoring the persisted faucet queue failed"))
+	}
+
+	checkPendingTxTicker := f.opts.clock.NewTicker(5 * time.Second)
+	defer checkPendingTxTicker.Stop()
+
+	var checkSignerTick <-chan time.Time
+	if f.opts.signerCheckInterval > 0 {
+		checkSignerTicker := f.opts.clock.NewTicker(f.opts.signerCheckInterval)
+		defer checkSignerTicker.Stop()
+		checkSignerTick = checkSignerTicker.C()
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			// faucet was stopped
+			f.shutdown()
+
 			return nil
 
-		case <-checkPendingTxTicker.C:
+		case <-checkPendingTxTicker.C():
 			// check periodically for pending transaction state
-			f.checkPendingTransactionState()
+			f.checkPendingTransactionState(ctx)
+
+		case <-checkSignerTick:
+			// check periodically that the signer still produces valid signatures, e.g. after the key of a remote signer was changed
+			if err := f.checkSigner(); err != nil {
+				if IsCriticalError(err) != nil {
+					return err
+				}
+				f.logSoftError(ierrors.Wrap(err, "signer check failed"))
+			}
 
 		default:
+			if f.IsPaused() {
+				// the queued requests are kept until the faucet is resumed
+				select {
+				case <-ctx.Done():
+				case <-f.opts.clock.After(time.Second):
+				}
+
+				continue
+			}
+
+			if !f.IsLeader() {
+				// another instance issues the transactions for the requests in the shared queue
+				f.waitAndRefreshFaucetBalance(ctx)
+
+				continue
+			}
+
 			if err := f.collectRequestsAndSendFaucetBlock(ctx); err != nil {
 				return err
 			}
@@ -895,51 +2014,65 @@ func (f *Faucet) RunFaucetLoop(ctx context.Context) error {
 	}
 }
 
-// checkPendingTransactionState checks if a pending transaction was orphaned or another error occurred.
-// If a problem is found, all requests are readded to the queue.
-func (f *Faucet) checkPendingTransactionState() {
+// checkPendingTransactionState checks if the pending transactions were orphaned or another error occurred.
+// If the block of a pending transaction was orphaned, the transaction is reattached in a new block.
+// If another problem is found, all requests of the transaction are readded to the queue.
+func (f *Faucet) checkPendingTransactionState(ctx context.Context) {
 	f.LogDebug("entering checkPendingTransactionState...")
 	defer f.LogDebug("leaving checkPendingTransactionState...")
 
 	//nolint:nonamedreturns // easier to read in this case
-	checkPendingTransaction := func(pendingTx *pendingTransaction) (clearPending bool, readdPending bool, logMessage string, softError error) {
+	checkPendingTransaction := func(pendingTx *pendingTransaction) (clearPending bool, readdPending bool, reattachPending bool, logMessage string, softError error) {
 		if pendingTx == nil {
 			// no pending transaction so there is no need for additional checks
-			return false, false, "no pending transaction found", nil
+			return false, false, false, "no pending transaction found", nil
 		}
 
-		metadata, err := f.fetchTransactionMetadataFunc(pendingTx.TransactionID)
+		metadata, err := f.node.FetchTransactionMetadata(pendingTx.TransactionID)
 		if err != nil {
 			// an error occurred => re-add the items to the queue and delete the pending transaction
-			return false, true, "", ierrors.Errorf("failed to fetch metadata of the pending transaction, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID)
+			return false, true, false, "", newSoftError(SoftErrorCategoryIndexer, ierrors.Errorf("failed to fetch metadata of the pending transaction, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID))
 		}
 
+		// the block of the transaction can be reattached if the maximum amount of reattachments is not reached yet.
+		canReattach := pendingTx.SignedTransaction != nil && pendingTx.Reattachments < f.opts.maxBlockReattachments
+
 		if metadata == nil {
 			// metadata unknown, this can only happen if the block was orphaned.
+			if canReattach {
+				// => reattach the transaction in a new block
+				return false, false, true, fmt.Sprintf("metadata of the pending transaction is unknown, reattaching transaction, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID), nil
+			}
+
 			// => re-add the items to the queue and delete the pending transaction
-			return false, true, "", ierrors.Errorf("metadata of the pending transaction is unknown, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID)
+			return false, true, false, "", newSoftError(SoftErrorCategorySubmit, ierrors.Errorf("metadata of the pending transaction is unknown, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID))
 		}
 
 		switch metadata.TransactionState {
 		case api.TransactionStateUnknown:
 			// transaction is not known, so the block must have been filtered
+			if canReattach {
+				// => reattach the transaction in a new block
+				return false, false, true, fmt.Sprintf("metadata of the pending transaction is no transaction, reattaching transaction, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID), nil
+			}
+
 			// => re-add the items to the queue and delete the pending transaction
-			return false, true, "", ierrors.Errorf("metadata of the pending transaction is no transaction, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID)
+			return false, true, false, "", newSoftError(SoftErrorCategorySubmit, ierrors.Errorf("metadata of the pending transaction is no transaction, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID))
 
 		case api.TransactionStatePending:
 			// transaction is still pending
 			// => do nothing
-			return false, false, fmt.Sprintf("transaction still pending, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID), nil
+			return false, false, false, fmt.Sprintf("transaction still pending, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID), nil
 
 		case api.TransactionStateAccepted, api.TransactionStateCommitted, api.TransactionStateFinalized:
 			// transaction was accepted
 			// => delete the requests and the pending transaction
-			return true, false, fmt.Sprintf("transaction successful, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID), nil
+			return true, false, false, fmt.Sprintf("transaction successful, blockID: %s, txID: %s", pendingTx.BlockID, pendingTx.TransactionID), nil
 
 		case api.TransactionStateFailed:
 			// transaction failed
 			// => re-add the items to the queue and delete the pending transaction
-			return false, true, "", ierrors.Errorf("transaction failed, blockID: %s, txID: %s, reason: %d", pendingTx.BlockID, pendingTx.TransactionID, metadata.TransactionFailureReason)
+			return false, true, false, "", newSoftError(SoftErrorCategoryConflict, ierrors.Errorf("transaction failed, blockID: %s, txID: %s, reason: %d", pendingTx.BlockID, pendingTx.TransactionID, metadata.TransactionFailureReason))
 
 		default:
 			// unknown transaction state
@@ -947,13 +2080,31 @@ func (f *Faucet) checkPendingTransactionState() {
 		}
 	}
 
-	f.RLock()
-	pendingTx := f.pendingTransaction
-	f.RUnlock()
+	resolvePendingTransaction := func(pendingTx *pendingTransaction) {
+		clearPending, readdPending, reattachPending, logMessage, softError := checkPendingTransaction(pendingTx)
+		if !(clearPending || readdPending || reattachPending) {
+			// transaction is still pending
+			if softError != nil {
+				f.logSoftError(ierrors.Wrap(softError, "checkPendingTransactionState failed"))
+			}
+
+			if logMessage != "" {
+				f.LogDebugf("checkPendingTransactionState: %s", logMessage)
+			}
+
+			return
+		}
+
+		// we need to acquire a write lock here and check again if the transaction is still pending.
+		f.Lock()
+		defer f.Unlock()
+
+		if currentTx := f.pendingTransactions[pendingTx.TransactionID]; currentTx != pendingTx {
+			// the pending transaction was resolved or reattached in the meantime, check again
+			pendingTx = currentTx
+			clearPending, readdPending, reattachPending, logMessage, softError = checkPendingTransaction(pendingTx)
+		}
 
-	clearPending, readdPending, logMessage, softError := checkPendingTransaction(pendingTx)
-	if !(clearPending || readdPending) {
-		// no pending transaction or transaction is still pending
 		if softError != nil {
 			f.logSoftError(ierrors.Wrap(softError, "checkPendingTransactionState failed"))
 		}
@@ -962,38 +2113,43 @@ func (f *Faucet) checkPendingTransactionState() {
 			f.LogDebugf("checkPendingTransactionState: %s", logMessage)
 		}
 
-		return
-	}
-
-	// we need to acquire a write lock here and check again if there is a pending transaction.
-	f.Lock()
-	defer f.Unlock()
-
-	if pendingTx != f.pendingTransaction {
-		// the pending transaction changed, check again
-		clearPending, readdPending, logMessage, softError = checkPendingTransaction(f.pendingTransaction)
-	}
+		if clearPending {
+			f.clearPendingRequestsWithoutLocking(pendingTx)
+			return
+		}
+		if reattachPending {
+			if err := f.reattachPendingTransactionWithoutLocking(ctx, pendingTx); err != nil {
+				// reattaching failed => re-add the items to the queue and delete the pending transaction
+				f.logSoftError(ierrors.Wrap(err, "checkPendingTransactionState failed"))
+				f.readdPendingRequestsWithoutLocking(pendingTx, err)
+			}
 
-	if softError != nil {
-		f.logSoftError(ierrors.Wrap(softError, "checkPendingTransactionState failed"))
+			return
+		}
+		if readdPending {
+			f.readdPendingRequestsWithoutLocking(pendingTx, softError)
+		}
 	}
 
-	if logMessage != "" {
-		f.LogDebugf("checkPendingTransactionState: %s", logMessage)
-	}
+	f.RLock()
+	pendingTransactions := f.pendingTransactionsWithoutLocking()
+	f.RUnlock()
 
-	if clearPending {
-		f.clearPendingRequestsWithoutLocking()
+	if len(pendingTransactions) == 0 {
+		// no pending transaction so there is no need for additional checks
+		f.LogDebug("checkPendingTransactionState: no pending transaction found")
 		return
 	}
-	if readdPending {
-		f.readdPendingRequestsWithoutLocking()
+
+	// the pending transactions consume disjoint inputs, so they are resolved independently
+	for _, pendingTx := range pendingTransactions {
+		resolvePendingTransaction(pendingTx)
 	}
 }
 
 // ApplyAcceptedTransaction applies an accepted transaction to the faucet.
-// If there is a pending transaction, it is checked if the transaction was confirmed or conflicting.
-// If a conflict is found, all requests are readded to the queue.
+// Every pending transaction is checked if it was confirmed or conflicting.
+// If a conflict is found, all requests of the conflicting transaction are readded to the queue.
 func (f *Faucet) ApplyAcceptedTransaction(createdOutputs map[iotago.OutputID]struct{}, consumedOutputs map[iotago.OutputID]struct{}) {
 	f.LogDebug("entering ApplyAcceptedTransaction...")
 	defer f.LogDebug("leaving ApplyAcceptedTransaction...")
@@ -1035,37 +2191,43 @@ func (f *Faucet) ApplyAcceptedTransaction(createdOutputs map[iotago.OutputID]str
 	}
 
 	f.RLock()
-	pendingTx := f.pendingTransaction
+	pendingTransactions := f.pendingTransactionsWithoutLocking()
 	f.RUnlock()
 
-	clearPending, readdPending, logMessage := checkPendingTransaction(pendingTx)
-	if !(clearPending || readdPending) {
-		// no pending transaction or transaction is not affected by the update
-		if logMessage != "" {
-			f.LogDebugf("ApplyAcceptedTransaction: %s", logMessage)
-		}
-
+	if len(pendingTransactions) == 0 {
+		// no pending transaction so there is no need for additional checks
+		f.LogDebug("ApplyAcceptedTransaction: no pending transaction found")
 		return
 	}
 
-	// we need to acquire a write lock here and check again if there is a pending transaction.
-	f.Lock()
-	defer f.Unlock()
+	for _, pendingTx := range pendingTransactions {
+		clearPending, readdPending, logMessage := checkPendingTransaction(pendingTx)
+		if !(clearPending || readdPending) {
+			// transaction is not affected by the update
+			continue
+		}
 
-	if pendingTx != f.pendingTransaction {
-		// the pending transaction changed, check again
-		clearPending, readdPending, logMessage = checkPendingTransaction(f.pendingTransaction)
-	}
+		// we need to acquire a write lock here and check again if the transaction is still pending.
+		f.Lock()
 
-	if logMessage != "" {
-		f.LogDebugf("ApplyAcceptedTransaction: %s", logMessage)
-	}
+		if currentTx := f.pendingTransactions[pendingTx.TransactionID]; currentTx != pendingTx {
+			// the pending transaction was resolved or reattached in the meantime, check again
+			pendingTx = currentTx
+			clearPending, readdPending, logMessage = checkPendingTransaction(pendingTx)
+		}
 
-	if clearPending {
-		f.clearPendingRequestsWithoutLocking()
-		return
-	}
-	if readdPending {
-		f.readdPendingRequestsWithoutLocking()
+		if logMessage != "" {
+			f.LogDebugf("ApplyAcceptedTransaction: %s", logMessage)
+		}
+
+		if clearPending {
+			f.clearPendingRequestsWithoutLocking(pendingTx)
+		} else if readdPending {
+			conflictErr := newSoftError(SoftErrorCategoryConflict, ierrors.Errorf("%s, blockID: %s, txID: %s", logMessage, pendingTx.BlockID, pendingTx.TransactionID))
+			f.logSoftError(conflictErr)
+			f.readdPendingRequestsWithoutLocking(pendingTx, conflictErr)
+		}
+
+		f.Unlock()
 	}
 }
@@ -0,0 +1,425 @@
+//nolint:revive // we don't care about these linters in test cases
+package faucet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	faucet_test "github.com/iotaledger/inx-faucet/pkg/faucet/test"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+)
+
+const (
+	faucetAmount            iotago.BaseToken = 10_000_000 // 10 Mi
+	faucetSmallAmount       iotago.BaseToken = 1_000_000  //  1 Mi
+	faucetMaxAddressBalance iotago.BaseToken = 20_000_000 // 20 Mi
+)
+
+func TestSingleRequest(t *testing.T) {
+	// requests to a single address
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, wallet1Balance)
+
+	err := env.RequestFundsAndConfirm(env.Wallet1)
+	require.NoError(t, err)
+
+	faucetBalance -= faucetAmount
+	calculatedWallet1Balance := wallet1Balance + faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+
+	// small amount
+	for calculatedWallet1Balance < faucetMaxAddressBalance {
+		err = env.RequestFundsAndConfirm(env.Wallet1)
+		require.NoError(t, err)
+
+		faucetBalance -= faucetSmallAmount
+		calculatedWallet1Balance += faucetSmallAmount
+		env.AssertFaucetBalance(faucetBalance)
+		env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+	}
+
+	// max reached
+	err = env.RequestFundsAndConfirm(env.Wallet1)
+	require.Error(t, err)
+
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+}
+
+func TestMultipleRequests(t *testing.T) {
+	// requests to multiple addresses
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+	var wallet2Balance iotago.BaseToken                //  0  i
+	var wallet3Balance iotago.BaseToken = 5_000_000    //  5 Mi
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		wallet2Balance,
+		wallet3Balance,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	env.AssertFaucetBalance(faucetBalance)
+
+	// multiple target addresses in a single transaction
+	blockIDs, err := env.RequestFunds(env.Wallet1, env.Wallet2, env.Wallet3)
+	require.NoError(t, err)
+	require.Len(t, blockIDs, 1)
+	require.NoError(t, env.ConfirmPendingTransactions())
+
+	faucetBalance -= 3 * faucetAmount
+	calculatedWallet1Balance := wallet1Balance + faucetAmount
+	calculatedWallet2Balance := wallet2Balance + faucetAmount
+	calculatedWallet3Balance := wallet3Balance + faucetAmount
+	env.AssertAddressUTXOCount(env.Wallet1.Address, 1)
+	env.AssertAddressUTXOCount(env.Wallet2.Address, 1)
+	env.AssertAddressUTXOCount(env.Wallet3.Address, 2)
+	env.AssertAddressUTXOCount(env.FaucetAddress, 1)
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+	env.AssertLedgerBalance(env.Wallet2.Address, calculatedWallet2Balance)
+	env.AssertLedgerBalance(env.Wallet3.Address, calculatedWallet3Balance)
+
+	// small amount
+	for calculatedWallet1Balance < faucetMaxAddressBalance {
+		err = env.RequestFundsAndConfirm(env.Wallet1, env.Wallet2)
+		require.NoError(t, err)
+
+		faucetBalance -= 2 * faucetSmallAmount
+		calculatedWallet1Balance += faucetSmallAmount
+		calculatedWallet2Balance += faucetSmallAmount
+		env.AssertFaucetBalance(faucetBalance)
+		env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+		env.AssertLedgerBalance(env.Wallet2.Address, calculatedWallet2Balance)
+	}
+
+	// the first two wallets reached the max, the third one started with more funds and still receives the small amount
+	blockIDs, err = env.RequestFunds(env.Wallet1, env.Wallet2, env.Wallet3)
+	require.Error(t, err)
+	require.Len(t, blockIDs, 1)
+	require.NoError(t, env.ConfirmPendingTransactions())
+
+	faucetBalance -= faucetSmallAmount
+	calculatedWallet3Balance += faucetSmallAmount
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+	env.AssertLedgerBalance(env.Wallet2.Address, calculatedWallet2Balance)
+	env.AssertLedgerBalance(env.Wallet3.Address, calculatedWallet3Balance)
+}
+
+func TestDoubleSpent(t *testing.T) {
+	// reuse of the private key of the faucet (double spent)
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	env.AssertFaucetBalance(faucetBalance)
+
+	// create the faucet transaction that will be conflicting
+	_, err := env.RequestFunds(env.Wallet1)
+	require.NoError(t, err)
+
+	// a conflicting transaction that spends the outputs of the faucet gets accepted instead of the faucet transaction
+	env.SendConflictingTransaction(faucetAmount)
+
+	faucetBalance -= faucetAmount // we stole some funds from the faucet
+	env.AssertLedgerBalance(env.FaucetAddress, faucetBalance)
+	env.AssertLedgerBalance(env.OtherWallet.Address, faucetAmount)
+
+	// the faucet transaction fails because its inputs were spent already
+	require.NoError(t, env.ConfirmPendingTransactions())
+
+	submittedTransactions := env.Node.SubmittedTransactions()
+	require.Len(t, submittedTransactions, 1)
+	require.Equal(t, api.TransactionStateFailed, submittedTransactions[0].Metadata.TransactionState)
+	env.AssertLedgerBalance(env.Wallet1.Address, wallet1Balance)
+
+	// the request was readded to the queue and is paid out with the remaining outputs of the faucet
+	err = env.FlushRequestsAndConfirmNewFaucetBlock()
+	require.NoError(t, err)
+
+	faucetBalance -= faucetAmount // now the request is booked
+	calculatedWallet1Balance := wallet1Balance + faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.OtherWallet.Address, faucetAmount)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+}
+
+func TestSubmitRetryWithSpentInputs(t *testing.T) {
+	// the outputs of the faucet are spent while the faucet waits for the backoff of a failed submission
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance,
+		faucet.WithSubmitErrorClassifier(func(error) faucet.SubmitErrorClass { return faucet.SubmitErrorClassUnavailable }),
+		faucet.WithSubmitRetryPolicy(faucet.SubmitErrorClassUnavailable, &faucet.RetryPolicy{MaxRetries: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute}),
+	)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	retried := make(chan struct{}, 1)
+	defer env.Faucet.Events.SubmitRetried.Hook(func(faucet.SubmitErrorClass) {
+		select {
+		case retried <- struct{}{}:
+		default:
+		}
+	}).Unhook()
+
+	submitErrors := make(chan *faucet.SoftError, 1)
+	defer env.Faucet.Events.SoftError.Hook(func(softErr *faucet.SoftError) {
+		if softErr.Category == faucet.SoftErrorCategorySubmit {
+			select {
+			case submitErrors <- softErr:
+			default:
+			}
+		}
+	}).Unhook()
+
+	// the block issuer is unavailable, so the submission is retried after the backoff
+	env.Node.SetSubmitError(ierrors.New("block issuer unavailable"))
+
+	_, err := env.Faucet.Enqueue(&faucet.EnqueueRequest{Address: env.Wallet1.Address.Bech32(iotago.PrefixTestnet)}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, env.Faucet.FlushRequestsWithContext(ctx))
+
+	select {
+	case <-retried:
+	case <-ctx.Done():
+		require.FailNow(t, "faucet didn't retry the submission")
+	}
+
+	// a conflicting transaction spends the selected inputs during the backoff
+	env.SendConflictingTransaction(faucetAmount)
+	env.Node.SetSubmitError(nil)
+
+	faucetBalance -= faucetAmount // we stole some funds from the faucet
+
+	// the retry is aborted and the request is paid out with the new outputs of the faucet
+	_, err = env.WaitForIssuedBlock()
+	require.NoError(t, err)
+
+	select {
+	case softErr := <-submitErrors:
+		require.ErrorContains(t, softErr, "not available anymore")
+	default:
+		require.FailNow(t, "retry of the submission wasn't aborted")
+	}
+
+	require.NoError(t, env.ConfirmPendingTransactions())
+
+	submittedTransactions := env.Node.SubmittedTransactions()
+	require.Len(t, submittedTransactions, 1)
+	require.Equal(t, api.TransactionStateAccepted, submittedTransactions[0].Metadata.TransactionState)
+
+	faucetBalance -= faucetAmount // now the request is booked
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.OtherWallet.Address, faucetAmount)
+	env.AssertLedgerBalance(env.Wallet1.Address, wallet1Balance+faucetAmount)
+}
+
+func TestOrphanedBlock(t *testing.T) {
+	// the block of the faucet transaction is orphaned, the transaction is reattached in a new block
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	blockIDs, err := env.RequestFunds(env.Wallet1)
+	require.NoError(t, err)
+
+	env.OrphanPendingTransactions()
+
+	reattachedBlockID, err := env.WaitForIssuedBlock()
+	require.NoError(t, err)
+	require.NotEqual(t, blockIDs[0], reattachedBlockID)
+
+	// the transaction keeps its ID, so the request is not paid out twice
+	submittedTransactions := env.Node.SubmittedTransactions()
+	require.Len(t, submittedTransactions, 1)
+	require.Equal(t, []iotago.BlockID{reattachedBlockID}, submittedTransactions[0].BlockIDs)
+
+	require.NoError(t, env.ConfirmPendingTransactions())
+
+	faucetBalance -= faucetAmount
+	calculatedWallet1Balance := wallet1Balance + faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+}
+
+func TestOrphanedBlockWithoutReattachment(t *testing.T) {
+	// the block of the faucet transaction is orphaned and can't be reattached,
+	// so the request is paid out in a new transaction
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance,
+		faucet.WithMaxBlockReattachments(0))
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	_, err := env.RequestFunds(env.Wallet1)
+	require.NoError(t, err)
+
+	env.OrphanPendingTransactions()
+
+	_, err = env.WaitForIssuedBlock()
+	require.NoError(t, err)
+
+	require.Len(t, env.Node.SubmittedTransactions(), 2)
+	require.NoError(t, env.ConfirmPendingTransactions())
+
+	faucetBalance -= faucetAmount
+	calculatedWallet1Balance := wallet1Balance + faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+}
+
+func TestNotEnoughFaucetFunds(t *testing.T) {
+	// check if faucet returns an error if not enough funds available
+
+	var faucetBalance iotago.BaseToken = 29_000_000 // 29 Mi
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		0,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	env.AssertFaucetBalance(faucetBalance)
+
+	// 29 Mi - 10 Mi = 19 Mi
+	err := env.RequestFundsAndConfirm(env.Wallet1)
+	require.NoError(t, err)
+
+	faucetBalance -= faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+
+	// 19 Mi - 10 Mi = 9 Mi
+	err = env.RequestFundsAndConfirm(env.Wallet2)
+	require.NoError(t, err)
+
+	faucetBalance -= faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+
+	// 9 Mi - 10 Mi = error
+	err = env.RequestFundsAndConfirm(env.Wallet3)
+	require.Error(t, err)
+
+	env.AssertFaucetBalance(faucetBalance)
+}
+
+func TestCollectFaucetFunds(t *testing.T) {
+	// check if faucet collects funds if no requests left
+
+	var faucetBalance iotago.BaseToken = 1_000_000_000 //  1 Gi
+	var wallet1Balance iotago.BaseToken                //  0  i
+
+	env := faucet_test.NewFaucetTestEnv(t,
+		faucetBalance,
+		wallet1Balance,
+		0,
+		0,
+		faucetAmount,
+		faucetSmallAmount,
+		faucetMaxAddressBalance)
+	defer env.Cleanup()
+	require.NotNil(t, env)
+
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertAddressUTXOCount(env.FaucetAddress, 1)
+
+	err := env.RequestFundsAndConfirm(env.Wallet1)
+	require.NoError(t, err)
+
+	env.AssertAddressUTXOCount(env.FaucetAddress, 1)
+
+	faucetBalance -= faucetAmount
+	calculatedWallet1Balance := wallet1Balance + faucetAmount
+	env.AssertFaucetBalance(faucetBalance)
+	env.AssertLedgerBalance(env.Wallet1.Address, calculatedWallet1Balance)
+
+	env.AddFaucetFunds(faucetAmount)
+
+	faucetBalance += faucetAmount
+	env.AssertAddressUTXOCount(env.FaucetAddress, 2)
+
+	// flushing requests should collect all outputs
+	err = env.FlushRequestsAndConfirmNewFaucetBlock()
+	require.NoError(t, err)
+
+	env.AssertAddressUTXOCount(env.FaucetAddress, 1)
+	env.AssertFaucetBalance(faucetBalance)
+}
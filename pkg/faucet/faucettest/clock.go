@@ -0,0 +1,147 @@
+package faucettest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+)
+
+// Clock is a faucet.Clock that only moves forward if it is advanced explicitly.
+type Clock struct {
+	mutex sync.Mutex
+	now   time.Time
+	// the pending timers and tickers of the clock.
+	waiters []*clockWaiter
+}
+
+var _ faucet.Clock = &Clock{}
+
+// clockWaiter is a timer or a ticker of the clock.
+type clockWaiter struct {
+	deadline time.Time
+	// the interval of a ticker, 0 for a timer.
+	period  time.Duration
+	channel chan time.Time
+}
+
+// NewClock creates a new clock that starts at the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{
+		now:     now,
+		waiters: make([]*clockWaiter, 0),
+	}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	waiter := &clockWaiter{
+		deadline: c.now.Add(d),
+		channel:  make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		waiter.channel <- c.now
+
+		return waiter.channel
+	}
+	c.waiters = append(c.waiters, waiter)
+
+	return waiter.channel
+}
+
+func (c *Clock) NewTicker(d time.Duration) faucet.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for Clock.NewTicker")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	waiter := &clockWaiter{
+		deadline: c.now.Add(d),
+		period:   d,
+		channel:  make(chan time.Time, 1),
+	}
+	c.waiters = append(c.waiters, waiter)
+
+	return &clockTicker{clock: c, waiter: waiter}
+}
+
+// Advance moves the clock forward by the given duration and fires all timers and tickers that are due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(c.now) {
+			pending = append(pending, waiter)
+
+			continue
+		}
+
+		// like the tickers of the time package, ticks are dropped if the receiver is too slow
+		select {
+		case waiter.channel <- c.now:
+		default:
+		}
+
+		if waiter.period == 0 {
+			continue
+		}
+
+		for !waiter.deadline.After(c.now) {
+			waiter.deadline = waiter.deadline.Add(waiter.period)
+		}
+		pending = append(pending, waiter)
+	}
+	c.waiters = pending
+}
+
+// Waiters returns the amount of pending timers and tickers, so a test can wait until the faucet is blocked on the clock.
+func (c *Clock) Waiters() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.waiters)
+}
+
+// removeWaiter removes a stopped ticker from the clock.
+func (c *Clock) removeWaiter(waiter *clockWaiter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, w := range c.waiters {
+		if w == waiter {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// clockTicker is a ticker of the Clock.
+type clockTicker struct {
+	clock  *Clock
+	waiter *clockWaiter
+}
+
+func (t *clockTicker) C() <-chan time.Time {
+	return t.waiter.channel
+}
+
+func (t *clockTicker) Stop() {
+	t.clock.removeWaiter(t.waiter)
+}
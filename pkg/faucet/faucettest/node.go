@@ -0,0 +1,551 @@
+// Package faucettest provides an in-memory implementation of the node adapter and a manually advanced clock,
+// so the faucet can be run against a simulated ledger with deterministic behavior.
+package faucettest
+
+import (
+	"context"
+	"encoding/binary"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+	"github.com/iotaledger/iota.go/v4/builder"
+)
+
+var (
+	// ErrTransactionNotFound is returned if a transaction is not known to the node.
+	ErrTransactionNotFound = ierrors.New("transaction not found")
+)
+
+// Transaction is a transaction that was submitted to the node.
+type Transaction struct {
+	// SignedTransaction is the submitted transaction.
+	SignedTransaction *iotago.SignedTransaction
+	// BlockIDs are the blocks the transaction was attached to, the latest one is last.
+	BlockIDs []iotago.BlockID
+	// Metadata is the metadata the node returns for the transaction.
+	Metadata api.TransactionMetadataResponse
+}
+
+// Node is an in-memory implementation of the node adapter of the faucet.
+// the ledger only changes if outputs are added or transactions are accepted explicitly.
+type Node struct {
+	mutex sync.RWMutex
+
+	// the address of the faucet, it may be a restricted address.
+	faucetAddress iotago.Address
+	// the account the blocks are issued by.
+	blockIssuerAccountID iotago.AccountID
+
+	healthy             bool
+	latestSlot          iotago.SlotIndex
+	latestCommittedSlot iotago.SlotIndex
+	referenceManaCost   iotago.Mana
+
+	// the unspent outputs of the ledger.
+	outputs map[iotago.OutputID]iotago.Output
+	// the counter used to derive the IDs of the outputs that are added directly to the ledger.
+	outputCounter uint64
+	// the counter used to derive the IDs of the issued blocks.
+	blockCounter uint64
+
+	delegationRewards map[iotago.OutputID]iotago.Mana
+	transactions      map[iotago.TransactionID]*Transaction
+	// the transactions in the order they were submitted.
+	submittedTransactionIDs []iotago.TransactionID
+	// the error that is returned by the next submissions, nil to accept them.
+	submitError error
+}
+
+var _ faucet.NodeAdapter = &Node{}
+
+// NewNode creates a new healthy in-memory node with an empty ledger for the given faucet address.
+func NewNode(faucetAddress iotago.Address) *Node {
+	return &Node{
+		faucetAddress:     faucetAddress,
+		healthy:           true,
+		outputs:           make(map[iotago.OutputID]iotago.Output),
+		delegationRewards: make(map[iotago.OutputID]iotago.Mana),
+		transactions:      make(map[iotago.TransactionID]*Transaction),
+	}
+}
+
+// unrestrictedAddress returns the underlying address of a restricted address.
+func unrestrictedAddress(address iotago.Address) iotago.Address {
+	if restrictedAddress, ok := address.(*iotago.RestrictedAddress); ok {
+		return restrictedAddress.Address
+	}
+
+	return address
+}
+
+// ownerWithoutLocking returns the address that can unlock the output in the latest slot, or nil if it is timelocked.
+// read lock must be acquired outside.
+func (n *Node) ownerWithoutLocking(output iotago.Output) iotago.Address {
+	unlockConditions := output.UnlockConditionSet()
+	if unlockConditions.HasTimelockUntil(n.latestSlot) {
+		return nil
+	}
+
+	if expiration := unlockConditions.Expiration(); expiration != nil && n.latestSlot >= expiration.Slot {
+		return expiration.ReturnAddress
+	}
+
+	if addressUnlockCondition := unlockConditions.Address(); addressUnlockCondition != nil {
+		return addressUnlockCondition.Address
+	}
+
+	return nil
+}
+
+// sortedOutputIDsWithoutLocking returns the IDs of the unspent outputs in a deterministic order.
+// read lock must be acquired outside.
+func (n *Node) sortedOutputIDsWithoutLocking() []iotago.OutputID {
+	outputIDs := make([]iotago.OutputID, 0, len(n.outputs))
+	for outputID := range n.outputs {
+		outputIDs = append(outputIDs, outputID)
+	}
+	sort.Slice(outputIDs, func(i, j int) bool {
+		return outputIDs[i].Compare(outputIDs[j]) < 0
+	})
+
+	return outputIDs
+}
+
+// SetHealthy sets whether the node is synced.
+func (n *Node) SetHealthy(healthy bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.healthy = healthy
+}
+
+// SetLatestSlot sets the latest known slot in the network.
+func (n *Node) SetLatestSlot(slot iotago.SlotIndex) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.latestSlot = slot
+}
+
+// SetLatestCommittedSlot sets the slot of the latest commitment of the node.
+func (n *Node) SetLatestCommittedSlot(slot iotago.SlotIndex) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.latestCommittedSlot = slot
+}
+
+// SetReferenceManaCost sets the current reference mana cost of the network.
+func (n *Node) SetReferenceManaCost(referenceManaCost iotago.Mana) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.referenceManaCost = referenceManaCost
+}
+
+// SetBlockIssuerAccountID sets the account the blocks are issued by, the mana for the blocks is allotted to it.
+func (n *Node) SetBlockIssuerAccountID(accountID iotago.AccountID) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.blockIssuerAccountID = accountID
+}
+
+// SetSubmitError sets the error that is returned by the next submissions, nil to accept them again.
+func (n *Node) SetSubmitError(err error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.submitError = err
+}
+
+// AddOutput adds an unspent output to the ledger and returns its ID.
+// The output is created in the latest committed slot, so it can be spent right away.
+func (n *Node) AddOutput(output iotago.Output) iotago.OutputID {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.outputCounter++
+
+	var idBytes [8]byte
+	binary.LittleEndian.PutUint64(idBytes[:], n.outputCounter)
+	outputID := iotago.OutputIDFromTransactionIDAndIndex(iotago.NewTransactionID(n.latestCommittedSlot, iotago.IdentifierFromData(idBytes[:])), 0)
+
+	n.outputs[outputID] = output
+
+	return outputID
+}
+
+// RemoveOutput removes an unspent output from the ledger, e.g. to simulate a conflicting spend.
+func (n *Node) RemoveOutput(outputID iotago.OutputID) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	delete(n.outputs, outputID)
+}
+
+// Output returns the unspent output with the given ID.
+func (n *Node) Output(outputID iotago.OutputID) (iotago.Output, bool) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	output, exists := n.outputs[outputID]
+
+	return output, exists
+}
+
+// UnspentOutputs returns the IDs of the unspent outputs that can be unlocked by the given address in the latest slot.
+func (n *Node) UnspentOutputs(address iotago.Address) iotago.OutputIDs {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	outputIDs := make(iotago.OutputIDs, 0)
+	for _, outputID := range n.sortedOutputIDsWithoutLocking() {
+		if owner := n.ownerWithoutLocking(n.outputs[outputID]); owner != nil && owner.Equal(address) {
+			outputIDs = append(outputIDs, outputID)
+		}
+	}
+
+	return outputIDs
+}
+
+// SetDelegationRewards sets the mana rewards of a delegation output.
+func (n *Node) SetDelegationRewards(outputID iotago.OutputID, rewards iotago.Mana) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.delegationRewards[outputID] = rewards
+}
+
+// SubmittedTransactions returns a copy of the submitted transactions in the order they were submitted.
+func (n *Node) SubmittedTransactions() []*Transaction {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	transactions := make([]*Transaction, 0, len(n.submittedTransactionIDs))
+	for _, transactionID := range n.submittedTransactionIDs {
+		transaction, exists := n.transactions[transactionID]
+		if !exists {
+			continue
+		}
+
+		// the transactions are copied, so they don't change while they are used
+		transactionCopy := *transaction
+		transactionCopy.BlockIDs = slices.Clone(transaction.BlockIDs)
+		transactions = append(transactions, &transactionCopy)
+	}
+
+	return transactions
+}
+
+// AcceptTransaction applies the transaction to the ledger and marks it as accepted.
+// the transaction fails if one of its inputs was already spent.
+func (n *Node) AcceptTransaction(transactionID iotago.TransactionID) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	transaction, exists := n.transactions[transactionID]
+	if !exists {
+		return ErrTransactionNotFound
+	}
+
+	if transaction.Metadata.TransactionState != api.TransactionStatePending {
+		return nil
+	}
+
+	inputs := transaction.SignedTransaction.Transaction.Inputs()
+	for _, input := range inputs {
+		if _, exists := n.outputs[input.OutputID()]; !exists {
+			transaction.Metadata.TransactionState = api.TransactionStateFailed
+			transaction.Metadata.TransactionFailureReason = api.TxFailureInputAlreadySpent
+
+			return nil
+		}
+	}
+
+	for _, input := range inputs {
+		delete(n.outputs, input.OutputID())
+	}
+
+	for index, output := range transaction.SignedTransaction.Transaction.Outputs {
+		n.outputs[iotago.OutputIDFromTransactionIDAndIndex(transactionID, uint16(index))] = output
+	}
+
+	transaction.Metadata.TransactionState = api.TransactionStateAccepted
+
+	return nil
+}
+
+// AcceptPendingTransactions accepts all pending transactions in the order they were submitted.
+func (n *Node) AcceptPendingTransactions() error {
+	n.mutex.RLock()
+	transactionIDs := append([]iotago.TransactionID{}, n.submittedTransactionIDs...)
+	n.mutex.RUnlock()
+
+	for _, transactionID := range transactionIDs {
+		if err := n.AcceptTransaction(transactionID); err != nil && !ierrors.Is(err, ErrTransactionNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetTransactionState sets the state of an accepted transaction, e.g. to committed or finalized.
+// the ledger is not changed, use AcceptTransaction to apply a pending transaction first.
+func (n *Node) SetTransactionState(transactionID iotago.TransactionID, state api.TransactionState) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	transaction, exists := n.transactions[transactionID]
+	if !exists {
+		return ErrTransactionNotFound
+	}
+
+	transaction.Metadata.TransactionState = state
+
+	return nil
+}
+
+// FailTransaction marks a pending transaction as failed with the given reason.
+func (n *Node) FailTransaction(transactionID iotago.TransactionID, reason api.TransactionFailureReason) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	transaction, exists := n.transactions[transactionID]
+	if !exists {
+		return ErrTransactionNotFound
+	}
+
+	transaction.Metadata.TransactionState = api.TransactionStateFailed
+	transaction.Metadata.TransactionFailureReason = reason
+
+	return nil
+}
+
+// EvictTransaction removes a transaction from the node, so its metadata is not found anymore.
+func (n *Node) EvictTransaction(transactionID iotago.TransactionID) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	delete(n.transactions, transactionID)
+}
+
+// nextBlockIDWithoutLocking returns the ID of the next issued block.
+// write lock must be acquired outside.
+func (n *Node) nextBlockIDWithoutLocking() iotago.BlockID {
+	n.blockCounter++
+
+	var idBytes [8]byte
+	binary.LittleEndian.PutUint64(idBytes[:], n.blockCounter)
+
+	return iotago.NewBlockID(n.latestSlot, iotago.IdentifierFromData(idBytes[:]))
+}
+
+func (n *Node) IsNodeHealthy() bool {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return n.healthy
+}
+
+func (n *Node) FetchTransactionMetadata(transactionID iotago.TransactionID) (*api.TransactionMetadataResponse, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	transaction, exists := n.transactions[transactionID]
+	if !exists {
+		//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
+		return nil, nil
+	}
+
+	metadata := transaction.Metadata
+
+	return &metadata, nil
+}
+
+func (n *Node) CollectUnlockableFaucetOutputs() ([]faucet.UTXOBasicOutput, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	faucetOutputs := make([]faucet.UTXOBasicOutput, 0)
+	for _, outputID := range n.sortedOutputIDsWithoutLocking() {
+		basicOutput, ok := n.outputs[outputID].(*iotago.BasicOutput)
+		if !ok {
+			continue
+		}
+
+		// only simple outputs are unlockable by the faucet, like the restricted address of the faucet returns them
+		if len(basicOutput.UnlockConditions) != 1 || basicOutput.UnlockConditionSet().Address() == nil {
+			continue
+		}
+
+		if !basicOutput.UnlockConditionSet().Address().Address.Equal(n.faucetAddress) {
+			continue
+		}
+
+		faucetOutputs = append(faucetOutputs, faucet.UTXOBasicOutput{
+			OutputID: outputID,
+			Output:   basicOutput,
+		})
+	}
+
+	return faucetOutputs, nil
+}
+
+func (n *Node) ComputeUnlockableAddressBalance(address iotago.Address) (iotago.BaseToken, iotago.Mana, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	var unlockableBalance iotago.BaseToken
+	var unlockableMana iotago.Mana
+	for _, output := range n.outputs {
+		owner := n.ownerWithoutLocking(output)
+		if owner == nil || !owner.Equal(address) {
+			continue
+		}
+
+		unlockableBalance += output.BaseTokenAmount()
+		unlockableMana += output.StoredMana()
+	}
+
+	return unlockableBalance, unlockableMana, nil
+}
+
+func (n *Node) LatestSlot() iotago.SlotIndex {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return n.latestSlot
+}
+
+func (n *Node) ReferenceManaCost() (iotago.Mana, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return n.referenceManaCost, nil
+}
+
+func (n *Node) LatestCommittedSlot() iotago.SlotIndex {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return n.latestCommittedSlot
+}
+
+func (n *Node) CollectFaucetDelegationOutputs() ([]faucet.UTXODelegationOutput, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	// delegation outputs are owned by the faucet address without restrictions.
+	faucetAddress := unrestrictedAddress(n.faucetAddress)
+
+	delegationOutputs := make([]faucet.UTXODelegationOutput, 0)
+	for _, outputID := range n.sortedOutputIDsWithoutLocking() {
+		delegationOutput, ok := n.outputs[outputID].(*iotago.DelegationOutput)
+		if !ok {
+			continue
+		}
+
+		if addressUnlockCondition := delegationOutput.UnlockConditionSet().Address(); addressUnlockCondition == nil || !addressUnlockCondition.Address.Equal(faucetAddress) {
+			continue
+		}
+
+		delegationOutputs = append(delegationOutputs, faucet.UTXODelegationOutput{
+			OutputID: outputID,
+			Output:   delegationOutput,
+		})
+	}
+
+	return delegationOutputs, nil
+}
+
+func (n *Node) DelegationRewards(outputID iotago.OutputID) (iotago.Mana, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return n.delegationRewards[outputID], nil
+}
+
+func (n *Node) SubmitTransactionPayload(_ context.Context, builder *builder.TransactionBuilder, storedManaOutputIndex int, _ ...int) (iotago.ApplicationPayload, iotago.BlockID, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.submitError != nil {
+		return nil, iotago.EmptyBlockID, n.submitError
+	}
+
+	// set the commitment slot as the creation slot of the transaction if no slot was set yet.
+	if builder.CreationSlot() == 0 {
+		builder.SetCreationSlot(n.latestCommittedSlot)
+	}
+
+	// allot the required mana to the block issuer and sign the transaction
+	builder.AllotMinRequiredManaAndStoreRemainingManaInOutput(builder.CreationSlot(), n.referenceManaCost, n.blockIssuerAccountID, storedManaOutputIndex)
+
+	signedTx, err := builder.Build()
+	if err != nil {
+		return nil, iotago.EmptyBlockID, ierrors.Wrap(err, "failed to build the signed transaction payload")
+	}
+
+	transactionID, err := signedTx.Transaction.ID()
+	if err != nil {
+		return nil, iotago.EmptyBlockID, err
+	}
+
+	blockID := n.nextBlockIDWithoutLocking()
+	n.transactions[transactionID] = &Transaction{
+		SignedTransaction: signedTx,
+		BlockIDs:          []iotago.BlockID{blockID},
+		Metadata: api.TransactionMetadataResponse{
+			TransactionID:          transactionID,
+			TransactionState:       api.TransactionStatePending,
+			EarliestAttachmentSlot: n.latestSlot,
+		},
+	}
+	n.submittedTransactionIDs = append(n.submittedTransactionIDs, transactionID)
+
+	return signedTx, blockID, nil
+}
+
+func (n *Node) ReissueTransactionPayload(_ context.Context, signedTx *iotago.SignedTransaction, _ ...int) (iotago.BlockID, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.submitError != nil {
+		return iotago.EmptyBlockID, n.submitError
+	}
+
+	transactionID, err := signedTx.Transaction.ID()
+	if err != nil {
+		return iotago.EmptyBlockID, err
+	}
+
+	blockID := n.nextBlockIDWithoutLocking()
+
+	transaction, exists := n.transactions[transactionID]
+	if !exists {
+		// the transaction was evicted, it is attached again
+		transaction = &Transaction{
+			SignedTransaction: signedTx,
+			Metadata: api.TransactionMetadataResponse{
+				TransactionID:          transactionID,
+				TransactionState:       api.TransactionStatePending,
+				EarliestAttachmentSlot: n.latestSlot,
+			},
+		}
+		n.transactions[transactionID] = transaction
+
+		if !slices.Contains(n.submittedTransactionIDs, transactionID) {
+			n.submittedTransactionIDs = append(n.submittedTransactionIDs, transactionID)
+		}
+	}
+	transaction.BlockIDs = append(transaction.BlockIDs, blockID)
+
+	return blockID, nil
+}
@@ -0,0 +1,279 @@
+package faucet
+
+import (
+	"sort"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// InputSelectionStrategy defines which unspent outputs of the faucet are consumed in a faucet transaction.
+type InputSelectionStrategy string
+
+const (
+	// InputSelectionAll consumes all unspent outputs of the faucet.
+	InputSelectionAll InputSelectionStrategy = "all"
+	// InputSelectionLargestFirst consumes the outputs with the most base tokens first until the requests are covered.
+	InputSelectionLargestFirst InputSelectionStrategy = "largest-first"
+	// InputSelectionOldestFirst consumes the oldest outputs first until the requests are covered.
+	InputSelectionOldestFirst InputSelectionStrategy = "oldest-first"
+	// InputSelectionBranchAndBound searches the combination of outputs that covers the requests with the least excess.
+	InputSelectionBranchAndBound InputSelectionStrategy = "branch-and-bound"
+)
+
+const (
+	// the maximum amount of combinations that are evaluated by the branch-and-bound strategy.
+	branchAndBoundMaxTries = 100000
+)
+
+// ParseInputSelectionStrategy parses the name of an input selection strategy.
+func ParseInputSelectionStrategy(name string) (InputSelectionStrategy, error) {
+	switch strategy := InputSelectionStrategy(name); strategy {
+	case InputSelectionAll, InputSelectionLargestFirst, InputSelectionOldestFirst, InputSelectionBranchAndBound:
+		return strategy, nil
+	default:
+		return "", ierrors.Errorf("unknown input selection strategy \"%s\", expected \"%s\", \"%s\", \"%s\" or \"%s\"", name, InputSelectionAll, InputSelectionLargestFirst, InputSelectionOldestFirst, InputSelectionBranchAndBound)
+	}
+}
+
+// WithInputSelection defines which unspent outputs are consumed in a faucet transaction
+// and the maximum amount of inputs per transaction (0 to use the protocol limit).
+func WithInputSelection(strategy InputSelectionStrategy, maxInputs int) Option {
+	return func(opts *Options) {
+		opts.inputSelectionStrategy = strategy
+		opts.maxInputsPerTransaction = maxInputs
+	}
+}
+
+// maxInputs returns the maximum amount of inputs per faucet transaction.
+func (f *Faucet) maxInputs() int {
+	if f.opts.maxInputsPerTransaction <= 0 || f.opts.maxInputsPerTransaction > iotago.MaxInputsCount {
+		return iotago.MaxInputsCount
+	}
+
+	return f.opts.maxInputsPerTransaction
+}
+
+// sortedInputCandidates returns a copy of the outputs in the order they are selected by the strategy.
+func (f *Faucet) sortedInputCandidates(unspentOutputs []UTXOBasicOutput) []UTXOBasicOutput {
+	candidates := make([]UTXOBasicOutput, len(unspentOutputs))
+	copy(candidates, unspentOutputs)
+
+	switch f.opts.inputSelectionStrategy {
+	case InputSelectionLargestFirst, InputSelectionBranchAndBound:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Output.Amount > candidates[j].Output.Amount
+		})
+	case InputSelectionOldestFirst:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].OutputID.CreationSlot() < candidates[j].OutputID.CreationSlot()
+		})
+	}
+
+	return candidates
+}
+
+// selectInputs selects the unspent outputs that are consumed in the next faucet transaction.
+// It returns the selected outputs and their balance minus the storage deposit of the remainder output.
+func (f *Faucet) selectInputs(unspentOutputs []UTXOBasicOutput, batchedRequests []*queueItem) ([]UTXOBasicOutput, iotago.BaseToken, error) {
+	minStorageDeposit, err := f.apiProvider.CommittedAPI().StorageScoreStructure().MinDeposit(EmptyBasicOutput)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	candidates := f.sortedInputCandidates(unspentOutputs)
+	maxInputs := f.maxInputs()
+
+	// the outputs of retired addresses are always consumed first, so their funds are swept to the faucet address
+	retiredOutputs, candidates := f.partitionRetiredOutputs(candidates)
+	selectedOutputs := retiredOutputs[:min(len(retiredOutputs), maxInputs)]
+	maxRemainingInputs := maxInputs - len(selectedOutputs)
+
+	switch {
+	case f.opts.inputSelectionStrategy == InputSelectionAll, len(batchedRequests) == 0:
+		// without requests the outputs are only swept, so as many outputs as possible are consolidated
+		selectedOutputs = append(selectedOutputs, candidates[:min(len(candidates), maxRemainingInputs)]...)
+
+	default:
+		targetBaseTokens := minStorageDeposit
+		for _, request := range batchedRequests {
+			targetBaseTokens += request.BaseTokenAmount
+		}
+		for _, output := range selectedOutputs {
+			targetBaseTokens -= min(targetBaseTokens, output.Output.Amount)
+		}
+
+		targetMana, err := f.requiredManaPayouts(batchedRequests)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var addedOutputs []UTXOBasicOutput
+		if f.opts.inputSelectionStrategy == InputSelectionBranchAndBound {
+			addedOutputs = selectInputsBranchAndBound(candidates, targetBaseTokens, maxRemainingInputs)
+		}
+		if addedOutputs == nil {
+			addedOutputs = selectInputsInOrder(candidates, targetBaseTokens, maxRemainingInputs)
+		}
+
+		selectedOutputs = addInputsForMana(append(selectedOutputs, addedOutputs...), candidates, targetMana, maxInputs)
+	}
+
+	var balance iotago.BaseToken
+	for _, output := range selectedOutputs {
+		balance += output.Output.Amount
+	}
+
+	// subtract the storage deposit for a simple basic output, so we can simplify our logic for remainder handling
+	if balance >= minStorageDeposit {
+		balance -= minStorageDeposit
+	} else {
+		balance = 0
+	}
+
+	if len(selectedOutputs) < len(unspentOutputs) {
+		f.LogDebugf("selected %d of %d unspent outputs with strategy %s", len(selectedOutputs), len(unspentOutputs), f.opts.inputSelectionStrategy)
+	}
+
+	return selectedOutputs, balance, nil
+}
+
+// selectInputsInOrder selects the candidates in the given order until the target amount is covered.
+func selectInputsInOrder(candidates []UTXOBasicOutput, targetBaseTokens iotago.BaseToken, maxInputs int) []UTXOBasicOutput {
+	var selectedOutputs []UTXOBasicOutput
+	var selectedBaseTokens iotago.BaseToken
+	for _, candidate := range candidates {
+		if selectedBaseTokens >= targetBaseTokens || len(selectedOutputs) >= maxInputs {
+			break
+		}
+
+		selectedOutputs = append(selectedOutputs, candidate)
+		selectedBaseTokens += candidate.Output.Amount
+	}
+
+	return selectedOutputs
+}
+
+// selectInputsBranchAndBound searches the combination of candidates that covers the target amount with the least excess.
+// the candidates must be sorted by their amount in descending order.
+// It returns nil if no combination was found.
+func selectInputsBranchAndBound(candidates []UTXOBasicOutput, targetBaseTokens iotago.BaseToken, maxInputs int) []UTXOBasicOutput {
+	// the remaining amounts are used to cut branches that can't reach the target anymore
+	remainingBaseTokens := make([]iotago.BaseToken, len(candidates)+1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		remainingBaseTokens[i] = remainingBaseTokens[i+1] + candidates[i].Output.Amount
+	}
+
+	var bestSelection []int
+	var bestExcess iotago.BaseToken
+	var tries int
+
+	selection := make([]int, 0, maxInputs)
+	var search func(index int, selectedBaseTokens iotago.BaseToken)
+	search = func(index int, selectedBaseTokens iotago.BaseToken) {
+		tries++
+		if tries > branchAndBoundMaxTries {
+			return
+		}
+
+		if selectedBaseTokens >= targetBaseTokens {
+			if excess := selectedBaseTokens - targetBaseTokens; bestSelection == nil || excess < bestExcess {
+				bestSelection = append(bestSelection[:0], selection...)
+				bestExcess = excess
+			}
+
+			return
+		}
+
+		if index >= len(candidates) || len(selection) >= maxInputs || selectedBaseTokens+remainingBaseTokens[index] < targetBaseTokens {
+			return
+		}
+
+		if bestSelection != nil && bestExcess == 0 {
+			// there is no better combination than an exact match
+			return
+		}
+
+		// include the candidate
+		selection = append(selection, index)
+		search(index+1, selectedBaseTokens+candidates[index].Output.Amount)
+		selection = selection[:len(selection)-1]
+
+		// exclude the candidate
+		search(index+1, selectedBaseTokens)
+	}
+	search(0, 0)
+
+	if bestSelection == nil {
+		return nil
+	}
+
+	selectedOutputs := make([]UTXOBasicOutput, 0, len(bestSelection))
+	for _, index := range bestSelection {
+		selectedOutputs = append(selectedOutputs, candidates[index])
+	}
+
+	return selectedOutputs
+}
+
+// addInputsForMana adds the candidates with the most stored mana to the selection until the target mana is covered.
+func addInputsForMana(selectedOutputs []UTXOBasicOutput, candidates []UTXOBasicOutput, targetMana iotago.Mana, maxInputs int) []UTXOBasicOutput {
+	selectedOutputIDs := make(map[iotago.OutputID]struct{}, len(selectedOutputs))
+	var selectedMana iotago.Mana
+	for _, output := range selectedOutputs {
+		selectedOutputIDs[output.OutputID] = struct{}{}
+		selectedMana += output.Output.Mana
+	}
+
+	if selectedMana >= targetMana {
+		return selectedOutputs
+	}
+
+	manaCandidates := make([]UTXOBasicOutput, 0, len(candidates))
+	for _, candidate := range candidates {
+		if _, selected := selectedOutputIDs[candidate.OutputID]; !selected {
+			manaCandidates = append(manaCandidates, candidate)
+		}
+	}
+	sort.SliceStable(manaCandidates, func(i, j int) bool {
+		return manaCandidates[i].Output.Mana > manaCandidates[j].Output.Mana
+	})
+
+	for _, candidate := range manaCandidates {
+		if selectedMana >= targetMana || len(selectedOutputs) >= maxInputs {
+			break
+		}
+
+		selectedOutputs = append(selectedOutputs, candidate)
+		selectedMana += candidate.Output.Mana
+	}
+
+	return selectedOutputs
+}
+
+// planSweep checks if the unspent outputs of the faucet fit into a single transaction.
+// If they don't fit and all outputs should be consumed, it returns the outputs of the next sweep transaction,
+// which consolidates the smallest outputs. The payouts are resumed after all outputs fit into one transaction.
+func (f *Faucet) planSweep(unspentOutputs []UTXOBasicOutput) []UTXOBasicOutput {
+	maxInputs := f.maxInputs()
+	if f.opts.inputSelectionStrategy != InputSelectionAll || len(unspentOutputs) <= maxInputs || maxInputs < 2 {
+		// the other strategies only consume the outputs needed for the requests
+		return nil
+	}
+
+	candidates := make([]UTXOBasicOutput, len(unspentOutputs))
+	copy(candidates, unspentOutputs)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Output.Amount < candidates[j].Output.Amount
+	})
+
+	// the outputs of retired addresses are consolidated first
+	retiredOutputs, otherOutputs := f.partitionRetiredOutputs(candidates)
+	candidates = append(retiredOutputs, otherOutputs...)
+
+	// every sweep transaction turns its inputs into a single remainder output
+	sweepsNeeded := (len(unspentOutputs) - 2) / (maxInputs - 1)
+	f.LogInfof("faucet holds %d unspent outputs, but only %d fit into one transaction, issuing sweep transaction (%d remaining) before resuming payouts", len(unspentOutputs), maxInputs, sweepsNeeded)
+
+	return candidates[:maxInputs]
+}
@@ -0,0 +1,86 @@
+package faucet
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// INXConnectionState defines the state of the INX streams the faucet listens to.
+type INXConnectionState struct {
+	// Whether all INX streams are connected.
+	Connected bool `json:"connected"`
+	// The time since when at least one INX stream is reconnecting, omitted if all streams are connected.
+	ReconnectingSince *time.Time `json:"reconnectingSince,omitempty"`
+	// The names of the INX streams that are reconnecting.
+	ReconnectingStreams []string `json:"reconnectingStreams,omitempty"`
+	// The total amount of reconnects of the INX streams.
+	Reconnects uint64 `json:"reconnects"`
+}
+
+// inxConnection tracks the connection state of the INX streams.
+type inxConnection struct {
+	mutex sync.RWMutex
+	// the time since when the streams are disconnected per stream name.
+	disconnectedSince map[string]time.Time
+	// the total amount of reconnects.
+	reconnects uint64
+}
+
+func newINXConnection() *inxConnection {
+	return &inxConnection{
+		disconnectedSince: make(map[string]time.Time),
+	}
+}
+
+// SetINXStreamConnected marks the INX stream with the given name as connected.
+func (f *Faucet) SetINXStreamConnected(name string) {
+	f.inxConnection.mutex.Lock()
+	defer f.inxConnection.mutex.Unlock()
+
+	disconnectedSince, wasDisconnected := f.inxConnection.disconnectedSince[name]
+	if !wasDisconnected {
+		return
+	}
+
+	delete(f.inxConnection.disconnectedSince, name)
+	f.inxConnection.reconnects++
+
+	f.LogInfof("INX stream %s reconnected after %s", name, f.opts.clock.Now().Sub(disconnectedSince).Truncate(time.Second))
+}
+
+// SetINXStreamDisconnected marks the INX stream with the given name as disconnected.
+func (f *Faucet) SetINXStreamDisconnected(name string) {
+	f.inxConnection.mutex.Lock()
+	defer f.inxConnection.mutex.Unlock()
+
+	if _, alreadyDisconnected := f.inxConnection.disconnectedSince[name]; alreadyDisconnected {
+		return
+	}
+
+	f.inxConnection.disconnectedSince[name] = f.opts.clock.Now()
+}
+
+// INXConnectionState returns the connection state of the INX streams.
+func (f *Faucet) INXConnectionState() *INXConnectionState {
+	f.inxConnection.mutex.RLock()
+	defer f.inxConnection.mutex.RUnlock()
+
+	state := &INXConnectionState{
+		Connected:           len(f.inxConnection.disconnectedSince) == 0,
+		ReconnectingStreams: make([]string, 0, len(f.inxConnection.disconnectedSince)),
+		Reconnects:          f.inxConnection.reconnects,
+	}
+
+	for name, disconnectedSince := range f.inxConnection.disconnectedSince {
+		state.ReconnectingStreams = append(state.ReconnectingStreams, name)
+
+		if state.ReconnectingSince == nil || disconnectedSince.Before(*state.ReconnectingSince) {
+			since := disconnectedSince
+			state.ReconnectingSince = &since
+		}
+	}
+	sort.Strings(state.ReconnectingStreams)
+
+	return state
+}
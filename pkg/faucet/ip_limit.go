@@ -0,0 +1,71 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// ErrTooManyPendingRequestsPerIP is returned by a SharedQueue if the originating IP address of a request already has too many unconfirmed requests.
+var ErrTooManyPendingRequestsPerIP = ierrors.New("too many unconfirmed requests of the IP address")
+
+// WithMaxPendingRequestsPerIP defines the maximum amount of unconfirmed requests per originating IP address.
+// A value of 0 disables the limit. If a shared queue is used, the limit is enforced by the shared queue.
+func WithMaxPendingRequestsPerIP(maxPendingRequestsPerIP int) Option {
+	return func(opts *Options) {
+		opts.maxPendingRequestsPerIP = maxPendingRequestsPerIP
+	}
+}
+
+// checkPendingRequestsPerIPWithoutLocking checks if the originating IP address of a request already has too many unconfirmed requests.
+// read lock must be acquired outside.
+func (f *Faucet) checkPendingRequestsPerIPWithoutLocking(remoteIP string) error {
+	if f.opts.maxPendingRequestsPerIP <= 0 || remoteIP == "" {
+		return nil
+	}
+
+	if pendingRequests := f.pendingRequestsPerIP[remoteIP]; pendingRequests >= f.opts.maxPendingRequestsPerIP {
+		return f.tooManyPendingRequestsError(pendingRequests)
+	}
+
+	return nil
+}
+
+// tooManyPendingRequestsError returns the error for a request of an IP address that already has too many unconfirmed requests.
+func (f *Faucet) tooManyPendingRequestsError(pendingRequests int) error {
+	return NewRequestError(ErrorCodeRateLimited, http.StatusTooManyRequests, fmt.Sprintf("Too many unconfirmed requests from your IP address (%d). Please wait until they are processed!", pendingRequests)).
+		WithRetryAfter(f.opts.batchTimeout).
+		WithDetail("maxPendingRequests", f.opts.maxPendingRequestsPerIP)
+}
+
+// trackPendingRequestWithoutLocking counts the given request as unconfirmed request of its originating IP address.
+// write lock must be acquired outside.
+func (f *Faucet) trackPendingRequestWithoutLocking(request *queueItem) {
+	if request.RemoteIP == "" {
+		return
+	}
+
+	f.pendingRequestsPerIP[request.RemoteIP]++
+}
+
+// untrackPendingRequestWithoutLocking removes the given request from the unconfirmed requests of its originating IP address.
+// write lock must be acquired outside.
+func (f *Faucet) untrackPendingRequestWithoutLocking(request *queueItem) {
+	if request.RemoteIP == "" {
+		return
+	}
+
+	pendingRequests, exists := f.pendingRequestsPerIP[request.RemoteIP]
+	if !exists {
+		return
+	}
+
+	if pendingRequests <= 1 {
+		delete(f.pendingRequestsPerIP, request.RemoteIP)
+
+		return
+	}
+
+	f.pendingRequestsPerIP[request.RemoteIP] = pendingRequests - 1
+}
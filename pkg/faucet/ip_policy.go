@@ -0,0 +1,120 @@
+package faucet
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// IPPolicyAction is the action that is applied to the requests from a network that matches a rule of an IPPolicy.
+type IPPolicyAction string
+
+const (
+	// IPPolicyActionDowngrade means that only the small amount is paid out.
+	IPPolicyActionDowngrade IPPolicyAction = "downgrade"
+	// IPPolicyActionChallenge means that the request must include a solved proof-of-work challenge.
+	IPPolicyActionChallenge IPPolicyAction = "challenge"
+	// IPPolicyActionBlock means that the request is rejected.
+	IPPolicyActionBlock IPPolicyAction = "block"
+)
+
+// ParseIPPolicyAction parses the given action.
+func ParseIPPolicyAction(action string) (IPPolicyAction, error) {
+	switch IPPolicyAction(strings.ToLower(action)) {
+	case IPPolicyActionDowngrade:
+		return IPPolicyActionDowngrade, nil
+	case IPPolicyActionChallenge:
+		return IPPolicyActionChallenge, nil
+	case IPPolicyActionBlock:
+		return IPPolicyActionBlock, nil
+	default:
+		return "", ierrors.Errorf("unknown IP policy action: %s", action)
+	}
+}
+
+// severity returns the precedence of the action if several rules match.
+func (a IPPolicyAction) severity() int {
+	switch a {
+	case IPPolicyActionDowngrade:
+		return 1
+	case IPPolicyActionChallenge:
+		return 2
+	case IPPolicyActionBlock:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// IPClassifier returns the labels of the network an IP address belongs to, e.g. its country or autonomous system.
+type IPClassifier interface {
+	Classify(ip net.IP) []string
+}
+
+// IPClassifierFunc is a function that implements the IPClassifier interface.
+type IPClassifierFunc func(ip net.IP) []string
+
+// Classify calls the function itself.
+func (f IPClassifierFunc) Classify(ip net.IP) []string {
+	return f(ip)
+}
+
+// IPPolicy is a RequestValidator that applies actions to the requests of clients whose IP address is classified with a label that has a rule.
+// If several rules match, the most restrictive action is applied.
+type IPPolicy struct {
+	classifier           IPClassifier
+	rules                map[string]IPPolicyAction
+	baseTokenAmountSmall iotago.BaseToken
+	challenger           *PoWChallenger
+}
+
+var _ RequestValidator = &IPPolicy{}
+
+// NewIPPolicy creates a new IPPolicy with the given rules per label.
+// The challenger may be nil if all requests already require a solved proof-of-work challenge.
+func NewIPPolicy(classifier IPClassifier, rules map[string]IPPolicyAction, baseTokenAmountSmall iotago.BaseToken, challenger *PoWChallenger) *IPPolicy {
+	return &IPPolicy{
+		classifier:           classifier,
+		rules:                rules,
+		baseTokenAmountSmall: baseTokenAmountSmall,
+		challenger:           challenger,
+	}
+}
+
+// ValidateRequest applies the most restrictive action of the rules that match the IP address of the client.
+func (p *IPPolicy) ValidateRequest(request *ValidationRequest) (iotago.BaseToken, error) {
+	if request.Client == nil {
+		return request.BaseTokenAmount, nil
+	}
+
+	ip := net.ParseIP(request.Client.RemoteIP)
+	if ip == nil {
+		// the request was not issued from an IP address, e.g. by a CI workflow
+		return request.BaseTokenAmount, nil
+	}
+
+	var action IPPolicyAction
+	for _, label := range p.classifier.Classify(ip) {
+		if ruleAction, exists := p.rules[label]; exists && ruleAction.severity() > action.severity() {
+			action = ruleAction
+		}
+	}
+
+	switch action {
+	case IPPolicyActionBlock:
+		return 0, NewRequestError(ErrorCodeForbidden, http.StatusForbidden, "Requests from your network are not allowed.")
+	case IPPolicyActionChallenge:
+		if p.challenger == nil {
+			return request.BaseTokenAmount, nil
+		}
+
+		return p.challenger.ValidateRequest(request)
+	case IPPolicyActionDowngrade:
+		return min(request.BaseTokenAmount, p.baseTokenAmountSmall), nil
+	default:
+		return request.BaseTokenAmount, nil
+	}
+}
@@ -0,0 +1,77 @@
+package faucet
+
+import (
+	"sync"
+	"time"
+)
+
+// issuanceRateLimiter limits the amount of transactions the faucet issues per minute,
+// so the faucet can't exhaust the mana of the block issuer.
+type issuanceRateLimiter struct {
+	mutex sync.Mutex
+	// the maximum amount of transactions per minute, 0 disables the limit.
+	maxPerMinute int
+	// the times of the issued transactions within the last minute, oldest first.
+	issued []time.Time
+	clock  Clock
+}
+
+func newIssuanceRateLimiter(maxPerMinute int, clock Clock) *issuanceRateLimiter {
+	return &issuanceRateLimiter{
+		maxPerMinute: maxPerMinute,
+		issued:       make([]time.Time, 0),
+		clock:        clock,
+	}
+}
+
+// WithMaxTransactionsPerMinute defines the maximum amount of transactions the faucet issues per minute.
+// A value of 0 disables the limit.
+func WithMaxTransactionsPerMinute(maxTransactionsPerMinute int) Option {
+	return func(opts *Options) {
+		opts.maxTransactionsPerMinute = maxTransactionsPerMinute
+	}
+}
+
+// pruneWithoutLocking removes the transactions that were issued more than a minute ago.
+// write lock must be acquired outside.
+func (l *issuanceRateLimiter) pruneWithoutLocking(now time.Time) {
+	var expired int
+	for expired < len(l.issued) && now.Sub(l.issued[expired]) >= time.Minute {
+		expired++
+	}
+	l.issued = append(l.issued[:0], l.issued[expired:]...)
+}
+
+// waitDuration returns how long to wait until the next transaction can be issued, 0 if it can be issued immediately.
+func (l *issuanceRateLimiter) waitDuration() time.Duration {
+	if l.maxPerMinute <= 0 {
+		return 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.clock.Now()
+	l.pruneWithoutLocking(now)
+
+	if len(l.issued) < l.maxPerMinute {
+		return 0
+	}
+
+	// the oldest transactions need to leave the window
+	return l.issued[len(l.issued)-l.maxPerMinute].Add(time.Minute).Sub(now)
+}
+
+// record adds an issued transaction.
+func (l *issuanceRateLimiter) record() {
+	if l.maxPerMinute <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.clock.Now()
+	l.pruneWithoutLocking(now)
+	l.issued = append(l.issued, now)
+}
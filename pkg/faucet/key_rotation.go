@@ -0,0 +1,109 @@
+package faucet
+
+import (
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithRetiredAddresses defines the addresses of retired faucet keys.
+// Their outputs are consumed first, so the funds are swept to the faucet address across multiple transactions.
+// The signer of the faucet must hold the keys of the retired addresses.
+func WithRetiredAddresses(addresses ...iotago.Address) Option {
+	return func(opts *Options) {
+		opts.retiredAddresses = addresses
+	}
+}
+
+// underlyingAddress returns the address behind a restricted address.
+func underlyingAddress(addr iotago.Address) iotago.Address {
+	if restrictedAddress, ok := addr.(*iotago.RestrictedAddress); ok {
+		return restrictedAddress.Address
+	}
+
+	return addr
+}
+
+// retiredAddressKey returns the key of the retired address the output belongs to, or an empty string.
+func (f *Faucet) retiredAddressKey(output UTXOBasicOutput) string {
+	if output.UnlockTarget == nil {
+		// the output belongs to the faucet address
+		return ""
+	}
+
+	unlockTargetKey := underlyingAddress(output.UnlockTarget).Key()
+	for _, retiredAddress := range f.opts.retiredAddresses {
+		if underlyingAddress(retiredAddress).Key() == unlockTargetKey {
+			return unlockTargetKey
+		}
+	}
+
+	return ""
+}
+
+// isRetiredOutput returns true if the output belongs to a retired address.
+func (f *Faucet) isRetiredOutput(output UTXOBasicOutput) bool {
+	return f.retiredAddressKey(output) != ""
+}
+
+// hasRetiredOutputs returns true if any of the outputs belongs to a retired address.
+func (f *Faucet) hasRetiredOutputs(outputs []UTXOBasicOutput) bool {
+	for _, output := range outputs {
+		if f.isRetiredOutput(output) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// partitionRetiredOutputs splits the outputs into the ones of retired addresses and all others, keeping their order.
+func (f *Faucet) partitionRetiredOutputs(outputs []UTXOBasicOutput) ([]UTXOBasicOutput, []UTXOBasicOutput) {
+	retiredOutputs := make([]UTXOBasicOutput, 0)
+	otherOutputs := make([]UTXOBasicOutput, 0, len(outputs))
+	for _, output := range outputs {
+		if f.isRetiredOutput(output) {
+			retiredOutputs = append(retiredOutputs, output)
+
+			continue
+		}
+		otherOutputs = append(otherOutputs, output)
+	}
+
+	return retiredOutputs, otherOutputs
+}
+
+// logRetiredAddressesWithoutLocking logs the progress of sweeping the retired addresses.
+// Once an address holds no funds anymore, the operator is told once that its key can be removed.
+// write lock must be acquired outside.
+func (f *Faucet) logRetiredAddressesWithoutLocking(unspentOutputs []UTXOBasicOutput) {
+	if len(f.opts.retiredAddresses) == 0 {
+		return
+	}
+
+	retiredOutputsCount := make(map[string]int)
+	for _, output := range unspentOutputs {
+		if key := f.retiredAddressKey(output); key != "" {
+			retiredOutputsCount[key]++
+		}
+	}
+
+	protocolParams := f.apiProvider.CommittedAPI().ProtocolParameters()
+	for _, retiredAddress := range f.opts.retiredAddresses {
+		key := underlyingAddress(retiredAddress).Key()
+		bech32Addr := retiredAddress.Bech32(protocolParams.Bech32HRP())
+
+		swept, seen := f.retiredAddressesSwept[key]
+		if count := retiredOutputsCount[key]; count > 0 {
+			if !seen || swept {
+				f.LogInfof("sweeping %d outputs of retired address %s to the faucet address", count, bech32Addr)
+			}
+			f.retiredAddressesSwept[key] = false
+
+			continue
+		}
+
+		if !swept {
+			f.LogInfof("all funds of retired address %s were swept, its key can be removed", bech32Addr)
+			f.retiredAddressesSwept[key] = true
+		}
+	}
+}
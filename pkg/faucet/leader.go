@@ -0,0 +1,144 @@
+package faucet
+
+import (
+	"context"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// LeaderLease is a lease in shared storage that is held by the faucet instance that issues the transactions.
+type LeaderLease interface {
+	// Acquire acquires or renews the lease for the given duration.
+	// It returns true if this instance holds the lease.
+	Acquire(ctx context.Context, duration time.Duration) (bool, error)
+	// Release gives up the lease if it is held by this instance.
+	Release(ctx context.Context) error
+}
+
+// WithLeaderLease enables the leader election between multiple faucet instances that share the same key.
+// Only the instance that holds the lease issues transactions.
+func WithLeaderLease(leaderLease LeaderLease, leaseDuration time.Duration) Option {
+	return func(opts *Options) {
+		opts.leaderLease = leaderLease
+		opts.leaderLeaseDuration = leaseDuration
+	}
+}
+
+// IsLeader returns true if this instance issues the faucet transactions.
+func (f *Faucet) IsLeader() bool {
+	if !f.opts.issuanceEnabled {
+		return false
+	}
+
+	if f.opts.leaderLease == nil {
+		// no leader election, this is the only instance
+		return true
+	}
+
+	return f.opts.clock.Now().UnixNano() < f.leaderUntil.Load()
+}
+
+// RunLeaderElection periodically acquires or renews the leader lease.
+// If the lease is lost, all queued requests are handed over to the new leader via the shared queue.
+func (f *Faucet) RunLeaderElection(ctx context.Context) error {
+	if f.opts.leaderLease == nil || !f.opts.issuanceEnabled {
+		return nil
+	}
+
+	if f.opts.sharedQueue == nil {
+		return CriticalError(ierrors.New("leader election requires a shared queue"))
+	}
+
+	if f.opts.leaderLeaseDuration <= 0 {
+		return CriticalError(ierrors.New("leader lease duration must be greater than zero"))
+	}
+
+	// renew the lease well before it expires
+	renewInterval := f.opts.leaderLeaseDuration / 3
+
+	for {
+		wasLeader := f.IsLeader()
+
+		// the lease is considered lost a bit before it expires in the shared storage,
+		// to make sure that no other instance issues transactions at the same time.
+		validUntil := f.opts.clock.Now().Add(f.opts.leaderLeaseDuration - renewInterval)
+
+		ctxAcquire, cancelAcquire := context.WithTimeout(ctx, renewInterval)
+		acquired, err := f.opts.leaderLease.Acquire(ctxAcquire, f.opts.leaderLeaseDuration)
+		cancelAcquire()
+
+		switch {
+		case err != nil:
+			// keep the current state until the lease expires
+			f.logSoftError(ierrors.Wrap(err, "failed to acquire the leader lease"))
+		case acquired:
+			f.leaderUntil.Store(validUntil.UnixNano())
+		default:
+			f.leaderUntil.Store(0)
+		}
+
+		if isLeader := f.IsLeader(); isLeader != wasLeader {
+			if isLeader {
+				f.LogInfo("acquired the leader lease, issuing faucet transactions")
+			} else {
+				f.LogInfo("lost the leader lease, handing over the queued requests")
+				f.handOverQueuedRequests()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			// faucet was stopped => give up the lease, so another instance can take over immediately
+			f.leaderUntil.Store(0)
+			f.handOverQueuedRequests()
+
+			ctxRelease, cancelRelease := context.WithTimeout(context.Background(), sharedQueueRequestTimeout)
+			defer cancelRelease()
+
+			if err := f.opts.leaderLease.Release(ctxRelease); err != nil {
+				f.logSoftError(ierrors.Wrap(err, "failed to release the leader lease"))
+			}
+
+			return nil
+
+		case <-f.opts.clock.After(renewInterval):
+		}
+	}
+}
+
+// handOverQueuedRequests moves all requests from the local queue back to the shared queue.
+func (f *Faucet) handOverQueuedRequests() {
+	f.Lock()
+	defer f.Unlock()
+
+	f.handOverRequestsWithoutLocking(f.drainQueueWithoutLocking())
+}
+
+// handOverRequestsWithoutLocking moves the given requests back to the shared queue.
+// the requests are added in the background, so it can be called while holding the lock.
+// write lock must be acquired outside.
+func (f *Faucet) handOverRequestsWithoutLocking(requests []*queueItem) {
+	// airdrops are not part of the shared queue, their payouts are continued if this instance becomes the leader again
+	requests, airdropRequests := splitAirdropRequests(requests)
+	f.airdrops.requeue(airdropRequests)
+
+	if f.opts.sharedQueue == nil || len(requests) == 0 {
+		return
+	}
+
+	for _, request := range requests {
+		// the address stays marked as queued in the shared queue
+		delete(f.queueMap, request.Bech32)
+		f.reservations.release(request.Bech32)
+		f.untrackPendingRequestWithoutLocking(request)
+	}
+
+	sharedRequests := toSharedRequests(requests)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedQueueRequestTimeout)
+		defer cancel()
+
+		f.requeueSharedRequests(ctx, sharedRequests)
+	}()
+}
@@ -0,0 +1,138 @@
+package faucet
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/hive.go/core/safemath"
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/builder"
+)
+
+// calculateUnboundMana calculates the stored and the potential mana of the given outputs at the latest slot.
+func (f *Faucet) calculateUnboundMana(unspentOutputs []UTXOBasicOutput) (iotago.Mana, iotago.Mana, error) {
+	txBuilder := builder.NewTransactionBuilder(f.apiProvider.CommittedAPI(), f.addressSigner)
+	for _, unspentOutput := range unspentOutputs {
+		txBuilder.AddInput(&builder.TxInput{UnlockTarget: f.unlockTarget(unspentOutput), InputID: unspentOutput.OutputID, Input: unspentOutput.Output})
+	}
+
+	availableManaInputs, err := txBuilder.CalculateAvailableManaInputs(f.node.LatestSlot())
+	if err != nil {
+		return 0, 0, ierrors.Wrap(err, "failed to calculate available mana balance")
+	}
+
+	return availableManaInputs.UnboundStoredMana, availableManaInputs.UnboundPotentialMana, nil
+}
+
+// targetPayoutAmounts returns the amount of base tokens a basic request to the given address receives
+// and whether the mana payout is skipped, based on the funds that are already on the target address.
+func (f *Faucet) targetPayoutAmounts(addr iotago.Address) (iotago.BaseToken, bool, error) {
+	balance, mana, err := f.addressBalance(addr)
+	if err != nil {
+		// the funds on the target address are unknown, so the request receives the full amount
+		return f.RuntimeParameters().BaseTokenAmount, !canReceiveMana(addr), nil
+	}
+
+	return f.payoutAmountsForFunds(addr, balance, mana)
+}
+
+// payoutAmountsForFunds returns the amount of base tokens a basic request to the given address receives
+// and whether the mana payout is skipped, based on the given funds on the target address.
+func (f *Faucet) payoutAmountsForFunds(addr iotago.Address, balance iotago.BaseToken, mana iotago.Mana) (iotago.BaseToken, bool, error) {
+	params := f.RuntimeParameters()
+
+	manaCheckEnabled := f.opts.manaAmountMaxTarget > 0
+	hasEnoughMana := manaCheckEnabled && mana >= f.opts.manaAmountMaxTarget
+
+	// restricted addresses may forbid to receive mana, in which case the mana payout is skipped
+	skipMana := hasEnoughMana || !canReceiveMana(addr)
+
+	if balance < params.BaseTokenAmount {
+		return params.BaseTokenAmount, skipMana, nil
+	}
+
+	if balance < params.BaseTokenAmountMaxTarget {
+		return params.BaseTokenAmountSmall, skipMana, nil
+	}
+
+	if f.opts.manaOnlyPayouts && manaCheckEnabled && !skipMana && params.ManaAmount > 0 {
+		// the address has enough base tokens, but still needs mana
+		minStorageDeposit, err := f.apiProvider.CommittedAPI().StorageScoreStructure().MinDeposit(EmptyBasicOutput)
+		if err != nil {
+			return 0, false, ierrors.Wrap(err, "failed to calculate the minimum storage deposit")
+		}
+
+		return minStorageDeposit, false, nil
+	}
+
+	requestErr := NewRequestError(ErrorCodeAddressHasEnoughFunds, http.StatusBadRequest, "You already have enough funds on your address.").
+		WithDetail("balance", balance).
+		WithDetail("maxTargetBalance", params.BaseTokenAmountMaxTarget)
+	if manaCheckEnabled {
+		requestErr = requestErr.
+			WithDetail("mana", mana).
+			WithDetail("maxTargetMana", f.opts.manaAmountMaxTarget)
+	}
+
+	return 0, false, requestErr
+}
+
+// canReceiveMana checks if outputs that hold mana can be sent to the given address.
+func canReceiveMana(addr iotago.Address) bool {
+	if restrictedAddress, ok := addr.(*iotago.RestrictedAddress); ok {
+		return !restrictedAddress.CannotReceiveMana()
+	}
+
+	return true
+}
+
+// isManaClaimDueWithoutLocking checks if the potential mana of the faucet outputs should be converted into stored mana.
+// This is the case if the stored mana is not sufficient for the mana payouts of a full batch anymore,
+// but enough potential mana was generated by the faucet outputs.
+// write lock must be acquired outside.
+func (f *Faucet) isManaClaimDueWithoutLocking(unspentOutputs []UTXOBasicOutput) bool {
+	if f.opts.manaClaimInterval == 0 || len(unspentOutputs) == 0 {
+		// mana claiming is disabled or there is nothing to claim
+		return false
+	}
+
+	if f.opts.clock.Now().Sub(f.lastManaClaimCheck) < f.opts.manaClaimInterval {
+		return false
+	}
+	f.lastManaClaimCheck = f.opts.clock.Now()
+
+	storedMana, potentialMana, err := f.calculateUnboundMana(unspentOutputs)
+	if err != nil {
+		f.logSoftError(err)
+
+		return false
+	}
+
+	if potentialMana < f.opts.manaClaimMinPotentialMana {
+		// not worth to issue a transaction yet
+		return false
+	}
+
+	manaPayoutsFullBatch, err := safemath.SafeMul(iotago.Mana(f.opts.batchMaxSize), f.opts.manaAmount)
+	if err != nil {
+		f.logSoftError(ierrors.Wrap(err, "failed to calculate required mana for a full batch"))
+
+		return false
+	}
+
+	requiredStoredMana, err := safemath.SafeAdd(f.opts.manaAmountMinFaucet, manaPayoutsFullBatch)
+	if err != nil {
+		f.logSoftError(ierrors.Wrap(err, "failed to calculate required stored mana of the faucet"))
+
+		return false
+	}
+
+	if storedMana >= requiredStoredMana {
+		// there is still enough stored mana for the payouts
+		return false
+	}
+
+	f.LogInfof("claiming potential mana of the faucet, stored mana: %d, potential mana: %d, required stored mana: %d", storedMana, potentialMana, requiredStoredMana)
+
+	return true
+}
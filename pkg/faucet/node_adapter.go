@@ -0,0 +1,150 @@
+package faucet
+
+import (
+	"context"
+	"time"
+
+	"github.com/iotaledger/hive.go/runtime/event"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+	"github.com/iotaledger/iota.go/v4/builder"
+)
+
+// NodeAdapter is used by the faucet to access the ledger of the node and to issue blocks.
+type NodeAdapter interface {
+	// IsNodeHealthy returns whether the used node is synced.
+	IsNodeHealthy() bool
+	// FetchTransactionMetadata fetches the required metadata of a transaction.
+	// This returns nil if the transaction is not found.
+	FetchTransactionMetadata(transactionID iotago.TransactionID) (*api.TransactionMetadataResponse, error)
+	// CollectUnlockableFaucetOutputs collects the unlockable outputs of the faucet.
+	CollectUnlockableFaucetOutputs() ([]UTXOBasicOutput, error)
+	// ComputeUnlockableAddressBalance computes the unlockable balance of an address.
+	ComputeUnlockableAddressBalance(address iotago.Address) (iotago.BaseToken, iotago.Mana, error)
+	// LatestSlot returns the latest known slot in the network.
+	LatestSlot() iotago.SlotIndex
+	// ReferenceManaCost returns the current reference mana cost of the network.
+	ReferenceManaCost() (iotago.Mana, error)
+	// LatestCommittedSlot returns the slot of the latest commitment of the node.
+	LatestCommittedSlot() iotago.SlotIndex
+	// CollectFaucetDelegationOutputs collects the delegation outputs owned by the faucet.
+	CollectFaucetDelegationOutputs() ([]UTXODelegationOutput, error)
+	// DelegationRewards returns the mana rewards of a delegation output.
+	DelegationRewards(outputID iotago.OutputID) (iotago.Mana, error)
+	// SubmitTransactionPayload creates a signed transaction payload and sends it to a block issuer.
+	SubmitTransactionPayload(ctx context.Context, builder *builder.TransactionBuilder, storedManaOutputIndex int, numPoWWorkers ...int) (iotago.ApplicationPayload, iotago.BlockID, error)
+	// ReissueTransactionPayload sends an already signed transaction payload in a new block to a block issuer.
+	ReissueTransactionPayload(ctx context.Context, signedTx *iotago.SignedTransaction, numPoWWorkers ...int) (iotago.BlockID, error)
+}
+
+// NodeCallType defines the type of a call of the faucet to the node, the indexer or the block issuer.
+type NodeCallType string
+
+const (
+	// NodeCallCollectFaucetOutputs is the indexer query for the unlockable outputs of the faucet.
+	NodeCallCollectFaucetOutputs NodeCallType = "collect_faucet_outputs"
+	// NodeCallAddressBalance is the indexer query for the unlockable balance of an address.
+	NodeCallAddressBalance NodeCallType = "address_balance"
+	// NodeCallCollectDelegationOutputs is the indexer query for the delegation outputs of the faucet.
+	NodeCallCollectDelegationOutputs NodeCallType = "collect_delegation_outputs"
+	// NodeCallTransactionMetadata is the INX request for the metadata of a transaction.
+	NodeCallTransactionMetadata NodeCallType = "transaction_metadata"
+	// NodeCallDelegationRewards is the request for the rewards of a delegation output.
+	NodeCallDelegationRewards NodeCallType = "delegation_rewards"
+	// NodeCallSubmitTransaction is the submission of a new transaction to the block issuer.
+	NodeCallSubmitTransaction NodeCallType = "submit_transaction"
+	// NodeCallReissueTransaction is the submission of an already signed transaction to the block issuer.
+	NodeCallReissueTransaction NodeCallType = "reissue_transaction"
+)
+
+// NodeCall is a completed call of the faucet to the node, the indexer or the block issuer.
+type NodeCall struct {
+	// The type of the call.
+	Type NodeCallType
+	// The round-trip time of the call.
+	Duration time.Duration
+	// The error of the call, it is nil if the call succeeded.
+	Err error
+}
+
+// instrumentedNodeAdapter measures the round-trip time of the calls to the node that leave the process.
+// the calls that only read the cached state of the node bridge are passed through.
+type instrumentedNodeAdapter struct {
+	NodeAdapter
+
+	clock     Clock
+	completed *event.Event1[*NodeCall]
+}
+
+func newInstrumentedNodeAdapter(node NodeAdapter, clock Clock, completed *event.Event1[*NodeCall]) *instrumentedNodeAdapter {
+	return &instrumentedNodeAdapter{
+		NodeAdapter: node,
+		clock:       clock,
+		completed:   completed,
+	}
+}
+
+// track triggers the event of the completed call that started at the given time.
+func (n *instrumentedNodeAdapter) track(callType NodeCallType, start time.Time, err error) {
+	n.completed.Trigger(&NodeCall{
+		Type:     callType,
+		Duration: n.clock.Now().Sub(start),
+		Err:      err,
+	})
+}
+
+func (n *instrumentedNodeAdapter) FetchTransactionMetadata(transactionID iotago.TransactionID) (*api.TransactionMetadataResponse, error) {
+	start := n.clock.Now()
+	metadata, err := n.NodeAdapter.FetchTransactionMetadata(transactionID)
+	n.track(NodeCallTransactionMetadata, start, err)
+
+	return metadata, err
+}
+
+func (n *instrumentedNodeAdapter) CollectUnlockableFaucetOutputs() ([]UTXOBasicOutput, error) {
+	start := n.clock.Now()
+	outputs, err := n.NodeAdapter.CollectUnlockableFaucetOutputs()
+	n.track(NodeCallCollectFaucetOutputs, start, err)
+
+	return outputs, err
+}
+
+func (n *instrumentedNodeAdapter) ComputeUnlockableAddressBalance(address iotago.Address) (iotago.BaseToken, iotago.Mana, error) {
+	start := n.clock.Now()
+	balance, mana, err := n.NodeAdapter.ComputeUnlockableAddressBalance(address)
+	n.track(NodeCallAddressBalance, start, err)
+
+	return balance, mana, err
+}
+
+func (n *instrumentedNodeAdapter) CollectFaucetDelegationOutputs() ([]UTXODelegationOutput, error) {
+	start := n.clock.Now()
+	outputs, err := n.NodeAdapter.CollectFaucetDelegationOutputs()
+	n.track(NodeCallCollectDelegationOutputs, start, err)
+
+	return outputs, err
+}
+
+func (n *instrumentedNodeAdapter) DelegationRewards(outputID iotago.OutputID) (iotago.Mana, error) {
+	start := n.clock.Now()
+	rewards, err := n.NodeAdapter.DelegationRewards(outputID)
+	n.track(NodeCallDelegationRewards, start, err)
+
+	return rewards, err
+}
+
+func (n *instrumentedNodeAdapter) SubmitTransactionPayload(ctx context.Context, builder *builder.TransactionBuilder, storedManaOutputIndex int, numPoWWorkers ...int) (iotago.ApplicationPayload, iotago.BlockID, error) {
+	start := n.clock.Now()
+	payload, blockID, err := n.NodeAdapter.SubmitTransactionPayload(ctx, builder, storedManaOutputIndex, numPoWWorkers...)
+	n.track(NodeCallSubmitTransaction, start, err)
+
+	return payload, blockID, err
+}
+
+func (n *instrumentedNodeAdapter) ReissueTransactionPayload(ctx context.Context, signedTx *iotago.SignedTransaction, numPoWWorkers ...int) (iotago.BlockID, error) {
+	start := n.clock.Now()
+	blockID, err := n.NodeAdapter.ReissueTransactionPayload(ctx, signedTx, numPoWWorkers...)
+	n.track(NodeCallReissueTransaction, start, err)
+
+	return blockID, err
+}
@@ -0,0 +1,124 @@
+package faucet
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// OwnershipVerifier is a RequestValidator that only pays out more than the small amount of base tokens
+// if the requester proves the control of the target address with a signature over a server-provided challenge.
+// This prevents that third-party addresses are filled up to the maximum target amount by others.
+type OwnershipVerifier struct {
+	issuer               *challengeIssuer
+	baseTokenAmountSmall iotago.BaseToken
+}
+
+var _ RequestValidator = &OwnershipVerifier{}
+
+// NewOwnershipVerifier creates a new OwnershipVerifier.
+// The clock defines the expiry of the challenges, it should be the clock of the faucet.
+// The challenges are kept in the given store, or in memory if it is nil.
+func NewOwnershipVerifier(baseTokenAmountSmall iotago.BaseToken, challengeTTL time.Duration, clock Clock, store ChallengeStore) (*OwnershipVerifier, error) {
+	issuer, err := newChallengeIssuer(challengeTTL, clock, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OwnershipVerifier{
+		issuer:               issuer,
+		baseTokenAmountSmall: baseTokenAmountSmall,
+	}, nil
+}
+
+// NewChallenge creates a new challenge that needs to be signed by the owner of the target address.
+func (v *OwnershipVerifier) NewChallenge() (*ChallengeResponse, error) {
+	challenge, expiresAt, err := v.issuer.issue()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChallengeResponse{
+		Challenge: challenge,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+// ValidateRequest reduces the amount of the request to the small amount if the ownership of the target address is not proven.
+func (v *OwnershipVerifier) ValidateRequest(request *ValidationRequest) (iotago.BaseToken, error) {
+	if request.BaseTokenAmount <= v.baseTokenAmountSmall {
+		// small payouts don't need a proof
+		return request.BaseTokenAmount, nil
+	}
+
+	if request.OwnershipChallenge == "" && request.PublicKey == "" && request.Signature == "" {
+		return v.baseTokenAmountSmall, nil
+	}
+
+	if err := v.verifyOwnership(request.Bech32, request.Address, request.OwnershipChallenge, request.PublicKey, request.Signature); err != nil {
+		return 0, err
+	}
+
+	return request.BaseTokenAmount, nil
+}
+
+// VerifyOwnership checks that the given signature over the ownership challenge was created by the owner of the given address,
+// e.g. to cancel a queued request. The challenge is consumed if the signature is valid.
+func (v *OwnershipVerifier) VerifyOwnership(bech32Addr string, challenge string, publicKeyHex string, signatureHex string) error {
+	_, addr, err := iotago.ParseBech32(bech32Addr)
+	if err != nil {
+		return NewRequestError(ErrorCodeInvalidAddress, http.StatusBadRequest, "Invalid bech32 address provided!")
+	}
+
+	return v.verifyOwnership(bech32Addr, addr, challenge, publicKeyHex, signatureHex)
+}
+
+func (v *OwnershipVerifier) verifyOwnership(bech32Addr string, addr iotago.Address, challenge string, publicKeyHex string, signatureHex string) error {
+	invalidProofErr := NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "Invalid ownership proof provided!")
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return invalidProofErr
+	}
+
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return invalidProofErr
+	}
+
+	if !isAddressOfPublicKey(addr, publicKey) {
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "The public key of the ownership proof does not belong to the target address!")
+	}
+
+	if !ed25519.Verify(publicKey, OwnershipMessage(challenge, bech32Addr), signature) {
+		return invalidProofErr
+	}
+
+	// the challenge is only consumed if the signature is valid
+	return v.issuer.consume(challenge)
+}
+
+// OwnershipMessage returns the message that needs to be signed to prove the ownership of the given address.
+// Bech32 addresses are case-insensitive, so the address is signed in lower case.
+func OwnershipMessage(challenge string, bech32Addr string) []byte {
+	return []byte(challenge + ":" + strings.ToLower(bech32Addr))
+}
+
+// isAddressOfPublicKey checks if the given address is derived from the given public key.
+func isAddressOfPublicKey(address iotago.Address, publicKey ed25519.PublicKey) bool {
+	switch addr := address.(type) {
+	case *iotago.Ed25519Address:
+		return addr.Equal(iotago.Ed25519AddressFromPubKey(publicKey))
+	case *iotago.ImplicitAccountCreationAddress:
+		return addr.Equal(iotago.ImplicitAccountCreationAddressFromPubKey(publicKey))
+	case *iotago.RestrictedAddress:
+		return isAddressOfPublicKey(addr.Address, publicKey)
+	default:
+		// the ownership of other address types can't be proven with a signature
+		return false
+	}
+}
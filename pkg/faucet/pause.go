@@ -0,0 +1,44 @@
+package faucet
+
+import (
+	"net/http"
+)
+
+// Pause stops accepting new requests and issuing transactions.
+// The queued requests and the pending transaction are kept until the faucet is resumed.
+// It returns false if the faucet was already paused.
+func (f *Faucet) Pause() bool {
+	if f.paused.Swap(true) {
+		return false
+	}
+
+	f.LogInfo("faucet paused, new requests are rejected and no transactions are issued")
+
+	return true
+}
+
+// Resume accepts new requests and issues transactions again after the faucet was paused.
+// It returns false if the faucet was not paused.
+func (f *Faucet) Resume() bool {
+	if !f.paused.Swap(false) {
+		return false
+	}
+
+	f.LogInfo("faucet resumed")
+
+	return true
+}
+
+// IsPaused returns true if the faucet was paused by the operator.
+func (f *Faucet) IsPaused() bool {
+	return f.paused.Load()
+}
+
+// checkPaused returns an error if the faucet was paused and doesn't accept new requests.
+func (f *Faucet) checkPaused() error {
+	if f.IsPaused() {
+		return NewRequestError(ErrorCodeFaucetPaused, http.StatusServiceUnavailable, "Faucet is paused. Please try again later!")
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+//go:build pkcs11
+
+package faucet
+
+import (
+	"crypto/ed25519"
+	"encoding/asn1"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/hexutil"
+)
+
+const (
+	// the PKCS#11 v3.0 key type and mechanism of Ed25519 keys, they are not defined by the pkcs11 package.
+	pkcs11KeyTypeECEdwards = 0x00000040
+	pkcs11MechanismEdDSA   = 0x00001057
+)
+
+// PKCS11AddressSigner is an AddressSigner that signs with an Ed25519 key stored in an HSM, accessed via PKCS#11,
+// so the private key never leaves the HSM.
+// The key pair is identified by the label of its public and private key objects.
+type PKCS11AddressSigner struct {
+	// a PKCS#11 session must not be used concurrently.
+	mutex      sync.Mutex
+	ctx        *pkcs11.Ctx
+	session    pkcs11.SessionHandle
+	privateKey pkcs11.ObjectHandle
+	publicKey  ed25519.PublicKey
+	address    *iotago.Ed25519Address
+}
+
+var _ iotago.AddressSigner = &PKCS11AddressSigner{}
+
+// NewPKCS11AddressSigner loads the PKCS#11 module, logs in to the token in the given slot with the PIN
+// and looks up the Ed25519 key pair with the given label.
+func NewPKCS11AddressSigner(modulePath string, slot uint, pin string, keyLabel string) (*PKCS11AddressSigner, error) {
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, ierrors.Errorf("failed to load the PKCS#11 module %s", modulePath)
+	}
+
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+
+		return nil, ierrors.Wrap(err, "failed to initialize the PKCS#11 module")
+	}
+
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		_ = ctx.Finalize()
+		ctx.Destroy()
+
+		return nil, ierrors.Wrapf(err, "failed to open a session on slot %d", slot)
+	}
+
+	s := &PKCS11AddressSigner{
+		ctx:     ctx,
+		session: session,
+	}
+
+	if err := s.init(pin, keyLabel); err != nil {
+		_ = s.Close()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// init logs in to the token and looks up the key pair.
+func (s *PKCS11AddressSigner) init(pin string, keyLabel string) error {
+	if err := s.ctx.Login(s.session, pkcs11.CKU_USER, pin); err != nil && !ierrors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		return ierrors.Wrap(err, "failed to log in to the token")
+	}
+
+	publicKeyObject, err := s.findKey(pkcs11.CKO_PUBLIC_KEY, keyLabel)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := s.ctx.GetAttributeValue(s.session, publicKeyObject, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to read the public key \"%s\"", keyLabel)
+	}
+
+	publicKey, err := decodePKCS11ECPoint(attributes[0].Value)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to decode the public key \"%s\"", keyLabel)
+	}
+
+	privateKeyObject, err := s.findKey(pkcs11.CKO_PRIVATE_KEY, keyLabel)
+	if err != nil {
+		return err
+	}
+
+	s.privateKey = privateKeyObject
+	s.publicKey = publicKey
+	s.address = iotago.Ed25519AddressFromPubKey(publicKey)
+
+	return nil
+}
+
+// findKey returns the single Ed25519 key object of the given class with the given label.
+func (s *PKCS11AddressSigner) findKey(class uint, keyLabel string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11KeyTypeECEdwards),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+	}
+
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, ierrors.Wrapf(err, "failed to search the key \"%s\"", keyLabel)
+	}
+
+	objects, _, err := s.ctx.FindObjects(s.session, 2)
+	if finalErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, ierrors.Wrapf(err, "failed to search the key \"%s\"", keyLabel)
+	}
+
+	switch len(objects) {
+	case 0:
+		return 0, ierrors.Errorf("no Ed25519 key with label \"%s\" found on the token", keyLabel)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, ierrors.Errorf("multiple Ed25519 keys with label \"%s\" found on the token", keyLabel)
+	}
+}
+
+// decodePKCS11ECPoint decodes an Ed25519 public key from the CKA_EC_POINT attribute.
+// PKCS#11 defines it as a DER encoded octet string, but some tokens return the raw key.
+func decodePKCS11ECPoint(ecPoint []byte) (ed25519.PublicKey, error) {
+	if len(ecPoint) == ed25519.PublicKeySize {
+		return ecPoint, nil
+	}
+
+	var publicKey []byte
+	if rest, err := asn1.Unmarshal(ecPoint, &publicKey); err != nil || len(rest) != 0 {
+		return nil, ierrors.New("the EC point is not an octet string")
+	}
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ierrors.Errorf("the public key has a wrong length: %d", len(publicKey))
+	}
+
+	return publicKey, nil
+}
+
+// Address returns the Ed25519 address of the key in the HSM.
+func (s *PKCS11AddressSigner) Address() *iotago.Ed25519Address {
+	return s.address
+}
+
+// Close logs out of the token and unloads the PKCS#11 module.
+func (s *PKCS11AddressSigner) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// logging out fails if the login failed, so the error is ignored
+	_ = s.ctx.Logout(s.session)
+
+	err := s.ctx.CloseSession(s.session)
+	if finalizeErr := s.ctx.Finalize(); err == nil {
+		err = finalizeErr
+	}
+	s.ctx.Destroy()
+
+	return err
+}
+
+// checkAddress returns an error if the key in the HSM doesn't belong to the given address.
+func (s *PKCS11AddressSigner) checkAddress(addr iotago.Address) error {
+	switch address := addr.(type) {
+	case *iotago.Ed25519Address:
+		if address.Equal(s.address) {
+			return nil
+		}
+
+	case *iotago.RestrictedAddress:
+		return s.checkAddress(address.Address)
+
+	default:
+		return ierrors.WithMessagef(iotago.ErrAddressKeysNotMapped, "address type %T is not supported by the PKCS#11 signer", addr)
+	}
+
+	return ierrors.WithMessagef(iotago.ErrAddressKeysNotMapped, "the key in the HSM doesn't belong to address %s", addr)
+}
+
+// SignerUIDForAddress returns the signer unique identifier for a given address.
+func (s *PKCS11AddressSigner) SignerUIDForAddress(addr iotago.Address) (iotago.Identifier, error) {
+	if err := s.checkAddress(addr); err != nil {
+		return iotago.EmptyIdentifier, err
+	}
+
+	// the UID is the blake2b 256 hash of the public key
+	return iotago.IdentifierFromData(s.publicKey), nil
+}
+
+// Sign signs the message with the key in the HSM.
+func (s *PKCS11AddressSigner) Sign(addr iotago.Address, msg []byte) (iotago.Signature, error) {
+	if err := s.checkAddress(addr); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11MechanismEdDSA, nil)}, s.privateKey); err != nil {
+		return nil, ierrors.Wrap(err, "failed to sign the message with the HSM")
+	}
+
+	signature, err := s.ctx.Sign(s.session, msg)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to sign the message with the HSM")
+	}
+
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(s.publicKey, msg, signature) {
+		return nil, ierrors.Errorf("the HSM returned an invalid signature for public key %s", hexutil.EncodeHex(s.publicKey))
+	}
+
+	ed25519Sig := &iotago.Ed25519Signature{}
+	copy(ed25519Sig.Signature[:], signature)
+	copy(ed25519Sig.PublicKey[:], s.publicKey)
+
+	return ed25519Sig, nil
+}
+
+// EmptySignatureForAddress returns an empty signature for the given address.
+func (s *PKCS11AddressSigner) EmptySignatureForAddress(addr iotago.Address) (iotago.Signature, error) {
+	if err := s.checkAddress(addr); err != nil {
+		return nil, err
+	}
+
+	return &iotago.Ed25519Signature{}, nil
+}
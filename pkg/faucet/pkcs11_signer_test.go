@@ -0,0 +1,238 @@
+//go:build pkcs11
+
+package faucet
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// The tests against an HSM need a PKCS#11 module with an initialized token that supports Ed25519, e.g. SoftHSM 2.6:
+//
+//	softhsm2-util --init-token --free --label faucet-test --pin 1234 --so-pin 1234
+//	FAUCET_PKCS11_TEST_MODULE=/usr/lib/softhsm/libsofthsm2.so FAUCET_PKCS11_TEST_SLOT=<slot> FAUCET_PKCS11_TEST_PIN=1234 \
+//		go test -tags pkcs11 -run PKCS11 ./pkg/faucet/
+//
+// They are skipped if no module is configured.
+
+const (
+	// the mechanism to generate Ed25519 key pairs, it is not defined by the pkcs11 package.
+	pkcs11MechanismECEdwardsKeyPairGen = 0x00001055
+)
+
+type pkcs11TestToken struct {
+	modulePath string
+	slot       uint
+	pin        string
+}
+
+func newPKCS11TestToken(t *testing.T) *pkcs11TestToken {
+	t.Helper()
+
+	modulePath := os.Getenv("FAUCET_PKCS11_TEST_MODULE")
+	if modulePath == "" {
+		t.Skip("FAUCET_PKCS11_TEST_MODULE not set")
+	}
+
+	slot, err := strconv.ParseUint(os.Getenv("FAUCET_PKCS11_TEST_SLOT"), 10, 0)
+	if err != nil {
+		t.Fatalf("invalid FAUCET_PKCS11_TEST_SLOT: %s", err)
+	}
+
+	return &pkcs11TestToken{
+		modulePath: modulePath,
+		slot:       uint(slot),
+		pin:        os.Getenv("FAUCET_PKCS11_TEST_PIN"),
+	}
+}
+
+// withSession runs the given function in a logged in read/write session.
+// the module is finalized afterwards, so the signer under test can initialize it on its own.
+func (tt *pkcs11TestToken) withSession(t *testing.T, fn func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle)) {
+	t.Helper()
+
+	ctx := pkcs11.New(tt.modulePath)
+	if ctx == nil {
+		t.Fatalf("failed to load the PKCS#11 module %s", tt.modulePath)
+	}
+	defer ctx.Destroy()
+
+	if err := ctx.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ctx.Finalize() }()
+
+	session, err := ctx.OpenSession(tt.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ctx.CloseSession(session) }()
+
+	if err := ctx.Login(session, pkcs11.CKU_USER, tt.pin); err != nil && !ierrors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		t.Fatal(err)
+	}
+	defer func() { _ = ctx.Logout(session) }()
+
+	fn(ctx, session)
+}
+
+// generateKey creates an Ed25519 key pair with a random label on the token, it is removed after the test.
+func (tt *pkcs11TestToken) generateKey(t *testing.T) string {
+	t.Helper()
+
+	labelBytes := make([]byte, 8)
+	if _, err := rand.Read(labelBytes); err != nil {
+		t.Fatal(err)
+	}
+	label := "faucet-test-" + hex.EncodeToString(labelBytes)
+
+	// the curve is identified by the OID of Ed25519
+	ecParams, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 101, 112})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt.withSession(t, func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) {
+		publicKeyTemplate := []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11KeyTypeECEdwards),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		}
+		privateKeyTemplate := []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11KeyTypeECEdwards),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		}
+
+		if _, _, err := ctx.GenerateKeyPair(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11MechanismECEdwardsKeyPairGen, nil)}, publicKeyTemplate, privateKeyTemplate); err != nil {
+			t.Fatalf("failed to generate the key pair: %s", err)
+		}
+	})
+
+	t.Cleanup(func() {
+		tt.withSession(t, func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) {
+			if err := ctx.FindObjectsInit(session, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_LABEL, label)}); err != nil {
+				t.Fatal(err)
+			}
+			objects, _, err := ctx.FindObjects(session, 10)
+			_ = ctx.FindObjectsFinal(session)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, object := range objects {
+				_ = ctx.DestroyObject(session, object)
+			}
+		})
+	})
+
+	return label
+}
+
+func TestPKCS11AddressSigner(t *testing.T) {
+	token := newPKCS11TestToken(t)
+	label := token.generateKey(t)
+
+	signer, err := NewPKCS11AddressSigner(token.modulePath, token.slot, token.pin, label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := signer.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	msg := make([]byte, 32)
+	if _, err := rand.Read(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, address := range []iotago.Address{signer.Address(), iotago.RestrictedAddressWithCapabilities(signer.Address())} {
+		signature, err := signer.Sign(address, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ed25519Signature, ok := signature.(*iotago.Ed25519Signature)
+		if !ok {
+			t.Fatalf("unexpected signature type %T", signature)
+		}
+
+		if !ed25519.Verify(ed25519Signature.PublicKey[:], msg, ed25519Signature.Signature[:]) {
+			t.Fatal("the signature doesn't verify")
+		}
+
+		if !iotago.Ed25519AddressFromPubKey(ed25519Signature.PublicKey[:]).Equal(signer.Address()) {
+			t.Fatal("the key of the signature doesn't belong to the address of the signer")
+		}
+	}
+
+	otherAddress := iotago.Ed25519AddressFromPubKey(make([]byte, ed25519.PublicKeySize))
+	if _, err := signer.Sign(otherAddress, msg); !ierrors.Is(err, iotago.ErrAddressKeysNotMapped) {
+		t.Fatalf("expected ErrAddressKeysNotMapped for a foreign address, got: %v", err)
+	}
+}
+
+func TestPKCS11AddressSignerUnknownLabel(t *testing.T) {
+	token := newPKCS11TestToken(t)
+
+	if _, err := NewPKCS11AddressSigner(token.modulePath, token.slot, token.pin, "faucet-test-unknown"); err == nil {
+		t.Fatal("expected an error for an unknown key label")
+	}
+}
+
+func TestDecodePKCS11ECPoint(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derPublicKey, err := asn1.Marshal([]byte(publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derShortPublicKey, err := asn1.Marshal([]byte(publicKey[:ed25519.PublicKeySize-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ecPoint := range map[string][]byte{
+		"der": derPublicKey,
+		"raw": publicKey,
+	} {
+		decoded, err := decodePKCS11ECPoint(ecPoint)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if !publicKey.Equal(decoded) {
+			t.Fatalf("%s: decoded public key doesn't match", name)
+		}
+	}
+
+	for name, ecPoint := range map[string][]byte{
+		"truncated":    derPublicKey[:20],
+		"wrong length": derShortPublicKey,
+		"empty":        nil,
+	} {
+		if _, err := decodePKCS11ECPoint(ecPoint); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
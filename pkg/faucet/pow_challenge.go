@@ -0,0 +1,100 @@
+package faucet
+
+import (
+	"context"
+	"crypto/sha256"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// PoWChallenger is a RequestValidator that requires a proof-of-work over a server-provided challenge and the requested address.
+// It is a CAPTCHA-free protection against bots for headless clients.
+type PoWChallenger struct {
+	issuer     *challengeIssuer
+	difficulty int
+}
+
+var _ RequestValidator = &PoWChallenger{}
+
+// NewPoWChallenger creates a new PoWChallenger.
+// The difficulty is the required amount of leading zero bits of the proof-of-work hash.
+// The clock defines the expiry of the challenges, it should be the clock of the faucet.
+// The challenges are kept in the given store, or in memory if it is nil.
+func NewPoWChallenger(difficulty int, challengeTTL time.Duration, clock Clock, store ChallengeStore) (*PoWChallenger, error) {
+	issuer, err := newChallengeIssuer(challengeTTL, clock, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PoWChallenger{
+		issuer:     issuer,
+		difficulty: difficulty,
+	}, nil
+}
+
+// NewChallenge creates a new challenge that needs to be solved by the client.
+func (c *PoWChallenger) NewChallenge() (*ChallengeResponse, error) {
+	challenge, expiresAt, err := c.issuer.issue()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChallengeResponse{
+		Challenge:  challenge,
+		Difficulty: c.difficulty,
+		ExpiresAt:  expiresAt.Unix(),
+	}, nil
+}
+
+// ValidateRequest checks the proof-of-work of the request.
+func (c *PoWChallenger) ValidateRequest(request *ValidationRequest) (iotago.BaseToken, error) {
+	if request.Challenge == "" || request.Nonce == "" {
+		return 0, NewRequestError(ErrorCodeForbidden, http.StatusForbidden, "A solved proof-of-work challenge is required!")
+	}
+
+	if PoWScore(request.Challenge, request.Bech32, request.Nonce) < c.difficulty {
+		return 0, NewRequestError(ErrorCodeForbidden, http.StatusForbidden, "Invalid proof-of-work provided!")
+	}
+
+	// the challenge is only consumed if the proof-of-work is valid
+	if err := c.issuer.consume(request.Challenge); err != nil {
+		return 0, err
+	}
+
+	return request.BaseTokenAmount, nil
+}
+
+// PoWScore returns the amount of leading zero bits of the proof-of-work hash of the given challenge, address and nonce.
+// Bech32 addresses are case-insensitive, so the address is hashed in lower case.
+func PoWScore(challenge string, bech32Addr string, nonce string) int {
+	hash := sha256.Sum256([]byte(challenge + ":" + strings.ToLower(bech32Addr) + ":" + nonce))
+
+	var score int
+	for _, b := range hash {
+		if b != 0 {
+			return score + bits.LeadingZeros8(b)
+		}
+		score += 8
+	}
+
+	return score
+}
+
+// SolvePoWChallenge searches a nonce for the given challenge and address that reaches the given difficulty.
+func SolvePoWChallenge(ctx context.Context, challenge string, bech32Addr string, difficulty int) (string, error) {
+	for nonce := uint64(0); ; nonce++ {
+		if nonce%10000 == 0 && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		nonceStr := strconv.FormatUint(nonce, 10)
+		if PoWScore(challenge, bech32Addr, nonceStr) >= difficulty {
+			return nonceStr, nil
+		}
+	}
+}
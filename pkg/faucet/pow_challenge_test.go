@@ -0,0 +1,122 @@
+package faucet_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/faucet/faucettest"
+)
+
+const (
+	testPoWDifficulty = 16
+	testBech32Addr    = "rms1qp5hcr6mr7rxehx5z6kfv3kkqj4sd2u5unaqg8hyl8vdtkjzwv2zxvlv2n5"
+)
+
+// sharedChallengeStore is a faucet.ChallengeStore that is shared by several challengers, like the redis store of the faucet instances.
+type sharedChallengeStore struct {
+	mutex sync.Mutex
+	used  map[string]struct{}
+}
+
+func (s *sharedChallengeStore) Secret(_ context.Context) ([]byte, error) {
+	return []byte("shared secret of all instances"), nil
+}
+
+func (s *sharedChallengeStore) Use(_ context.Context, challenge string, _ time.Time) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, used := s.used[challenge]; used {
+		return false, nil
+	}
+	s.used[challenge] = struct{}{}
+
+	return true, nil
+}
+
+func solvedPoWRequest(t *testing.T, challenger *faucet.PoWChallenger, bech32Addr string) *faucet.ValidationRequest {
+	t.Helper()
+
+	challenge, err := challenger.NewChallenge()
+	require.NoError(t, err)
+	require.Equal(t, testPoWDifficulty, challenge.Difficulty)
+
+	nonce, err := faucet.SolvePoWChallenge(context.Background(), challenge.Challenge, bech32Addr, challenge.Difficulty)
+	require.NoError(t, err)
+
+	return &faucet.ValidationRequest{
+		Bech32:          bech32Addr,
+		Challenge:       challenge.Challenge,
+		Nonce:           nonce,
+		BaseTokenAmount: 1_000_000,
+	}
+}
+
+func TestPoWChallenger(t *testing.T) {
+	clock := faucettest.NewClock(time.Now())
+
+	challenger, err := faucet.NewPoWChallenger(testPoWDifficulty, time.Minute, clock, nil)
+	require.NoError(t, err)
+
+	request := solvedPoWRequest(t, challenger, testBech32Addr)
+	amount, err := challenger.ValidateRequest(request)
+	require.NoError(t, err)
+	require.EqualValues(t, 1_000_000, amount)
+
+	// every challenge can only be used once
+	_, err = challenger.ValidateRequest(request)
+	require.Error(t, err)
+
+	// the solution is bound to the address
+	request = solvedPoWRequest(t, challenger, testBech32Addr)
+	request.Bech32 = "rms1qzg5r7dxy8t0pzk7qwdyx3k5tsjm5k4wxtqgqjkfvhw3qkqzr0qrwfvzag4"
+	_, err = challenger.ValidateRequest(request)
+	require.Error(t, err)
+
+	// expired challenges are rejected
+	request = solvedPoWRequest(t, challenger, testBech32Addr)
+	clock.Advance(2 * time.Minute)
+	_, err = challenger.ValidateRequest(request)
+	require.Error(t, err)
+
+	// challenges of another challenger are rejected
+	otherChallenger, err := faucet.NewPoWChallenger(testPoWDifficulty, time.Minute, clock, nil)
+	require.NoError(t, err)
+	_, err = challenger.ValidateRequest(solvedPoWRequest(t, otherChallenger, testBech32Addr))
+	require.Error(t, err)
+}
+
+func TestPoWChallengerAddressCase(t *testing.T) {
+	challenger, err := faucet.NewPoWChallenger(testPoWDifficulty, time.Minute, faucet.SystemClock, nil)
+	require.NoError(t, err)
+
+	// bech32 addresses are case-insensitive, e.g. in QR codes they are upper case
+	request := solvedPoWRequest(t, challenger, strings.ToUpper(testBech32Addr))
+	request.Bech32 = testBech32Addr
+
+	_, err = challenger.ValidateRequest(request)
+	require.NoError(t, err)
+}
+
+func TestPoWChallengerSharedStore(t *testing.T) {
+	store := &sharedChallengeStore{used: make(map[string]struct{})}
+
+	instance1, err := faucet.NewPoWChallenger(testPoWDifficulty, time.Minute, faucet.SystemClock, store)
+	require.NoError(t, err)
+	instance2, err := faucet.NewPoWChallenger(testPoWDifficulty, time.Minute, faucet.SystemClock, store)
+	require.NoError(t, err)
+
+	// a challenge issued by one instance is accepted by the other one, but only once
+	request := solvedPoWRequest(t, instance1, testBech32Addr)
+	_, err = instance2.ValidateRequest(request)
+	require.NoError(t, err)
+
+	_, err = instance1.ValidateRequest(request)
+	require.Error(t, err)
+}
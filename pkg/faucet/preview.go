@@ -0,0 +1,235 @@
+package faucet
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// PayoutAmountKind describes which of the configured amounts a request receives.
+type PayoutAmountKind string
+
+const (
+	// PayoutAmountFull is the regular amount of base tokens.
+	PayoutAmountFull PayoutAmountKind = "full"
+	// PayoutAmountSmall is the reduced amount for addresses that already hold funds.
+	PayoutAmountSmall PayoutAmountKind = "small"
+	// PayoutAmountManaOnly is the minimum storage deposit for addresses that hold enough base tokens, but still need mana.
+	PayoutAmountManaOnly PayoutAmountKind = "manaOnly"
+	// PayoutAmountAllowlist is the amount of the entry of the address on the allowlist.
+	PayoutAmountAllowlist PayoutAmountKind = "allowlist"
+	// PayoutAmountRequested is the amount the client asked for, clamped to the bounds of the faucet.
+	PayoutAmountRequested PayoutAmountKind = "requested"
+	// PayoutAmountNone means that the request would be rejected.
+	PayoutAmountNone PayoutAmountKind = "none"
+)
+
+// payoutAmountKind returns which of the configured amounts the given amount of base tokens of a request is.
+// It is empty for delegation and allotment requests.
+func (f *Faucet) payoutAmountKind(requestType RequestType, baseTokenAmount iotago.BaseToken, allowlisted bool) PayoutAmountKind {
+	if requestType != RequestTypeBasic {
+		return ""
+	}
+
+	params := f.RuntimeParameters()
+
+	switch {
+	case allowlisted:
+		return PayoutAmountAllowlist
+	case baseTokenAmount >= params.BaseTokenAmount:
+		return PayoutAmountFull
+	case baseTokenAmount >= params.BaseTokenAmountSmall:
+		return PayoutAmountSmall
+	default:
+		return PayoutAmountManaOnly
+	}
+}
+
+// PreviewResponse describes what a basic request for an address would receive with the current state of the faucet.
+type PreviewResponse struct {
+	// The bech32 address.
+	Address string `json:"address"`
+	// Whether a request for the address would be accepted.
+	Eligible bool `json:"eligible"`
+	// Which of the configured amounts the address receives.
+	AmountKind PayoutAmountKind `json:"amountKind"`
+	// The amount of base tokens the address receives.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of base tokens the address receives in token units, e.g. "10.5".
+	BaseTokenAmountFormatted string `json:"amountFormatted"`
+	// The amount of mana the address receives.
+	ManaAmount iotago.Mana `json:"mana"`
+	// The minimum storage deposit of the output that is sent to the address.
+	MinStorageDeposit iotago.BaseToken `json:"minStorageDeposit"`
+	// The base tokens on the address, it is omitted if the funds are unknown.
+	Balance *iotago.BaseToken `json:"balance,omitempty"`
+	// The stored mana on the address, it is omitted if the funds are unknown.
+	StoredMana *iotago.Mana `json:"storedMana,omitempty"`
+	// The human-readable reasons for the amounts and the eligibility.
+	Reasons []string `json:"reasons"`
+}
+
+// reject marks the preview as not eligible for the given reason.
+func (p *PreviewResponse) reject(reason string) {
+	p.Eligible = false
+	p.AmountKind = PayoutAmountNone
+	p.BaseTokenAmount = 0
+	p.ManaAmount = 0
+	p.Reasons = append(p.Reasons, reason)
+}
+
+// Preview returns what a basic request for the given address would receive with the current state of the faucet,
+// without adding a request to the queue. The request validators are not evaluated, because they may consume
+// challenges or count towards rate limits, so they can still reduce the amount or reject the request.
+func (f *Faucet) Preview(bech32Addr string) (*PreviewResponse, error) {
+	preview, err := f.previewAmounts(bech32Addr)
+	if err != nil {
+		return nil, err
+	}
+	preview.BaseTokenAmountFormatted = f.FormatBaseTokens(preview.BaseTokenAmount)
+
+	return preview, nil
+}
+
+// previewAmounts determines the amounts and the eligibility of a basic request for the given address.
+func (f *Faucet) previewAmounts(bech32Addr string) (*PreviewResponse, error) {
+	addr, bech32Addr, err := f.canonicalBech32Address(bech32Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	minStorageDeposit, err := f.apiProvider.CommittedAPI().StorageScoreStructure().MinDeposit(&iotago.BasicOutput{
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: addr},
+		},
+	})
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to calculate the minimum storage deposit")
+	}
+
+	params := f.RuntimeParameters()
+	preview := &PreviewResponse{
+		Address:           bech32Addr,
+		Eligible:          true,
+		MinStorageDeposit: minStorageDeposit,
+		Reasons:           make([]string, 0),
+	}
+
+	for _, check := range []func() error{
+		f.checkPaused,
+		f.checkBalanceInitialized,
+		func() error { return f.checkDenylist(bech32Addr) },
+		func() error { return f.checkPayoutTarget(addr) },
+		func() error { return f.checkTargetAddressType(addr) },
+	} {
+		if err := check(); err != nil {
+			preview.reject(AsRequestError(err).Message)
+
+			return preview, nil
+		}
+	}
+
+	if !f.node.IsNodeHealthy() {
+		preview.reject("The node of the faucet is not synchronized/healthy.")
+
+		return preview, nil
+	}
+
+	if f.isAlreadyinQueue(bech32Addr) {
+		preview.reject("The address is already in the queue.")
+
+		return preview, nil
+	}
+
+	var skipMana bool
+	if allowEntry := f.addressLists.get(AddressListAllow, bech32Addr); allowEntry != nil {
+		preview.BaseTokenAmount, skipMana = f.allowlistPayoutAmounts(addr, allowEntry)
+		preview.AmountKind = PayoutAmountAllowlist
+		preview.Reasons = append(preview.Reasons, "The address is on the allowlist, the funds on the address are not checked.")
+	} else {
+		balance, mana, err := f.addressBalance(addr)
+		if err != nil {
+			preview.BaseTokenAmount, skipMana = params.BaseTokenAmount, !canReceiveMana(addr)
+			preview.AmountKind = PayoutAmountFull
+			preview.Reasons = append(preview.Reasons, "The funds on the address are unknown, so the full amount is paid out.")
+		} else {
+			preview.Balance = &balance
+			preview.StoredMana = &mana
+
+			preview.BaseTokenAmount, skipMana, err = f.payoutAmountsForFunds(addr, balance, mana)
+			if err != nil {
+				preview.reject(AsRequestError(err).Message)
+
+				return preview, nil
+			}
+
+			switch {
+			case balance < params.BaseTokenAmount:
+				preview.AmountKind = PayoutAmountFull
+				preview.Reasons = append(preview.Reasons, fmt.Sprintf("The address holds less than the full amount of %d base tokens.", params.BaseTokenAmount))
+			case balance < params.BaseTokenAmountMaxTarget:
+				preview.AmountKind = PayoutAmountSmall
+				preview.Reasons = append(preview.Reasons, fmt.Sprintf("The address holds at least the full amount of %d base tokens, so only the small amount is paid out.", params.BaseTokenAmount))
+			default:
+				preview.AmountKind = PayoutAmountManaOnly
+				preview.Reasons = append(preview.Reasons, "The address holds enough base tokens, but still needs mana, so only the minimum storage deposit is paid out.")
+			}
+		}
+	}
+
+	baseTokenAmount, err := f.applyMinStorageDeposit(RequestTypeBasic, addr, preview.BaseTokenAmount)
+	if err != nil {
+		preview.reject(AsRequestError(err).Message)
+
+		return preview, nil
+	}
+	if baseTokenAmount != preview.BaseTokenAmount {
+		preview.BaseTokenAmount = baseTokenAmount
+		preview.Reasons = append(preview.Reasons, "The amount is raised to the minimum storage deposit of the output.")
+	}
+
+	if f.opts.baseTokenAmountJitter > 0 && isJitteredAmountKind(preview.AmountKind) {
+		if preview.AmountKind == PayoutAmountFull {
+			// the payout never exceeds the amount reserved on enqueue
+			preview.Reasons = append(preview.Reasons, fmt.Sprintf("The amount is randomly lowered by up to %g%%.", f.opts.baseTokenAmountJitter*100))
+		} else {
+			preview.Reasons = append(preview.Reasons, fmt.Sprintf("The amount is randomly raised or lowered by up to %g%%.", f.opts.baseTokenAmountJitter*100))
+		}
+	}
+
+	switch {
+	case params.ManaAmount == 0:
+		// the faucet doesn't pay out mana at all
+	case !canReceiveMana(addr):
+		preview.Reasons = append(preview.Reasons, "The address can't receive mana.")
+	case skipMana:
+		preview.Reasons = append(preview.Reasons, "The address already holds enough mana.")
+	default:
+		f.RLock()
+		availableMana := f.reservations.availableMana()
+		f.RUnlock()
+
+		if availableMana <= f.opts.manaAmountMinFaucet {
+			preview.Reasons = append(preview.Reasons, "The faucet does not have enough mana left, so no mana is paid out.")
+		} else {
+			preview.ManaAmount = params.ManaAmount
+		}
+	}
+
+	f.RLock()
+	availableBaseTokens := f.reservations.availableBaseTokens()
+	f.RUnlock()
+
+	if preview.BaseTokenAmount > availableBaseTokens {
+		preview.reject("The faucet does not have enough funds to process the request.")
+
+		return preview, nil
+	}
+
+	if len(f.opts.requestValidators) > 0 {
+		preview.Reasons = append(preview.Reasons, "Further request policies, e.g. rate limits or challenges, are not evaluated and may still reduce the amount.")
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,91 @@
+package faucet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// QueueOverflowPolicy defines how new requests are handled if the queue of the faucet is full.
+type QueueOverflowPolicy string
+
+const (
+	// QueueOverflowPolicyReject rejects new requests if the queue is full.
+	QueueOverflowPolicyReject QueueOverflowPolicy = "reject"
+	// QueueOverflowPolicyDropOldest drops the oldest request in the queue to make room for the new request.
+	QueueOverflowPolicyDropOldest QueueOverflowPolicy = "drop-oldest"
+	// QueueOverflowPolicyBlock waits until there is room in the queue or the timeout is reached.
+	QueueOverflowPolicyBlock QueueOverflowPolicy = "block"
+)
+
+// ParseQueueOverflowPolicy parses the name of a queue overflow policy.
+func ParseQueueOverflowPolicy(name string) (QueueOverflowPolicy, error) {
+	switch policy := QueueOverflowPolicy(name); policy {
+	case QueueOverflowPolicyReject, QueueOverflowPolicyDropOldest, QueueOverflowPolicyBlock:
+		return policy, nil
+	default:
+		return "", ierrors.Errorf("unknown queue overflow policy \"%s\", expected \"%s\", \"%s\" or \"%s\"", name, QueueOverflowPolicyReject, QueueOverflowPolicyDropOldest, QueueOverflowPolicyBlock)
+	}
+}
+
+// WithQueueSize defines the maximum amount of requests in the queue of the faucet.
+func WithQueueSize(queueSize int) Option {
+	return func(opts *Options) {
+		opts.queueSize = queueSize
+	}
+}
+
+// WithQueueOverflowPolicy defines how new requests are handled if the queue is full.
+// The block timeout is the maximum duration a request waits for room in the queue if the block policy is used.
+func WithQueueOverflowPolicy(policy QueueOverflowPolicy, blockTimeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.queueOverflowPolicy = policy
+		opts.queueBlockTimeout = blockTimeout
+	}
+}
+
+// pushRequestWithoutLocking adds the request to the queue and reserves its funds, considering the queue overflow policy.
+// the lock is temporarily released while waiting for room in the queue if the block policy is used.
+// write lock must be acquired outside.
+func (f *Faucet) pushRequestWithoutLocking(request *queueItem) error {
+	// reserve the request before it is added to the queue, so it is known if it gets processed immediately
+	f.reserveRequestWithoutLocking(request)
+	f.queueMap[request.Bech32] = request
+	f.trackPendingRequestWithoutLocking(request)
+
+	if f.queue.tryPush(request) {
+		return nil
+	}
+
+	// queue is full
+	switch f.opts.queueOverflowPolicy {
+	case QueueOverflowPolicyDropOldest:
+		if droppedRequest := f.queue.dropOldest(); droppedRequest != nil {
+			f.LogDebugf("queue is full, dropping the oldest request of %s", droppedRequest.Bech32)
+			f.failRequestWithoutLocking(droppedRequest, ErrRequestDropped)
+		}
+
+		if f.queue.tryPush(request) {
+			return nil
+		}
+
+	case QueueOverflowPolicyBlock:
+		f.Unlock()
+		ctx, cancel := context.WithTimeout(f.daemon.ContextStopped(), f.opts.queueBlockTimeout)
+		added := f.queue.waitPush(ctx, request)
+		cancel()
+		f.Lock()
+
+		if added {
+			return nil
+		}
+	}
+
+	// the request couldn't be added => release the reservation
+	f.clearRequestWithoutLocking(request)
+
+	return NewRequestError(ErrorCodeQueueFull, http.StatusServiceUnavailable, fmt.Sprintf("Faucet queue is full (%d requests). Please try again later!", f.opts.queueSize)).WithRetryAfter(f.opts.batchTimeout)
+}
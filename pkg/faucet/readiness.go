@@ -0,0 +1,80 @@
+package faucet
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// retryAfterBalanceNotInitialized is the suggested retry duration for clients if the initial balance is not computed yet.
+	retryAfterBalanceNotInitialized = 5 * time.Second
+)
+
+// the names of the readiness checks of the faucet.
+const (
+	ReadinessCheckNode    = "node"
+	ReadinessCheckBalance = "balance"
+	ReadinessCheckINX     = "inx"
+	ReadinessCheckSigner  = "signer"
+)
+
+// ReadinessCheck defines the result of a single readiness check.
+type ReadinessCheck struct {
+	// The name of the check.
+	Name string `json:"name"`
+	// Whether the check passed.
+	Ready bool `json:"ready"`
+	// The reason why the check failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReadinessResponse defines the response of a GET RouteFaucetReady REST API call.
+type ReadinessResponse struct {
+	// Whether the faucet is ready to serve requests.
+	Ready bool `json:"ready"`
+	// The results of the single checks.
+	Checks []*ReadinessCheck `json:"checks"`
+}
+
+// IsBalanceInitialized returns true if the initial balance of the faucet was computed.
+func (f *Faucet) IsBalanceInitialized() bool {
+	return f.balanceInitialized.Load()
+}
+
+// checkBalanceInitialized returns an error if the initial balance of the faucet is not computed yet.
+// Requests are rejected until then, because they would fail with misleading "not enough funds" errors.
+func (f *Faucet) checkBalanceInitialized() error {
+	if !f.IsBalanceInitialized() {
+		return NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "Faucet is starting up. Please try again later!").WithRetryAfter(retryAfterBalanceNotInitialized)
+	}
+
+	return nil
+}
+
+// Readiness checks if the faucet is ready to serve requests.
+// The node must be synced, the initial balance computed, the INX streams connected and the signer loaded.
+func (f *Faucet) Readiness() *ReadinessResponse {
+	response := &ReadinessResponse{
+		Ready:  true,
+		Checks: make([]*ReadinessCheck, 0, 4),
+	}
+
+	addCheck := func(name string, ready bool, reason string) {
+		check := &ReadinessCheck{
+			Name:  name,
+			Ready: ready,
+		}
+		if !ready {
+			check.Reason = reason
+			response.Ready = false
+		}
+		response.Checks = append(response.Checks, check)
+	}
+
+	addCheck(ReadinessCheckNode, f.node.IsNodeHealthy(), "the node is not synchronized/healthy")
+	addCheck(ReadinessCheckBalance, f.IsBalanceInitialized(), "the initial balance of the faucet is not computed yet")
+	addCheck(ReadinessCheckINX, f.INXConnectionState().Connected, "at least one INX stream is reconnecting")
+	addCheck(ReadinessCheckSigner, f.addressSigner != nil, "the signer of the faucet address is not loaded")
+
+	return response
+}
@@ -0,0 +1,193 @@
+package faucet
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/hexutil"
+)
+
+const (
+	// RemoteSignerRoutePublicKey is the route of the signing service that returns the Ed25519 public key.
+	RemoteSignerRoutePublicKey = "/public-key"
+	// RemoteSignerRouteSign is the route of the signing service that signs a message with the private key.
+	RemoteSignerRouteSign = "/sign"
+)
+
+// RemoteSignerPublicKeyResponse is the response of the signing service to a public key request.
+type RemoteSignerPublicKeyResponse struct {
+	// The hex encoded Ed25519 public key.
+	PublicKey string `json:"publicKey"`
+}
+
+// RemoteSignerSignRequest is the request to the signing service to sign a message.
+type RemoteSignerSignRequest struct {
+	// The hex encoded message.
+	Message string `json:"message"`
+}
+
+// RemoteSignerSignResponse is the response of the signing service to a sign request.
+type RemoteSignerSignResponse struct {
+	// The hex encoded Ed25519 signature of the message.
+	Signature string `json:"signature"`
+}
+
+// RemoteAddressSigner is an AddressSigner that delegates the signing to an external signing service,
+// so the host of the faucet never holds the private key.
+// The service holds a single Ed25519 key, every returned signature is verified with its public key.
+type RemoteAddressSigner struct {
+	httpClient *http.Client
+	// the URL of the signing service.
+	baseURL   string
+	publicKey ed25519.PublicKey
+	address   *iotago.Ed25519Address
+}
+
+var _ iotago.AddressSigner = &RemoteAddressSigner{}
+
+// NewRemoteAddressSigner creates a new RemoteAddressSigner and queries the public key of the signing service.
+// The HTTP client defines the timeout and the TLS configuration of the requests, e.g. for mutual TLS.
+func NewRemoteAddressSigner(ctx context.Context, baseURL string, httpClient *http.Client) (*RemoteAddressSigner, error) {
+	s := &RemoteAddressSigner{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+
+	response := &RemoteSignerPublicKeyResponse{}
+	if err := s.do(ctx, http.MethodGet, RemoteSignerRoutePublicKey, nil, response); err != nil {
+		return nil, ierrors.Wrap(err, "failed to query the public key of the signing service")
+	}
+
+	publicKey, err := hexutil.DecodeHex(response.PublicKey)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to decode the public key of the signing service")
+	}
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ierrors.Errorf("the public key of the signing service has a wrong length: %d", len(publicKey))
+	}
+
+	s.publicKey = publicKey
+	s.address = iotago.Ed25519AddressFromPubKey(publicKey)
+
+	return s, nil
+}
+
+// Address returns the Ed25519 address of the key held by the signing service.
+func (s *RemoteAddressSigner) Address() *iotago.Ed25519Address {
+	return s.address
+}
+
+// do sends a request to the signing service and decodes the response into result.
+func (s *RemoteAddressSigner) do(ctx context.Context, method string, route string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return ierrors.Wrap(err, "unable to marshal request")
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+route, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ierrors.Wrap(err, "unable to read response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return ierrors.Errorf("signing service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(responseBytes)))
+	}
+
+	if err := json.Unmarshal(responseBytes, result); err != nil {
+		return ierrors.Wrap(err, "unable to unmarshal response")
+	}
+
+	return nil
+}
+
+// checkAddress returns an error if the key of the signing service doesn't belong to the given address.
+func (s *RemoteAddressSigner) checkAddress(addr iotago.Address) error {
+	switch address := addr.(type) {
+	case *iotago.Ed25519Address:
+		if address.Equal(s.address) {
+			return nil
+		}
+
+	case *iotago.RestrictedAddress:
+		return s.checkAddress(address.Address)
+
+	default:
+		return ierrors.WithMessagef(iotago.ErrAddressKeysNotMapped, "address type %T is not supported by the remote signer", addr)
+	}
+
+	return ierrors.WithMessagef(iotago.ErrAddressKeysNotMapped, "the key of the signing service doesn't belong to address %s", addr)
+}
+
+// SignerUIDForAddress returns the signer unique identifier for a given address.
+func (s *RemoteAddressSigner) SignerUIDForAddress(addr iotago.Address) (iotago.Identifier, error) {
+	if err := s.checkAddress(addr); err != nil {
+		return iotago.EmptyIdentifier, err
+	}
+
+	// the UID is the blake2b 256 hash of the public key
+	return iotago.IdentifierFromData(s.publicKey), nil
+}
+
+// Sign signs the message with the key of the signing service.
+func (s *RemoteAddressSigner) Sign(addr iotago.Address, msg []byte) (iotago.Signature, error) {
+	if err := s.checkAddress(addr); err != nil {
+		return nil, err
+	}
+
+	// the timeout of the request is defined by the HTTP client
+	response := &RemoteSignerSignResponse{}
+	if err := s.do(context.Background(), http.MethodPost, RemoteSignerRouteSign, &RemoteSignerSignRequest{Message: hexutil.EncodeHex(msg)}, response); err != nil {
+		return nil, ierrors.Wrap(err, "failed to sign the message with the signing service")
+	}
+
+	signature, err := hexutil.DecodeHex(response.Signature)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to decode the signature of the signing service")
+	}
+
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(s.publicKey, msg, signature) {
+		return nil, ierrors.Errorf("the signing service returned an invalid signature for public key %s", hexutil.EncodeHex(s.publicKey))
+	}
+
+	ed25519Sig := &iotago.Ed25519Signature{}
+	copy(ed25519Sig.Signature[:], signature)
+	copy(ed25519Sig.PublicKey[:], s.publicKey)
+
+	return ed25519Sig, nil
+}
+
+// EmptySignatureForAddress returns an empty signature for the given address.
+func (s *RemoteAddressSigner) EmptySignatureForAddress(addr iotago.Address) (iotago.Signature, error) {
+	if err := s.checkAddress(addr); err != nil {
+		return nil, err
+	}
+
+	return &iotago.Ed25519Signature{}, nil
+}
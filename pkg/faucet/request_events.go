@@ -0,0 +1,60 @@
+package faucet
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+var (
+	// ErrFaucetNotEnoughFunds is the reason of a failed request if the faucet doesn't hold enough funds for the payout.
+	ErrFaucetNotEnoughFunds = ierrors.New("faucet does not hold enough funds")
+	// ErrRequestDropped is the reason of a failed request if it was dropped from the full queue for a newer request.
+	ErrRequestDropped = ierrors.New("request was dropped from the full queue")
+)
+
+// ProcessedRequest holds info about a request whose payout was confirmed.
+type ProcessedRequest struct {
+	// The ID of the block that contained the transaction of the payout.
+	BlockID iotago.BlockID
+	// The ID of the transaction of the payout.
+	TransactionID iotago.TransactionID
+	// The payout of the request.
+	Payout *Payout
+}
+
+// FailedRequest holds info about a queued request that was dropped without a payout.
+type FailedRequest struct {
+	// The payout the request would have received.
+	Payout *Payout
+	// The reason why the request failed.
+	Reason error
+}
+
+// BalanceChange holds the funds of the faucet before and after they changed.
+type BalanceChange struct {
+	OldBaseTokens iotago.BaseToken
+	NewBaseTokens iotago.BaseToken
+	OldMana       iotago.Mana
+	NewMana       iotago.Mana
+}
+
+// failRequestWithoutLocking drops the queued request and triggers the RequestFailed event.
+// write lock must be acquired outside.
+func (f *Faucet) failRequestWithoutLocking(request *queueItem, reason error) {
+	f.clearRequestWithoutLocking(request)
+	f.Events.RequestFailed.Trigger(&FailedRequest{
+		Payout: newPayout(request),
+		Reason: reason,
+	})
+}
+
+// triggerProcessedRequests triggers the RequestProcessed event for every payout of the confirmed transaction.
+func (f *Faucet) triggerProcessedRequests(confirmedTx *ConfirmedTransaction) {
+	for _, payout := range confirmedTx.Payouts {
+		f.Events.RequestProcessed.Trigger(&ProcessedRequest{
+			BlockID:       confirmedTx.BlockID,
+			TransactionID: confirmedTx.TransactionID,
+			Payout:        payout,
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package faucet
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestPriority is the priority of a request in the queue of the faucet.
+// Requests with a higher priority are processed first, requests with the same priority in order of arrival.
+type RequestPriority int
+
+const (
+	// RequestPriorityAnonymous is the priority of plain anonymous requests.
+	RequestPriorityAnonymous RequestPriority = iota
+	// RequestPriorityAuthenticated is the priority of requests of authenticated clients.
+	RequestPriorityAuthenticated
+	// RequestPriorityRetry is the priority of requests that were already taken from the queue and need to be processed again.
+	RequestPriorityRetry
+
+	requestPriorityCount = int(RequestPriorityRetry) + 1
+)
+
+// requestQueue is a bounded queue of faucet requests with a FIFO queue per priority.
+// It is safe for concurrent use.
+type requestQueue struct {
+	mutex    sync.Mutex
+	tiers    [requestPriorityCount][]*queueItem
+	length   int
+	capacity int
+
+	// signaled if a request was added.
+	added chan struct{}
+	// signaled if a request was removed.
+	removed chan struct{}
+}
+
+func newRequestQueue(capacity int) *requestQueue {
+	return &requestQueue{
+		capacity: capacity,
+		added:    make(chan struct{}, 1),
+		removed:  make(chan struct{}, 1),
+	}
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+		// already signaled
+	}
+}
+
+func (q *requestQueue) pushWithoutLocking(request *queueItem) {
+	priority := min(max(int(request.Priority), 0), requestPriorityCount-1)
+
+	q.tiers[priority] = append(q.tiers[priority], request)
+	q.length++
+
+	signal(q.added)
+}
+
+// tryPush adds the request to the queue. It returns false if the queue is full.
+func (q *requestQueue) tryPush(request *queueItem) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.length >= q.capacity {
+		return false
+	}
+	q.pushWithoutLocking(request)
+
+	return true
+}
+
+// forcePush adds the request to the queue even if the queue is full.
+// It is used for requests that were already accepted before, so they are not lost.
+func (q *requestQueue) forcePush(request *queueItem) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pushWithoutLocking(request)
+}
+
+// waitPush adds the request to the queue and waits for room in the queue if it is full.
+// It returns false if the context is done before the request could be added.
+func (q *requestQueue) waitPush(ctx context.Context, request *queueItem) bool {
+	for {
+		if q.tryPush(request) {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-q.removed:
+		}
+	}
+}
+
+// tryPop removes the request with the highest priority from the queue. It returns nil if the queue is empty.
+func (q *requestQueue) tryPop() *queueItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for priority := requestPriorityCount - 1; priority >= 0; priority-- {
+		if request := q.popWithoutLocking(priority); request != nil {
+			return request
+		}
+	}
+
+	return nil
+}
+
+// dropOldest removes the oldest request with the lowest priority from the queue. It returns nil if the queue is empty.
+// requests with the retry priority are never dropped.
+func (q *requestQueue) dropOldest() *queueItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for priority := range int(RequestPriorityRetry) {
+		if request := q.popWithoutLocking(priority); request != nil {
+			return request
+		}
+	}
+
+	return nil
+}
+
+func (q *requestQueue) popWithoutLocking(priority int) *queueItem {
+	tier := q.tiers[priority]
+	if len(tier) == 0 {
+		return nil
+	}
+
+	request := tier[0]
+	tier[0] = nil
+	q.tiers[priority] = tier[1:]
+	q.length--
+
+	signal(q.removed)
+
+	return request
+}
+
+// remove removes the given request from the queue. It returns false if the request is not in the queue.
+func (q *requestQueue) remove(request *queueItem) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for priority, tier := range q.tiers {
+		for i, queuedRequest := range tier {
+			if queuedRequest != request {
+				continue
+			}
+
+			copy(tier[i:], tier[i+1:])
+			tier[len(tier)-1] = nil
+			q.tiers[priority] = tier[:len(tier)-1]
+			q.length--
+
+			signal(q.removed)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// position returns the 1-based position of the given request in the processing order of the queue.
+// It returns 0 if the request is not in the queue.
+func (q *requestQueue) position(request *queueItem) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var ahead int
+	for priority := requestPriorityCount - 1; priority >= 0; priority-- {
+		for i, queuedRequest := range q.tiers[priority] {
+			if queuedRequest == request {
+				return ahead + i + 1
+			}
+		}
+		ahead += len(q.tiers[priority])
+	}
+
+	return 0
+}
+
+// drain removes all requests from the queue, ordered by priority.
+func (q *requestQueue) drain() []*queueItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	requests := make([]*queueItem, 0, q.length)
+	for priority := requestPriorityCount - 1; priority >= 0; priority-- {
+		requests = append(requests, q.tiers[priority]...)
+		q.tiers[priority] = nil
+	}
+	q.length = 0
+
+	signal(q.removed)
+
+	return requests
+}
+
+// len returns the amount of requests in the queue.
+func (q *requestQueue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.length
+}
@@ -0,0 +1,86 @@
+package faucet
+
+import (
+	"net/http"
+)
+
+// RequestState defines the processing state of a request that is known to the faucet.
+type RequestState string
+
+const (
+	// RequestStateQueued is the state of a request that waits in the queue.
+	RequestStateQueued RequestState = "queued"
+	// RequestStateProcessing is the state of a request that was taken from the queue for the next transaction.
+	RequestStateProcessing RequestState = "processing"
+	// RequestStatePending is the state of a request that is part of a transaction that is not accepted yet.
+	RequestStatePending RequestState = "pending"
+)
+
+// RequestStatusResponse defines the status of a request of the faucet.
+type RequestStatusResponse struct {
+	// The bech32 address of the request.
+	Address string `json:"address"`
+	// The explorer link of the address, it is omitted if no explorer URL is configured.
+	AddressURL string `json:"addressUrl,omitempty"`
+	// The processing state of the request.
+	State RequestState `json:"state"`
+	// The position of the request in the queue, it is omitted if the request is not queued.
+	Position int `json:"position,omitempty"`
+	// The number of waiting requests in the queue.
+	WaitingRequests int `json:"waitingRequests"`
+	// The ID of the transaction that pays out the request, it is omitted if the request is not pending.
+	TransactionID string `json:"transactionId,omitempty"`
+	// The ID of the block that contains the transaction, it is omitted if the request is not pending.
+	BlockID string `json:"blockId,omitempty"`
+	// The explorer link of the transaction, it is omitted if the request is not pending or no explorer URL is configured.
+	TransactionURL string `json:"transactionUrl,omitempty"`
+	// The explorer link of the block, it is omitted if the request is not pending or no explorer URL is configured.
+	BlockURL string `json:"blockUrl,omitempty"`
+}
+
+// RequestStatus returns the processing state of the request of the given address.
+// Requests are only known until their transaction is accepted, afterwards the funds are on the address.
+func (f *Faucet) RequestStatus(bech32Addr string) (*RequestStatusResponse, error) {
+	bech32Addr = f.canonicalBech32(bech32Addr)
+
+	f.RLock()
+	defer f.RUnlock()
+
+	// requests in the shared queue are only known to the instance that issues the transactions
+	request, exists := f.queueMap[bech32Addr]
+	if !exists {
+		return nil, NewRequestError(ErrorCodeNotFound, http.StatusNotFound, "No request found for the given address, it was either paid out already or never enqueued.")
+	}
+
+	response := &RequestStatusResponse{
+		Address:         bech32Addr,
+		AddressURL:      f.ExplorerURL(ExplorerLinkKindAddress, bech32Addr),
+		State:           RequestStateProcessing,
+		WaitingRequests: len(f.queueMap),
+	}
+
+	if position := f.queue.position(request); position > 0 {
+		response.State = RequestStateQueued
+		response.Position = position
+
+		return response, nil
+	}
+
+	for _, pendingTx := range f.pendingTransactions {
+		for _, pendingRequest := range pendingTx.QueuedItems {
+			if pendingRequest != request {
+				continue
+			}
+
+			response.State = RequestStatePending
+			response.TransactionID = pendingTx.TransactionID.ToHex()
+			response.BlockID = pendingTx.BlockID.ToHex()
+			response.TransactionURL = f.ExplorerURL(ExplorerLinkKindTransaction, response.TransactionID)
+			response.BlockURL = f.ExplorerURL(ExplorerLinkKindBlock, response.BlockID)
+
+			return response, nil
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,46 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithRequestableAmountRange defines the bounds of the amount of base tokens a basic request can ask for.
+// Requested amounts outside the bounds are clamped to them. A maximum of zero disables requesting an amount.
+func WithRequestableAmountRange(minAmount iotago.BaseToken, maxAmount iotago.BaseToken) Option {
+	return func(opts *Options) {
+		opts.requestableAmountMin = minAmount
+		opts.requestableAmountMax = maxAmount
+	}
+}
+
+// requestedAmount parses the amount of base tokens a request asks for and clamps it to the configured bounds.
+func (f *Faucet) requestedAmount(requestType RequestType, amount string) (iotago.BaseToken, error) {
+	if f.opts.requestableAmountMax == 0 {
+		return 0, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "Requesting a specific amount is not enabled on this faucet.")
+	}
+
+	if requestType != RequestTypeBasic {
+		return 0, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("An amount can't be requested for requests of type \"%s\".", requestType))
+	}
+
+	baseTokenAmount, err := f.ParseBaseTokens(amount)
+	if err != nil {
+		return 0, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid amount provided! %s", err.Error()))
+	}
+
+	return min(max(baseTokenAmount, f.opts.requestableAmountMin), f.opts.requestableAmountMax), nil
+}
+
+// requestedPayoutAmount returns the payout of a request that asked for the given amount,
+// based on the amount that results from the funds on the target address.
+// The requested amount replaces the full amount, addresses that receive less, e.g. the small amount, receive at most that.
+func (f *Faucet) requestedPayoutAmount(requestedAmount iotago.BaseToken, targetAmount iotago.BaseToken) iotago.BaseToken {
+	if targetAmount >= f.RuntimeParameters().BaseTokenAmount {
+		return requestedAmount
+	}
+
+	return min(requestedAmount, targetAmount)
+}
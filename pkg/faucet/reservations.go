@@ -0,0 +1,192 @@
+package faucet
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// reservation are the funds that are reserved for a single request.
+type reservation struct {
+	baseTokens iotago.BaseToken
+	mana       iotago.Mana
+}
+
+// ReservationStats describe which part of the funds of the faucet is reserved for queued and pending requests.
+type ReservationStats struct {
+	// The base tokens of the faucet that can be used for payouts.
+	TotalBaseTokens iotago.BaseToken
+	// The base tokens that are reserved for queued and pending requests.
+	ReservedBaseTokens iotago.BaseToken
+	// The base tokens that can be reserved for new requests.
+	AvailableBaseTokens iotago.BaseToken
+	// The stored mana of the faucet.
+	TotalMana iotago.Mana
+	// The mana that is reserved for queued and pending requests.
+	ReservedMana iotago.Mana
+	// The mana that can be reserved for new requests.
+	AvailableMana iotago.Mana
+	// The amount of requests with a reservation.
+	Reservations int
+}
+
+// reservationLedger tracks which part of the funds of the faucet is reserved for queued and pending requests.
+// the total funds are updated every time the outputs of the faucet are collected,
+// the reservations are added when a request is queued and removed when it is cleared.
+// it is guarded by the lock of the faucet.
+type reservationLedger struct {
+	totalBaseTokens    iotago.BaseToken
+	totalMana          iotago.Mana
+	reservedBaseTokens iotago.BaseToken
+	reservedMana       iotago.Mana
+	// the reservations per address (bech32).
+	reservations map[string]*reservation
+}
+
+func newReservationLedger() *reservationLedger {
+	return &reservationLedger{
+		reservations: make(map[string]*reservation),
+	}
+}
+
+// setTotal sets the funds of the faucet that can be used for payouts.
+func (l *reservationLedger) setTotal(baseTokens iotago.BaseToken, mana iotago.Mana) {
+	l.totalBaseTokens = baseTokens
+	l.totalMana = mana
+}
+
+// availableBaseTokens returns the base tokens that are not reserved.
+// the reservations can exceed the total funds if the funds of the faucet decreased, in which case 0 is returned.
+func (l *reservationLedger) availableBaseTokens() iotago.BaseToken {
+	if l.reservedBaseTokens >= l.totalBaseTokens {
+		return 0
+	}
+
+	return l.totalBaseTokens - l.reservedBaseTokens
+}
+
+// availableMana returns the mana that is not reserved.
+func (l *reservationLedger) availableMana() iotago.Mana {
+	if l.reservedMana >= l.totalMana {
+		return 0
+	}
+
+	return l.totalMana - l.reservedMana
+}
+
+// reserve reserves the given funds for the request of the given address.
+// an existing reservation of the address is replaced.
+func (l *reservationLedger) reserve(bech32Addr string, baseTokens iotago.BaseToken, mana iotago.Mana) {
+	l.release(bech32Addr)
+
+	l.reservations[bech32Addr] = &reservation{
+		baseTokens: baseTokens,
+		mana:       mana,
+	}
+	l.reservedBaseTokens += baseTokens
+	l.reservedMana += mana
+}
+
+// release removes the reservation of the given address.
+func (l *reservationLedger) release(bech32Addr string) {
+	existing, exists := l.reservations[bech32Addr]
+	if !exists {
+		return
+	}
+
+	delete(l.reservations, bech32Addr)
+	l.reservedBaseTokens -= existing.baseTokens
+	l.reservedMana -= existing.mana
+}
+
+// checkInvariants checks that the reserved funds match the sum of the reservations.
+func (l *reservationLedger) checkInvariants() error {
+	var reservedBaseTokens iotago.BaseToken
+	var reservedMana iotago.Mana
+	for _, existing := range l.reservations {
+		reservedBaseTokens += existing.baseTokens
+		reservedMana += existing.mana
+	}
+
+	if reservedBaseTokens != l.reservedBaseTokens || reservedMana != l.reservedMana {
+		return ierrors.Errorf("reserved funds don't match the reservations, base tokens: %d != %d, mana: %d != %d", l.reservedBaseTokens, reservedBaseTokens, l.reservedMana, reservedMana)
+	}
+
+	return nil
+}
+
+func (l *reservationLedger) stats() *ReservationStats {
+	return &ReservationStats{
+		TotalBaseTokens:     l.totalBaseTokens,
+		ReservedBaseTokens:  l.reservedBaseTokens,
+		AvailableBaseTokens: l.availableBaseTokens(),
+		TotalMana:           l.totalMana,
+		ReservedMana:        l.reservedMana,
+		AvailableMana:       l.availableMana(),
+		Reservations:        len(l.reservations),
+	}
+}
+
+// ReservationStats returns which part of the funds of the faucet is reserved for queued and pending requests.
+func (f *Faucet) ReservationStats() *ReservationStats {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.reservations.stats()
+}
+
+// reserveRequestWithoutLocking reserves the funds for the given request.
+// write lock must be acquired outside.
+func (f *Faucet) reserveRequestWithoutLocking(request *queueItem) {
+	f.reservations.reserve(request.Bech32, request.BaseTokenAmount, f.requestManaAmount(request))
+}
+
+// setFaucetFundsWithoutLocking updates the total funds of the faucet from its unspent outputs and checks the reservations.
+// write lock must be acquired outside.
+func (f *Faucet) setFaucetFundsWithoutLocking(unspentOutputs []UTXOBasicOutput, balance iotago.BaseToken) {
+	storedMana, potentialMana, err := f.calculateUnboundMana(unspentOutputs)
+	if err != nil {
+		f.logSoftError(err)
+
+		// fall back to the stored mana without decay
+		storedMana, potentialMana = 0, 0
+		for _, output := range unspentOutputs {
+			storedMana += output.Output.Mana
+		}
+	}
+	oldBaseTokens, oldMana := f.reservations.totalBaseTokens, f.reservations.totalMana
+	f.reservations.setTotal(balance, storedMana)
+	f.potentialMana = potentialMana
+
+	if oldBaseTokens != balance || oldMana != storedMana {
+		f.Events.BalanceChanged.Trigger(&BalanceChange{
+			OldBaseTokens: oldBaseTokens,
+			NewBaseTokens: balance,
+			OldMana:       oldMana,
+			NewMana:       storedMana,
+		})
+	}
+
+	if err := f.checkReservationsWithoutLocking(); err != nil {
+		f.logSoftError(err)
+	}
+}
+
+// checkReservationsWithoutLocking checks that the reservations are consistent with the queued requests.
+// read lock must be acquired outside.
+func (f *Faucet) checkReservationsWithoutLocking() error {
+	if err := f.reservations.checkInvariants(); err != nil {
+		return err
+	}
+
+	for bech32Addr := range f.queueMap {
+		if _, exists := f.reservations.reservations[bech32Addr]; !exists {
+			return ierrors.Errorf("queued request of %s has no reservation", bech32Addr)
+		}
+	}
+
+	if len(f.reservations.reservations) != len(f.queueMap) {
+		return ierrors.Errorf("amount of reservations doesn't match the queued requests: %d != %d", len(f.reservations.reservations), len(f.queueMap))
+	}
+
+	return nil
+}
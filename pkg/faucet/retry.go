@@ -0,0 +1,90 @@
+package faucet
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// SubmitErrorClass is the class of an error that occurred while submitting a faucet transaction.
+type SubmitErrorClass string
+
+const (
+	// SubmitErrorClassTimeout is the class of errors caused by timeouts.
+	SubmitErrorClassTimeout SubmitErrorClass = "timeout"
+	// SubmitErrorClassUnavailable is the class of errors caused by an unavailable or failing block issuer.
+	SubmitErrorClassUnavailable SubmitErrorClass = "unavailable"
+	// SubmitErrorClassRejected is the class of errors caused by the block issuer rejecting the payload.
+	SubmitErrorClassRejected SubmitErrorClass = "rejected"
+	// SubmitErrorClassUnknown is the class of all other errors.
+	SubmitErrorClassUnknown SubmitErrorClass = "unknown"
+)
+
+// isRetryable checks if a submission that failed with an error of this class can safely be retried with a new transaction.
+// After timeouts and unknown errors the block may still have been accepted, so a new transaction would conflict with it
+// and the requests of the batch could be paid out twice.
+func (c SubmitErrorClass) isRetryable() bool {
+	return c == SubmitErrorClassUnavailable || c == SubmitErrorClassRejected
+}
+
+// SubmitErrorClassifierFunc is a function that determines the class of an error that occurred while submitting a faucet transaction.
+type SubmitErrorClassifierFunc func(err error) SubmitErrorClass
+
+// DefaultSubmitErrorClassifier classifies timeouts and network errors.
+// All other errors are classified as unknown.
+func DefaultSubmitErrorClassifier(err error) SubmitErrorClass {
+	if ierrors.Is(err, context.DeadlineExceeded) {
+		return SubmitErrorClassTimeout
+	}
+
+	var netErr net.Error
+	if ierrors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return SubmitErrorClassTimeout
+		}
+
+		return SubmitErrorClassUnavailable
+	}
+
+	return SubmitErrorClassUnknown
+}
+
+// RetryPolicy defines how often and with which delay a failed submission is retried.
+type RetryPolicy struct {
+	// MaxRetries is the maximum amount of retries.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between two retries.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the delay that is randomly added or subtracted.
+	Jitter float64
+}
+
+// Backoff returns the delay before the given retry attempt (starting at 0).
+// The delay grows exponentially and is capped at the maximum backoff.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for range attempt {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			backoff = p.MaxBackoff
+
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		//nolint:gosec // we don't need crypto secure randomness for the jitter
+		backoff += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(backoff))
+	}
+
+	if backoff < 0 {
+		return 0
+	}
+
+	return backoff
+}
@@ -0,0 +1,291 @@
+package faucet
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// the maximum amount of requests that are kept to correlate new requests with.
+	riskMaxObservations = 10000
+	// the amount of assessments that are kept for the admin API.
+	riskMaxAssessments = 500
+	// the amount of requests from the same IP or subnet that are considered normal within the window.
+	riskTolerableRequestsPerIP     = 2
+	riskTolerableRequestsPerSubnet = 5
+	// the minimum amount of intervals between requests of the same IP to detect automated requests.
+	riskMinTimingSamples = 3
+	// the coefficient of variation of the intervals below which the requests are considered automated.
+	riskTimingVariationThreshold = 0.1
+)
+
+// RiskAction is the action that was taken for a request based on its risk score.
+type RiskAction string
+
+const (
+	// RiskActionAllow means that the request was processed as requested.
+	RiskActionAllow RiskAction = "allow"
+	// RiskActionDowngrade means that only the small amount is paid out.
+	RiskActionDowngrade RiskAction = "downgrade"
+	// RiskActionReject means that the request was rejected.
+	RiskActionReject RiskAction = "reject"
+)
+
+// RiskAssessment is the risk score of a single request and the factors it consists of.
+type RiskAssessment struct {
+	// The time of the request.
+	Timestamp time.Time `json:"timestamp"`
+	// The bech32 address of the request.
+	Address string `json:"address"`
+	// The IP address of the client.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	// The user agent of the client.
+	UserAgent string `json:"userAgent,omitempty"`
+	// The total risk score between 0 and 100.
+	Score int `json:"score"`
+	// The contribution of the single heuristics to the score.
+	Factors map[string]int `json:"factors,omitempty"`
+	// The action that was taken.
+	Action RiskAction `json:"action"`
+}
+
+// riskObservation is a previous request that new requests are correlated with.
+type riskObservation struct {
+	timestamp time.Time
+	ip        string
+	subnet    string
+	userAgent string
+	bech32    string
+}
+
+// RiskScorer is a RequestValidator that correlates requests by IP, subnet, user agent, timing and address reuse
+// to detect clusters of requests that are issued by the same party (Sybil attacks).
+// Requests with a high risk score only receive the small amount or are rejected.
+type RiskScorer struct {
+	window               time.Duration
+	downgradeScore       int
+	rejectScore          int
+	baseTokenAmountSmall iotago.BaseToken
+	clock                Clock
+
+	mutex        sync.Mutex
+	observations []*riskObservation
+	assessments  []*RiskAssessment
+}
+
+var _ RequestValidator = &RiskScorer{}
+
+// NewRiskScorer creates a new RiskScorer that correlates the requests within the given window.
+// A score of 0 disables the corresponding action. The clock defines the timing of the requests, it should be the clock of the faucet.
+func NewRiskScorer(window time.Duration, downgradeScore int, rejectScore int, baseTokenAmountSmall iotago.BaseToken, clock Clock) *RiskScorer {
+	return &RiskScorer{
+		window:               window,
+		downgradeScore:       downgradeScore,
+		rejectScore:          rejectScore,
+		baseTokenAmountSmall: baseTokenAmountSmall,
+		clock:                clock,
+		observations:         make([]*riskObservation, 0),
+		assessments:          make([]*RiskAssessment, 0),
+	}
+}
+
+// ValidateRequest scores the request and downgrades or rejects it if the score is too high.
+func (s *RiskScorer) ValidateRequest(request *ValidationRequest) (iotago.BaseToken, error) {
+	if request.Client == nil || request.Client.Authenticated {
+		// only anonymous requests via the HTTP API are scored
+		return request.BaseTokenAmount, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.clock.Now()
+	s.pruneWithoutLocking(now)
+
+	observation := &riskObservation{
+		timestamp: now,
+		ip:        request.Client.RemoteIP,
+		subnet:    riskSubnet(request.Client.RemoteIP),
+		userAgent: request.Client.UserAgent,
+		bech32:    request.Bech32,
+	}
+
+	assessment := &RiskAssessment{
+		Timestamp: now,
+		Address:   request.Bech32,
+		RemoteIP:  observation.ip,
+		UserAgent: observation.userAgent,
+		Factors:   s.scoreWithoutLocking(observation),
+		Action:    RiskActionAllow,
+	}
+	for _, score := range assessment.Factors {
+		assessment.Score += score
+	}
+	assessment.Score = min(assessment.Score, 100)
+
+	s.observations = append(s.observations, observation)
+	if len(s.observations) > riskMaxObservations {
+		s.observations = s.observations[len(s.observations)-riskMaxObservations:]
+	}
+
+	amount := request.BaseTokenAmount
+	var err error
+	switch {
+	case s.rejectScore > 0 && assessment.Score >= s.rejectScore:
+		assessment.Action = RiskActionReject
+		amount = 0
+		err = NewRequestError(ErrorCodeForbidden, http.StatusForbidden, "Your request was rejected by the abuse protection of the faucet.")
+	case s.downgradeScore > 0 && assessment.Score >= s.downgradeScore && amount > s.baseTokenAmountSmall:
+		assessment.Action = RiskActionDowngrade
+		amount = s.baseTokenAmountSmall
+	}
+
+	s.assessments = append(s.assessments, assessment)
+	if len(s.assessments) > riskMaxAssessments {
+		s.assessments = s.assessments[len(s.assessments)-riskMaxAssessments:]
+	}
+
+	return amount, err
+}
+
+// Assessments returns the most recent risk assessments, the newest first.
+func (s *RiskScorer) Assessments() []*RiskAssessment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	assessments := make([]*RiskAssessment, 0, len(s.assessments))
+	for i := len(s.assessments) - 1; i >= 0; i-- {
+		assessments = append(assessments, s.assessments[i])
+	}
+
+	return assessments
+}
+
+// pruneWithoutLocking removes the observations that are older than the window.
+// write lock must be acquired outside.
+func (s *RiskScorer) pruneWithoutLocking(now time.Time) {
+	cutoff := now.Add(-s.window)
+
+	index := 0
+	for index < len(s.observations) && s.observations[index].timestamp.Before(cutoff) {
+		index++
+	}
+	s.observations = s.observations[index:]
+}
+
+// scoreWithoutLocking returns the contribution of the single heuristics to the risk score of the given request.
+// write lock must be acquired outside.
+func (s *RiskScorer) scoreWithoutLocking(observation *riskObservation) map[string]int {
+	var requestsPerIP, requestsPerSubnet int
+	addressesPerIP := make(map[string]struct{})
+	ipsPerAddress := make(map[string]struct{})
+	ipsPerUserAgent := make(map[string]struct{})
+	var timestampsOfIP []time.Time
+
+	for _, previous := range s.observations {
+		if previous.ip == observation.ip {
+			requestsPerIP++
+			addressesPerIP[previous.bech32] = struct{}{}
+			timestampsOfIP = append(timestampsOfIP, previous.timestamp)
+		}
+		if observation.subnet != "" && previous.subnet == observation.subnet {
+			requestsPerSubnet++
+		}
+		if previous.bech32 == observation.bech32 && previous.ip != observation.ip {
+			ipsPerAddress[previous.ip] = struct{}{}
+		}
+		if observation.userAgent != "" && previous.userAgent == observation.userAgent && previous.ip != observation.ip {
+			ipsPerUserAgent[previous.ip] = struct{}{}
+		}
+	}
+
+	factors := make(map[string]int)
+	addFactor := func(name string, score int) {
+		if score > 0 {
+			factors[name] = score
+		}
+	}
+
+	// many requests from the same IP or the same subnet
+	addFactor("ipRequests", min(10*max(requestsPerIP-riskTolerableRequestsPerIP+1, 0), 30))
+	addFactor("subnetRequests", min(4*max(requestsPerSubnet-riskTolerableRequestsPerSubnet+1, 0), 20))
+
+	// a single IP that fills up many different addresses
+	addFactor("addressesPerIP", min(15*len(addressesPerIP), 30))
+
+	// a single address that is requested from many different IPs
+	addFactor("ipsPerAddress", min(10*len(ipsPerAddress), 30))
+
+	// the same uncommon user agent from different IPs of the same subnet points to a single operator
+	if observation.subnet != "" && requestsPerSubnet > 0 && len(ipsPerUserAgent) > 0 && !isBrowserUserAgent(observation.userAgent) {
+		addFactor("sharedUserAgent", min(5*len(ipsPerUserAgent), 15))
+	}
+
+	// scripts often don't send a user agent or use the default one of their HTTP library
+	switch {
+	case observation.userAgent == "":
+		addFactor("missingUserAgent", 15)
+	case !isBrowserUserAgent(observation.userAgent):
+		addFactor("scriptUserAgent", 10)
+	}
+
+	// requests in regular intervals are issued by a script
+	if isRegularTiming(append(timestampsOfIP, observation.timestamp)) {
+		addFactor("regularTiming", 25)
+	}
+
+	return factors
+}
+
+// riskSubnet returns the /24 subnet of an IPv4 address or the /48 subnet of an IPv6 address.
+func riskSubnet(remoteIP string) string {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return ""
+	}
+
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+
+	return ip.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
+
+// isBrowserUserAgent checks if the user agent looks like the one of a web browser.
+func isBrowserUserAgent(userAgent string) bool {
+	return strings.HasPrefix(userAgent, "Mozilla/")
+}
+
+// isRegularTiming checks if the intervals between the given timestamps are nearly constant.
+func isRegularTiming(timestamps []time.Time) bool {
+	if len(timestamps) < riskMinTimingSamples+1 {
+		return false
+	}
+
+	intervals := make([]float64, 0, len(timestamps)-1)
+	var sum float64
+	for i := 1; i < len(timestamps); i++ {
+		interval := timestamps[i].Sub(timestamps[i-1]).Seconds()
+		intervals = append(intervals, interval)
+		sum += interval
+	}
+
+	mean := sum / float64(len(intervals))
+	if mean <= 0 {
+		return true
+	}
+
+	var variance float64
+	for _, interval := range intervals {
+		variance += (interval - mean) * (interval - mean)
+	}
+	variance /= float64(len(intervals))
+
+	return math.Sqrt(variance)/mean < riskTimingVariationThreshold
+}
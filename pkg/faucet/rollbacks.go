@@ -0,0 +1,147 @@
+package faucet
+
+import (
+	"context"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+)
+
+// restoreRequestsWithoutLocking tracks the requests of a rolled back transaction again, as if they were still queued.
+// Requests of addresses that already have a new request in the queue are dropped.
+// write lock must be acquired outside.
+func (f *Faucet) restoreRequestsWithoutLocking(requests []*queueItem) []*queueItem {
+	publicRequests, airdropRequests := splitAirdropRequests(requests)
+	f.airdrops.reopen(airdropRequests)
+
+	restoredRequests := make([]*queueItem, 0, len(requests))
+	for _, request := range publicRequests {
+		if _, exists := f.queueMap[request.Bech32]; exists {
+			f.LogDebugf("dropping request of %s of rolled back transaction, the address has a new request", request.Bech32)
+
+			continue
+		}
+
+		f.reserveRequestWithoutLocking(request)
+		f.queueMap[request.Bech32] = request
+		f.trackPendingRequestWithoutLocking(request)
+		restoredRequests = append(restoredRequests, request)
+	}
+
+	return append(restoredRequests, airdropRequests...)
+}
+
+// handleRolledBackTransactionWithoutLocking handles an accepted faucet transaction that is no longer included after a chain switch.
+// If its inputs are still unspent, the transaction is reissued, otherwise the requests are added to the queue again.
+// write lock must be acquired outside.
+func (f *Faucet) handleRolledBackTransactionWithoutLocking(ctx context.Context, rolledBackTx *pendingTransaction) {
+	delete(f.unfinalizedTransactions, rolledBackTx.TransactionID)
+
+	f.logSoftError(newSoftError(SoftErrorCategoryConflict, ierrors.Errorf("faucet transaction was rolled back, txID: %s", rolledBackTx.TransactionID)))
+
+	restoredRequests := f.restoreRequestsWithoutLocking(rolledBackTx.QueuedItems)
+
+	inputsUnspent := func() bool {
+		unspentOutputs, _, err := f.collectUnlockableFaucetOutputsAndBalanceWithoutLocking()
+		if err != nil {
+			f.logSoftError(ierrors.Wrap(err, "failed to collect faucet outputs to validate the inputs of the rolled back transaction"))
+
+			return false
+		}
+		// the inputs that are consumed by a pending transaction can't be reused
+		unspentOutputs = f.filterPendingOutputsWithoutLocking(unspentOutputs)
+
+		unspentOutputIDs := make(map[iotago.OutputID]struct{}, len(unspentOutputs))
+		for _, output := range unspentOutputs {
+			unspentOutputIDs[output.OutputID] = struct{}{}
+		}
+
+		for _, consumedInput := range rolledBackTx.ConsumedInputs {
+			if _, exists := unspentOutputIDs[consumedInput]; !exists {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// the transaction can only be reissued if its source address has no pending transaction, which may consume the same inputs,
+	// and none of its inputs is consumed by another pending transaction
+	if !f.isSourceAddressPendingWithoutLocking(rolledBackTx.SourceAddressIndex) && !f.submitRetryPending && rolledBackTx.SignedTransaction != nil && inputsUnspent() {
+		reissuedTx := *rolledBackTx
+		reissuedTx.QueuedItems = restoredRequests
+		reissuedTx.Reattachments = 0
+		reissuedTx.Reissued = true
+		f.setPendingTransactionWithoutLocking(&reissuedTx)
+
+		if err := f.reattachPendingTransactionWithoutLocking(ctx, &reissuedTx); err != nil {
+			// reissuing failed => re-add the items to the queue and delete the pending transaction
+			f.logSoftError(ierrors.Wrap(err, "reissuing rolled back transaction failed"))
+			f.readdPendingRequestsWithoutLocking(&reissuedTx, err)
+		}
+
+		return
+	}
+
+	f.Events.TransactionFailed.Trigger(&FailedTransaction{
+		BlockID:       rolledBackTx.BlockID,
+		TransactionID: rolledBackTx.TransactionID,
+		Payouts:       pendingTransactionPayouts(rolledBackTx),
+		Reason:        ierrors.New("transaction was rolled back"),
+	})
+	f.readdRequestsWithoutLocking(restoredRequests)
+}
+
+// CheckUnfinalizedTransactions checks if the accepted faucet transactions that are not finalized yet are still included,
+// e.g. after the node switched to another chain. It should be called whenever the latest commitment changes.
+func (f *Faucet) CheckUnfinalizedTransactions(ctx context.Context) {
+	f.RLock()
+	unfinalizedTransactions := make([]*pendingTransaction, 0, len(f.unfinalizedTransactions))
+	for _, unfinalizedTx := range f.unfinalizedTransactions {
+		unfinalizedTransactions = append(unfinalizedTransactions, unfinalizedTx)
+	}
+	f.RUnlock()
+
+	var finalizedTransactions, rolledBackTransactions []*pendingTransaction
+	for _, unfinalizedTx := range unfinalizedTransactions {
+		metadata, err := f.node.FetchTransactionMetadata(unfinalizedTx.TransactionID)
+		if err != nil {
+			// the transaction is checked again with the next commitment
+			f.LogDebugf("failed to fetch metadata of unfinalized transaction, txID: %s, error: %s", unfinalizedTx.TransactionID, err)
+
+			continue
+		}
+
+		switch {
+		case metadata == nil:
+			// the transaction is unknown to the node
+			rolledBackTransactions = append(rolledBackTransactions, unfinalizedTx)
+
+		case metadata.TransactionState == api.TransactionStateFinalized:
+			finalizedTransactions = append(finalizedTransactions, unfinalizedTx)
+
+		case metadata.TransactionState == api.TransactionStateUnknown, metadata.TransactionState == api.TransactionStateFailed:
+			rolledBackTransactions = append(rolledBackTransactions, unfinalizedTx)
+		}
+	}
+
+	if len(finalizedTransactions) == 0 && len(rolledBackTransactions) == 0 {
+		return
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	for _, finalizedTx := range finalizedTransactions {
+		delete(f.unfinalizedTransactions, finalizedTx.TransactionID)
+	}
+
+	for _, rolledBackTx := range rolledBackTransactions {
+		if _, exists := f.unfinalizedTransactions[rolledBackTx.TransactionID]; !exists {
+			continue
+		}
+
+		f.handleRolledBackTransactionWithoutLocking(ctx, rolledBackTx)
+	}
+}
@@ -0,0 +1,78 @@
+package faucet
+
+import (
+	"net/http"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// RuntimeParameters are the parameters of the faucet that can be changed while it is running.
+type RuntimeParameters struct {
+	// The amount of funds the requester receives.
+	BaseTokenAmount iotago.BaseToken
+	// The amount of funds the requester receives if the target address has more funds than the faucet amount and less than maximum.
+	BaseTokenAmountSmall iotago.BaseToken
+	// The maximum allowed amount of funds on the target address.
+	BaseTokenAmountMaxTarget iotago.BaseToken
+	// The amount of mana the requester receives.
+	ManaAmount iotago.Mana
+	// The maximum duration for collecting faucet batches.
+	BatchTimeout time.Duration
+}
+
+// RuntimeParameters returns the current values of the parameters that can be changed while the faucet is running.
+func (f *Faucet) RuntimeParameters() *RuntimeParameters {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.runtimeParametersWithoutLocking()
+}
+
+// runtimeParametersWithoutLocking returns the current values of the parameters that can be changed while the faucet is running.
+// read lock must be acquired outside.
+func (f *Faucet) runtimeParametersWithoutLocking() *RuntimeParameters {
+	return &RuntimeParameters{
+		BaseTokenAmount:          f.opts.baseTokenAmount,
+		BaseTokenAmountSmall:     f.opts.baseTokenAmountSmall,
+		BaseTokenAmountMaxTarget: f.opts.baseTokenAmountMaxTarget,
+		ManaAmount:               f.opts.manaAmount,
+		BatchTimeout:             f.opts.batchTimeout,
+	}
+}
+
+// SetRuntimeParameters validates the given parameters and applies all of them at once.
+// Queued requests keep the amounts they were enqueued with.
+func (f *Faucet) SetRuntimeParameters(params *RuntimeParameters) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	f.opts.baseTokenAmount = params.BaseTokenAmount
+	f.opts.baseTokenAmountSmall = params.BaseTokenAmountSmall
+	f.opts.baseTokenAmountMaxTarget = params.BaseTokenAmountMaxTarget
+	f.opts.manaAmount = params.ManaAmount
+	f.opts.batchTimeout = params.BatchTimeout
+
+	return nil
+}
+
+func (p *RuntimeParameters) validate() error {
+	switch {
+	case p.BaseTokenAmount == 0:
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "baseTokenAmount must be greater than zero")
+	case p.BaseTokenAmountSmall == 0:
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "baseTokenAmountSmall must be greater than zero")
+	case p.BaseTokenAmountSmall > p.BaseTokenAmount:
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "baseTokenAmountSmall must not be greater than baseTokenAmount")
+	case p.BaseTokenAmountMaxTarget < p.BaseTokenAmount:
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "baseTokenAmountMaxTarget must not be less than baseTokenAmount")
+	case p.BatchTimeout <= 0:
+		return NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "batchTimeout must be greater than zero")
+	}
+
+	return nil
+}
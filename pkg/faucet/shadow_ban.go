@@ -0,0 +1,257 @@
+package faucet
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShadowBanType defines which property of a request is matched by a shadow-ban rule.
+type ShadowBanType string
+
+const (
+	// ShadowBanTypeIP matches the originating IP address of the request, either exactly or by CIDR range.
+	ShadowBanTypeIP ShadowBanType = "ip"
+	// ShadowBanTypeAPIKey matches the API key of the client.
+	ShadowBanTypeAPIKey ShadowBanType = "apiKey"
+	// ShadowBanTypeAddress matches the bech32 target address by a glob pattern, e.g. "rms1qz*".
+	ShadowBanTypeAddress ShadowBanType = "address"
+)
+
+// ShadowBanRule is a rule whose matching requests are accepted, but never processed.
+type ShadowBanRule struct {
+	// The ID of the rule, it is derived from the type and the pattern.
+	ID string `json:"id"`
+	// The property of the request that is matched.
+	Type ShadowBanType `json:"type"`
+	// The pattern that is matched.
+	Pattern string `json:"pattern"`
+	// An optional note of the operator, e.g. why the rule was added.
+	Note string `json:"note,omitempty"`
+}
+
+// shadowBanStore holds the shadow-ban rules.
+type shadowBanStore struct {
+	mutex sync.RWMutex
+	rules map[string]*ShadowBanRule
+	// the parsed CIDR ranges of the IP rules by rule ID.
+	networks map[string]*net.IPNet
+}
+
+func newShadowBanStore() *shadowBanStore {
+	return &shadowBanStore{
+		rules:    make(map[string]*ShadowBanRule),
+		networks: make(map[string]*net.IPNet),
+	}
+}
+
+// shadowBanRuleID returns the ID of a rule with the given type and pattern.
+func shadowBanRuleID(banType ShadowBanType, pattern string) string {
+	hash := sha256.Sum256([]byte(string(banType) + ":" + pattern))
+
+	return hex.EncodeToString(hash[:8])
+}
+
+// normalizeShadowBanRule validates the given rule and returns a normalized copy with its ID
+// and the parsed network of IP rules.
+func normalizeShadowBanRule(rule *ShadowBanRule) (*ShadowBanRule, *net.IPNet, error) {
+	normalized := &ShadowBanRule{
+		Type:    rule.Type,
+		Pattern: strings.TrimSpace(rule.Pattern),
+		Note:    rule.Note,
+	}
+
+	if normalized.Pattern == "" {
+		return nil, nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "The pattern of a shadow-ban rule must not be empty.")
+	}
+
+	var network *net.IPNet
+	switch normalized.Type {
+	case ShadowBanTypeIP:
+		if ip := net.ParseIP(normalized.Pattern); ip != nil {
+			normalized.Pattern = ip.String()
+
+			break
+		}
+
+		_, ipNet, err := net.ParseCIDR(normalized.Pattern)
+		if err != nil {
+			return nil, nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid IP address or CIDR range \"%s\" provided!", normalized.Pattern))
+		}
+		normalized.Pattern = ipNet.String()
+		network = ipNet
+
+	case ShadowBanTypeAPIKey:
+
+	case ShadowBanTypeAddress:
+		// bech32 addresses are case-insensitive
+		normalized.Pattern = strings.ToLower(normalized.Pattern)
+		if _, err := path.Match(normalized.Pattern, ""); err != nil {
+			return nil, nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid address pattern \"%s\" provided!", normalized.Pattern))
+		}
+
+	default:
+		return nil, nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Invalid shadow-ban type \"%s\" provided!", normalized.Type))
+	}
+
+	normalized.ID = shadowBanRuleID(normalized.Type, normalized.Pattern)
+
+	return normalized, network, nil
+}
+
+// matches returns the rule that matches the given request or nil if it is not shadow-banned.
+func (s *shadowBanStore) matches(bech32Addr string, client *ClientMetadata) *ShadowBanRule {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if len(s.rules) == 0 {
+		return nil
+	}
+
+	var remoteIP net.IP
+	var apiKey string
+	if client != nil {
+		remoteIP = net.ParseIP(client.RemoteIP)
+		apiKey = client.APIKey
+	}
+	address := strings.ToLower(bech32Addr)
+
+	for id, rule := range s.rules {
+		switch rule.Type {
+		case ShadowBanTypeIP:
+			if remoteIP == nil {
+				continue
+			}
+			if network, isNetwork := s.networks[id]; isNetwork {
+				if network.Contains(remoteIP) {
+					return rule
+				}
+			} else if remoteIP.String() == rule.Pattern {
+				return rule
+			}
+
+		case ShadowBanTypeAPIKey:
+			if apiKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(rule.Pattern)) == 1 {
+				return rule
+			}
+
+		case ShadowBanTypeAddress:
+			if matched, err := path.Match(rule.Pattern, address); err == nil && matched {
+				return rule
+			}
+		}
+	}
+
+	return nil
+}
+
+// ShadowBanRules returns all shadow-ban rules, sorted by type and pattern.
+func (f *Faucet) ShadowBanRules() []*ShadowBanRule {
+	f.shadowBans.mutex.RLock()
+	defer f.shadowBans.mutex.RUnlock()
+
+	rules := make([]*ShadowBanRule, 0, len(f.shadowBans.rules))
+	for _, rule := range f.shadowBans.rules {
+		ruleCopy := *rule
+		rules = append(rules, &ruleCopy)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Type != rules[j].Type {
+			return rules[i].Type < rules[j].Type
+		}
+
+		return rules[i].Pattern < rules[j].Pattern
+	})
+
+	return rules
+}
+
+// SetShadowBanRules validates the given rules and replaces the current rules.
+func (f *Faucet) SetShadowBanRules(rules []*ShadowBanRule) error {
+	newRules := make(map[string]*ShadowBanRule, len(rules))
+	newNetworks := make(map[string]*net.IPNet)
+
+	for _, rule := range rules {
+		normalized, network, err := normalizeShadowBanRule(rule)
+		if err != nil {
+			return err
+		}
+
+		newRules[normalized.ID] = normalized
+		if network != nil {
+			newNetworks[normalized.ID] = network
+		}
+	}
+
+	f.shadowBans.mutex.Lock()
+	defer f.shadowBans.mutex.Unlock()
+
+	f.shadowBans.rules = newRules
+	f.shadowBans.networks = newNetworks
+
+	return nil
+}
+
+// AddShadowBanRule adds a shadow-ban rule or replaces the note of an existing rule with the same type and pattern.
+func (f *Faucet) AddShadowBanRule(rule *ShadowBanRule) (*ShadowBanRule, error) {
+	normalized, network, err := normalizeShadowBanRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	f.shadowBans.mutex.Lock()
+	defer f.shadowBans.mutex.Unlock()
+
+	f.shadowBans.rules[normalized.ID] = normalized
+	if network != nil {
+		f.shadowBans.networks[normalized.ID] = network
+	}
+
+	ruleCopy := *normalized
+
+	return &ruleCopy, nil
+}
+
+// RemoveShadowBanRule removes the shadow-ban rule with the given ID.
+// It returns false if the rule doesn't exist.
+func (f *Faucet) RemoveShadowBanRule(id string) bool {
+	f.shadowBans.mutex.Lock()
+	defer f.shadowBans.mutex.Unlock()
+
+	if _, exists := f.shadowBans.rules[id]; !exists {
+		return false
+	}
+
+	delete(f.shadowBans.rules, id)
+	delete(f.shadowBans.networks, id)
+
+	return true
+}
+
+// shadowBannedResponse returns a response that looks like the request was enqueued if the request is shadow-banned.
+// the request is never processed, the client can't distinguish it from an accepted request.
+func (f *Faucet) shadowBannedResponse(bech32Addr string, tag string, clientMetadata *ClientMetadata) *EnqueueResponse {
+	rule := f.shadowBans.matches(bech32Addr, clientMetadata)
+	if rule == nil {
+		return nil
+	}
+
+	f.LogDebugf("dropping shadow-banned request of %s, rule: %s (%s %s)", bech32Addr, rule.ID, rule.Type, rule.Pattern)
+
+	f.RLock()
+	waitingRequests := len(f.queueMap)
+	f.RUnlock()
+
+	return &EnqueueResponse{
+		Address:         bech32Addr,
+		WaitingRequests: waitingRequests + 1,
+		Tag:             tag,
+	}
+}
@@ -0,0 +1,262 @@
+package faucet
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	sharedQueuePopTimeout     = time.Second
+	sharedQueueRequestTimeout = 5 * time.Second
+)
+
+// SharedRequest is a faucet request in a queue that is shared between multiple faucet instances.
+type SharedRequest struct {
+	// The bech32 address.
+	Bech32 string `json:"address"`
+	// The amount of base tokens that will be paid out.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The sanitized tag of the request.
+	Tag string `json:"tag,omitempty"`
+	// The type of the request.
+	Type RequestType `json:"type"`
+	// The originating IP address of the request.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	// The time the request was enqueued.
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	// The priority of the request in the queue.
+	Priority RequestPriority `json:"priority,omitempty"`
+	// Whether the request receives no mana, because the target address already holds enough mana.
+	SkipMana bool `json:"skipMana,omitempty"`
+	// Whether the funds on the target address were not checked yet.
+	BalanceUnchecked bool `json:"balanceUnchecked,omitempty"`
+	// Whether the amount of base tokens was requested by the client.
+	AmountRequested bool `json:"amountRequested,omitempty"`
+	// The hash of the idempotency key the client sent.
+	IdempotencyKeyHash string `json:"idempotencyKeyHash,omitempty"`
+}
+
+// SharedQueue is a queue of faucet requests that is shared between multiple faucet instances.
+// All instances can add requests, but only the instance that issues the transactions drains the queue.
+type SharedQueue interface {
+	// Push adds a request to the queue. It returns false if the address is already in the queue.
+	// If maxPendingRequestsPerIP is greater than 0, it returns ErrTooManyPendingRequestsPerIP
+	// if the originating IP address already has that many requests that were not released yet.
+	Push(ctx context.Context, request *SharedRequest, maxPendingRequestsPerIP int) (bool, error)
+	// Pop removes the next request from the queue.
+	// It blocks until a request is available or the timeout is reached, in which case nil is returned.
+	Pop(ctx context.Context, timeout time.Duration) (*SharedRequest, error)
+	// Requeue adds a request that was already taken from the queue back to the front of the queue.
+	// The address of the request is still considered to be in the queue.
+	Requeue(ctx context.Context, request *SharedRequest) error
+	// Release allows new requests for the given address after its request was processed or dropped,
+	// and removes the request from the unconfirmed requests of its originating IP address.
+	Release(ctx context.Context, bech32Addr string) error
+	// Len returns the amount of requests in the queue.
+	Len(ctx context.Context) (int, error)
+}
+
+// WithSharedQueue sets a queue that is shared between multiple faucet instances.
+// Requests are added to the shared queue instead of the local queue.
+func WithSharedQueue(sharedQueue SharedQueue) Option {
+	return func(opts *Options) {
+		opts.sharedQueue = sharedQueue
+	}
+}
+
+// WithIssuanceEnabled defines whether this instance issues faucet transactions.
+// Only a single instance per faucet address may issue transactions.
+func WithIssuanceEnabled(issuanceEnabled bool) Option {
+	return func(opts *Options) {
+		opts.issuanceEnabled = issuanceEnabled
+	}
+}
+
+// enqueueShared adds a request to the shared queue.
+func (f *Faucet) enqueueShared(request *queueItem) (*EnqueueResponse, error) {
+	// the instance that issues the transactions reserves the funds when it takes the request from the shared queue,
+	// so only the available funds are checked here.
+	f.Lock()
+	if request.BaseTokenAmount > f.reservations.availableBaseTokens() {
+		f.Unlock()
+
+		return nil, NewRequestError(ErrorCodeFaucetNotEnoughFunds, http.StatusServiceUnavailable, "Faucet does not have enough funds to process your request. Please try again later!").WithRetryAfter(retryAfterNotEnoughFunds)
+	}
+	if err := f.checkBudgetWithoutLocking(request); err != nil {
+		f.Unlock()
+
+		return nil, err
+	}
+	f.Unlock()
+
+	ctx, cancel := context.WithTimeout(f.daemon.ContextStopped(), sharedQueueRequestTimeout)
+	defer cancel()
+
+	added, err := f.opts.sharedQueue.Push(ctx, &SharedRequest{
+		Bech32:             request.Bech32,
+		BaseTokenAmount:    request.BaseTokenAmount,
+		Tag:                request.Tag,
+		Type:               request.Type,
+		RemoteIP:           request.RemoteIP,
+		EnqueuedAt:         request.EnqueuedAt,
+		Priority:           request.Priority,
+		SkipMana:           request.SkipMana,
+		BalanceUnchecked:   request.BalanceUnchecked,
+		AmountRequested:    request.AmountRequested,
+		IdempotencyKeyHash: request.IdempotencyKeyHash,
+	}, f.opts.maxPendingRequestsPerIP)
+	if err != nil {
+		if ierrors.Is(err, ErrTooManyPendingRequestsPerIP) {
+			return nil, f.tooManyPendingRequestsError(f.opts.maxPendingRequestsPerIP)
+		}
+
+		f.logSoftError(ierrors.Wrap(err, "failed to add request to the shared queue"))
+
+		return nil, NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "Faucet queue is unavailable. Please try again later!").WithRetryAfter(f.RuntimeParameters().BatchTimeout)
+	}
+	if !added {
+		return nil, NewRequestError(ErrorCodeAddressAlreadyInQueue, http.StatusBadRequest, "Address is already in the queue.")
+	}
+
+	waitingRequests, err := f.opts.sharedQueue.Len(ctx)
+	if err != nil {
+		f.logSoftError(ierrors.Wrap(err, "failed to get the length of the shared queue"))
+	}
+
+	return &EnqueueResponse{
+		Address:         request.Bech32,
+		WaitingRequests: waitingRequests,
+		Tag:             request.Tag,
+	}, nil
+}
+
+// releaseSharedRequest allows new requests for the given address in the shared queue.
+// it is executed in the background, so it can be called while holding the lock.
+func (f *Faucet) releaseSharedRequest(bech32Addr string) {
+	if f.opts.sharedQueue == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedQueueRequestTimeout)
+		defer cancel()
+
+		if err := f.opts.sharedQueue.Release(ctx, bech32Addr); err != nil {
+			f.logSoftError(ierrors.Wrapf(err, "failed to release %s in the shared queue", bech32Addr))
+		}
+	}()
+}
+
+// RunSharedQueueConsumer moves the requests from the shared queue to the local queue of the faucet
+// while this instance issues the faucet transactions.
+func (f *Faucet) RunSharedQueueConsumer(ctx context.Context) error {
+	if f.opts.sharedQueue == nil || !f.opts.issuanceEnabled {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// faucet was stopped
+			return nil
+		default:
+		}
+
+		if !f.IsLeader() {
+			// another instance issues the transactions
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-f.opts.clock.After(sharedQueuePopTimeout):
+			}
+
+			continue
+		}
+
+		// the request timeout must be longer than the blocking pop
+		ctxPop, cancelPop := context.WithTimeout(ctx, sharedQueuePopTimeout+sharedQueueRequestTimeout)
+		sharedRequest, err := f.opts.sharedQueue.Pop(ctxPop, sharedQueuePopTimeout)
+		cancelPop()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			f.logSoftError(ierrors.Wrap(err, "failed to get request from the shared queue"))
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-f.opts.clock.After(sharedQueuePopTimeout):
+				// cooldown
+			}
+
+			continue
+		}
+		if sharedRequest == nil {
+			continue
+		}
+
+		request, err := f.queueItemFromSharedRequest(sharedRequest)
+		if err != nil {
+			f.logSoftError(err)
+			f.releaseSharedRequest(sharedRequest.Bech32)
+
+			continue
+		}
+
+		f.Lock()
+		f.queueMap[request.Bech32] = request
+		f.reserveRequestWithoutLocking(request)
+		f.Unlock()
+
+		if !f.queue.waitPush(ctx, request) {
+			return nil
+		}
+	}
+}
+
+// queueItemFromSharedRequest converts a request from the shared queue into a queue item.
+func (f *Faucet) queueItemFromSharedRequest(sharedRequest *SharedRequest) (*queueItem, error) {
+	addr, err := f.parseBech32Address(sharedRequest.Bech32)
+	if err != nil {
+		return nil, ierrors.Wrapf(err, "invalid address in shared queue: %s", sharedRequest.Bech32)
+	}
+
+	requestType := sharedRequest.Type
+	if requestType == "" {
+		requestType = RequestTypeBasic
+	}
+
+	return &queueItem{
+		Bech32:             sharedRequest.Bech32,
+		BaseTokenAmount:    sharedRequest.BaseTokenAmount,
+		Address:            addr,
+		Tag:                sharedRequest.Tag,
+		Type:               requestType,
+		RemoteIP:           sharedRequest.RemoteIP,
+		EnqueuedAt:         sharedRequest.EnqueuedAt,
+		Priority:           sharedRequest.Priority,
+		SkipMana:           sharedRequest.SkipMana,
+		BalanceUnchecked:   sharedRequest.BalanceUnchecked,
+		AmountRequested:    sharedRequest.AmountRequested,
+		IdempotencyKeyHash: sharedRequest.IdempotencyKeyHash,
+	}, nil
+}
+
+// waitAndRefreshFaucetBalance refreshes the faucet balance after the batch timeout on instances that don't issue transactions.
+func (f *Faucet) waitAndRefreshFaucetBalance(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		// faucet was stopped
+		return
+
+	case <-f.opts.clock.After(f.RuntimeParameters().BatchTimeout):
+		if err := f.computeAndSetInitialFaucetBalance(); err != nil {
+			f.logSoftError(ierrors.Wrap(err, "failed to refresh the faucet balance"))
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+)
+
+// WithShutdownTimeout defines the maximum duration to wait for the pending transaction to be resolved on shutdown.
+func WithShutdownTimeout(shutdownTimeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.shutdownTimeout = shutdownTimeout
+	}
+}
+
+// WithQueueFilePath defines the file the remaining requests are persisted to on shutdown and restored from on startup.
+// An empty path disables the persistence.
+func WithQueueFilePath(queueFilePath string) Option {
+	return func(opts *Options) {
+		opts.queueFilePath = queueFilePath
+	}
+}
+
+// checkStopping returns an error if the faucet is shutting down and doesn't accept new requests.
+func (f *Faucet) checkStopping() error {
+	if f.stopping.Load() {
+		return NewRequestError(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "Faucet is shutting down. Please try again later!").WithRetryAfter(retryAfterNodeUnhealthy)
+	}
+
+	return nil
+}
+
+// toSharedRequests converts the queue items into their serializable form.
+func toSharedRequests(requests []*queueItem) []*SharedRequest {
+	sharedRequests := make([]*SharedRequest, 0, len(requests))
+	for _, request := range requests {
+		sharedRequests = append(sharedRequests, &SharedRequest{
+			Bech32:             request.Bech32,
+			BaseTokenAmount:    request.BaseTokenAmount,
+			Tag:                request.Tag,
+			Type:               request.Type,
+			RemoteIP:           request.RemoteIP,
+			EnqueuedAt:         request.EnqueuedAt,
+			Priority:           request.Priority,
+			SkipMana:           request.SkipMana,
+			BalanceUnchecked:   request.BalanceUnchecked,
+			AmountRequested:    request.AmountRequested,
+			IdempotencyKeyHash: request.IdempotencyKeyHash,
+		})
+	}
+
+	return sharedRequests
+}
+
+// drainQueueWithoutLocking removes all requests from the local queue.
+// write lock must be acquired outside.
+func (f *Faucet) drainQueueWithoutLocking() []*queueItem {
+	return f.queue.drain()
+}
+
+// shutdown stops accepting new requests, waits for the pending transaction to be resolved
+// and persists the remaining requests, so they are not lost after a restart.
+func (f *Faucet) shutdown() {
+	f.stopping.Store(true)
+
+	// the context of the faucet is already canceled, so we use a new one for the remaining work
+	ctx, cancel := context.WithTimeout(context.Background(), f.opts.shutdownTimeout)
+	defer cancel()
+
+	f.waitForPendingTransactions(ctx)
+
+	f.Lock()
+	defer f.Unlock()
+
+	queuedRequests := f.drainQueueWithoutLocking()
+	for _, request := range queuedRequests {
+		delete(f.queueMap, request.Bech32)
+		f.reservations.release(request.Bech32)
+		f.untrackPendingRequestWithoutLocking(request)
+	}
+
+	if f.opts.sharedQueue != nil {
+		// another instance takes over the requests
+		ctxRequeue, cancelRequeue := context.WithTimeout(context.Background(), sharedQueueRequestTimeout)
+		defer cancelRequeue()
+
+		f.requeueSharedRequests(ctxRequeue, toSharedRequests(queuedRequests))
+
+		for _, pendingTx := range f.pendingTransactions {
+			f.LogWarnf("pending transaction was not resolved before shutdown, txID: %s, requests: %d", pendingTx.TransactionID, len(pendingTx.QueuedItems))
+		}
+
+		return
+	}
+
+	if f.opts.queueFilePath == "" {
+		if len(queuedRequests) > 0 {
+			f.LogWarnf("dropping %d queued requests on shutdown, queue persistence is disabled", len(queuedRequests))
+		}
+
+		return
+	}
+
+	queue := &Snapshot{
+		Requests:            toSharedRequests(queuedRequests),
+		PendingTransactions: f.snapshotPendingTransactionsWithoutLocking(),
+	}
+
+	if err := writeQueueFile(f.opts.queueFilePath, queue); err != nil {
+		f.LogErrorf("failed to persist the faucet queue: %s", err)
+
+		return
+	}
+
+	f.LogInfof("persisted %d queued requests to %s", len(queue.Requests), f.opts.queueFilePath)
+}
+
+// waitForPendingTransactions waits until all pending transactions are resolved or the context is done.
+func (f *Faucet) waitForPendingTransactions(ctx context.Context) {
+	ticker := f.opts.clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		f.RLock()
+		pendingTransactions := f.pendingTransactionsWithoutLocking()
+		f.RUnlock()
+
+		if len(pendingTransactions) == 0 {
+			return
+		}
+
+		for _, pendingTx := range pendingTransactions {
+			f.LogInfof("waiting for the pending transaction to be resolved before shutdown, txID: %s", pendingTx.TransactionID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			f.checkPendingTransactionState(ctx)
+		}
+	}
+}
+
+// requeueSharedRequests adds the given requests back to the front of the shared queue.
+func (f *Faucet) requeueSharedRequests(ctx context.Context, sharedRequests []*SharedRequest) {
+	for _, sharedRequest := range sharedRequests {
+		if err := f.opts.sharedQueue.Requeue(ctx, sharedRequest); err != nil {
+			f.logSoftError(ierrors.Wrapf(err, "failed to hand over request of %s to the shared queue", sharedRequest.Bech32))
+		}
+	}
+}
+
+// restorePersistedQueue adds the requests that were persisted on the last shutdown back to the queue.
+func (f *Faucet) restorePersistedQueue() error {
+	if f.opts.queueFilePath == "" || f.opts.sharedQueue != nil {
+		return nil
+	}
+
+	queue, err := readQueueFile(f.opts.queueFilePath)
+	if err != nil {
+		return err
+	}
+	if queue == nil {
+		// nothing was persisted
+		return nil
+	}
+
+	restored := f.Restore(queue)
+
+	// the requests are in the queue again, so the file is not needed anymore
+	if err := os.Remove(f.opts.queueFilePath); err != nil {
+		return ierrors.Wrap(err, "failed to remove the queue file")
+	}
+
+	f.LogInfof("restored %d queued requests from %s", restored, f.opts.queueFilePath)
+
+	return nil
+}
+
+// isSnapshotTransactionUnresolved checks if the transaction that was pending when the snapshot was created will never be accepted.
+func (f *Faucet) isSnapshotTransactionUnresolved(pendingTx *SnapshotPendingTransaction) bool {
+	transactionID, err := iotago.TransactionIDFromHexString(pendingTx.TransactionID)
+	if err != nil {
+		f.logSoftError(ierrors.Wrapf(err, "invalid transaction ID in snapshot: %s", pendingTx.TransactionID))
+
+		return true
+	}
+
+	metadata, err := f.node.FetchTransactionMetadata(transactionID)
+	if err != nil {
+		// we don't know the state, it is better to not pay out twice
+		f.logSoftError(newSoftError(SoftErrorCategoryIndexer, ierrors.Wrapf(err, "failed to fetch metadata of the pending transaction of the snapshot, txID: %s", pendingTx.TransactionID)))
+
+		return false
+	}
+
+	if metadata == nil {
+		// the transaction is unknown
+		return true
+	}
+
+	switch metadata.TransactionState {
+	case api.TransactionStateUnknown, api.TransactionStateFailed:
+		return true
+	default:
+		// the transaction is still pending or already accepted
+		return false
+	}
+}
+
+// writeQueueFile writes the persisted queue to the given file.
+// the file is replaced atomically, so a crash while writing doesn't corrupt it.
+func writeQueueFile(filePath string, queue *Snapshot) error {
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return err
+	}
+
+	tmpFilePath := filePath + ".tmp"
+	if err := os.WriteFile(tmpFilePath, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilePath, filePath)
+}
+
+// readQueueFile reads the persisted queue from the given file.
+// it returns nil if the file doesn't exist.
+func readQueueFile(filePath string) (*Snapshot, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
+			return nil, nil
+		}
+
+		return nil, ierrors.Wrap(err, "failed to read the queue file")
+	}
+
+	queue := &Snapshot{}
+	if err := json.Unmarshal(data, queue); err != nil {
+		return nil, ierrors.Wrap(err, "failed to parse the queue file")
+	}
+
+	return queue, nil
+}
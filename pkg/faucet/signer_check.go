@@ -0,0 +1,68 @@
+package faucet
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// ErrInvalidSigner is returned if the signer of the faucet doesn't produce valid signatures for an address of the faucet.
+var ErrInvalidSigner = ierrors.New("signer doesn't produce valid signatures for the faucet address")
+
+// WithSignerCheckInterval defines the interval in which the signer of the faucet is tested with a sign/verify round trip.
+// A value of 0 disables the periodic test.
+func WithSignerCheckInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.signerCheckInterval = interval
+	}
+}
+
+// CheckAddressSigner signs a random message for the given address and verifies the signature,
+// and that the public key of the signature belongs to the address.
+// It returns an error wrapping ErrInvalidSigner if the signature is invalid, other errors are returned if the signing failed.
+func CheckAddressSigner(signer iotago.AddressSigner, address iotago.Address) error {
+	msg := make([]byte, 32)
+	if _, err := rand.Read(msg); err != nil {
+		return ierrors.Wrap(err, "failed to create the message for the signer check")
+	}
+
+	signature, err := signer.Sign(address, msg)
+	if err != nil {
+		return ierrors.Wrapf(err, "signing a message for address %s failed", address)
+	}
+
+	ed25519Signature, ok := signature.(*iotago.Ed25519Signature)
+	if !ok {
+		return ierrors.WithMessagef(ErrInvalidSigner, "address %s, unsupported signature type %T", address, signature)
+	}
+
+	if !ed25519.Verify(ed25519Signature.PublicKey[:], msg, ed25519Signature.Signature[:]) {
+		return ierrors.WithMessagef(ErrInvalidSigner, "address %s, the signature doesn't verify", address)
+	}
+
+	if signatureAddress := iotago.Ed25519AddressFromPubKey(ed25519Signature.PublicKey[:]); !signatureAddress.Equal(underlyingAddress(address)) {
+		return ierrors.WithMessagef(ErrInvalidSigner, "address %s, the key of the signer belongs to address %s", address, signatureAddress)
+	}
+
+	return nil
+}
+
+// checkSigner tests the signer with all addresses of the faucet.
+// An invalid signer is a critical error, because all further transactions would be invalid.
+// locking not required.
+func (f *Faucet) checkSigner() error {
+	for _, address := range append(f.sourceAddresses(), f.opts.retiredAddresses...) {
+		if err := CheckAddressSigner(f.addressSigner, address); err != nil {
+			if ierrors.Is(err, ErrInvalidSigner) {
+				return CriticalError(err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
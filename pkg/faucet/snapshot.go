@@ -0,0 +1,137 @@
+package faucet
+
+import (
+	"context"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// Snapshot contains the internal state of the faucet that is needed to continue on another host.
+type Snapshot struct {
+	// The requests that are waiting in the queue.
+	Requests []*SharedRequest `json:"requests"`
+	// The pending transactions that were not resolved when the snapshot was created.
+	PendingTransactions []*SnapshotPendingTransaction `json:"pendingTransactions,omitempty"`
+}
+
+// SnapshotPendingTransaction is a pending transaction that was not resolved when the snapshot was created.
+type SnapshotPendingTransaction struct {
+	// The hex encoded ID of the transaction.
+	TransactionID string `json:"transactionId"`
+	// The requests that are paid out by the transaction.
+	Requests []*SharedRequest `json:"requests"`
+}
+
+// Snapshot exports the queued requests and the pending transactions of the faucet.
+func (f *Faucet) Snapshot() *Snapshot {
+	f.RLock()
+	defer f.RUnlock()
+
+	pendingRequests := make(map[string]struct{})
+	for _, pendingTx := range f.pendingTransactions {
+		for _, request := range pendingTx.QueuedItems {
+			pendingRequests[request.Bech32] = struct{}{}
+		}
+	}
+
+	// the queue map contains the queued requests and the requests of the pending transactions
+	queuedRequests := make([]*queueItem, 0, len(f.queueMap))
+	for _, request := range f.queueMap {
+		if _, pending := pendingRequests[request.Bech32]; pending {
+			continue
+		}
+		queuedRequests = append(queuedRequests, request)
+	}
+
+	return &Snapshot{
+		Requests:            toSharedRequests(queuedRequests),
+		PendingTransactions: f.snapshotPendingTransactionsWithoutLocking(),
+	}
+}
+
+// snapshotPendingTransactionsWithoutLocking returns the pending transactions in their serializable form.
+// read lock must be acquired outside.
+func (f *Faucet) snapshotPendingTransactionsWithoutLocking() []*SnapshotPendingTransaction {
+	if len(f.pendingTransactions) == 0 {
+		return nil
+	}
+
+	pendingTransactions := make([]*SnapshotPendingTransaction, 0, len(f.pendingTransactions))
+	for _, pendingTx := range f.pendingTransactions {
+		pendingTransactions = append(pendingTransactions, &SnapshotPendingTransaction{
+			TransactionID: pendingTx.TransactionID.ToHex(),
+			Requests:      toSharedRequests(pendingTx.QueuedItems),
+		})
+	}
+
+	return pendingTransactions
+}
+
+// Restore adds the requests of the given snapshot to the queue and returns the amount of restored requests.
+// The requests of a pending transaction are only restored if the transaction will never be accepted.
+func (f *Faucet) Restore(snapshot *Snapshot) int {
+	sharedRequests := make([]*SharedRequest, 0, len(snapshot.Requests))
+	for _, pendingTx := range snapshot.PendingTransactions {
+		if f.isSnapshotTransactionUnresolved(pendingTx) {
+			// the transaction will never be accepted => the requests need to be paid out again
+			sharedRequests = append(sharedRequests, pendingTx.Requests...)
+		}
+	}
+	sharedRequests = append(sharedRequests, snapshot.Requests...)
+
+	if f.opts.sharedQueue != nil {
+		return f.restoreShared(sharedRequests)
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	var restored int
+	for _, sharedRequest := range sharedRequests {
+		request, err := f.queueItemFromSharedRequest(sharedRequest)
+		if err != nil {
+			f.logSoftError(err)
+
+			continue
+		}
+
+		if _, exists := f.queueMap[request.Bech32]; exists {
+			continue
+		}
+
+		if !f.queue.tryPush(request) {
+			f.LogWarnf("queue is full, dropping restored request of %s", request.Bech32)
+
+			continue
+		}
+
+		f.queueMap[request.Bech32] = request
+		f.reserveRequestWithoutLocking(request)
+		f.trackPendingRequestWithoutLocking(request)
+		restored++
+	}
+
+	return restored
+}
+
+// restoreShared adds the given requests to the shared queue and returns the amount of added requests.
+func (f *Faucet) restoreShared(sharedRequests []*SharedRequest) int {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedQueueRequestTimeout)
+	defer cancel()
+
+	var restored int
+	for _, sharedRequest := range sharedRequests {
+		// restored requests were already accepted, so the limit per IP address is not applied
+		added, err := f.opts.sharedQueue.Push(ctx, sharedRequest, 0)
+		if err != nil {
+			f.logSoftError(ierrors.Wrapf(err, "failed to add restored request of %s to the shared queue", sharedRequest.Bech32))
+
+			continue
+		}
+		if added {
+			restored++
+		}
+	}
+
+	return restored
+}
@@ -0,0 +1,54 @@
+package faucet
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// SoftErrorCategory is the category of a soft error, so operators can alert on specific failure classes.
+type SoftErrorCategory string
+
+const (
+	// SoftErrorCategoryIndexer is the category of failed queries of the indexer or the ledger of the node.
+	SoftErrorCategoryIndexer SoftErrorCategory = "indexer"
+	// SoftErrorCategorySubmit is the category of faucet transactions that couldn't be submitted or reattached.
+	SoftErrorCategorySubmit SoftErrorCategory = "submit"
+	// SoftErrorCategoryInsufficientMana is the category of payouts that were skipped because the faucet doesn't hold enough mana.
+	SoftErrorCategoryInsufficientMana SoftErrorCategory = "insufficient-mana"
+	// SoftErrorCategoryConflict is the category of faucet transactions that failed, conflicted or were rolled back.
+	SoftErrorCategoryConflict SoftErrorCategory = "conflict"
+	// SoftErrorCategoryOther is the category of all other soft errors.
+	SoftErrorCategoryOther SoftErrorCategory = "other"
+)
+
+// SoftError is an error that doesn't stop the faucet, together with its category.
+type SoftError struct {
+	// The category of the error.
+	Category SoftErrorCategory
+	// The error.
+	Err error
+}
+
+func (e *SoftError) Error() string { return e.Err.Error() }
+func (e *SoftError) Unwrap() error { return e.Err }
+
+// newSoftError assigns the category to the error, it can be wrapped further before it is logged.
+func newSoftError(category SoftErrorCategory, err error) *SoftError {
+	return &SoftError{
+		Category: category,
+		Err:      err,
+	}
+}
+
+// logSoftError logs a soft error and triggers the event.
+// The category is taken from the first categorized error in the chain, errors without a category belong to SoftErrorCategoryOther.
+func (f *Faucet) logSoftError(err error) {
+	category := SoftErrorCategoryOther
+
+	var softErr *SoftError
+	if ierrors.As(err, &softErr) {
+		category = softErr.Category
+	}
+
+	f.LogWarnf("%s (category: %s)", err, category)
+	f.Events.SoftError.Trigger(newSoftError(category, err))
+}
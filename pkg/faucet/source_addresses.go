@@ -0,0 +1,164 @@
+package faucet
+
+import (
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// WithSourceAddresses defines additional addresses the payouts are funded from.
+// Every faucet transaction only consumes the outputs of one of the source addresses, which take turns,
+// so the UTXO load is spread and the input sets of consecutive transactions are disjoint.
+// Every source address can have a pending transaction, so the transactions don't wait for each other's confirmation.
+// The signer of the faucet must hold the keys of the source addresses.
+func WithSourceAddresses(addresses ...iotago.Address) Option {
+	return func(opts *Options) {
+		opts.sourceAddresses = addresses
+	}
+}
+
+// sourceAddresses returns the faucet address followed by the additional source addresses.
+func (f *Faucet) sourceAddresses() []iotago.Address {
+	return append([]iotago.Address{f.address}, f.opts.sourceAddresses...)
+}
+
+// sourceAddressIndex returns the index of the source address the output belongs to, or -1 if it belongs to a retired address.
+func (f *Faucet) sourceAddressIndex(output UTXOBasicOutput) int {
+	if output.UnlockTarget == nil {
+		// the output belongs to the faucet address
+		return 0
+	}
+
+	unlockTargetKey := underlyingAddress(output.UnlockTarget).Key()
+	for i, sourceAddress := range f.sourceAddresses() {
+		if underlyingAddress(sourceAddress).Key() == unlockTargetKey {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// remainderAddressIndex returns the index of the source address the remainder of a transaction with the given inputs is sent to.
+// The remainder stays on the source address of the inputs, or goes to the faucet address if only retired outputs are consumed.
+func (f *Faucet) remainderAddressIndex(inputs []UTXOBasicOutput) int {
+	for _, input := range inputs {
+		if index := f.sourceAddressIndex(input); index >= 0 {
+			return index
+		}
+	}
+
+	return 0
+}
+
+// remainderAddress returns the source address the remainder of a transaction with the given inputs is sent to.
+func (f *Faucet) remainderAddress(inputs []UTXOBasicOutput) iotago.Address {
+	return f.sourceAddresses()[f.remainderAddressIndex(inputs)]
+}
+
+// isSourceAddressPendingWithoutLocking checks if there is a pending transaction that consumes the outputs of the source address with the given index.
+// read lock must be acquired outside.
+func (f *Faucet) isSourceAddressPendingWithoutLocking(index int) bool {
+	for _, pendingTx := range f.pendingTransactions {
+		if pendingTx.SourceAddressIndex == index {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allSourceAddressesPendingWithoutLocking checks if every source address has a pending transaction.
+// read lock must be acquired outside.
+func (f *Faucet) allSourceAddressesPendingWithoutLocking() bool {
+	for index := range f.sourceAddresses() {
+		if !f.isSourceAddressPendingWithoutLocking(index) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterPendingOutputsWithoutLocking returns the outputs that are not consumed by a pending transaction.
+// read lock must be acquired outside.
+func (f *Faucet) filterPendingOutputsWithoutLocking(unspentOutputs []UTXOBasicOutput) []UTXOBasicOutput {
+	if len(f.pendingTransactions) == 0 {
+		return unspentOutputs
+	}
+
+	pendingOutputIDs := make(map[iotago.OutputID]struct{})
+	for _, pendingTx := range f.pendingTransactions {
+		for _, outputID := range pendingTx.ConsumedInputs {
+			pendingOutputIDs[outputID] = struct{}{}
+		}
+	}
+
+	filteredOutputs := make([]UTXOBasicOutput, 0, len(unspentOutputs))
+	for _, output := range unspentOutputs {
+		if _, pending := pendingOutputIDs[output.OutputID]; !pending {
+			filteredOutputs = append(filteredOutputs, output)
+		}
+	}
+
+	return filteredOutputs
+}
+
+// selectSourceOutputsWithoutLocking returns the outputs of the next source address that holds funds
+// and has no pending transaction in round-robin order,
+// together with the outputs of the retired addresses, which are swept in every transaction.
+// write lock must be acquired outside.
+func (f *Faucet) selectSourceOutputsWithoutLocking(unspentOutputs []UTXOBasicOutput) []UTXOBasicOutput {
+	if len(f.opts.sourceAddresses) == 0 {
+		if f.isSourceAddressPendingWithoutLocking(0) {
+			return nil
+		}
+
+		return unspentOutputs
+	}
+
+	sourceOutputs := make([][]UTXOBasicOutput, len(f.opts.sourceAddresses)+1)
+	retiredOutputs := make([]UTXOBasicOutput, 0)
+	for _, output := range unspentOutputs {
+		index := f.sourceAddressIndex(output)
+		if index < 0 {
+			retiredOutputs = append(retiredOutputs, output)
+
+			continue
+		}
+		sourceOutputs[index] = append(sourceOutputs[index], output)
+	}
+
+	for i := range sourceOutputs {
+		index := (f.nextSourceAddressIndex + i) % len(sourceOutputs)
+		if len(sourceOutputs[index]) == 0 || f.isSourceAddressPendingWithoutLocking(index) {
+			continue
+		}
+
+		f.nextSourceAddressIndex = (index + 1) % len(sourceOutputs)
+
+		return append(sourceOutputs[index], retiredOutputs...)
+	}
+
+	// the remainder of a transaction that only consumes retired outputs is sent to the faucet address
+	if f.isSourceAddressPendingWithoutLocking(0) {
+		return nil
+	}
+
+	return retiredOutputs
+}
+
+// faucetAddressOutputs returns the outputs of the faucet address and the retired addresses,
+// so transactions that send their remainder to the faucet address don't merge the funds of the other source addresses.
+func (f *Faucet) faucetAddressOutputs(unspentOutputs []UTXOBasicOutput) []UTXOBasicOutput {
+	if len(f.opts.sourceAddresses) == 0 {
+		return unspentOutputs
+	}
+
+	faucetOutputs := make([]UTXOBasicOutput, 0, len(unspentOutputs))
+	for _, output := range unspentOutputs {
+		if f.sourceAddressIndex(output) <= 0 {
+			faucetOutputs = append(faucetOutputs, output)
+		}
+	}
+
+	return faucetOutputs
+}
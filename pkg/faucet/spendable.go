@@ -0,0 +1,142 @@
+package faucet
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+var (
+	// ErrOutputNotOwned is returned when the address unlock condition of an output is not the faucet address.
+	ErrOutputNotOwned = ierrors.New("output is not owned by the faucet")
+	// ErrOutputTimelocked is returned when an output is still timelocked.
+	ErrOutputTimelocked = ierrors.New("output is timelocked")
+	// ErrOutputExpirationNotUnlockable is returned when the faucet can't unlock an output because of its expiration.
+	ErrOutputExpirationNotUnlockable = ierrors.New("output can't be unlocked by the faucet because of its expiration")
+	// ErrOutputForeignStorageDeposit is returned when the storage deposit of an output must be returned to another address.
+	ErrOutputForeignStorageDeposit = ierrors.New("output has a storage deposit return to a foreign address")
+	// ErrOutputNativeTokens is returned when an output holds native tokens.
+	ErrOutputNativeTokens = ierrors.New("output holds native tokens")
+)
+
+// spendableSlot returns the slot up to which time based unlock conditions must have passed,
+// so the outputs are also unlockable with the older commitment the transaction references.
+func (f *Faucet) spendableSlot() iotago.SlotIndex {
+	latestSlot := f.node.LatestSlot()
+
+	maxCommittableAge := f.apiProvider.CommittedAPI().ProtocolParameters().MaxCommittableAge()
+	if latestSlot <= maxCommittableAge {
+		return 0
+	}
+
+	return latestSlot - maxCommittableAge
+}
+
+// checkSpendableOutput checks if the given output can be consumed in a faucet transaction
+// without additional outputs or unlock conditions the transaction builder can't handle.
+func (f *Faucet) checkSpendableOutput(output UTXOBasicOutput, spendableSlot iotago.SlotIndex) error {
+	unlockTarget := f.unlockTarget(output)
+	unlockConditions := output.Output.UnlockConditionSet()
+
+	if expiration := unlockConditions.Expiration(); expiration != nil {
+		// outputs with an expiration are only spendable by the return address after they expired
+		if canUnlock, returnAddress := unlockConditions.ReturnAddressCanUnlock(spendableSlot); !canUnlock || !returnAddress.Equal(unlockTarget) {
+			return ErrOutputExpirationNotUnlockable
+		}
+	} else if addressUnlockCondition := unlockConditions.Address(); addressUnlockCondition == nil || !addressUnlockCondition.Address.Equal(unlockTarget) {
+		return ErrOutputNotOwned
+	}
+
+	if unlockConditions.HasTimelockUntil(spendableSlot) {
+		return ErrOutputTimelocked
+	}
+
+	if storageDepositReturn := unlockConditions.StorageDepositReturn(); storageDepositReturn != nil && !storageDepositReturn.ReturnAddress.Equal(unlockTarget) {
+		// the storage deposit only doesn't need to be returned if it belongs to the address that unlocks the output
+		return ErrOutputForeignStorageDeposit
+	}
+
+	if output.Output.FeatureSet().NativeToken() != nil {
+		return ErrOutputNativeTokens
+	}
+
+	return nil
+}
+
+// filterSpendableOutputs discards the outputs that can't be consumed in a faucet transaction.
+// this is a second validation pass for the outputs returned by the indexer,
+// so outputs sent by an attacker to the faucet can't break the transaction building.
+func (f *Faucet) filterSpendableOutputs(outputs []UTXOBasicOutput) []UTXOBasicOutput {
+	spendableSlot := f.spendableSlot()
+
+	spendableOutputs := make([]UTXOBasicOutput, 0, len(outputs))
+	for _, output := range outputs {
+		if err := f.checkSpendableOutput(output, spendableSlot); err != nil {
+			f.LogDebugf("discarding faucet output %s with %d base tokens: %s", output.OutputID.ToHex(), output.Output.Amount, err)
+
+			continue
+		}
+
+		spendableOutputs = append(spendableOutputs, output)
+	}
+
+	return spendableOutputs
+}
+
+// UnlockableFunds returns the base tokens and the stored mana of the output the given address can spend
+// in a transaction that references the commitment of the given slot.
+// Outputs that are still timelocked, that can only be unlocked by another address because of their expiration,
+// or that are in the range around their expiration slot in which nobody can unlock them, hold no unlockable funds.
+// The storage deposit that must be returned to another address is not part of the unlockable base tokens.
+func UnlockableFunds(apiForSlot iotago.API, output iotago.Output, address iotago.Address, commitmentSlot iotago.SlotIndex) (iotago.BaseToken, iotago.Mana) {
+	protocolParams := apiForSlot.ProtocolParameters()
+	futureBoundedSlot := commitmentSlot + protocolParams.MinCommittableAge()
+	pastBoundedSlot := commitmentSlot + protocolParams.MaxCommittableAge()
+
+	unlockConditions := output.UnlockConditionSet()
+	if err := unlockConditions.TimelocksExpired(futureBoundedSlot); err != nil {
+		return 0, 0
+	}
+
+	unlockTarget, err := unlockConditions.CheckExpirationCondition(futureBoundedSlot, pastBoundedSlot)
+	if err != nil {
+		// the output is in the range around its expiration slot, so neither the owner nor the return address can unlock it
+		return 0, 0
+	}
+
+	if unlockTarget == nil {
+		if addressUnlockCondition := unlockConditions.Address(); addressUnlockCondition != nil {
+			unlockTarget = addressUnlockCondition.Address
+		}
+	}
+
+	// outputs without an address unlock condition, e.g. accounts, are unlockable by the address they were found for
+	if unlockTarget != nil && !isUnlockableBy(unlockTarget, address) {
+		// e.g. the address is only the return address of the expiration or the storage deposit return
+		return 0, 0
+	}
+
+	baseTokens := output.BaseTokenAmount()
+	if storageDepositReturn := unlockConditions.StorageDepositReturn(); storageDepositReturn != nil && !isUnlockableBy(storageDepositReturn.ReturnAddress, address) {
+		// the storage deposit must be returned to another address when the output is spent
+		if storageDepositReturn.Amount >= baseTokens {
+			baseTokens = 0
+		} else {
+			baseTokens -= storageDepositReturn.Amount
+		}
+	}
+
+	return baseTokens, output.StoredMana()
+}
+
+// isUnlockableBy checks if the given unlock target is the address, or a restricted address of it.
+func isUnlockableBy(unlockTarget iotago.Address, address iotago.Address) bool {
+	if unlockTarget.Equal(address) {
+		return true
+	}
+
+	if restrictedAddress, ok := unlockTarget.(*iotago.RestrictedAddress); ok {
+		return restrictedAddress.Address.Equal(address)
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package faucet
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// StorageDepositPolicy defines how payouts below the minimum storage deposit of their output are handled.
+type StorageDepositPolicy string
+
+const (
+	// StorageDepositPolicyBump raises the payout to the minimum storage deposit of its output.
+	StorageDepositPolicyBump StorageDepositPolicy = "bump"
+	// StorageDepositPolicyReject rejects requests whose payout doesn't cover the minimum storage deposit of its output.
+	StorageDepositPolicyReject StorageDepositPolicy = "reject"
+)
+
+// ParseStorageDepositPolicy parses the name of a storage deposit policy.
+func ParseStorageDepositPolicy(name string) (StorageDepositPolicy, error) {
+	switch policy := StorageDepositPolicy(name); policy {
+	case StorageDepositPolicyBump, StorageDepositPolicyReject:
+		return policy, nil
+	default:
+		return "", ierrors.Errorf("unknown storage deposit policy \"%s\", expected \"%s\" or \"%s\"", name, StorageDepositPolicyBump, StorageDepositPolicyReject)
+	}
+}
+
+// WithStorageDepositPolicy defines how payouts below the minimum storage deposit of their output are handled.
+func WithStorageDepositPolicy(policy StorageDepositPolicy) Option {
+	return func(opts *Options) {
+		opts.storageDepositPolicy = policy
+	}
+}
+
+// newPayoutOutput creates the output that pays out a request of the given type to the given address.
+func (f *Faucet) newPayoutOutput(api iotago.API, requestType RequestType, addr iotago.Address, baseTokenAmount iotago.BaseToken, manaAmount iotago.Mana) iotago.Output {
+	if requestType == RequestTypeDelegation {
+		//nolint:forcetypeassert // the address type is checked when the request is enqueued
+		return f.newDelegationOutput(api, addr.(*iotago.AccountAddress), baseTokenAmount)
+	}
+
+	return &iotago.BasicOutput{
+		Amount: baseTokenAmount,
+		Mana:   manaAmount,
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: addr},
+		},
+	}
+}
+
+// applyMinStorageDeposit checks that the payout of a request covers the minimum storage deposit of the output that is created for it.
+// Depending on the storage deposit policy, smaller payouts are raised to the minimum or rejected.
+// The minimum depends on the target address, e.g. outputs to implicit account creation addresses need a higher deposit.
+func (f *Faucet) applyMinStorageDeposit(requestType RequestType, addr iotago.Address, baseTokenAmount iotago.BaseToken) (iotago.BaseToken, error) {
+	if requestType == RequestTypeAllotment {
+		// allotment requests don't create outputs
+		return baseTokenAmount, nil
+	}
+
+	api := f.apiProvider.CommittedAPI()
+
+	minStorageDeposit, err := api.StorageScoreStructure().MinDeposit(f.newPayoutOutput(api, requestType, addr, baseTokenAmount, 0))
+	if err != nil {
+		return 0, ierrors.Wrap(err, "failed to calculate the minimum storage deposit")
+	}
+
+	if baseTokenAmount >= minStorageDeposit {
+		return baseTokenAmount, nil
+	}
+
+	if f.opts.storageDepositPolicy == StorageDepositPolicyReject {
+		return 0, NewRequestError(ErrorCodeAmountBelowStorageDeposit, http.StatusBadRequest, fmt.Sprintf("The payout of %d doesn't cover the minimum storage deposit of %d of the output.", baseTokenAmount, minStorageDeposit)).
+			WithDetail("minStorageDeposit", minStorageDeposit)
+	}
+
+	return minStorageDeposit, nil
+}
@@ -0,0 +1,260 @@
+package faucet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// Subscription is an address that receives a fixed payout in a regular interval.
+type Subscription struct {
+	// The unique ID of the subscription.
+	ID string `json:"id"`
+	// The bech32 address that receives the payouts.
+	Address string `json:"address"`
+	// The amount of base tokens of every payout, the configured amount is used if it is 0.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of hours between two payouts.
+	IntervalHours int `json:"intervalHours"`
+	// The optional tag that is added to the data of the faucet transactions.
+	Tag string `json:"tag,omitempty"`
+	// An optional note, e.g. the deployment the subscription is used for.
+	Note string `json:"note,omitempty"`
+	// The time the subscription was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// The time of the last payout.
+	LastPayoutAt time.Time `json:"lastPayoutAt"`
+	// The time the next payout is due.
+	NextPayoutAt time.Time `json:"nextPayoutAt"`
+	// The reason why the last payout failed, it is cleared after the next successful payout.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// subscriptionStore holds the subscriptions by ID.
+type subscriptionStore struct {
+	mutex         sync.RWMutex
+	subscriptions map[string]*Subscription
+}
+
+func newSubscriptionStore() *subscriptionStore {
+	return &subscriptionStore{
+		subscriptions: make(map[string]*Subscription),
+	}
+}
+
+// newSubscriptionID creates a random ID for a subscription.
+func newSubscriptionID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", ierrors.Wrap(err, "failed to create subscription ID")
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// validateSubscription checks the given subscription and returns a normalized copy.
+func (f *Faucet) validateSubscription(subscription *Subscription) (*Subscription, error) {
+	normalized := *subscription
+	addr, canonicalAddr, err := f.canonicalBech32Address(strings.TrimSpace(subscription.Address))
+	if err != nil {
+		return nil, err
+	}
+	normalized.Address = canonicalAddr
+
+	if err := f.checkTargetAddressType(addr); err != nil {
+		return nil, err
+	}
+
+	if err := f.checkPayoutTarget(addr); err != nil {
+		return nil, err
+	}
+
+	if normalized.Tag, err = f.sanitizeRequestTag(subscription.Tag); err != nil {
+		return nil, err
+	}
+
+	if normalized.IntervalHours < 1 {
+		return nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, "The interval of a subscription must be at least 1 hour.")
+	}
+
+	return &normalized, nil
+}
+
+// Subscriptions returns all subscriptions, ordered by the time of their next payout.
+func (f *Faucet) Subscriptions() []*Subscription {
+	f.subscriptions.mutex.RLock()
+	defer f.subscriptions.mutex.RUnlock()
+
+	subscriptions := make([]*Subscription, 0, len(f.subscriptions.subscriptions))
+	for _, subscription := range f.subscriptions.subscriptions {
+		subscriptionCopy := *subscription
+		subscriptions = append(subscriptions, &subscriptionCopy)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].NextPayoutAt.Before(subscriptions[j].NextPayoutAt)
+	})
+
+	return subscriptions
+}
+
+// SetSubscriptions replaces all subscriptions, e.g. with the persisted ones after a restart.
+func (f *Faucet) SetSubscriptions(subscriptions []*Subscription) error {
+	normalizedSubscriptions := make(map[string]*Subscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if subscription.ID == "" {
+			return ierrors.Errorf("subscription of %s has no ID", subscription.Address)
+		}
+
+		normalized, err := f.validateSubscription(subscription)
+		if err != nil {
+			return ierrors.Wrapf(err, "invalid subscription %s", subscription.ID)
+		}
+		normalizedSubscriptions[normalized.ID] = normalized
+	}
+
+	f.subscriptions.mutex.Lock()
+	defer f.subscriptions.mutex.Unlock()
+
+	f.subscriptions.subscriptions = normalizedSubscriptions
+
+	return nil
+}
+
+// AddSubscription registers a new subscription, the first payout is due immediately.
+func (f *Faucet) AddSubscription(subscription *Subscription) (*Subscription, error) {
+	normalized, err := f.validateSubscription(subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	if normalized.ID, err = newSubscriptionID(); err != nil {
+		return nil, err
+	}
+	normalized.CreatedAt = f.opts.clock.Now()
+	normalized.LastPayoutAt = time.Time{}
+	normalized.NextPayoutAt = normalized.CreatedAt
+	normalized.LastError = ""
+
+	f.subscriptions.mutex.Lock()
+	defer f.subscriptions.mutex.Unlock()
+
+	for _, existing := range f.subscriptions.subscriptions {
+		if existing.Address == normalized.Address {
+			return nil, NewRequestError(ErrorCodeInvalidRequest, http.StatusBadRequest, fmt.Sprintf("Address %s already has a subscription (%s).", normalized.Address, existing.ID))
+		}
+	}
+	f.subscriptions.subscriptions[normalized.ID] = normalized
+
+	subscriptionCopy := *normalized
+
+	return &subscriptionCopy, nil
+}
+
+// RemoveSubscription removes the subscription with the given ID. It returns false if it doesn't exist.
+func (f *Faucet) RemoveSubscription(id string) bool {
+	f.subscriptions.mutex.Lock()
+	defer f.subscriptions.mutex.Unlock()
+
+	if _, exists := f.subscriptions.subscriptions[id]; !exists {
+		return false
+	}
+	delete(f.subscriptions.subscriptions, id)
+
+	return true
+}
+
+// enqueueSubscriptionPayout adds the payout of a subscription to the queue.
+// the payouts are scheduled by the operator, so the request validators and the maximum target balance are not applied.
+func (f *Faucet) enqueueSubscriptionPayout(subscription *Subscription) error {
+	addr, bech32Addr, err := f.canonicalBech32Address(subscription.Address)
+	if err != nil {
+		return err
+	}
+
+	if err := f.checkDenylist(bech32Addr); err != nil {
+		return err
+	}
+
+	if exists := f.isAlreadyinQueue(bech32Addr); exists {
+		return NewRequestError(ErrorCodeAddressAlreadyInQueue, http.StatusBadRequest, "Address is already in the queue.")
+	}
+
+	baseTokenAmount := subscription.BaseTokenAmount
+	if baseTokenAmount == 0 {
+		baseTokenAmount = f.opts.baseTokenAmount
+	}
+
+	baseTokenAmount, err = f.applyMinStorageDeposit(RequestTypeBasic, addr, baseTokenAmount)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.enqueueItem(&queueItem{
+		Bech32:          bech32Addr,
+		BaseTokenAmount: baseTokenAmount,
+		Address:         addr,
+		Tag:             subscription.Tag,
+		Type:            RequestTypeBasic,
+		EnqueuedAt:      f.opts.clock.Now(),
+		Priority:        RequestPriorityAuthenticated,
+	})
+
+	return err
+}
+
+// ProcessDueSubscriptions enqueues the payouts of all subscriptions that are due.
+// It returns true if any subscription was changed, so the caller can persist them.
+func (f *Faucet) ProcessDueSubscriptions() bool {
+	if f.checkStopping() != nil || f.IsPaused() || !f.IsLeader() {
+		// the payouts are enqueued once the faucet issues transactions again
+		return false
+	}
+
+	now := f.opts.clock.Now()
+	var due []*Subscription
+	for _, subscription := range f.Subscriptions() {
+		if subscription.NextPayoutAt.After(now) {
+			// the subscriptions are ordered by their next payout
+			break
+		}
+		due = append(due, subscription)
+	}
+
+	if len(due) == 0 {
+		return false
+	}
+
+	for _, subscription := range due {
+		// the store is not locked while the payout is enqueued, because the faucet lock is acquired
+		err := f.enqueueSubscriptionPayout(subscription)
+
+		f.subscriptions.mutex.Lock()
+		current, exists := f.subscriptions.subscriptions[subscription.ID]
+		if exists {
+			if err != nil {
+				// the payout is retried on the next check
+				current.LastError = err.Error()
+			} else {
+				current.LastPayoutAt = now
+				current.NextPayoutAt = now.Add(time.Duration(current.IntervalHours) * time.Hour)
+				current.LastError = ""
+			}
+		}
+		f.subscriptions.mutex.Unlock()
+
+		if err != nil {
+			f.LogDebugf("failed to enqueue the payout of subscription %s for %s: %s", subscription.ID, subscription.Address, err)
+		}
+	}
+
+	return true
+}
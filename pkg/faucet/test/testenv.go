@@ -0,0 +1,436 @@
+package faucet_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/app/daemon"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/log"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	"github.com/iotaledger/inx-faucet/pkg/faucet/faucettest"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+)
+
+const (
+	faucetTagMessage   = "FAUCET"
+	faucetBatchTimeout = 2 * time.Second
+
+	// the real time the faucet has to react to an event of the test, e.g. to issue a block after a flush.
+	faucetReactionTimeout = 5 * time.Second
+)
+
+var (
+	// ErrNoBlockIssued is returned if the faucet didn't issue a block for the requests.
+	ErrNoBlockIssued = ierrors.New("no faucet block issued")
+
+	faucetSeed, _ = hex.DecodeString("96d9ff7a79e4b0a5f3e5848ae7867064402da92a62eabb4ebbe463f12d1f3b1a")
+	seed1, _      = hex.DecodeString("b15209ddc93cbdb600137ea6a8f88cdd7c5d480d5815c9352a0fb5c4e4b86f71")
+	seed2, _      = hex.DecodeString("d5353ceeed380ab89a0f6abe4630c2091acc82617c0edd4ff10bd60bba89e2ed")
+	seed3, _      = hex.DecodeString("bd6fe09d8a309ca309c5db7b63513240490109cd0ac6b123551e9da0d5c8916c")
+	otherSeed, _  = hex.DecodeString("2f54b071657e6644629a40518ba6554de4eee89f0757713005ad26137d80968d")
+)
+
+// Wallet is an address that receives funds from the faucet.
+type Wallet struct {
+	Name    string
+	Address *iotago.Ed25519Address
+}
+
+func newWallet(name string, seed []byte) *Wallet {
+	//nolint:forcetypeassert // the public key of an ed25519 private key is always an ed25519 public key
+	return &Wallet{
+		Name:    name,
+		Address: iotago.Ed25519AddressFromPubKey(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)),
+	}
+}
+
+// FaucetTestEnv runs a faucet against the in-memory node of the faucettest package.
+// The clock of the faucet only moves forward if the test environment advances it,
+// and transactions are only accepted if the test confirms them.
+type FaucetTestEnv struct {
+	t *testing.T
+
+	API   iotago.API
+	Node  *faucettest.Node
+	Clock *faucettest.Clock
+
+	// FaucetAddress is the restricted address of the faucet, like the one of the component.
+	FaucetAddress iotago.Address
+	// OtherWallet is an address that is not funded by the faucet, e.g. the target of a conflicting transaction.
+	OtherWallet *Wallet
+	Wallet1     *Wallet
+	Wallet2     *Wallet
+	Wallet3     *Wallet
+
+	Faucet *faucet.Faucet
+
+	faucetCtxCancel context.CancelFunc
+	faucetLoopDone  chan error
+}
+
+// NewFaucetTestEnv creates a ledger with the given balances and starts a faucet on top of it.
+// The given options are applied after the options of the test environment.
+func NewFaucetTestEnv(t *testing.T,
+	faucetBalance iotago.BaseToken,
+	wallet1Balance iotago.BaseToken,
+	wallet2Balance iotago.BaseToken,
+	wallet3Balance iotago.BaseToken,
+	faucetAmount iotago.BaseToken,
+	faucetSmallAmount iotago.BaseToken,
+	faucetMaxAddressBalance iotago.BaseToken,
+	opts ...faucet.Option) *FaucetTestEnv {
+	t.Helper()
+
+	// the storage deposit is zero, so the balances of the tests are not affected by it
+	testAPI := iotago.V3API(iotago.NewV3SnapshotProtocolParameters(
+		iotago.WithNetworkOptions("faucet-test", iotago.PrefixTestnet),
+		iotago.WithStorageOptions(0, 0, 0, 0, 0, 0),
+	))
+
+	faucetPrivateKey := ed25519.NewKeyFromSeed(faucetSeed)
+	//nolint:forcetypeassert // the public key of an ed25519 private key is always an ed25519 public key
+	faucetEd25519Address := iotago.Ed25519AddressFromPubKey(faucetPrivateKey.Public().(ed25519.PublicKey))
+	faucetAddress := iotago.RestrictedAddressWithCapabilities(faucetEd25519Address, iotago.WithAddressCanReceiveMana(true))
+	faucetSigner := iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(faucetEd25519Address, faucetPrivateKey))
+
+	env := &FaucetTestEnv{
+		t:              t,
+		API:            testAPI,
+		Node:           faucettest.NewNode(faucetAddress),
+		Clock:          faucettest.NewClock(time.Unix(1_700_000_000, 0)),
+		FaucetAddress:  faucetAddress,
+		OtherWallet:    newWallet("Other", otherSeed),
+		Wallet1:        newWallet("Wallet1", seed1),
+		Wallet2:        newWallet("Wallet2", seed2),
+		Wallet3:        newWallet("Wallet3", seed3),
+		faucetLoopDone: make(chan error, 1),
+	}
+
+	for address, balance := range map[iotago.Address]iotago.BaseToken{
+		faucetAddress:       faucetBalance,
+		env.Wallet1.Address: wallet1Balance,
+		env.Wallet2.Address: wallet2Balance,
+		env.Wallet3.Address: wallet3Balance,
+	} {
+		if balance > 0 {
+			env.Node.AddOutput(basicOutput(address, balance))
+		}
+	}
+
+	defaultDaemon := daemon.New()
+	defaultDaemon.Start()
+
+	env.Faucet = faucet.New(
+		defaultDaemon,
+		env.Node,
+		iotago.SingleVersionProvider(testAPI),
+		faucetAddress,
+		faucetSigner,
+		append([]faucet.Option{
+			faucet.WithLogger(log.NewLogger(log.WithName("Faucet"), log.WithLevel(log.LevelWarning))),
+			faucet.WithClock(env.Clock),
+			faucet.WithBaseTokenAmount(faucetAmount),
+			faucet.WithBaseTokenAmountSmall(faucetSmallAmount),
+			faucet.WithBaseTokenAmountMaxTarget(faucetMaxAddressBalance),
+			faucet.WithManaAmount(0),
+			faucet.WithTagMessage(faucetTagMessage),
+			faucet.WithBatchTimeout(faucetBatchTimeout),
+			// the clock doesn't move while the faucet checks the target addresses, so the checks must not be paced
+			faucet.WithBalanceCheckRateLimit(0, 4),
+			faucet.WithBalanceCacheTTL(0),
+			// the pending transactions are not awaited on cleanup
+			faucet.WithShutdownTimeout(0),
+		}, opts...)...,
+	)
+
+	faucetCtx, faucetCtxCancel := context.WithCancel(context.Background())
+	env.faucetCtxCancel = faucetCtxCancel
+
+	go func() {
+		env.faucetLoopDone <- env.Faucet.RunFaucetLoop(faucetCtx)
+	}()
+
+	// the tickers of the faucet loop are created after the initial balance was set
+	require.Eventually(t, func() bool {
+		return env.Clock.Waiters() > 0
+	}, faucetReactionTimeout, time.Millisecond, "faucet loop was not started")
+
+	return env
+}
+
+// basicOutput returns a basic output with the given amount that is owned by the given address.
+func basicOutput(address iotago.Address, amount iotago.BaseToken) *iotago.BasicOutput {
+	return &iotago.BasicOutput{
+		Amount: amount,
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: address},
+		},
+	}
+}
+
+// Cleanup stops the faucet loop.
+func (env *FaucetTestEnv) Cleanup() {
+	if env.faucetCtxCancel == nil {
+		return
+	}
+
+	env.faucetCtxCancel()
+	env.faucetCtxCancel = nil
+
+	select {
+	case err := <-env.faucetLoopDone:
+		require.NoError(env.t, err)
+	case <-time.After(faucetReactionTimeout):
+		env.t.Error("faucet loop didn't stop")
+	}
+}
+
+// flushRequests ends the current batch of the faucet.
+// If the faucet is in a cooldown, e.g. because of a pending transaction, the clock is advanced until it collects requests again.
+func (env *FaucetTestEnv) flushRequests() error {
+	deadline := time.Now().Add(faucetReactionTimeout)
+	for time.Now().Before(deadline) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := env.Faucet.FlushRequestsWithContext(ctx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		env.Clock.Advance(time.Second)
+	}
+
+	return ierrors.New("faucet didn't collect requests")
+}
+
+// processFaucetRequests flushes the requests of the faucet and waits until the next faucet block is issued.
+// The requests that are enqueued by preFlushFunc are awaited as well, if all of them are rejected by the faucet,
+// no block is issued and the rejections are returned.
+func (env *FaucetTestEnv) processFaucetRequests(preFlushFunc func() (int, error)) (iotago.BlockIDs, error) {
+	issued := make(chan iotago.BlockID, 1)
+	unhookIssued := env.Faucet.Events.IssuedBlock.Hook(func(blockID iotago.BlockID) {
+		select {
+		case issued <- blockID:
+		default:
+		}
+	}).Unhook
+	defer unhookIssued()
+
+	rejected := make(chan *faucet.RejectedRequest, iotago.MaxOutputsCount)
+	unhookRejected := env.Faucet.Events.RequestRejected.Hook(func(request *faucet.RejectedRequest) {
+		rejected <- request
+	}).Unhook
+	defer unhookRejected()
+
+	var enqueued int
+	if preFlushFunc != nil {
+		var err error
+		if enqueued, err = preFlushFunc(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := env.flushRequests(); err != nil {
+		return nil, err
+	}
+
+	var rejections []error
+	for {
+		select {
+		case blockID := <-issued:
+			// the requests are rejected before the block is issued
+			for len(rejected) > 0 {
+				request := <-rejected
+				rejections = append(rejections, ierrors.Errorf("request of %s rejected: %s", request.Bech32, request.Error.Message))
+			}
+
+			return iotago.BlockIDs{blockID}, ierrors.Join(rejections...)
+
+		case request := <-rejected:
+			rejections = append(rejections, ierrors.Errorf("request of %s rejected: %s", request.Bech32, request.Error.Message))
+			if enqueued > 0 && len(rejections) == enqueued {
+				// all requests were dropped, so no block is issued
+				return nil, ierrors.Join(rejections...)
+			}
+
+		case <-time.After(faucetReactionTimeout):
+			return nil, ierrors.Join(append(rejections, ErrNoBlockIssued)...)
+		}
+	}
+}
+
+// RequestFunds sends requests to the faucet and waits until the next faucet block is issued.
+func (env *FaucetTestEnv) RequestFunds(wallets ...*Wallet) (iotago.BlockIDs, error) {
+	require.NotEmpty(env.t, wallets)
+
+	return env.processFaucetRequests(func() (int, error) {
+		for _, wallet := range wallets {
+			if _, err := env.Faucet.Enqueue(&faucet.EnqueueRequest{Address: wallet.Address.Bech32(iotago.PrefixTestnet)}, nil); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(wallets), nil
+	})
+}
+
+// RequestFundsAndConfirm sends requests to the faucet, waits until the next faucet block is issued and confirms its transaction.
+func (env *FaucetTestEnv) RequestFundsAndConfirm(wallets ...*Wallet) error {
+	if _, err := env.RequestFunds(wallets...); err != nil {
+		return err
+	}
+
+	return env.ConfirmPendingTransactions()
+}
+
+// FlushRequestsAndConfirmNewFaucetBlock flushes pending faucet requests, waits until the next faucet block is issued and
+// confirms its transaction.
+func (env *FaucetTestEnv) FlushRequestsAndConfirmNewFaucetBlock() error {
+	if _, err := env.processFaucetRequests(nil); err != nil {
+		return err
+	}
+
+	return env.ConfirmPendingTransactions()
+}
+
+// ConfirmPendingTransactions accepts all pending transactions of the node and applies them to the faucet,
+// like the ledger updates of the node do. Transactions whose inputs were spent already fail.
+func (env *FaucetTestEnv) ConfirmPendingTransactions() error {
+	for _, transaction := range env.Node.SubmittedTransactions() {
+		if transaction.Metadata.TransactionState != api.TransactionStatePending {
+			continue
+		}
+
+		transactionID := transaction.Metadata.TransactionID
+		if err := env.Node.AcceptTransaction(transactionID); err != nil {
+			return err
+		}
+
+		metadata, err := env.Node.FetchTransactionMetadata(transactionID)
+		if err != nil {
+			return err
+		}
+		if metadata.TransactionState != api.TransactionStateAccepted {
+			// the transaction was conflicting
+			continue
+		}
+
+		createdOutputs := make(map[iotago.OutputID]struct{})
+		for index := range transaction.SignedTransaction.Transaction.Outputs {
+			createdOutputs[iotago.OutputIDFromTransactionIDAndIndex(transactionID, uint16(index))] = struct{}{}
+		}
+
+		consumedOutputs := make(map[iotago.OutputID]struct{})
+		for _, input := range transaction.SignedTransaction.Transaction.Inputs() {
+			consumedOutputs[input.OutputID()] = struct{}{}
+		}
+
+		env.Faucet.ApplyAcceptedTransaction(createdOutputs, consumedOutputs)
+	}
+
+	return nil
+}
+
+// SendConflictingTransaction spends the given amount of the faucet outputs to the other wallet outside of the faucet,
+// like a second user of the private key of the faucet would do, and applies the transaction to the faucet.
+func (env *FaucetTestEnv) SendConflictingTransaction(amount iotago.BaseToken) {
+	faucetOutputs, err := env.Node.CollectUnlockableFaucetOutputs()
+	require.NoError(env.t, err)
+
+	var balance iotago.BaseToken
+	consumedOutputs := make(map[iotago.OutputID]struct{})
+	for _, output := range faucetOutputs {
+		balance += output.Output.Amount
+		consumedOutputs[output.OutputID] = struct{}{}
+		env.Node.RemoveOutput(output.OutputID)
+	}
+	require.GreaterOrEqual(env.t, balance, amount)
+
+	createdOutputs := map[iotago.OutputID]struct{}{
+		env.Node.AddOutput(basicOutput(env.OtherWallet.Address, amount)): {},
+	}
+	if balance > amount {
+		createdOutputs[env.Node.AddOutput(basicOutput(env.FaucetAddress, balance-amount))] = struct{}{}
+	}
+
+	env.Faucet.ApplyAcceptedTransaction(createdOutputs, consumedOutputs)
+}
+
+// AddFaucetFunds sends the given amount to the faucet in a new output and applies it to the faucet.
+func (env *FaucetTestEnv) AddFaucetFunds(amount iotago.BaseToken) {
+	outputID := env.Node.AddOutput(basicOutput(env.FaucetAddress, amount))
+
+	env.Faucet.ApplyAcceptedTransaction(map[iotago.OutputID]struct{}{outputID: {}}, map[iotago.OutputID]struct{}{})
+}
+
+// OrphanPendingTransactions removes the pending transactions from the node, like if their blocks were orphaned.
+// The faucet notices it the next time it checks the state of its pending transactions.
+func (env *FaucetTestEnv) OrphanPendingTransactions() {
+	for _, transaction := range env.Node.SubmittedTransactions() {
+		if transaction.Metadata.TransactionState == api.TransactionStatePending {
+			env.Node.EvictTransaction(transaction.Metadata.TransactionID)
+		}
+	}
+}
+
+// WaitForIssuedBlock advances the clock until the faucet issues a block, e.g. after it reattached an orphaned transaction.
+func (env *FaucetTestEnv) WaitForIssuedBlock() (iotago.BlockID, error) {
+	issued := make(chan iotago.BlockID, 1)
+	unhook := env.Faucet.Events.IssuedBlock.Hook(func(blockID iotago.BlockID) {
+		select {
+		case issued <- blockID:
+		default:
+		}
+	}).Unhook
+	defer unhook()
+
+	deadline := time.After(faucetReactionTimeout)
+	for {
+		select {
+		case blockID := <-issued:
+			return blockID, nil
+		case <-deadline:
+			return iotago.EmptyBlockID, ErrNoBlockIssued
+		case <-time.After(10 * time.Millisecond):
+			env.Clock.Advance(time.Second)
+		}
+	}
+}
+
+// AssertFaucetBalance checks the funds of the faucet in the ledger and that the faucet picks them up.
+// The faucet refreshes its funds once the current batch ended, so the clock is advanced until it did.
+func (env *FaucetTestEnv) AssertFaucetBalance(expected iotago.BaseToken) {
+	env.AssertLedgerBalance(env.FaucetAddress, expected)
+
+	require.Eventually(env.t, func() bool {
+		info, err := env.Faucet.Info()
+		require.NoError(env.t, err)
+
+		if info.Balance == expected {
+			return true
+		}
+		env.Clock.Advance(faucetBatchTimeout)
+
+		return false
+	}, faucetReactionTimeout, 10*time.Millisecond, "faucet balance doesn't match the ledger")
+}
+
+// AssertLedgerBalance checks the unlockable base tokens of the given address in the ledger.
+func (env *FaucetTestEnv) AssertLedgerBalance(address iotago.Address, expected iotago.BaseToken) {
+	balance, _, err := env.Node.ComputeUnlockableAddressBalance(address)
+	require.NoError(env.t, err)
+	require.Equal(env.t, expected, balance, "balance of %s", address.Bech32(iotago.PrefixTestnet))
+}
+
+// AssertAddressUTXOCount checks the amount of unspent outputs of the given address in the ledger.
+func (env *FaucetTestEnv) AssertAddressUTXOCount(address iotago.Address, expected int) {
+	require.Len(env.t, env.Node.UnspentOutputs(address), expected, "unspent outputs of %s", address.Bech32(iotago.PrefixTestnet))
+}
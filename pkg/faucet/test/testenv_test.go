@@ -6,13 +6,15 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	iotago "github.com/iotaledger/iota.go/v4"
 )
 
 // TestFaucetTestEnv verifies that our FaucetTestEnv is sane. This allows us to skip the assertions on the other tests to speed them up.
 func TestFaucetTestEnv(t *testing.T) {
 
-	randomBalance := func() uint64 {
-		return uint64(rand.Intn(256)) * 1_000_000
+	randomBalance := func() iotago.BaseToken {
+		return iotago.BaseToken(rand.Intn(256)+1) * 1_000_000
 	}
 
 	env := NewFaucetTestEnv(t,
@@ -23,7 +25,7 @@ func TestFaucetTestEnv(t *testing.T) {
 		10_000_000,      // faucetAmount:				10 Mi
 		1_000_000,       // faucetSmallAmount: 		 	 1 Mi
 		20_000_000,      // faucetMaxAddressBalance:	20 Mi
-		true)
+	)
 	defer env.Cleanup()
 	require.NotNil(t, env)
 }
@@ -0,0 +1,137 @@
+package faucet
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/builder"
+)
+
+const (
+	// the bytes that are reserved for the context inputs and the allotment that are added when the block is issued.
+	transactionSizeReserve = 256
+)
+
+// transactionLimits tracks the serialized size and the work score of a faucet transaction while outputs are added,
+// so the block of the transaction doesn't exceed the protocol limits.
+type transactionLimits struct {
+	workScoreParameters *iotago.WorkScoreParameters
+	size                int
+	maxSize             int
+	workScore           iotago.WorkScore
+	maxWorkScore        iotago.WorkScore
+}
+
+// newTransactionLimits creates the limits for a transaction with the inputs of the given builder,
+// the remainder output and the tagged data payload are already accounted for.
+func (f *Faucet) newTransactionLimits(api iotago.API, txBuilder *builder.TransactionBuilder, transactionTag []byte) (*transactionLimits, error) {
+	// the essence is signed to get the size of the unlocks
+	signedTx, err := txBuilder.Clone().Build()
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to build transaction to estimate its size")
+	}
+
+	workScore, err := signedTx.WorkScore(api.ProtocolParameters().WorkScoreParameters())
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to calculate work score of transaction")
+	}
+
+	limits := &transactionLimits{
+		workScoreParameters: api.ProtocolParameters().WorkScoreParameters(),
+		size:                signedTx.Size(),
+		maxSize:             iotago.MaxPayloadSize - transactionSizeReserve,
+		workScore:           workScore,
+		maxWorkScore:        api.MaxBlockWork(),
+	}
+
+	if err := limits.addSize((&iotago.TaggedData{Tag: transactionTag}).Size()); err != nil {
+		return nil, err
+	}
+
+	remainderOutput := &iotago.BasicOutput{
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: f.address},
+		},
+	}
+	if !limits.tryAddOutput(remainderOutput, "") {
+		return nil, ierrors.New("remainder output exceeds the transaction limits")
+	}
+
+	return limits, nil
+}
+
+// addSize adds the given amount of bytes to the transaction.
+func (l *transactionLimits) addSize(size int) error {
+	workScore, err := l.workScoreParameters.DataByte.Multiply(size)
+	if err != nil {
+		return err
+	}
+
+	if l.workScore, err = l.workScore.Add(workScore); err != nil {
+		return err
+	}
+	l.size += size
+
+	return nil
+}
+
+// tryAdd adds the given amount of bytes and work score if the transaction stays within the limits.
+// If the limits are unknown, only the protocol limits for the amount of outputs and allotments are checked.
+func (l *transactionLimits) tryAdd(size int, workScore iotago.WorkScore) bool {
+	if l == nil {
+		return true
+	}
+
+	dataWorkScore, err := l.workScoreParameters.DataByte.Multiply(size)
+	if err != nil {
+		return false
+	}
+
+	totalWorkScore, err := l.workScore.Add(workScore, dataWorkScore)
+	if err != nil {
+		return false
+	}
+
+	if l.size+size > l.maxSize || totalWorkScore > l.maxWorkScore {
+		return false
+	}
+
+	l.size += size
+	l.workScore = totalWorkScore
+
+	return true
+}
+
+// tagSize returns the amount of bytes the given request tag adds to the data of the tagged data payload.
+func tagSize(tag string) int {
+	if tag == "" {
+		return 0
+	}
+
+	// the tags are separated by a newline
+	return len(tag) + 1
+}
+
+// tryAddOutput adds the given output and the request tag if the transaction stays within the limits.
+func (l *transactionLimits) tryAddOutput(output iotago.Output, tag string) bool {
+	if l == nil {
+		return true
+	}
+
+	workScore, err := output.WorkScore(l.workScoreParameters)
+	if err != nil {
+		return false
+	}
+
+	return l.tryAdd(output.Size()+tagSize(tag), workScore)
+}
+
+// tryAddAllotment adds an allotment and the request tag if the transaction stays within the limits.
+func (l *transactionLimits) tryAddAllotment(tag string) bool {
+	if l == nil {
+		return true
+	}
+
+	allotmentSize := iotago.Allotments{&iotago.Allotment{}}.Size() - iotago.Allotments{}.Size()
+
+	return l.tryAdd(allotmentSize+tagSize(tag), l.workScoreParameters.Allotment)
+}
@@ -0,0 +1,72 @@
+package faucet
+
+// TransactionType is the type of a faucet transaction, it defines the tag of its tagged data payload.
+type TransactionType string
+
+const (
+	// TransactionTypePayout pays out the funds of regular requests.
+	TransactionTypePayout TransactionType = "payout"
+	// TransactionTypeAirdrop pays out the funds of airdrop entries.
+	TransactionTypeAirdrop TransactionType = "airdrop"
+	// TransactionTypeManaTopUp allots mana to the block issuance credits of accounts.
+	TransactionTypeManaTopUp TransactionType = "mana-top-up"
+	// TransactionTypeSweep consolidates the outputs of the faucet without paying out any requests,
+	// e.g. to sweep the outputs of retired addresses or to claim the potential mana.
+	TransactionTypeSweep TransactionType = "sweep"
+	// TransactionTypeDelegation creates a delegation of the faucet funds.
+	TransactionTypeDelegation TransactionType = "delegation"
+)
+
+// WithTransactionTag defines the tag of the tagged data payload of the faucet transactions of the given type.
+// An empty tag falls back to the tag defined by WithTagMessage.
+func WithTransactionTag(transactionType TransactionType, tag string) Option {
+	return func(opts *Options) {
+		if opts.transactionTags == nil {
+			opts.transactionTags = make(map[TransactionType][]byte)
+		}
+
+		if tag == "" {
+			delete(opts.transactionTags, transactionType)
+
+			return
+		}
+
+		opts.transactionTags[transactionType] = []byte(tag)
+	}
+}
+
+// transactionTag returns the tag of the tagged data payload of the faucet transactions of the given type.
+func (f *Faucet) transactionTag(transactionType TransactionType) []byte {
+	if tag, exists := f.opts.transactionTags[transactionType]; exists {
+		return tag
+	}
+
+	return f.opts.tagMessage
+}
+
+// transactionTypeOfRequests returns the type of the faucet transaction that pays out the given requests.
+// batches that mix different kinds of requests are regular payouts.
+func transactionTypeOfRequests(batchedRequests []*queueItem) TransactionType {
+	if len(batchedRequests) == 0 {
+		return TransactionTypeSweep
+	}
+
+	airdrops, allotments := 0, 0
+	for _, request := range batchedRequests {
+		if request.airdropEntry != nil {
+			airdrops++
+		}
+		if request.Type == RequestTypeAllotment {
+			allotments++
+		}
+	}
+
+	switch len(batchedRequests) {
+	case airdrops:
+		return TransactionTypeAirdrop
+	case allotments:
+		return TransactionTypeManaTopUp
+	default:
+		return TransactionTypePayout
+	}
+}
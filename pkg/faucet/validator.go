@@ -0,0 +1,93 @@
+package faucet
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// ClientMetadata holds information about the client that issued a faucet request.
+type ClientMetadata struct {
+	// RemoteIP is the IP address of the client.
+	RemoteIP string
+	// UserAgent is the user agent of the client.
+	UserAgent string
+	// Header contains the HTTP headers of the request.
+	Header http.Header
+	// Authenticated is true if the client is authenticated, its requests get a higher priority in the queue.
+	Authenticated bool
+	// APIKey is the API key the client sent, it is empty if none was sent.
+	APIKey string
+	// IdempotencyKey is the optional key the client sent to identify its request, it can be used to cancel the queued request.
+	IdempotencyKey string
+}
+
+// ValidationRequest holds the information about a faucet request that is passed to the request validators.
+type ValidationRequest struct {
+	// Bech32 is the bech32 address of the request.
+	Bech32 string
+	// Address is the parsed address of the request.
+	Address iotago.Address
+	// Type is the type of the request.
+	Type RequestType
+	// Tag is the sanitized tag of the request.
+	Tag string
+	// Challenge is the optional server-provided challenge of the request.
+	Challenge string
+	// Nonce is the optional solution of the challenge.
+	Nonce string
+	// OwnershipChallenge is the optional server-provided challenge that was signed to prove the ownership of the address.
+	OwnershipChallenge string
+	// PublicKey is the optional public key of the address that signed the ownership challenge.
+	PublicKey string
+	// Signature is the optional signature of the ownership challenge.
+	Signature string
+	// BaseTokenAmount is the amount of base tokens that will be paid out.
+	BaseTokenAmount iotago.BaseToken
+	// Client holds information about the client that issued the request.
+	// It is nil if the request was not issued via the HTTP API.
+	Client *ClientMetadata
+}
+
+// RequestValidator is an eligibility check that is invoked for every faucet request before it is enqueued.
+type RequestValidator interface {
+	// ValidateRequest returns the amount of base tokens that should be paid out for the given request.
+	// The request is rejected if an error is returned. A RequestError is passed to the client as is.
+	ValidateRequest(request *ValidationRequest) (iotago.BaseToken, error)
+}
+
+// RequestValidatorFunc is a function that implements the RequestValidator interface.
+type RequestValidatorFunc func(request *ValidationRequest) (iotago.BaseToken, error)
+
+// ValidateRequest calls the function itself.
+func (f RequestValidatorFunc) ValidateRequest(request *ValidationRequest) (iotago.BaseToken, error) {
+	return f(request)
+}
+
+// WithRequestValidators adds request validators that are invoked in the given order for every faucet request.
+func WithRequestValidators(validators ...RequestValidator) Option {
+	return func(opts *Options) {
+		opts.requestValidators = append(opts.requestValidators, validators...)
+	}
+}
+
+// validateRequest runs all request validators and returns the possibly adjusted amount of base tokens.
+func (f *Faucet) validateRequest(request *ValidationRequest) (iotago.BaseToken, error) {
+	for _, validator := range f.opts.requestValidators {
+		baseTokenAmount, err := validator.ValidateRequest(request)
+		if err != nil {
+			var reqErr *RequestError
+			if ierrors.As(err, &reqErr) {
+				return 0, reqErr
+			}
+
+			return 0, NewRequestError(ErrorCodeForbidden, http.StatusForbidden, err.Error())
+		}
+
+		// the following validators see the adjusted amount
+		request.BaseTokenAmount = baseTokenAmount
+	}
+
+	return request.BaseTokenAmount, nil
+}
@@ -0,0 +1,102 @@
+// Package geoip looks up the country and the autonomous system of IP addresses in MaxMind DB files (GeoIP2/GeoLite2 Country and ASN databases).
+package geoip
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is the country and the autonomous system of an IP address.
+type Location struct {
+	// CountryCode is the ISO 3166-1 alpha-2 code of the country, e.g. "DE". It is empty if it is unknown.
+	CountryCode string
+	// ASN is the number of the autonomous system. It is 0 if it is unknown.
+	ASN uint64
+	// Organization is the organization that operates the autonomous system.
+	Organization string
+}
+
+// countryRecord is the part of a record of a country database that is used by the faucet.
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// asnRecord is a record of an ASN database.
+type asnRecord struct {
+	AutonomousSystemNumber       uint64 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Database looks up the locations of IP addresses in a country and an ASN database.
+// Both databases are optional.
+type Database struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+}
+
+// OpenDatabase opens the country and the ASN database at the given paths. An empty path skips the database.
+func OpenDatabase(countryDatabasePath string, asnDatabasePath string) (*Database, error) {
+	database := &Database{}
+
+	if countryDatabasePath != "" {
+		reader, err := maxminddb.Open(countryDatabasePath)
+		if err != nil {
+			return nil, err
+		}
+		database.country = reader
+	}
+
+	if asnDatabasePath != "" {
+		reader, err := maxminddb.Open(asnDatabasePath)
+		if err != nil {
+			return nil, err
+		}
+		database.asn = reader
+	}
+
+	return database, nil
+}
+
+// Lookup returns the location of the given IP address.
+func (d *Database) Lookup(ip net.IP) (*Location, error) {
+	location := &Location{}
+
+	if d.country != nil {
+		record := &countryRecord{}
+		if err := d.country.Lookup(ip, record); err != nil {
+			return nil, err
+		}
+
+		// the registered country is used if the database contains no information about the actual location
+		isoCode := record.Country.ISOCode
+		if isoCode == "" {
+			isoCode = record.RegisteredCountry.ISOCode
+		}
+		location.CountryCode = strings.ToUpper(isoCode)
+	}
+
+	if d.asn != nil {
+		record := &asnRecord{}
+		if err := d.asn.Lookup(ip, record); err != nil {
+			return nil, err
+		}
+
+		location.ASN = record.AutonomousSystemNumber
+		location.Organization = record.AutonomousSystemOrganization
+	}
+
+	return location, nil
+}
+
+// FormatASN returns the usual notation of an autonomous system number, e.g. "AS3320".
+func FormatASN(asn uint64) string {
+	return "AS" + strconv.FormatUint(asn, 10)
+}
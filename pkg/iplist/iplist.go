@@ -0,0 +1,205 @@
+// Package iplist maintains sets of IP addresses and ranges that are periodically downloaded from public lists,
+// e.g. the exit nodes of the Tor network or the IP ranges of cloud providers.
+package iplist
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	// the maximum size of a downloaded list.
+	maxListSize = 32 << 20
+)
+
+// Set is an immutable set of IP addresses and ranges.
+type Set struct {
+	// the masked networks per prefix length, single addresses are stored with the full prefix length.
+	ipv4 map[int]map[string]struct{}
+	ipv6 map[int]map[string]struct{}
+	size int
+}
+
+// NewSet creates an empty set.
+func NewSet() *Set {
+	return &Set{
+		ipv4: make(map[int]map[string]struct{}),
+		ipv6: make(map[int]map[string]struct{}),
+	}
+}
+
+// Add adds an IP address (e.g. "192.0.2.1") or a range in CIDR notation (e.g. "192.0.2.0/24") to the set.
+// It returns false if the value is neither.
+func (s *Set) Add(value string) bool {
+	var network *net.IPNet
+	if strings.Contains(value, "/") {
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return false
+		}
+		network = ipNet
+	} else {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return false
+		}
+
+		bitCount := 8 * net.IPv6len
+		if ipv4 := ip.To4(); ipv4 != nil {
+			ip, bitCount = ipv4, 8*net.IPv4len
+		}
+		network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bitCount, bitCount)}
+	}
+
+	prefixLength, bitCount := network.Mask.Size()
+	networks := s.ipv6
+	if bitCount == 8*net.IPv4len {
+		networks = s.ipv4
+	}
+
+	if _, exists := networks[prefixLength]; !exists {
+		networks[prefixLength] = make(map[string]struct{})
+	}
+	if _, exists := networks[prefixLength][string(network.IP)]; !exists {
+		networks[prefixLength][string(network.IP)] = struct{}{}
+		s.size++
+	}
+
+	return true
+}
+
+// Contains checks if the given IP address is part of the set.
+func (s *Set) Contains(ip net.IP) bool {
+	networks, bitCount := s.ipv6, 8*net.IPv6len
+	if ipv4 := ip.To4(); ipv4 != nil {
+		networks, ip, bitCount = s.ipv4, ipv4, 8*net.IPv4len
+	}
+
+	for prefixLength, masked := range networks {
+		if _, exists := masked[string(ip.Mask(net.CIDRMask(prefixLength, bitCount)))]; exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the amount of addresses and ranges in the set.
+func (s *Set) Len() int {
+	return s.size
+}
+
+// List is a set of IP addresses and ranges that is downloaded from the given URLs.
+// The lists may be plain text, CSV or JSON, all addresses and ranges they contain are added.
+// It is safe for concurrent use.
+type List struct {
+	urls   []string
+	client *http.Client
+	set    atomic.Pointer[Set]
+}
+
+// NewList creates a new list that is empty until it is refreshed for the first time.
+func NewList(urls []string, timeout time.Duration) *List {
+	list := &List{
+		urls:   urls,
+		client: &http.Client{Timeout: timeout},
+	}
+	list.set.Store(NewSet())
+
+	return list
+}
+
+// Contains checks if the given IP address is part of the list.
+func (l *List) Contains(ip net.IP) bool {
+	return l.set.Load().Contains(ip)
+}
+
+// Len returns the amount of addresses and ranges in the list.
+func (l *List) Len() int {
+	return l.set.Load().Len()
+}
+
+// Refresh downloads all URLs and replaces the list.
+// The current list is kept if any of the downloads fails, so a partial list doesn't replace a complete one.
+func (l *List) Refresh(ctx context.Context) error {
+	set := NewSet()
+
+	for _, url := range l.urls {
+		if err := l.download(ctx, url, set); err != nil {
+			return err
+		}
+	}
+
+	l.set.Store(set)
+
+	return nil
+}
+
+// Run refreshes the list immediately and then in the given interval until the context is done.
+// Failed refreshes are reported to onError.
+func (l *List) Run(ctx context.Context, interval time.Duration, onError func(err error)) {
+	for {
+		if err := l.Refresh(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// download adds the addresses and ranges of the given URL to the set.
+func (l *List) download(ctx context.Context, url string, set *Set) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to download IP list %s", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ierrors.Errorf("failed to download IP list %s, status code %d", url, resp.StatusCode)
+	}
+
+	if err := parse(io.LimitReader(resp.Body, maxListSize), set); err != nil {
+		return ierrors.Wrapf(err, "failed to parse IP list %s", url)
+	}
+
+	return nil
+}
+
+// parse adds all tokens of the reader that are IP addresses or ranges to the set.
+func parse(reader io.Reader, set *Set) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(bufio.ScanWords)
+
+	for scanner.Scan() {
+		// the tokens are split at all characters that can't be part of an address or range, e.g. quotes and commas in JSON or CSV
+		for _, token := range strings.FieldsFunc(scanner.Text(), func(r rune) bool {
+			return !isAddressRune(r)
+		}) {
+			set.Add(token)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func isAddressRune(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F') || r == '.' || r == ':' || r == '/'
+}
@@ -0,0 +1,111 @@
+package iplist_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/inx-faucet/pkg/iplist"
+)
+
+func TestSet(t *testing.T) {
+	set := iplist.NewSet()
+	require.Zero(t, set.Len())
+
+	require.True(t, set.Add("192.0.2.1"))
+	require.True(t, set.Add("198.51.100.0/24"))
+	require.True(t, set.Add("2001:db8::1"))
+	require.True(t, set.Add("2001:db8:1::/48"))
+	require.False(t, set.Add("not-an-ip"))
+	require.False(t, set.Add("192.0.2.0/33"))
+
+	// duplicates are only counted once
+	require.True(t, set.Add("192.0.2.1"))
+	require.True(t, set.Add("198.51.100.7/24"))
+	require.Equal(t, 4, set.Len())
+
+	require.True(t, set.Contains(net.ParseIP("192.0.2.1")))
+	require.False(t, set.Contains(net.ParseIP("192.0.2.2")))
+	require.True(t, set.Contains(net.ParseIP("198.51.100.0")))
+	require.True(t, set.Contains(net.ParseIP("198.51.100.255")))
+	require.False(t, set.Contains(net.ParseIP("198.51.101.0")))
+
+	require.True(t, set.Contains(net.ParseIP("2001:db8::1")))
+	require.False(t, set.Contains(net.ParseIP("2001:db8::2")))
+	require.True(t, set.Contains(net.ParseIP("2001:db8:1:ffff::1")))
+	require.False(t, set.Contains(net.ParseIP("2001:db8:2::1")))
+}
+
+func TestSetIPv4MappedIPv6(t *testing.T) {
+	set := iplist.NewSet()
+	require.True(t, set.Add("::ffff:192.0.2.1"))
+
+	// IPv4-mapped IPv6 addresses are treated as IPv4 addresses
+	require.True(t, set.Contains(net.ParseIP("192.0.2.1")))
+	require.True(t, set.Contains(net.ParseIP("::ffff:192.0.2.1")))
+	require.False(t, set.Contains(net.ParseIP("2001:db8::1")))
+}
+
+func newListServer(t *testing.T, lists map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, exists := lists[r.URL.Path]
+		if !exists {
+			http.NotFound(w, r)
+
+			return
+		}
+		_, _ = fmt.Fprint(w, list)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestListRefresh(t *testing.T) {
+	server := newListServer(t, map[string]string{
+		"/plain": "192.0.2.1\n# comment\n198.51.100.0/24\n",
+		"/csv":   "ip,name\n203.0.113.5,exit\n",
+		"/json":  `{"prefixes":[{"ipv6Prefix":"2001:db8::/32"}]}`,
+	})
+
+	list := iplist.NewList([]string{server.URL + "/plain", server.URL + "/csv", server.URL + "/json"}, time.Second)
+	require.Zero(t, list.Len())
+	require.False(t, list.Contains(net.ParseIP("192.0.2.1")))
+
+	require.NoError(t, list.Refresh(context.Background()))
+	require.Equal(t, 4, list.Len())
+	require.True(t, list.Contains(net.ParseIP("192.0.2.1")))
+	require.True(t, list.Contains(net.ParseIP("198.51.100.42")))
+	require.True(t, list.Contains(net.ParseIP("203.0.113.5")))
+	require.True(t, list.Contains(net.ParseIP("2001:db8:ffff::1")))
+	require.False(t, list.Contains(net.ParseIP("203.0.113.6")))
+}
+
+func TestListRefreshKeepsListOnError(t *testing.T) {
+	lists := map[string]string{
+		"/plain": "192.0.2.1\n",
+	}
+	server := newListServer(t, lists)
+
+	list := iplist.NewList([]string{server.URL + "/plain", server.URL + "/missing"}, time.Second)
+	require.Error(t, list.Refresh(context.Background()))
+	require.Zero(t, list.Len())
+
+	lists["/missing"] = "198.51.100.1\n"
+	require.NoError(t, list.Refresh(context.Background()))
+	require.Equal(t, 2, list.Len())
+
+	// a failed download doesn't replace the complete list with a partial one
+	delete(lists, "/missing")
+	require.Error(t, list.Refresh(context.Background()))
+	require.Equal(t, 2, list.Len())
+	require.True(t, list.Contains(net.ParseIP("198.51.100.1")))
+}
@@ -0,0 +1,169 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/iota.go/v4/hexutil"
+)
+
+const (
+	// KeyFileVersion is the version of the format of encrypted key files.
+	KeyFileVersion = 1
+
+	// the name of the function that derives the encryption key from the passphrase.
+	keyFileKDFScrypt = "scrypt"
+	// the scrypt parameters of new key files, recommended for interactive logins.
+	keyFileScryptN      = 1 << 15
+	keyFileScryptR      = 8
+	keyFileScryptP      = 1
+	keyFileSaltLength   = 32
+	keyFileKeyLength    = 32
+	keyFileNonceLength  = 24
+	keyFileScryptMaxLog = 22
+)
+
+var (
+	// ErrInvalidKeyFile is returned if an encrypted key file can't be parsed.
+	ErrInvalidKeyFile = ierrors.New("invalid key file")
+	// ErrWrongPassphrase is returned if an encrypted key file can't be decrypted with the given passphrase.
+	ErrWrongPassphrase = ierrors.New("wrong passphrase or corrupted key file")
+	// ErrNoTerminal is returned if a passphrase should be prompted for, but there is no terminal.
+	ErrNoTerminal = ierrors.New("no terminal to prompt for the passphrase")
+)
+
+// KeyFile is an Ed25519 private key encrypted with a passphrase.
+// The key is sealed with NaCl secretbox (XSalsa20-Poly1305), the encryption key is derived from the passphrase with scrypt.
+type KeyFile struct {
+	// The version of the format.
+	Version int `json:"version"`
+	// The parameters of the function that derives the encryption key from the passphrase.
+	KDF KeyFileKDF `json:"kdf"`
+	// The hex encoded nonce of the secretbox.
+	Nonce string `json:"nonce"`
+	// The hex encoded sealed private key.
+	Ciphertext string `json:"ciphertext"`
+}
+
+// KeyFileKDF are the parameters of the function that derives the encryption key of a key file from the passphrase.
+type KeyFileKDF struct {
+	// The name of the function, only scrypt is supported.
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	// The hex encoded salt.
+	Salt string `json:"salt"`
+}
+
+// EncryptEd25519PrivateKey encrypts the private key with the passphrase and returns the JSON encoded key file.
+func EncryptEd25519PrivateKey(privateKey ed25519.PrivateKey, passphrase string) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ierrors.New("wrong private key length")
+	}
+	if passphrase == "" {
+		return nil, ierrors.New("the passphrase must not be empty")
+	}
+
+	salt := make([]byte, keyFileSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, ierrors.Wrap(err, "failed to create the salt")
+	}
+
+	var nonce [keyFileNonceLength]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, ierrors.Wrap(err, "failed to create the nonce")
+	}
+
+	kdf := KeyFileKDF{
+		Name: keyFileKDFScrypt,
+		N:    keyFileScryptN,
+		R:    keyFileScryptR,
+		P:    keyFileScryptP,
+		Salt: hexutil.EncodeHex(salt),
+	}
+
+	encryptionKey, err := deriveKeyFileKey(passphrase, kdf.N, kdf.R, kdf.P, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(&KeyFile{
+		Version:    KeyFileVersion,
+		KDF:        kdf,
+		Nonce:      hexutil.EncodeHex(nonce[:]),
+		Ciphertext: hexutil.EncodeHex(secretbox.Seal(nil, privateKey, &nonce, encryptionKey)),
+	}, "", "  ")
+}
+
+// DecryptEd25519PrivateKey decrypts the JSON encoded key file with the passphrase.
+func DecryptEd25519PrivateKey(data []byte, passphrase string) (ed25519.PrivateKey, error) {
+	keyFile := &KeyFile{}
+	if err := json.Unmarshal(data, keyFile); err != nil {
+		return nil, ierrors.WithMessagef(ErrInvalidKeyFile, "unable to parse the key file: %s", err)
+	}
+
+	if keyFile.Version != KeyFileVersion {
+		return nil, ierrors.WithMessagef(ErrInvalidKeyFile, "unsupported version %d", keyFile.Version)
+	}
+
+	if keyFile.KDF.Name != keyFileKDFScrypt {
+		return nil, ierrors.WithMessagef(ErrInvalidKeyFile, "unsupported key derivation function \"%s\"", keyFile.KDF.Name)
+	}
+
+	// the parameters are limited, so a manipulated file can't exhaust the memory
+	if keyFile.KDF.N <= 1 || keyFile.KDF.N > 1<<keyFileScryptMaxLog || keyFile.KDF.R <= 0 || keyFile.KDF.P <= 0 || keyFile.KDF.R*keyFile.KDF.P >= 1<<30 {
+		return nil, ierrors.WithMessage(ErrInvalidKeyFile, "invalid scrypt parameters")
+	}
+
+	salt, err := hexutil.DecodeHex(keyFile.KDF.Salt)
+	if err != nil {
+		return nil, ierrors.WithMessagef(ErrInvalidKeyFile, "unable to decode the salt: %s", err)
+	}
+
+	nonceBytes, err := hexutil.DecodeHex(keyFile.Nonce)
+	if err != nil || len(nonceBytes) != keyFileNonceLength {
+		return nil, ierrors.WithMessage(ErrInvalidKeyFile, "invalid nonce")
+	}
+	var nonce [keyFileNonceLength]byte
+	copy(nonce[:], nonceBytes)
+
+	ciphertext, err := hexutil.DecodeHex(keyFile.Ciphertext)
+	if err != nil {
+		return nil, ierrors.WithMessagef(ErrInvalidKeyFile, "unable to decode the ciphertext: %s", err)
+	}
+
+	encryptionKey, err := deriveKeyFileKey(passphrase, keyFile.KDF.N, keyFile.KDF.R, keyFile.KDF.P, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := secretbox.Open(nil, ciphertext, &nonce, encryptionKey)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ierrors.WithMessage(ErrInvalidKeyFile, "wrong private key length")
+	}
+
+	return privateKey, nil
+}
+
+// deriveKeyFileKey derives the encryption key of a key file from the passphrase with scrypt.
+func deriveKeyFileKey(passphrase string, n int, r int, p int, salt []byte) (*[keyFileKeyLength]byte, error) {
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keyFileKeyLength)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to derive the encryption key from the passphrase")
+	}
+
+	var encryptionKey [keyFileKeyLength]byte
+	copy(encryptionKey[:], derivedKey)
+
+	return &encryptionKey, nil
+}
@@ -0,0 +1,98 @@
+package keys_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/inx-faucet/pkg/keys"
+)
+
+const (
+	testKeyFilePassphrase = "correct horse battery staple"
+	// the private key of the SLIP-10 test vector "m/0'", encrypted with scrypt parameters that are cheap enough for tests.
+	testKeyFile = `{
+  "version": 1,
+  "kdf": {
+    "name": "scrypt",
+    "n": 1024,
+    "r": 8,
+    "p": 1,
+    "salt": "0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+  },
+  "nonce": "0xa0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7",
+  "ciphertext": "0x62ff6e9e8ab19fbb29d2c29e8260cac61cc7aca044df36016b437e54a032a1e36d7d0d6159691e230930254127873c3b915ee3868abcf4b3254e7adc0af3685d6bbb6b2e4898f8cbd97d31c397bbdec1"
+}`
+	testKeyFileSeed = "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"
+)
+
+func TestDecryptEd25519PrivateKey(t *testing.T) {
+	privateKey, err := keys.DecryptEd25519PrivateKey([]byte(testKeyFile), testKeyFilePassphrase)
+	require.NoError(t, err)
+	require.Equal(t, ed25519.NewKeyFromSeed(decodeHex(t, testKeyFileSeed)), privateKey)
+
+	_, err = keys.DecryptEd25519PrivateKey([]byte(testKeyFile), "wrong passphrase")
+	require.ErrorIs(t, err, keys.ErrWrongPassphrase)
+}
+
+func TestEncryptEd25519PrivateKey(t *testing.T) {
+	privateKey := ed25519.NewKeyFromSeed(decodeHex(t, testKeyFileSeed))
+
+	data, err := keys.EncryptEd25519PrivateKey(privateKey, testKeyFilePassphrase)
+	require.NoError(t, err)
+
+	keyFile := &keys.KeyFile{}
+	require.NoError(t, json.Unmarshal(data, keyFile))
+	require.Equal(t, keys.KeyFileVersion, keyFile.Version)
+	require.Equal(t, "scrypt", keyFile.KDF.Name)
+	require.NotContains(t, string(data), testKeyFileSeed)
+
+	decryptedKey, err := keys.DecryptEd25519PrivateKey(data, testKeyFilePassphrase)
+	require.NoError(t, err)
+	require.Equal(t, privateKey, decryptedKey)
+
+	// every key file uses a new salt and nonce
+	otherData, err := keys.EncryptEd25519PrivateKey(privateKey, testKeyFilePassphrase)
+	require.NoError(t, err)
+	require.NotEqual(t, data, otherData)
+
+	_, err = keys.EncryptEd25519PrivateKey(privateKey, "")
+	require.Error(t, err)
+
+	_, err = keys.EncryptEd25519PrivateKey(privateKey[:ed25519.SeedSize], testKeyFilePassphrase)
+	require.Error(t, err)
+}
+
+func TestDecryptEd25519PrivateKeyInvalid(t *testing.T) {
+	modify := func(fn func(keyFile *keys.KeyFile)) []byte {
+		keyFile := &keys.KeyFile{}
+		require.NoError(t, json.Unmarshal([]byte(testKeyFile), keyFile))
+		fn(keyFile)
+
+		data, err := json.Marshal(keyFile)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	for name, data := range map[string][]byte{
+		"no json":         []byte("0x1234"),
+		"wrong version":   modify(func(keyFile *keys.KeyFile) { keyFile.Version = 2 }),
+		"unknown kdf":     modify(func(keyFile *keys.KeyFile) { keyFile.KDF.Name = "pbkdf2" }),
+		"huge scrypt n":   modify(func(keyFile *keys.KeyFile) { keyFile.KDF.N = 1 << 30 }),
+		"invalid salt":    modify(func(keyFile *keys.KeyFile) { keyFile.KDF.Salt = "salt" }),
+		"short nonce":     modify(func(keyFile *keys.KeyFile) { keyFile.Nonce = "0xa0a1" }),
+		"invalid content": modify(func(keyFile *keys.KeyFile) { keyFile.Ciphertext = "ciphertext" }),
+	} {
+		_, err := keys.DecryptEd25519PrivateKey(data, testKeyFilePassphrase)
+		require.ErrorIs(t, err, keys.ErrInvalidKeyFile, name)
+	}
+
+	// a manipulated ciphertext can't be told apart from a wrong passphrase
+	_, err := keys.DecryptEd25519PrivateKey(modify(func(keyFile *keys.KeyFile) {
+		keyFile.Ciphertext = keyFile.Ciphertext[:len(keyFile.Ciphertext)-2] + "00"
+	}), testKeyFilePassphrase)
+	require.ErrorIs(t, err, keys.ErrWrongPassphrase)
+}
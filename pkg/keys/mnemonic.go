@@ -0,0 +1,124 @@
+// Package keys derives and loads the private key of the faucet.
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	// DefaultDerivationPath is the BIP44 path of the first address of the first account with the IOTA coin type.
+	DefaultDerivationPath = "m/44'/4218'/0'/0'/0'"
+
+	// hardenedOffset is added to the indexes of hardened derivation steps.
+	hardenedOffset uint32 = 0x80000000
+	// the key of the HMAC that derives the master key from the seed with SLIP-10.
+	slip10Ed25519Curve = "ed25519 seed"
+)
+
+var (
+	// ErrInvalidMnemonic is returned if the mnemonic doesn't consist of a valid amount of words of the English wordlist
+	// or if its checksum doesn't match.
+	ErrInvalidMnemonic = ierrors.New("invalid mnemonic")
+	// ErrInvalidDerivationPath is returned if the derivation path can't be parsed or contains non-hardened steps.
+	ErrInvalidDerivationPath = ierrors.New("invalid derivation path")
+)
+
+// SeedFromMnemonic returns the BIP39 seed of the given mnemonic and the optional passphrase.
+// Every word must be part of the English wordlist and the checksum of the mnemonic must match.
+func SeedFromMnemonic(mnemonic string, passphrase string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, ierrors.WithMessagef(ErrInvalidMnemonic, "expected 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+
+	// the errors only contain the position of the word, so the mnemonic doesn't end up in the logs
+	for i, word := range words {
+		if _, exists := bip39.GetWordIndex(word); !exists {
+			return nil, ierrors.WithMessagef(ErrInvalidMnemonic, "word %d is not part of the English wordlist", i+1)
+		}
+	}
+
+	normalizedMnemonic := strings.Join(words, " ")
+	if _, err := bip39.EntropyFromMnemonic(normalizedMnemonic); err != nil {
+		return nil, ierrors.WithMessage(ErrInvalidMnemonic, "the checksum doesn't match")
+	}
+
+	return bip39.NewSeed(normalizedMnemonic, norm.NFKD.String(passphrase)), nil
+}
+
+// ParseDerivationPath parses a BIP32 derivation path like "m/44'/4218'/0'/0'/0'".
+// Ed25519 keys can only be derived with hardened steps, so every index must be marked with "'" or "H".
+func ParseDerivationPath(path string) ([]uint32, error) {
+	segments := strings.Split(strings.TrimSpace(path), "/")
+	if len(segments) == 0 || segments[0] != "m" {
+		return nil, ierrors.WithMessagef(ErrInvalidDerivationPath, "path \"%s\" must start with \"m\"", path)
+	}
+
+	indexes := make([]uint32, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		trimmed := strings.TrimRight(segment, "'H")
+		if trimmed == segment {
+			return nil, ierrors.WithMessagef(ErrInvalidDerivationPath, "step \"%s\" of path \"%s\" is not hardened", segment, path)
+		}
+
+		index, err := strconv.ParseUint(trimmed, 10, 31)
+		if err != nil {
+			return nil, ierrors.WithMessagef(ErrInvalidDerivationPath, "step \"%s\" of path \"%s\" is not a valid index", segment, path)
+		}
+
+		indexes = append(indexes, uint32(index)+hardenedOffset)
+	}
+
+	return indexes, nil
+}
+
+// DeriveEd25519PrivateKey derives the Ed25519 private key of the given path from the seed with SLIP-10.
+func DeriveEd25519PrivateKey(seed []byte, path string) (ed25519.PrivateKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, chainCode := hmacSHA512([]byte(slip10Ed25519Curve), seed)
+	for _, index := range indexes {
+		data := make([]byte, 0, 1+len(key)+4)
+		data = append(data, 0x00)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		key, chainCode = hmacSHA512(chainCode, data)
+	}
+
+	return ed25519.NewKeyFromSeed(key), nil
+}
+
+// Ed25519PrivateKeyFromMnemonic derives the Ed25519 private key of the given path from the mnemonic and the optional passphrase.
+func Ed25519PrivateKeyFromMnemonic(mnemonic string, passphrase string, path string) (ed25519.PrivateKey, error) {
+	seed, err := SeedFromMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeriveEd25519PrivateKey(seed, path)
+}
+
+// hmacSHA512 returns the left and the right half of the HMAC-SHA512 of the data.
+func hmacSHA512(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	return sum[:32], sum[32:]
+}
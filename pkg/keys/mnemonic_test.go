@@ -0,0 +1,138 @@
+package keys_test
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/inx-faucet/pkg/keys"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
+
+// TestSeedFromMnemonic uses the test vectors of the reference implementation of BIP39 (passphrase "TREZOR").
+func TestSeedFromMnemonic(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		seed     string
+	}{
+		{
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+			seed:     "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			seed:     "dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+		},
+	}
+
+	for _, test := range tests {
+		seed, err := keys.SeedFromMnemonic(test.mnemonic, "TREZOR")
+		require.NoError(t, err)
+		require.Equal(t, test.seed, hex.EncodeToString(seed))
+	}
+
+	// additional whitespace between the words doesn't change the seed
+	seed, err := keys.SeedFromMnemonic("  abandon abandon abandon abandon abandon abandon\tabandon abandon abandon abandon abandon  about\n", "TREZOR")
+	require.NoError(t, err)
+	require.Equal(t, tests[0].seed, hex.EncodeToString(seed))
+}
+
+func TestSeedFromMnemonicInvalid(t *testing.T) {
+	for name, mnemonic := range map[string]string{
+		"wrong word count": "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"unknown word":     "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandonn",
+		"wrong checksum":   "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"empty":            "",
+	} {
+		_, err := keys.SeedFromMnemonic(mnemonic, "")
+		require.ErrorIs(t, err, keys.ErrInvalidMnemonic, name)
+		require.NotContains(t, err.Error(), "abandonn", name)
+	}
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	indexes, err := keys.ParseDerivationPath(keys.DefaultDerivationPath)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0x8000002c, 0x8000107a, 0x80000000, 0x80000000, 0x80000000}, indexes)
+
+	indexes, err = keys.ParseDerivationPath("m/1H/2'")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0x80000001, 0x80000002}, indexes)
+
+	for _, path := range []string{"", "44'/0'", "m/44'/0", "m/x'", "m/2147483648'"} {
+		_, err := keys.ParseDerivationPath(path)
+		require.ErrorIs(t, err, keys.ErrInvalidDerivationPath, path)
+	}
+}
+
+// TestDeriveEd25519PrivateKey uses the Ed25519 test vector 1 of SLIP-10.
+func TestDeriveEd25519PrivateKey(t *testing.T) {
+	seed := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+
+	tests := []struct {
+		path       string
+		privateKey string
+		publicKey  string
+	}{
+		{
+			path:       "m",
+			privateKey: "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			publicKey:  "a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+		},
+		{
+			path:       "m/0'",
+			privateKey: "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			publicKey:  "8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+		},
+		{
+			path:       "m/0'/1'",
+			privateKey: "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			publicKey:  "1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+		},
+		{
+			path:       "m/0'/1'/2'/2'/1000000000'",
+			privateKey: "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+			publicKey:  "3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a",
+		},
+	}
+
+	for _, test := range tests {
+		privateKey, err := keys.DeriveEd25519PrivateKey(seed, test.path)
+		require.NoError(t, err, test.path)
+		require.Equal(t, test.privateKey, hex.EncodeToString(privateKey.Seed()), test.path)
+		require.Equal(t, test.publicKey, hex.EncodeToString(privateKey[ed25519.SeedSize:]), test.path)
+	}
+}
+
+func TestEd25519PrivateKeyFromMnemonic(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	seed, err := keys.SeedFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	expectedKey, err := keys.DeriveEd25519PrivateKey(seed, keys.DefaultDerivationPath)
+	require.NoError(t, err)
+
+	privateKey, err := keys.Ed25519PrivateKeyFromMnemonic(mnemonic, "", keys.DefaultDerivationPath)
+	require.NoError(t, err)
+	require.Equal(t, expectedKey, privateKey)
+
+	// the passphrase changes the key
+	otherKey, err := keys.Ed25519PrivateKeyFromMnemonic(mnemonic, "TREZOR", keys.DefaultDerivationPath)
+	require.NoError(t, err)
+	require.NotEqual(t, privateKey, otherKey)
+}
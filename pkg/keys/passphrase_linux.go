@@ -0,0 +1,42 @@
+package keys
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// ReadPassphrase prints the prompt and reads a passphrase from the terminal without echoing it.
+// It returns ErrNoTerminal if the standard input is not a terminal.
+func ReadPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return "", ErrNoTerminal
+	}
+
+	noEcho := *termios
+	noEcho.Lflag &^= unix.ECHO
+	noEcho.Lflag |= unix.ICANON | unix.ISIG
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &noEcho); err != nil {
+		return "", ierrors.Wrap(err, "failed to disable the echo of the terminal")
+	}
+	//nolint:errcheck // the terminal is restored on a best effort basis
+	defer unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	passphrase, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", ierrors.Wrap(err, "failed to read the passphrase")
+	}
+
+	return strings.TrimRight(passphrase, "\r\n"), nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package keys
+
+// ReadPassphrase is only supported on Linux, the passphrase must be given in the environment on other systems.
+func ReadPassphrase(_ string) (string, error) {
+	return "", ErrNoTerminal
+}
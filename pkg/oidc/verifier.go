@@ -0,0 +1,273 @@
+// Package oidc contains a verifier for the OpenID Connect tokens of GitHub Actions that are signed with RSA-SHA256 (RS256).
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/time/rate"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	// GitHubActionsIssuer is the issuer of the OIDC tokens of GitHub Actions.
+	GitHubActionsIssuer = "https://token.actions.githubusercontent.com"
+
+	// the path of the OIDC discovery document relative to the issuer, it contains the URI of the signing keys.
+	discoveryPath = "/.well-known/openid-configuration"
+	// the minimum duration between two fetches of the signing keys, so unknown key IDs can't be used to flood the issuer.
+	// tokens with an unknown key ID wait for the next fetch instead of being rejected.
+	jwksMinRefreshInterval = 10 * time.Second
+	// the maximum age of the signing keys, so keys that were removed by the issuer are no longer accepted.
+	jwksMaxAge = time.Hour
+	// the maximum size of the responses of the issuer.
+	maxResponseSize = 1 << 20
+	// the tolerated clock difference between the issuer and the faucet.
+	clockSkew = time.Minute
+)
+
+var (
+	// ErrInvalidToken is returned if the token is malformed, the signature is invalid or a claim doesn't match.
+	ErrInvalidToken = ierrors.New("oidc: invalid token")
+	// ErrTokenExpired is returned if the token is expired or not valid yet.
+	ErrTokenExpired = ierrors.New("oidc: token expired")
+)
+
+// Claims are the claims of a GitHub Actions token that are relevant for the faucet.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Repository is the repository the workflow runs in (owner/name).
+	Repository string `json:"repository"`
+	// RepositoryOwner is the owner of the repository.
+	RepositoryOwner string `json:"repository_owner"`
+	// Ref is the git ref that triggered the workflow.
+	Ref string `json:"ref"`
+	// Workflow is the name of the workflow.
+	Workflow string `json:"workflow"`
+	// Actor is the user that triggered the workflow.
+	Actor string `json:"actor"`
+	// RunID is the ID of the workflow run.
+	RunID string `json:"run_id"`
+}
+
+// discoveryDocument contains the fields of the OIDC discovery document of the issuer that are relevant for the faucet.
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// jwk is a single key of the JSON Web Key Set of the issuer.
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Modulus string `json:"n"`
+	Exp     string `json:"e"`
+}
+
+// Verifier verifies the tokens of an issuer with the signing keys that are published by the issuer.
+// It is safe for concurrent use.
+type Verifier struct {
+	issuer string
+	client *http.Client
+	parser *jwt.Parser
+
+	// refreshMutex serializes the fetches of the signing keys, so concurrent tokens with an unknown key ID share a single fetch.
+	refreshMutex     sync.Mutex
+	refreshLimiter   *rate.Limiter
+	lastRefreshStart time.Time
+	// jwksURI is the URI of the signing keys from the discovery document, it is empty until the document was fetched.
+	jwksURI string
+
+	keysMutex   sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	lastRefresh time.Time
+}
+
+// NewVerifier creates a new verifier for the tokens of the given issuer that were issued for the given audience.
+func NewVerifier(issuer string, audience string, timeout time.Duration) *Verifier {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	return &Verifier{
+		issuer: issuer,
+		client: &http.Client{Timeout: timeout},
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(clockSkew),
+		),
+		refreshLimiter: rate.NewLimiter(rate.Every(jwksMinRefreshInterval), 1),
+		keys:           make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Verify verifies the signature, the issuer, the audience and the validity period of the given token and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	var keyErr error
+
+	claims := &Claims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		keyID, _ := token.Header["kid"].(string)
+
+		key, err := v.key(ctx, keyID)
+		if err != nil {
+			keyErr = err
+
+			return nil, err
+		}
+
+		return key, nil
+	}); err != nil {
+		switch {
+		case keyErr != nil && !ierrors.Is(keyErr, ErrInvalidToken):
+			// the signing keys couldn't be fetched, so the token can't be verified at all
+			return nil, keyErr
+		case ierrors.Is(err, jwt.ErrTokenExpired), ierrors.Is(err, jwt.ErrTokenNotValidYet):
+			return nil, ErrTokenExpired
+		default:
+			return nil, ErrInvalidToken
+		}
+	}
+
+	return claims, nil
+}
+
+// cachedKey returns the signing key with the given ID and whether the known keys are still up to date.
+func (v *Verifier) cachedKey(keyID string) (*rsa.PublicKey, bool) {
+	v.keysMutex.RLock()
+	defer v.keysMutex.RUnlock()
+
+	key, exists := v.keys[keyID]
+	if !exists || time.Since(v.lastRefresh) > jwksMaxAge {
+		return key, false
+	}
+
+	return key, true
+}
+
+// key returns the signing key with the given ID.
+// The keys are fetched again if the ID is unknown or the keys are outdated, so rotated keys are accepted right away.
+func (v *Verifier) key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	requested := time.Now()
+
+	if key, upToDate := v.cachedKey(keyID); upToDate {
+		return key, nil
+	}
+
+	v.refreshMutex.Lock()
+	defer v.refreshMutex.Unlock()
+
+	// the keys don't need to be fetched again if that started after the key was requested
+	if v.lastRefreshStart.Before(requested) {
+		if err := v.refreshLimiter.Wait(ctx); err != nil {
+			return nil, ierrors.Wrap(err, "oidc: the signing keys were fetched too recently")
+		}
+		v.lastRefreshStart = time.Now()
+
+		keys, err := v.fetchKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		v.keysMutex.Lock()
+		v.keys = keys
+		v.lastRefresh = time.Now()
+		v.keysMutex.Unlock()
+	}
+
+	v.keysMutex.RLock()
+	defer v.keysMutex.RUnlock()
+
+	key, exists := v.keys[keyID]
+	if !exists {
+		return nil, ErrInvalidToken
+	}
+
+	return key, nil
+}
+
+// fetchKeys fetches the RSA signing keys of the issuer.
+// The URI of the keys is taken from the discovery document, which is fetched again if the keys can't be fetched.
+// refresh mutex must be acquired outside.
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	if v.jwksURI == "" {
+		document := &discoveryDocument{}
+		if err := v.fetchJSON(ctx, v.issuer+discoveryPath, document); err != nil {
+			return nil, ierrors.Wrap(err, "oidc: failed to fetch the discovery document")
+		}
+
+		if strings.TrimSuffix(document.Issuer, "/") != v.issuer {
+			return nil, ierrors.Errorf("oidc: the discovery document belongs to issuer \"%s\"", document.Issuer)
+		}
+
+		if !strings.HasPrefix(document.JWKSURI, "https://") && !strings.HasPrefix(document.JWKSURI, "http://") {
+			return nil, ierrors.Errorf("oidc: invalid URI of the signing keys \"%s\" in the discovery document", document.JWKSURI)
+		}
+
+		v.jwksURI = document.JWKSURI
+	}
+
+	keySet := &struct {
+		Keys []*jwk `json:"keys"`
+	}{}
+	if err := v.fetchJSON(ctx, v.jwksURI, keySet); err != nil {
+		// the keys may have moved
+		v.jwksURI = ""
+
+		return nil, ierrors.Wrap(err, "oidc: failed to fetch the signing keys")
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		if key.KeyType != "RSA" {
+			continue
+		}
+
+		modulus, err := base64.RawURLEncoding.DecodeString(key.Modulus)
+		if err != nil {
+			continue
+		}
+
+		exponent, err := base64.RawURLEncoding.DecodeString(key.Exp)
+		if err != nil || len(exponent) > 4 {
+			continue
+		}
+
+		keys[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+// fetchJSON fetches the JSON document at the given URL and decodes it into the target.
+func (v *Verifier) fetchJSON(ctx context.Context, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ierrors.Errorf("status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(http.MaxBytesReader(nil, resp.Body, maxResponseSize)).Decode(target)
+}
@@ -0,0 +1,297 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+const testAudience = "faucet"
+
+// testIssuer serves the discovery document and the signing keys of an issuer.
+type testIssuer struct {
+	server *httptest.Server
+
+	mutex sync.Mutex
+	keys  map[string]*rsa.PrivateKey
+
+	discoveryRequests atomic.Int32
+	jwksRequests      atomic.Int32
+	jwksStatusCode    atomic.Int32
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	issuer := &testIssuer{
+		keys: make(map[string]*rsa.PrivateKey),
+	}
+	issuer.jwksStatusCode.Store(http.StatusOK)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		issuer.discoveryRequests.Add(1)
+
+		_ = json.NewEncoder(w).Encode(&discoveryDocument{
+			Issuer:  issuer.server.URL,
+			JWKSURI: issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		issuer.jwksRequests.Add(1)
+
+		if statusCode := int(issuer.jwksStatusCode.Load()); statusCode != http.StatusOK {
+			w.WriteHeader(statusCode)
+
+			return
+		}
+
+		issuer.mutex.Lock()
+		defer issuer.mutex.Unlock()
+
+		keySet := struct {
+			Keys []*jwk `json:"keys"`
+		}{}
+		for keyID, key := range issuer.keys {
+			keySet.Keys = append(keySet.Keys, &jwk{
+				KeyType: "RSA",
+				KeyID:   keyID,
+				Modulus: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				Exp:     base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(keySet)
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// rotateKey publishes a new signing key with the given ID and removes all other keys.
+func (i *testIssuer) rotateKey(t *testing.T, keyID string) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.keys = map[string]*rsa.PrivateKey{keyID: key}
+
+	return key
+}
+
+func (i *testIssuer) claims() *Claims {
+	now := time.Now()
+
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.server.URL,
+			Audience:  jwt.ClaimStrings{testAudience},
+			Subject:   "repo:iotaledger/inx-faucet:ref:refs/heads/develop",
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Repository: "iotaledger/inx-faucet",
+		Actor:      "octocat",
+	}
+}
+
+func signToken(t *testing.T, claims jwt.Claims, keyID string, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+
+	signedToken, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signedToken
+}
+
+func TestVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	key := issuer.rotateKey(t, "key-1")
+
+	verifier := NewVerifier(issuer.server.URL, testAudience, time.Second)
+
+	claims, err := verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.NoError(t, err)
+	require.Equal(t, "iotaledger/inx-faucet", claims.Repository)
+	require.Equal(t, "octocat", claims.Actor)
+
+	// the keys and the discovery document are cached
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, issuer.discoveryRequests.Load())
+	require.EqualValues(t, 1, issuer.jwksRequests.Load())
+}
+
+func TestVerifierInvalidClaims(t *testing.T) {
+	issuer := newTestIssuer(t)
+	key := issuer.rotateKey(t, "key-1")
+
+	verifier := NewVerifier(issuer.server.URL, testAudience, time.Second)
+
+	for name, test := range map[string]struct {
+		modify      func(claims *Claims)
+		expectedErr error
+	}{
+		"wrong issuer":      {modify: func(claims *Claims) { claims.Issuer = "https://example.com" }, expectedErr: ErrInvalidToken},
+		"wrong audience":    {modify: func(claims *Claims) { claims.Audience = jwt.ClaimStrings{"other"} }, expectedErr: ErrInvalidToken},
+		"missing audience":  {modify: func(claims *Claims) { claims.Audience = nil }, expectedErr: ErrInvalidToken},
+		"missing expiry":    {modify: func(claims *Claims) { claims.ExpiresAt = nil }, expectedErr: ErrInvalidToken},
+		"expired":           {modify: func(claims *Claims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-2 * clockSkew)) }, expectedErr: ErrTokenExpired},
+		"not valid yet":     {modify: func(claims *Claims) { claims.NotBefore = jwt.NewNumericDate(time.Now().Add(2 * clockSkew)) }, expectedErr: ErrTokenExpired},
+		"expired in leeway": {modify: func(claims *Claims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-clockSkew / 2)) }},
+	} {
+		claims := issuer.claims()
+		test.modify(claims)
+
+		_, err := verifier.Verify(context.Background(), signToken(t, claims, "key-1", key))
+		if test.expectedErr == nil {
+			require.NoError(t, err, name)
+
+			continue
+		}
+		require.ErrorIs(t, err, test.expectedErr, name)
+	}
+}
+
+func TestVerifierInvalidSignature(t *testing.T) {
+	issuer := newTestIssuer(t)
+	key := issuer.rotateKey(t, "key-1")
+
+	verifier := NewVerifier(issuer.server.URL, testAudience, time.Second)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", otherKey))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// the public key must not be accepted as HMAC secret
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims())
+	hmacToken.Header["kid"] = "key-1"
+	signedToken, err := hmacToken.SignedString(key.PublicKey.N.Bytes())
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), signedToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, issuer.claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), noneToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = verifier.Verify(context.Background(), "not.a.token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifierKeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	key := issuer.rotateKey(t, "key-1")
+
+	verifier := NewVerifier(issuer.server.URL, testAudience, time.Second)
+	verifier.refreshLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	_, err := verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.NoError(t, err)
+
+	// a token with an unknown key ID is rejected, the keys are fetched again
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-2", key))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.EqualValues(t, 2, issuer.jwksRequests.Load())
+
+	// a rotated key is accepted right away, not only after a while
+	rotatedKey := issuer.rotateKey(t, "key-2")
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-2", rotatedKey))
+	require.NoError(t, err)
+	require.EqualValues(t, 3, issuer.jwksRequests.Load())
+
+	// the removed key is no longer accepted
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.EqualValues(t, 1, issuer.discoveryRequests.Load())
+}
+
+func TestVerifierRefreshRateLimit(t *testing.T) {
+	issuer := newTestIssuer(t)
+	key := issuer.rotateKey(t, "key-1")
+
+	verifier := NewVerifier(issuer.server.URL, testAudience, time.Second)
+	verifier.refreshLimiter = rate.NewLimiter(rate.Every(200*time.Millisecond), 1)
+
+	_, err := verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.NoError(t, err)
+
+	// a rotated key is accepted once the rate limit allows to fetch the keys again
+	rotatedKey := issuer.rotateKey(t, "key-2")
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-2", rotatedKey))
+	require.NoError(t, err)
+
+	// concurrent tokens with unknown key IDs share the fetches
+	token := signToken(t, issuer.claims(), "unknown", rotatedKey)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := verifier.Verify(context.Background(), token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}()
+	}
+	wg.Wait()
+	require.LessOrEqual(t, issuer.jwksRequests.Load(), int32(4))
+
+	// the tokens are not rejected if the rate limit doesn't allow to fetch the keys in time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = verifier.Verify(ctx, token)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifierUnavailable(t *testing.T) {
+	issuer := newTestIssuer(t)
+	key := issuer.rotateKey(t, "key-1")
+	issuer.jwksStatusCode.Store(http.StatusInternalServerError)
+
+	verifier := NewVerifier(issuer.server.URL, testAudience, time.Second)
+	verifier.refreshLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	// the token can't be verified, but it is not invalid
+	_, err := verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrInvalidToken)
+	require.NotErrorIs(t, err, ErrTokenExpired)
+
+	// the discovery document is fetched again after a failure
+	issuer.jwksStatusCode.Store(http.StatusOK)
+	_, err = verifier.Verify(context.Background(), signToken(t, issuer.claims(), "key-1", key))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, issuer.discoveryRequests.Load())
+}
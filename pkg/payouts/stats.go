@@ -0,0 +1,113 @@
+package payouts
+
+import (
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// Totals are the aggregated statistics of all payouts in the ledger.
+type Totals struct {
+	// The amount of payouts.
+	Payouts int `json:"payouts"`
+	// The amount of distributed base tokens.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of distributed mana.
+	ManaAmount iotago.Mana `json:"mana"`
+	// The amount of distinct addresses that received a payout.
+	UniqueAddresses int `json:"uniqueAddresses"`
+	// The average duration in seconds between enqueuing a request and the confirmation of its payout.
+	AverageConfirmationTime float64 `json:"averageConfirmationTime"`
+}
+
+// Bucket are the aggregated statistics of the payouts in a time interval.
+type Bucket struct {
+	// The start of the interval.
+	Start time.Time `json:"start"`
+	// The amount of payouts.
+	Payouts int `json:"payouts"`
+	// The amount of distributed base tokens.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of distributed mana.
+	ManaAmount iotago.Mana `json:"mana"`
+}
+
+// stats are the running totals of the ledger.
+type stats struct {
+	payouts          int
+	baseTokenAmount  iotago.BaseToken
+	manaAmount       iotago.Mana
+	addresses        map[string]struct{}
+	confirmationTime time.Duration
+	// the amount of payouts with a known enqueue time.
+	confirmations int
+}
+
+func newStats() *stats {
+	return &stats{
+		addresses: make(map[string]struct{}),
+	}
+}
+
+func (s *stats) add(record *Record) {
+	s.payouts++
+	s.baseTokenAmount += record.BaseTokenAmount
+	s.manaAmount += record.ManaAmount
+	s.addresses[record.Address] = struct{}{}
+
+	if !record.EnqueuedAt.IsZero() && record.Timestamp.After(record.EnqueuedAt) {
+		s.confirmationTime += record.Timestamp.Sub(record.EnqueuedAt)
+		s.confirmations++
+	}
+}
+
+// Totals returns the aggregated statistics of all payouts in the ledger.
+func (s *Store) Totals() *Totals {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	totals := &Totals{
+		Payouts:         s.stats.payouts,
+		BaseTokenAmount: s.stats.baseTokenAmount,
+		ManaAmount:      s.stats.manaAmount,
+		UniqueAddresses: len(s.stats.addresses),
+	}
+	if s.stats.confirmations > 0 {
+		totals.AverageConfirmationTime = (s.stats.confirmationTime / time.Duration(s.stats.confirmations)).Seconds()
+	}
+
+	return totals
+}
+
+// TimeSeries returns the aggregated statistics of the payouts in the given period before now, split into buckets of the given size, oldest first.
+func (s *Store) TimeSeries(now time.Time, period time.Duration, bucketSize time.Duration) []*Bucket {
+	// the buckets are aligned to the bucket size, the last bucket contains now
+	end := now.Truncate(bucketSize).Add(bucketSize)
+	start := end.Add(-period)
+
+	buckets := make([]*Bucket, 0, period/bucketSize)
+	for bucketStart := start; bucketStart.Before(end); bucketStart = bucketStart.Add(bucketSize) {
+		buckets = append(buckets, &Bucket{Start: bucketStart})
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	// the records are ordered by time, so we can stop at the first record before the period
+	for i := len(s.records) - 1; i >= 0; i-- {
+		record := s.records[i]
+		if record.Timestamp.Before(start) {
+			break
+		}
+		if !record.Timestamp.Before(end) {
+			continue
+		}
+
+		bucket := buckets[record.Timestamp.Sub(start)/bucketSize]
+		bucket.Payouts++
+		bucket.BaseTokenAmount += record.BaseTokenAmount
+		bucket.ManaAmount += record.ManaAmount
+	}
+
+	return buckets
+}
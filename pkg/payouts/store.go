@@ -0,0 +1,301 @@
+// Package payouts contains a persistent ledger of the confirmed payouts of the faucet.
+package payouts
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+// Record is a single confirmed payout of the faucet.
+type Record struct {
+	// The time the payout was confirmed.
+	Timestamp time.Time `json:"timestamp"`
+	// The bech32 address of the receiver.
+	Address string `json:"address"`
+	// The type of the request.
+	Type string `json:"type"`
+	// The amount of base tokens that were paid out.
+	BaseTokenAmount iotago.BaseToken `json:"amount"`
+	// The amount of mana that was paid out.
+	ManaAmount iotago.Mana `json:"mana"`
+	// The ID of the faucet transaction.
+	TransactionID string `json:"transactionId"`
+	// The ID of the block that contained the faucet transaction.
+	BlockID string `json:"blockId"`
+	// The tag of the request.
+	Tag string `json:"tag,omitempty"`
+	// The originating IP address of the request.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	// The time the request was enqueued.
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+}
+
+// Filter defines which records are returned by a query.
+type Filter struct {
+	// Only records of the given address are returned if it is not empty.
+	Address string
+	// Only records of the given transaction are returned if it is not empty.
+	TransactionID string
+	// Only records confirmed at or after the given time are returned if it is not zero.
+	From time.Time
+	// Only records confirmed before the given time are returned if it is not zero.
+	To time.Time
+	// The amount of matching records that are skipped.
+	Offset int
+	// The maximum amount of returned records (0 for no limit).
+	Limit int
+}
+
+func (f *Filter) matches(record *Record) bool {
+	if f.Address != "" && record.Address != f.Address {
+		return false
+	}
+	if f.TransactionID != "" && record.TransactionID != f.TransactionID {
+		return false
+	}
+	if !f.From.IsZero() && record.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.Timestamp.Before(f.To) {
+		return false
+	}
+
+	return true
+}
+
+// Store is an append-only ledger of payouts that is persisted as JSON lines in a file.
+// All records are kept in memory for queries.
+// It is safe for concurrent use.
+type Store struct {
+	mutex    sync.RWMutex
+	filePath string
+	file     *os.File
+	records  []*Record
+	stats    *stats
+}
+
+// NewStore opens the ledger in the given file and loads the existing records.
+func NewStore(filePath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return nil, ierrors.Wrap(err, "failed to create the payout ledger directory")
+	}
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to open the payout ledger")
+	}
+
+	records, err := readRecords(file)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, ierrors.Wrapf(err, "failed to read the payout ledger %s", filePath)
+	}
+
+	store := &Store{
+		filePath: filePath,
+		file:     file,
+		records:  records,
+		stats:    newStats(),
+	}
+	for _, record := range records {
+		store.stats.add(record)
+	}
+
+	return store, nil
+}
+
+func readRecords(reader io.Reader) ([]*Record, error) {
+	records := make([]*Record, 0)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal(line, record); err != nil {
+			// a partially written last line after a crash is ignored
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// Add appends the given records to the ledger.
+func (s *Store) Add(records ...*Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return ierrors.Wrap(err, "failed to write to the payout ledger")
+	}
+	s.records = append(s.records, records...)
+	for _, record := range records {
+		s.stats.add(record)
+	}
+
+	return nil
+}
+
+// Query returns the records matching the filter, newest first, and the total amount of matching records.
+func (s *Store) Query(filter *Filter) ([]*Record, int) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]*Record, 0)
+	var total int
+	for i := len(s.records) - 1; i >= 0; i-- {
+		record := s.records[i]
+		if !filter.matches(record) {
+			continue
+		}
+
+		total++
+		if total <= filter.Offset || (filter.Limit > 0 && len(result) >= filter.Limit) {
+			continue
+		}
+		result = append(result, record)
+	}
+
+	return result, total
+}
+
+// Anonymize applies the given function to the records that were confirmed before the given time.
+// The function returns true if it changed the record, e.g. because it removed or hashed the client metadata.
+// The ledger file is rewritten if records were changed. It returns the amount of changed records.
+func (s *Store) Anonymize(before time.Time, anonymize func(record *Record) bool) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var changed int
+	for _, record := range s.records {
+		// the records are ordered by time
+		if !record.Timestamp.Before(before) {
+			break
+		}
+
+		if anonymize(record) {
+			changed++
+		}
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+
+	if err := s.rewriteWithoutLocking(); err != nil {
+		return 0, err
+	}
+
+	return changed, nil
+}
+
+// rewriteWithoutLocking replaces the ledger file with the records in memory.
+// write lock must be acquired outside.
+func (s *Store) rewriteWithoutLocking() error {
+	tempFilePath := s.filePath + ".tmp"
+	target, err := os.OpenFile(tempFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to create the rewritten payout ledger")
+	}
+
+	writer := bufio.NewWriter(target)
+	encoder := json.NewEncoder(writer)
+	for _, record := range s.records {
+		if err := encoder.Encode(record); err != nil {
+			_ = target.Close()
+
+			return ierrors.Wrap(err, "failed to write the rewritten payout ledger")
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = target.Close()
+
+		return ierrors.Wrap(err, "failed to write the rewritten payout ledger")
+	}
+	if err := target.Close(); err != nil {
+		return ierrors.Wrap(err, "failed to write the rewritten payout ledger")
+	}
+
+	if err := os.Rename(tempFilePath, s.filePath); err != nil {
+		return ierrors.Wrap(err, "failed to replace the payout ledger")
+	}
+
+	// the old file was replaced, so new records must be appended to the new one
+	file, err := os.OpenFile(s.filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to open the payout ledger")
+	}
+	_ = s.file.Close()
+	s.file = file
+
+	return nil
+}
+
+// Close closes the file of the ledger.
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
+
+// WriteCSV writes the given records including a header row as CSV.
+func WriteCSV(writer io.Writer, records []*Record) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if err := csvWriter.Write([]string{"timestamp", "address", "type", "amount", "mana", "transactionId", "blockId", "tag", "remoteIp"}); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := csvWriter.Write([]string{
+			record.Timestamp.UTC().Format(time.RFC3339),
+			record.Address,
+			record.Type,
+			strconv.FormatUint(uint64(record.BaseTokenAmount), 10),
+			strconv.FormatUint(uint64(record.ManaAmount), 10),
+			record.TransactionID,
+			record.BlockID,
+			record.Tag,
+			record.RemoteIP,
+		}); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
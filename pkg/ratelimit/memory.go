@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// MemoryLimiter is a token bucket rate limiter per identifier that is kept in memory.
+// In contrast to the store of echo, its state can be exported and imported.
+type MemoryLimiter struct {
+	mutex sync.Mutex
+
+	period      time.Duration
+	maxRequests int
+	limit       rate.Limit
+	burst       int
+	expiresIn   time.Duration
+
+	visitors    map[string]*visitor
+	lastCleanup time.Time
+}
+
+// visitor is the rate limiter of a single identifier.
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewMemoryLimiter creates a new limiter whose state is kept in memory.
+func NewMemoryLimiter(period time.Duration, maxRequests int, maxBurst int) (*MemoryLimiter, error) {
+	store := &MemoryLimiter{
+		visitors:    make(map[string]*visitor),
+		lastCleanup: time.Now(),
+	}
+	if err := store.SetLimits(period, maxRequests, maxBurst); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// SetLimits changes the limits of the limiter, the remaining tokens of the known identifiers are kept.
+func (s *MemoryLimiter) SetLimits(period time.Duration, maxRequests int, maxBurst int) error {
+	if err := validateLimits(period, maxRequests, maxBurst); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.period = period
+	s.maxRequests = maxRequests
+	s.limit = rate.Limit(float64(maxRequests) / period.Seconds())
+	s.burst = maxBurst
+	s.expiresIn = period
+
+	now := time.Now()
+	for _, v := range s.visitors {
+		v.limiter.SetLimitAt(now, s.limit)
+		v.limiter.SetBurstAt(now, s.burst)
+	}
+
+	return nil
+}
+
+// Limits returns the period and the maximum number of requests per period.
+func (s *MemoryLimiter) Limits() (time.Duration, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.period, s.maxRequests
+}
+
+// Allow checks if the given identifier is allowed to make a request.
+func (s *MemoryLimiter) Allow(identifier string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	v := s.visitorWithoutLocking(identifier, now)
+	v.lastSeen = now
+
+	if now.Sub(s.lastCleanup) > s.expiresIn {
+		s.cleanupStaleVisitorsWithoutLocking(now)
+	}
+
+	return v.limiter.AllowN(now, 1), nil
+}
+
+// visitorWithoutLocking returns the visitor of the given identifier and creates it if it doesn't exist.
+// lock must be acquired outside.
+func (s *MemoryLimiter) visitorWithoutLocking(identifier string, now time.Time) *visitor {
+	v, exists := s.visitors[identifier]
+	if !exists {
+		v = &visitor{
+			limiter:  rate.NewLimiter(s.limit, s.burst),
+			lastSeen: now,
+		}
+		s.visitors[identifier] = v
+	}
+
+	return v
+}
+
+// cleanupStaleVisitorsWithoutLocking removes all visitors that were not seen within the expiry duration.
+// lock must be acquired outside.
+func (s *MemoryLimiter) cleanupStaleVisitorsWithoutLocking(now time.Time) {
+	for identifier, v := range s.visitors {
+		if now.Sub(v.lastSeen) > s.expiresIn {
+			delete(s.visitors, identifier)
+		}
+	}
+	s.lastCleanup = now
+}
+
+// Snapshot returns the remaining tokens of all identifiers.
+func (s *MemoryLimiter) Snapshot() map[string]float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	tokens := make(map[string]float64, len(s.visitors))
+	for identifier, v := range s.visitors {
+		tokens[identifier] = v.limiter.TokensAt(now)
+	}
+
+	return tokens
+}
+
+// Restore sets the remaining tokens of the given identifiers.
+func (s *MemoryLimiter) Restore(tokens map[string]float64) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	for identifier, remainingTokens := range tokens {
+		v := s.visitorWithoutLocking(identifier, now)
+		v.lastSeen = now
+
+		// new limiters start with a full bucket, so we consume the tokens that were already used
+		// a partially used token counts as used, so the restored limiter never allows more requests
+		if usedTokens := int(math.Ceil(v.limiter.TokensAt(now) - remainingTokens)); usedTokens > 0 {
+			v.limiter.AllowN(now, usedTokens)
+		}
+	}
+
+	return len(tokens)
+}
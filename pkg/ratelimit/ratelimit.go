@@ -0,0 +1,145 @@
+// Package ratelimit contains the rate limiters of the faucet.
+// The limiters are either kept in memory or stored in redis, so the limits are shared between multiple faucet instances.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// ErrInvalidLimits is returned if the limits of a limiter are invalid.
+var ErrInvalidLimits = ierrors.New("invalid rate limits")
+
+// Scope defines by which property of a client the requests are counted.
+type Scope string
+
+const (
+	// ScopeIP counts the requests per originating IP address.
+	ScopeIP Scope = "ip"
+	// ScopeKey counts the requests per API key or other credentials of the client.
+	ScopeKey Scope = "key"
+	// ScopeGlobal counts all requests together.
+	ScopeGlobal Scope = "global"
+)
+
+// Identifier returns the identifier of a client in the given scope.
+// The value is ignored for the global scope.
+func Identifier(scope Scope, value string) string {
+	if scope == ScopeGlobal {
+		return string(ScopeGlobal)
+	}
+
+	return string(scope) + ":" + value
+}
+
+// Limiter limits the requests per identifier with a token bucket,
+// which holds up to maxBurst tokens and is refilled with maxRequests tokens per period.
+// It implements the rate limiter store of echo.
+type Limiter interface {
+	// Allow checks if the given identifier is allowed to make a request.
+	Allow(identifier string) (bool, error)
+	// SetLimits changes the limits of the limiter.
+	SetLimits(period time.Duration, maxRequests int, maxBurst int) error
+	// Limits returns the period and the maximum number of requests per period.
+	Limits() (time.Duration, int)
+}
+
+var (
+	_ Limiter = &MemoryLimiter{}
+	_ Limiter = &RedisLimiter{}
+)
+
+// validateLimits checks if the given limits can be applied to a limiter.
+func validateLimits(period time.Duration, maxRequests int, maxBurst int) error {
+	if period <= 0 {
+		return ierrors.Wrapf(ErrInvalidLimits, "period must be positive, got %s", period)
+	}
+	if maxRequests <= 0 {
+		return ierrors.Wrapf(ErrInvalidLimits, "maxRequests must be positive, got %d", maxRequests)
+	}
+	if maxBurst < 0 {
+		return ierrors.Wrapf(ErrInvalidLimits, "maxBurst must not be negative, got %d", maxBurst)
+	}
+
+	return nil
+}
+
+// Manager creates the limiters with the configured backend.
+type Manager struct {
+	redisClient    *redis.Client
+	keyPrefix      string
+	requestTimeout time.Duration
+
+	mutex          sync.RWMutex
+	memoryLimiters map[string]*MemoryLimiter
+}
+
+// NewManager creates a new manager. If a redis client is given, the limiters are stored in redis
+// under the given key prefix, otherwise they are kept in memory.
+func NewManager(redisClient *redis.Client, keyPrefix string, requestTimeout time.Duration) *Manager {
+	return &Manager{
+		redisClient:    redisClient,
+		keyPrefix:      keyPrefix,
+		requestTimeout: requestTimeout,
+		memoryLimiters: make(map[string]*MemoryLimiter),
+	}
+}
+
+// New creates a new limiter with the given name and limits.
+func (m *Manager) New(name string, period time.Duration, maxRequests int, maxBurst int) (Limiter, error) {
+	if m.redisClient != nil {
+		limiter, err := NewRedisLimiter(m.redisClient, m.keyPrefix+":"+name, m.requestTimeout, period, maxRequests, maxBurst)
+		if err != nil {
+			return nil, err
+		}
+
+		return limiter, nil
+	}
+
+	limiter, err := NewMemoryLimiter(period, maxRequests, maxBurst)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.memoryLimiters[name] = limiter
+
+	return limiter, nil
+}
+
+// Snapshot returns the remaining tokens per identifier of all limiters that are kept in memory by their name.
+func (m *Manager) Snapshot() map[string]map[string]float64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	snapshot := make(map[string]map[string]float64, len(m.memoryLimiters))
+	for name, limiter := range m.memoryLimiters {
+		snapshot[name] = limiter.Snapshot()
+	}
+
+	return snapshot
+}
+
+// Restore sets the remaining tokens of the limiters that are kept in memory and returns the amount of restored entries.
+// Limiters that don't exist or are stored in redis are skipped.
+func (m *Manager) Restore(snapshot map[string]map[string]float64) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var restored int
+	for name, tokens := range snapshot {
+		limiter, exists := m.memoryLimiters[name]
+		if !exists {
+			continue
+		}
+		restored += limiter.Restore(tokens)
+	}
+
+	return restored
+}
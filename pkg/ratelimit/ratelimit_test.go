@@ -0,0 +1,170 @@
+package ratelimit_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/inx-faucet/pkg/ratelimit"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return server, client
+}
+
+// allowed returns the amount of allowed requests of the given identifier.
+func allowed(t *testing.T, limiter ratelimit.Limiter, identifier string, requests int) int {
+	t.Helper()
+
+	var allowedRequests int
+	for range requests {
+		allow, err := limiter.Allow(identifier)
+		require.NoError(t, err)
+		if allow {
+			allowedRequests++
+		}
+	}
+
+	return allowedRequests
+}
+
+func TestIdentifier(t *testing.T) {
+	require.Equal(t, "ip:127.0.0.1", ratelimit.Identifier(ratelimit.ScopeIP, "127.0.0.1"))
+	require.Equal(t, "key:abc", ratelimit.Identifier(ratelimit.ScopeKey, "abc"))
+	require.Equal(t, "global", ratelimit.Identifier(ratelimit.ScopeGlobal, "127.0.0.1"))
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	limiter, err := ratelimit.NewMemoryLimiter(time.Hour, 1, 5)
+	require.NoError(t, err)
+
+	// the burst is allowed right away, afterwards the bucket is refilled with one token per hour
+	require.Equal(t, 5, allowed(t, limiter, "ip:1", 10))
+	require.Equal(t, 5, allowed(t, limiter, "ip:2", 10))
+
+	// the remaining tokens are kept when the limits change
+	require.NoError(t, limiter.SetLimits(time.Hour, 1, 10))
+	require.Equal(t, 0, allowed(t, limiter, "ip:1", 1))
+
+	period, maxRequests := limiter.Limits()
+	require.Equal(t, time.Hour, period)
+	require.Equal(t, 1, maxRequests)
+}
+
+func TestMemoryLimiterSnapshot(t *testing.T) {
+	limiter, err := ratelimit.NewMemoryLimiter(time.Hour, 1, 5)
+	require.NoError(t, err)
+	require.Equal(t, 3, allowed(t, limiter, "ip:1", 3))
+
+	snapshot := limiter.Snapshot()
+	require.InDelta(t, 2, snapshot["ip:1"], 0.01)
+
+	restoredLimiter, err := ratelimit.NewMemoryLimiter(time.Hour, 1, 5)
+	require.NoError(t, err)
+	require.Equal(t, 1, restoredLimiter.Restore(snapshot))
+	require.Equal(t, 2, allowed(t, restoredLimiter, "ip:1", 5))
+}
+
+func TestRedisLimiter(t *testing.T) {
+	server, client := newTestRedis(t)
+	now := time.Now()
+	server.SetTime(now)
+
+	limiter, err := ratelimit.NewRedisLimiter(client, "faucet:ratelimit:api", time.Second, time.Minute, 6, 3)
+	require.NoError(t, err)
+
+	// the burst is allowed right away, like in the memory limiter
+	require.Equal(t, 3, allowed(t, limiter, "ip:1", 5))
+	require.Equal(t, 3, allowed(t, limiter, "ip:2", 5))
+
+	// the bucket is refilled with one token every 10 seconds
+	server.SetTime(now.Add(10 * time.Second))
+	require.Equal(t, 1, allowed(t, limiter, "ip:1", 5))
+
+	// the bucket never holds more than the burst
+	server.SetTime(now.Add(time.Hour))
+	require.Equal(t, 3, allowed(t, limiter, "ip:1", 5))
+
+	// unused buckets expire after the period, they would be full again by then
+	require.True(t, server.Exists("faucet:ratelimit:api:ip:1"))
+	server.FastForward(59 * time.Second)
+	require.True(t, server.Exists("faucet:ratelimit:api:ip:1"))
+	server.FastForward(time.Second)
+	require.False(t, server.Exists("faucet:ratelimit:api:ip:1"))
+}
+
+func TestRedisLimiterConcurrent(t *testing.T) {
+	_, client := newTestRedis(t)
+
+	// two faucet instances share the same bucket
+	limiters := make([]*ratelimit.RedisLimiter, 2)
+	for i := range limiters {
+		limiter, err := ratelimit.NewRedisLimiter(client, "faucet:ratelimit:api", time.Second, time.Hour, 1, 10)
+		require.NoError(t, err)
+		limiters[i] = limiter
+	}
+
+	var allowedRequests atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			allow, err := limiters[i%len(limiters)].Allow("global")
+			assert.NoError(t, err)
+			if allow {
+				allowedRequests.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 10, allowedRequests.Load())
+}
+
+func TestInvalidLimits(t *testing.T) {
+	_, client := newTestRedis(t)
+
+	for name, limits := range map[string]struct {
+		period      time.Duration
+		maxRequests int
+		maxBurst    int
+	}{
+		"zero period":       {period: 0, maxRequests: 1, maxBurst: 1},
+		"negative period":   {period: -time.Second, maxRequests: 1, maxBurst: 1},
+		"zero max requests": {period: time.Second, maxRequests: 0, maxBurst: 1},
+		"negative burst":    {period: time.Second, maxRequests: 1, maxBurst: -1},
+	} {
+		_, err := ratelimit.NewMemoryLimiter(limits.period, limits.maxRequests, limits.maxBurst)
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimits, name)
+
+		_, err = ratelimit.NewRedisLimiter(client, "faucet", time.Second, limits.period, limits.maxRequests, limits.maxBurst)
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimits, name)
+
+		limiter, err := ratelimit.NewManager(nil, "faucet", time.Second).New("api", limits.period, limits.maxRequests, limits.maxBurst)
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimits, name)
+		require.Nil(t, limiter, name)
+	}
+
+	// invalid limits are not applied
+	limiter, err := ratelimit.NewRedisLimiter(client, "faucet", time.Second, time.Minute, 10, 5)
+	require.NoError(t, err)
+	require.ErrorIs(t, limiter.SetLimits(0, 10, 5), ratelimit.ErrInvalidLimits)
+
+	period, maxRequests := limiter.Limits()
+	require.Equal(t, time.Minute, period)
+	require.Equal(t, 10, maxRequests)
+}
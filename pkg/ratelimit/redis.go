@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript takes a token from the bucket of an identifier if one is available.
+// The bucket holds up to the burst amount of tokens and is refilled with the given rate.
+// The time of the redis server is used, so the clocks of the faucet instances don't need to be in sync,
+// and the whole update runs atomically, so concurrent requests can't take the same token.
+// Writing after reading the time requires the effects replication of scripts, which is the default since redis 5.
+//
+// KEYS[1]: the key of the bucket
+// ARGV[1]: the maximum amount of tokens in the bucket
+// ARGV[2]: the amount of tokens that are added per microsecond
+// ARGV[3]: the expiry of the bucket in milliseconds
+var tokenBucketScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local expiry = tonumber(ARGV[3])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil or updated == nil then
+	-- new buckets start full
+	tokens = burst
+	updated = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], expiry)
+
+return allowed
+`)
+
+// RedisLimiter is a token bucket rate limiter that is stored in redis,
+// so the limits are shared between all faucet instances.
+// It behaves like the MemoryLimiter, the buckets are updated atomically by a script on the redis server.
+type RedisLimiter struct {
+	client         *redis.Client
+	keyPrefix      string
+	requestTimeout time.Duration
+
+	mutex       sync.RWMutex
+	period      time.Duration
+	maxRequests int
+	burst       int
+	// the amount of tokens that are added per microsecond.
+	tokensPerMicrosecond float64
+	// the duration after which an unused bucket is full again and can be removed.
+	expiry time.Duration
+}
+
+// NewRedisLimiter creates a new limiter that is stored in redis under the given key prefix.
+func NewRedisLimiter(client *redis.Client, keyPrefix string, requestTimeout time.Duration, period time.Duration, maxRequests int, maxBurst int) (*RedisLimiter, error) {
+	store := &RedisLimiter{
+		client:         client,
+		keyPrefix:      keyPrefix,
+		requestTimeout: requestTimeout,
+	}
+	if err := store.SetLimits(period, maxRequests, maxBurst); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// SetLimits changes the limits of the limiter, the remaining tokens of the known identifiers are kept.
+func (s *RedisLimiter) SetLimits(period time.Duration, maxRequests int, maxBurst int) error {
+	if err := validateLimits(period, maxRequests, maxBurst); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.period = period
+	s.maxRequests = maxRequests
+	s.burst = maxBurst
+	s.tokensPerMicrosecond = float64(maxRequests) / (float64(period) / float64(time.Microsecond))
+	s.expiry = max(period, time.Duration(float64(maxBurst)/float64(maxRequests)*float64(period)))
+
+	return nil
+}
+
+// Limits returns the period and the maximum number of requests per period.
+func (s *RedisLimiter) Limits() (time.Duration, int) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.period, s.maxRequests
+}
+
+// Allow checks if the given identifier is allowed to make a request.
+func (s *RedisLimiter) Allow(identifier string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout)
+	defer cancel()
+
+	s.mutex.RLock()
+	burst, tokensPerMicrosecond, expiry := s.burst, s.tokensPerMicrosecond, s.expiry
+	s.mutex.RUnlock()
+
+	allowed, err := tokenBucketScript.Run(ctx, s.client, []string{s.keyPrefix + ":" + identifier},
+		burst,
+		strconv.FormatFloat(tokensPerMicrosecond, 'f', -1, 64),
+		max(expiry.Milliseconds(), 1),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return allowed == 1, nil
+}
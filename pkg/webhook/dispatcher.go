@@ -0,0 +1,228 @@
+// Package webhook delivers signed JSON notifications to external HTTP endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+const (
+	// HeaderEvent contains the type of the event of the notification.
+	HeaderEvent = "X-Faucet-Event"
+	// HeaderDelivery contains the unique ID of the notification, it is the same for all retries.
+	HeaderDelivery = "X-Faucet-Delivery"
+	// HeaderTimestamp contains the unix timestamp of the delivery attempt that is part of the signature.
+	HeaderTimestamp = "X-Faucet-Timestamp"
+	// HeaderSignature contains the HMAC-SHA256 signature of the timestamp and the body ("sha256=<hex>").
+	HeaderSignature = "X-Faucet-Signature"
+
+	// the amount of notifications that are delivered in parallel.
+	workerCount = 4
+)
+
+// ErrQueueFull is returned if a notification is dropped because too many notifications are waiting for delivery.
+var ErrQueueFull = ierrors.New("webhook queue is full")
+
+// Options define the delivery options of the dispatcher.
+type Options struct {
+	// URLs are the endpoints that receive all notifications.
+	URLs []string
+	// Secret is used to sign the notifications. The signature header is omitted if it is empty.
+	Secret string
+	// Timeout is the timeout of a single delivery attempt.
+	Timeout time.Duration
+	// MaxRetries is the maximum amount of retries of a failed delivery.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, it is doubled with every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries.
+	MaxBackoff time.Duration
+	// QueueSize is the maximum amount of notifications that wait for delivery.
+	QueueSize int
+}
+
+// Notification is the JSON body that is sent to the endpoints.
+type Notification struct {
+	// The unique ID of the notification.
+	ID string `json:"id"`
+	// The type of the event.
+	Event string `json:"event"`
+	// The time the event happened.
+	Timestamp time.Time `json:"timestamp"`
+	// The event specific data.
+	Data any `json:"data"`
+}
+
+// delivery is a notification that is sent to a single endpoint.
+type delivery struct {
+	url   string
+	id    string
+	event string
+	body  []byte
+}
+
+// Dispatcher sends notifications to the configured endpoints in the background.
+// It is safe for concurrent use.
+type Dispatcher struct {
+	opts       *Options
+	client     *http.Client
+	deliveries chan *delivery
+	onError    func(err error)
+}
+
+// NewDispatcher creates a new dispatcher. Failed deliveries are reported to onError after all retries.
+func NewDispatcher(opts *Options, onError func(err error)) *Dispatcher {
+	return &Dispatcher{
+		opts:       opts,
+		client:     &http.Client{Timeout: opts.Timeout},
+		deliveries: make(chan *delivery, opts.QueueSize),
+		onError:    onError,
+	}
+}
+
+// Sign returns the signature of a notification body for the given timestamp.
+// Receivers should recompute it with the shared secret and compare it in constant time.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify queues a notification for all endpoints. It doesn't block.
+func (d *Dispatcher) Notify(event string, data any) error {
+	id, err := newDeliveryID()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(&Notification{
+		ID:        id,
+		Event:     event,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return ierrors.Wrap(err, "failed to encode webhook notification")
+	}
+
+	for _, url := range d.opts.URLs {
+		select {
+		case d.deliveries <- &delivery{url: url, id: id, event: event, body: body}:
+		default:
+			return ierrors.Wrapf(ErrQueueFull, "dropping %s notification for %s", event, url)
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the queued notifications until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range workerCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-d.deliveries:
+					if err := d.deliver(ctx, delivery); err != nil && ctx.Err() == nil {
+						d.onError(err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends a notification to its endpoint and retries with an exponential backoff if it failed.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *delivery) error {
+	backoff := d.opts.InitialBackoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retryable bool
+		if retryable, err = d.send(ctx, delivery); err == nil {
+			return nil
+		}
+
+		if !retryable || attempt >= d.opts.MaxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, d.opts.MaxBackoff)
+	}
+
+	return ierrors.Wrapf(err, "failed to deliver %s notification %s to %s", delivery.event, delivery.id, delivery.url)
+}
+
+// send executes a single delivery attempt. It returns whether a failed attempt should be retried.
+func (d *Dispatcher) send(ctx context.Context, delivery *delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.event)
+	req.Header.Set(HeaderDelivery, delivery.id)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if d.opts.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(d.opts.Secret, timestamp, delivery.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	// the body is drained, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, ierrors.Errorf("unexpected status code %d", resp.StatusCode)
+	default:
+		// the endpoint rejected the notification, retrying doesn't help
+		return false, ierrors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
+
+// newDeliveryID creates a random ID for a notification.
+func newDeliveryID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", ierrors.Wrap(err, "failed to create webhook delivery ID")
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/client"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+)
+
+const (
+	// requestCommand is the name of the subcommand that requests funds from a running faucet.
+	requestCommand = "request"
+
+	exitCodeSuccess = 0
+	exitCodeFailure = 1
+	exitCodeUsage   = 2
+)
+
+// runRequestCommand requests funds for an address from a running faucet and waits until they are paid out.
+// It returns the exit code of the command.
+func runRequestCommand(args []string) int {
+	flagSet := flag.NewFlagSet(requestCommand, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), "Usage: inx-faucet %s --url <faucet URL> --address <bech32 address> [options]\n\n", requestCommand)
+		fmt.Fprintln(flagSet.Output(), "Requests funds from a running faucet and waits until they are paid out.")
+		fmt.Fprintln(flagSet.Output(), "The exit code is 0 if the funds arrived, 1 if the request failed and 2 for invalid arguments.")
+		fmt.Fprintln(flagSet.Output())
+		flagSet.PrintDefaults()
+	}
+
+	faucetURL := flagSet.String("url", "", "the URL of the faucet, including the base path")
+	address := flagSet.String("address", "", "the bech32 address that receives the funds")
+	tag := flagSet.String("tag", "", "the optional tag that is added to the data of the faucet transaction")
+	requestType := flagSet.String("type", "", "the optional type of the request")
+	apiKey := flagSet.String("apikey", "", "the optional priority API key of the faucet")
+	wait := flagSet.Bool("wait", true, "whether to wait until the funds are paid out")
+	timeout := flagSet.Duration("timeout", 5*time.Minute, "the maximum time to wait for the funds")
+	pollInterval := flagSet.Duration("poll-interval", 2*time.Second, "the interval in which the status of the request is polled")
+
+	if err := flagSet.Parse(args); err != nil {
+		return exitCodeUsage
+	}
+
+	if *faucetURL == "" || *address == "" {
+		flagSet.Usage()
+
+		return exitCodeUsage
+	}
+
+	// the address is validated locally, so typos are detected before the faucet is contacted
+	hrp, _, err := iotago.ParseBech32(*address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid address \"%s\": %s\n", *address, err)
+
+		return exitCodeUsage
+	}
+
+	var opts []client.Option
+	if *apiKey != "" {
+		opts = append(opts, client.WithAPIKey(*apiKey))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := requestFunds(ctx, client.New(*faucetURL, opts...), hrp, &faucet.EnqueueRequest{
+		Address: *address,
+		Tag:     *tag,
+		Type:    faucet.RequestType(*requestType),
+	}, *wait, *pollInterval); err != nil {
+		fmt.Fprintf(os.Stderr, "requesting funds failed: %s\n", err)
+
+		return exitCodeFailure
+	}
+
+	return exitCodeSuccess
+}
+
+// requestFunds enqueues the request and polls its status until the funds are on the address.
+func requestFunds(ctx context.Context, faucetClient *client.Client, hrp iotago.NetworkPrefix, request *faucet.EnqueueRequest, wait bool, pollInterval time.Duration) error {
+	info, err := faucetClient.Info(ctx)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to query the faucet info")
+	}
+
+	if hrp != info.Bech32HRP {
+		return ierrors.Errorf("the address belongs to network \"%s\", but the faucet serves network \"%s\"", hrp, info.Bech32HRP)
+	}
+
+	preview, err := faucetClient.Preview(ctx, request.Address)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to preview the request")
+	}
+
+	if !preview.Eligible {
+		return ierrors.Errorf("the address is not eligible: %v", preview.Reasons)
+	}
+
+	response, err := faucetClient.Enqueue(ctx, request)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("enqueued request for %d %s to %s, waiting requests: %d\n", preview.BaseTokenAmount, info.TokenName, response.Address, response.WaitingRequests)
+
+	if !wait {
+		return nil
+	}
+
+	var lastStatus faucet.RequestStatusResponse
+	for {
+		status, err := faucetClient.RequestStatus(ctx, request.Address)
+		switch {
+		case err == nil:
+			if status.State != lastStatus.State || status.Position != lastStatus.Position {
+				printRequestStatus(status)
+			}
+			lastStatus = *status
+
+		case client.IsNotFound(err):
+			// the request is not tracked anymore, it was either paid out or dropped by the faucet
+			paidOut, err := isPaidOut(ctx, faucetClient, request.Address, preview, lastStatus.State)
+			if err != nil {
+				return err
+			}
+
+			if paidOut {
+				fmt.Printf("paid out %d %s to %s\n", preview.BaseTokenAmount, info.TokenName, request.Address)
+
+				return nil
+			}
+
+		default:
+			return ierrors.Wrap(err, "failed to query the status of the request")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ierrors.New("the funds were not paid out before the timeout")
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// isPaidOut checks if the funds of the request arrived on the address.
+func isPaidOut(ctx context.Context, faucetClient *client.Client, bech32Addr string, before *faucet.PreviewResponse, lastState faucet.RequestState) (bool, error) {
+	after, err := faucetClient.Preview(ctx, bech32Addr)
+	if err != nil {
+		return false, ierrors.Wrap(err, "failed to query the balance of the address")
+	}
+
+	if before.Balance == nil || after.Balance == nil {
+		// the balance is unknown to the faucet, the request is assumed to be paid out if it was part of a transaction
+		return lastState == faucet.RequestStatePending, nil
+	}
+
+	return *after.Balance >= *before.Balance+before.BaseTokenAmount, nil
+}
+
+func printRequestStatus(status *faucet.RequestStatusResponse) {
+	switch status.State {
+	case faucet.RequestStateQueued:
+		fmt.Printf("request is queued at position %d of %d\n", status.Position, status.WaitingRequests)
+	case faucet.RequestStatePending:
+		fmt.Printf("request is part of transaction %s in block %s\n", status.TransactionID, status.BlockID)
+	default:
+		fmt.Printf("request is %s\n", status.State)
+	}
+}
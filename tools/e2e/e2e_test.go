@@ -0,0 +1,91 @@
+//go:build e2e
+
+// Package e2e runs end-to-end scenarios against a faucet that is connected to a private tangle.
+// The private tangle is started with the docker-compose.yml in this folder from the genesis snapshot
+// in testdata/snapshot.bin, see README.md for how the fixture is created.
+// The private key of the faucet is read from the FAUCET_PRV_KEY environment variable,
+// it is used to issue conflicting transactions.
+//
+// Usage:
+//
+//	FAUCET_PRV_KEY=<key> go test -tags e2e -timeout 30m ./tools/e2e
+//
+// The scenarios are skipped if the fixture or the private key of the faucet is missing.
+package e2e
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	envFaucetPrivateKey = "FAUCET_PRV_KEY"
+	// the fraction of the amounts that is randomly added or subtracted by the faucet,
+	// it is passed to the faucet by the docker-compose.yml as well.
+	envFaucetAmountJitter = "FAUCET_AMOUNT_JITTER"
+	// the default of FAUCET_AMOUNT_JITTER in the docker-compose.yml.
+	defaultAmountJitter = 0.1
+	// the genesis snapshot the private tangle is started from.
+	snapshotFixture = "testdata/snapshot.bin"
+)
+
+var (
+	faucetURL   = flag.String("e2e.faucet", "http://localhost:8091", "the URL of the faucet")
+	nodeURL     = flag.String("e2e.node", "http://localhost:14265", "the URL of the REST API of the node, including the indexer and the block issuer")
+	composeFile = flag.String("e2e.compose-file", "docker-compose.yml", "the docker compose file of the private tangle, it is used to pause the validator")
+	batchSize   = flag.Int("e2e.batch-size", 20, "the amount of concurrent requests in the batch scenarios")
+	timeout     = flag.Duration("e2e.timeout", 3*time.Minute, "the maximum time to wait for the funds of a request")
+	settle      = flag.Duration("e2e.settle", 15*time.Second, "the time to wait after a payout to detect duplicate payouts")
+	orphanPause = flag.Duration("e2e.orphan-pause", 5*time.Minute, "the time the validator is paused in the orphan scenario, it must exceed the maximum committable age")
+)
+
+var (
+	sharedHarnessOnce sync.Once
+	sharedHarness     *harness
+	sharedHarnessErr  error
+)
+
+// newTestHarness returns the harness that is shared by all scenarios.
+// The scenario is skipped if the fixture of the private tangle or the private key of the faucet is missing.
+func newTestHarness(t *testing.T) *harness {
+	t.Helper()
+
+	if _, err := os.Stat(snapshotFixture); err != nil {
+		t.Skipf("the genesis snapshot %s of the private tangle is missing, see README.md", snapshotFixture)
+	}
+	if os.Getenv(envFaucetPrivateKey) == "" {
+		t.Skipf("environment variable %s is not set, see README.md", envFaucetPrivateKey)
+	}
+
+	sharedHarnessOnce.Do(func() {
+		amountJitter := defaultAmountJitter
+		if value := os.Getenv(envFaucetAmountJitter); value != "" {
+			amountJitter, sharedHarnessErr = strconv.ParseFloat(value, 64)
+			if sharedHarnessErr != nil {
+				return
+			}
+		}
+
+		sharedHarness, sharedHarnessErr = newHarness(context.Background(), &harnessOptions{
+			faucetURL:    strings.TrimSuffix(*faucetURL, "/"),
+			nodeURL:      *nodeURL,
+			composeFile:  *composeFile,
+			batchSize:    *batchSize,
+			timeout:      *timeout,
+			settle:       *settle,
+			orphanPause:  *orphanPause,
+			amountJitter: amountJitter,
+		})
+	})
+	if sharedHarnessErr != nil {
+		t.Fatalf("failed to create the harness: %s", sharedHarnessErr)
+	}
+
+	return sharedHarness
+}
@@ -0,0 +1,376 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/iotaledger/hive.go/crypto"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+	"github.com/iotaledger/iota.go/v4/builder"
+	"github.com/iotaledger/iota.go/v4/nodeclient"
+	"github.com/iotaledger/iota.go/v4/tpkg"
+)
+
+const (
+	// the interval in which the node and the faucet are polled.
+	pollInterval = time.Second
+	// the timeout of a single HTTP request.
+	requestTimeout = 10 * time.Second
+	// the service of the validator in the docker compose file.
+	validatorService = "inx-validator"
+)
+
+type harnessOptions struct {
+	faucetURL   string
+	nodeURL     string
+	composeFile string
+	batchSize   int
+	timeout     time.Duration
+	settle      time.Duration
+	orphanPause time.Duration
+	// the fraction of the full and the small amount that is randomly added or subtracted by the faucet.
+	amountJitter float64
+}
+
+// harness accesses the faucet, the node and the docker containers of the private tangle.
+type harness struct {
+	opts *harnessOptions
+
+	httpClient  *http.Client
+	node        *nodeclient.Client
+	indexer     nodeclient.IndexerClient
+	blockIssuer nodeclient.BlockIssuerClient
+	hrp         iotago.NetworkPrefix
+
+	// the restricted address of the faucet and the signer that unlocks its outputs.
+	faucetAddress iotago.Address
+	faucetSigner  iotago.AddressSigner
+}
+
+func newHarness(ctx context.Context, opts *harnessOptions) (*harness, error) {
+	privateKey, err := crypto.ParseEd25519PrivateKeyFromString(os.Getenv(envFaucetPrivateKey))
+	if err != nil {
+		return nil, ierrors.Wrapf(err, "environment variable %s contains no valid private key", envFaucetPrivateKey)
+	}
+
+	//nolint:forcetypeassert // the public key of an ed25519 private key is always an ed25519 public key
+	address := iotago.Ed25519AddressFromPubKey(privateKey.Public().(ed25519.PublicKey))
+
+	ctxRequest, cancelRequest := context.WithTimeout(ctx, requestTimeout)
+	defer cancelRequest()
+
+	node, err := nodeclient.New(opts.nodeURL)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to connect to the node")
+	}
+
+	indexer, err := node.Indexer(ctxRequest)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "the node has no indexer")
+	}
+
+	blockIssuer, err := node.BlockIssuer(ctxRequest)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "the node has no block issuer")
+	}
+
+	return &harness{
+		opts:        opts,
+		httpClient:  &http.Client{Timeout: requestTimeout},
+		node:        node,
+		indexer:     indexer,
+		blockIssuer: blockIssuer,
+		hrp:         node.CommittedAPI().ProtocolParameters().Bech32HRP(),
+		faucetAddress: iotago.RestrictedAddressWithCapabilities(
+			address,
+			iotago.WithAddressCanReceiveMana(true),
+		),
+		faucetSigner: iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(address, privateKey)),
+	}, nil
+}
+
+// randomAddress returns the bech32 address of a new ed25519 address that holds no funds.
+func (h *harness) randomAddress() (iotago.Address, string) {
+	address := iotago.Ed25519AddressFromPubKey(ed25519.PublicKey(tpkg.RandBytes(ed25519.PublicKeySize)))
+
+	return address, address.Bech32(h.hrp)
+}
+
+// faucetRequest sends a request to the faucet API and decodes the response into result.
+func (h *harness) faucetRequest(ctx context.Context, method string, route string, body any, expectedStatusCode int, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return ierrors.Wrap(err, "unable to marshal request")
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.opts.faucetURL+route, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ierrors.Wrap(err, "unable to read response")
+	}
+
+	if resp.StatusCode != expectedStatusCode {
+		return ierrors.Errorf("%s %s: unexpected http status code %d, expected %d: %s", method, route, resp.StatusCode, expectedStatusCode, string(responseBytes))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(responseBytes, result)
+}
+
+// info returns the info of the faucet.
+func (h *harness) info(ctx context.Context) (*faucet.InfoResponse, error) {
+	info := &faucet.InfoResponse{}
+	if err := h.faucetRequest(ctx, http.MethodGet, "/api/info", nil, http.StatusOK, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// preview returns the amounts the faucet would pay out to the given address.
+func (h *harness) preview(ctx context.Context, bech32Address string) (*faucet.PreviewResponse, error) {
+	preview := &faucet.PreviewResponse{}
+	if err := h.faucetRequest(ctx, http.MethodGet, "/api/preview?address="+url.QueryEscape(bech32Address), nil, http.StatusOK, preview); err != nil {
+		return nil, err
+	}
+
+	if !preview.Eligible {
+		return nil, ierrors.Errorf("address %s is not eligible: %v", bech32Address, preview.Reasons)
+	}
+
+	return preview, nil
+}
+
+// enqueue enqueues a request for the given address.
+func (h *harness) enqueue(ctx context.Context, bech32Address string) error {
+	return h.faucetRequest(ctx, http.MethodPost, "/api/enqueue", &faucet.EnqueueRequest{Address: bech32Address}, http.StatusAccepted, nil)
+}
+
+// addressOutputs returns the basic outputs that are owned by the given address.
+func (h *harness) addressOutputs(ctx context.Context, address iotago.Address) (iotago.OutputIDs, []*iotago.BasicOutput, error) {
+	ctxRequest, cancelRequest := context.WithTimeout(ctx, requestTimeout)
+	defer cancelRequest()
+
+	result, err := h.indexer.Outputs(ctxRequest, &api.BasicOutputsQuery{AddressBech32: address.Bech32(h.hrp)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputIDs := make(iotago.OutputIDs, 0)
+	basicOutputs := make([]*iotago.BasicOutput, 0)
+	for result.Next() {
+		outputs, err := result.Outputs(ctxRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i, outputID := range result.Response.Items.MustOutputIDs() {
+			basicOutput, ok := outputs[i].(*iotago.BasicOutput)
+			if !ok {
+				return nil, nil, ierrors.Errorf("invalid type: expected *iotago.BasicOutput, got %T", outputs[i])
+			}
+
+			outputIDs = append(outputIDs, outputID)
+			basicOutputs = append(basicOutputs, basicOutput)
+		}
+	}
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return outputIDs, basicOutputs, nil
+}
+
+// payoutRange returns the range of base tokens the faucet may pay out for a request whose preview showed the given amount.
+// The faucet randomly lowers the amount by up to the jitter, but never below the minimum storage deposit,
+// and it never pays out more than the amount that was reserved on enqueue.
+func (h *harness) payoutRange(preview *faucet.PreviewResponse) (iotago.BaseToken, iotago.BaseToken) {
+	if h.opts.amountJitter <= 0 || (preview.AmountKind != faucet.PayoutAmountFull && preview.AmountKind != faucet.PayoutAmountSmall) {
+		return preview.BaseTokenAmount, preview.BaseTokenAmount
+	}
+
+	minAmount := preview.BaseTokenAmount - min(preview.BaseTokenAmount, iotago.BaseToken(math.Ceil(h.opts.amountJitter*float64(preview.BaseTokenAmount))))
+
+	return max(minAmount, preview.MinStorageDeposit), preview.BaseTokenAmount
+}
+
+// waitForPayout waits until the given address received a single payout within the expected range of base tokens.
+// It returns the ID of the output that was created by the faucet and the amount of base tokens that was paid out.
+func (h *harness) waitForPayout(ctx context.Context, address iotago.Address, minAmount iotago.BaseToken, maxAmount iotago.BaseToken) (iotago.OutputID, iotago.BaseToken, error) {
+	ctxTimeout, cancelTimeout := context.WithTimeout(ctx, h.opts.timeout)
+	defer cancelTimeout()
+
+	for {
+		outputIDs, outputs, err := h.addressOutputs(ctxTimeout, address)
+		if err == nil && len(outputs) > 0 {
+			var balance iotago.BaseToken
+			for _, output := range outputs {
+				balance += output.Amount
+			}
+
+			if len(outputs) > 1 || balance < minAmount || balance > maxAmount {
+				return iotago.EmptyOutputID, 0, ierrors.Errorf("address %s received %d outputs with %d base tokens, expected a single payout of %d to %d", address.Bech32(h.hrp), len(outputs), balance, minAmount, maxAmount)
+			}
+
+			return outputIDs[0], balance, nil
+		}
+
+		select {
+		case <-ctxTimeout.Done():
+			if err != nil {
+				return iotago.EmptyOutputID, 0, ierrors.Wrapf(err, "address %s received no payout", address.Bech32(h.hrp))
+			}
+
+			return iotago.EmptyOutputID, 0, ierrors.Errorf("address %s received no payout within %s", address.Bech32(h.hrp), h.opts.timeout)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// assertSinglePayout waits for the settle time and checks that the address didn't receive a second payout,
+// e.g. because a reattached transaction was paid out again.
+func (h *harness) assertSinglePayout(ctx context.Context, address iotago.Address, paidOut iotago.BaseToken) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(h.opts.settle):
+	}
+
+	_, outputs, err := h.addressOutputs(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	var balance iotago.BaseToken
+	for _, output := range outputs {
+		balance += output.Amount
+	}
+
+	if len(outputs) != 1 || balance != paidOut {
+		return ierrors.Errorf("address %s holds %d outputs with %d base tokens after %s, expected a single payout of %d", address.Bech32(h.hrp), len(outputs), balance, h.opts.settle, paidOut)
+	}
+
+	return nil
+}
+
+// waitForTransactionState waits until the transaction reached one of the given states.
+func (h *harness) waitForTransactionState(ctx context.Context, transactionID iotago.TransactionID, states ...api.TransactionState) (*api.TransactionMetadataResponse, error) {
+	ctxTimeout, cancelTimeout := context.WithTimeout(ctx, h.opts.timeout)
+	defer cancelTimeout()
+
+	var lastState api.TransactionState
+	for {
+		ctxRequest, cancelRequest := context.WithTimeout(ctxTimeout, requestTimeout)
+		metadata, err := h.node.TransactionMetadata(ctxRequest, transactionID)
+		cancelRequest()
+
+		if err == nil {
+			lastState = metadata.TransactionState
+
+			if metadata.TransactionState == api.TransactionStateFailed {
+				return metadata, ierrors.Errorf("transaction %s failed, reason: %d", transactionID, metadata.TransactionFailureReason)
+			}
+
+			for _, state := range states {
+				if metadata.TransactionState == state {
+					return metadata, nil
+				}
+			}
+		} else if !ierrors.Is(err, nodeclient.ErrHTTPNotFound) {
+			return nil, err
+		}
+
+		select {
+		case <-ctxTimeout.Done():
+			return nil, ierrors.Errorf("transaction %s did not reach the expected state within %s, last state: %s", transactionID, h.opts.timeout, lastState)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// spendFaucetOutputs issues a transaction that consumes all outputs of the faucet and sends the funds back to the faucet.
+// It conflicts with the transactions of the faucet that are not accepted yet.
+func (h *harness) spendFaucetOutputs(ctx context.Context) (iotago.TransactionID, error) {
+	outputIDs, outputs, err := h.addressOutputs(ctx, h.faucetAddress)
+	if err != nil {
+		return iotago.EmptyTransactionID, err
+	}
+	if len(outputs) == 0 {
+		return iotago.EmptyTransactionID, ierrors.New("the faucet holds no outputs")
+	}
+
+	txBuilder := builder.NewTransactionBuilder(h.node.CommittedAPI(), h.faucetSigner)
+
+	var amount iotago.BaseToken
+	for i, output := range outputs {
+		txBuilder.AddInput(&builder.TxInput{UnlockTarget: h.faucetAddress, InputID: outputIDs[i], Input: output})
+		amount += output.Amount
+	}
+
+	txBuilder.AddOutput(&iotago.BasicOutput{
+		Amount: amount,
+		UnlockConditions: iotago.BasicOutputUnlockConditions{
+			&iotago.AddressUnlockCondition{Address: h.faucetAddress},
+		},
+	})
+
+	ctxRequest, cancelRequest := context.WithTimeout(ctx, requestTimeout)
+	defer cancelRequest()
+
+	payload, _, err := h.blockIssuer.SendPayloadWithTransactionBuilder(ctxRequest, txBuilder, 0)
+	if err != nil {
+		return iotago.EmptyTransactionID, ierrors.Wrap(err, "failed to send the conflicting transaction")
+	}
+
+	signedTx, ok := payload.(*iotago.SignedTransaction)
+	if !ok {
+		return iotago.EmptyTransactionID, ierrors.Errorf("invalid type: expected *iotago.SignedTransaction, got %T", payload)
+	}
+
+	return signedTx.Transaction.ID()
+}
+
+// compose runs a docker compose command for the given service of the private tangle.
+func (h *harness) compose(ctx context.Context, command string, service string) error {
+	//nolint:gosec // the arguments are not user input
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", h.opts.composeFile, command, service)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return ierrors.Wrapf(err, "docker compose %s %s failed: %s", command, service, string(output))
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/api"
+)
+
+// request is a request of a scenario for a new address.
+type request struct {
+	address       iotago.Address
+	bech32Address string
+	// the range of base tokens the address receives.
+	minAmount iotago.BaseToken
+	maxAmount iotago.BaseToken
+}
+
+// enqueueRequests enqueues concurrent requests for the given amount of new addresses.
+func enqueueRequests(ctx context.Context, h *harness, count int) ([]*request, error) {
+	requests := make([]*request, count)
+	for i := range requests {
+		address, bech32Address := h.randomAddress()
+
+		preview, err := h.preview(ctx, bech32Address)
+		if err != nil {
+			return nil, err
+		}
+
+		minAmount, maxAmount := h.payoutRange(preview)
+		requests[i] = &request{
+			address:       address,
+			bech32Address: bech32Address,
+			minAmount:     minAmount,
+			maxAmount:     maxAmount,
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, count)
+	for i, req := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs[i] = h.enqueue(ctx, req.bech32Address)
+		}()
+	}
+	wg.Wait()
+
+	if err := ierrors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// waitForPayouts waits until all requests were paid out exactly once and the transactions are committed.
+// It returns the transactions that paid out the requests.
+func waitForPayouts(ctx context.Context, h *harness, requests []*request) (map[iotago.TransactionID]struct{}, error) {
+	transactionIDs := make(map[iotago.TransactionID]struct{})
+	paidOut := make([]iotago.BaseToken, len(requests))
+	for i, req := range requests {
+		outputID, amount, err := h.waitForPayout(ctx, req.address, req.minAmount, req.maxAmount)
+		if err != nil {
+			return nil, err
+		}
+		transactionIDs[outputID.TransactionID()] = struct{}{}
+		paidOut[i] = amount
+	}
+
+	for transactionID := range transactionIDs {
+		if _, err := h.waitForTransactionState(ctx, transactionID, api.TransactionStateCommitted, api.TransactionStateFinalized); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, req := range requests {
+		if err := h.assertSinglePayout(ctx, req.address, paidOut[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return transactionIDs, nil
+}
+
+// TestSingleRequest checks that a single request is paid out and the transaction is committed.
+func TestSingleRequest(t *testing.T) {
+	h := newTestHarness(t)
+	ctx := context.Background()
+
+	info, err := h.info(ctx)
+	require.NoError(t, err)
+	require.True(t, info.IsHealthy, "the faucet is not healthy")
+
+	requests, err := enqueueRequests(ctx, h, 1)
+	require.NoError(t, err)
+	t.Logf("enqueued request for %s", requests[0].bech32Address)
+
+	transactionIDs, err := waitForPayouts(ctx, h, requests)
+	require.NoError(t, err)
+
+	for transactionID := range transactionIDs {
+		t.Logf("paid out in transaction %s", transactionID.ToHex())
+	}
+}
+
+// TestBatch checks that concurrent requests are batched into fewer transactions and every address is paid out exactly once.
+func TestBatch(t *testing.T) {
+	h := newTestHarness(t)
+	ctx := context.Background()
+
+	requests, err := enqueueRequests(ctx, h, h.opts.batchSize)
+	require.NoError(t, err)
+	t.Logf("enqueued %d requests", len(requests))
+
+	transactionIDs, err := waitForPayouts(ctx, h, requests)
+	require.NoError(t, err)
+
+	if len(requests) > 1 {
+		require.Less(t, len(transactionIDs), len(requests), "the requests were not batched")
+	}
+	t.Logf("paid out %d requests in %d transactions", len(requests), len(transactionIDs))
+}
+
+// TestConflict checks that the requests are paid out once, even if the outputs of the faucet are spent by a conflicting transaction.
+func TestConflict(t *testing.T) {
+	h := newTestHarness(t)
+	ctx := context.Background()
+
+	requests, err := enqueueRequests(ctx, h, h.opts.batchSize)
+	require.NoError(t, err)
+	t.Logf("enqueued %d requests", len(requests))
+
+	// the faucet outputs are spent right away, so the transaction of the faucet for the requests
+	// either conflicts with this one or is built on top of outputs that are not available anymore.
+	conflictingTransactionID, err := h.spendFaucetOutputs(ctx)
+	require.NoError(t, err)
+	t.Logf("issued conflicting transaction %s", conflictingTransactionID.ToHex())
+
+	_, err = waitForPayouts(ctx, h, requests)
+	require.NoError(t, err)
+	t.Logf("paid out %d requests after the conflict", len(requests))
+
+	info, err := h.info(ctx)
+	require.NoError(t, err)
+	require.True(t, info.IsHealthy, "the faucet is not healthy after the conflict")
+}
+
+// TestOrphan pauses the validator until the block of the faucet is orphaned and checks that the transaction is reattached.
+func TestOrphan(t *testing.T) {
+	h := newTestHarness(t)
+	ctx := context.Background()
+
+	// without the validator no blocks are accepted, the blocks of the faucet are orphaned
+	// once the commitment they reference is older than the maximum committable age.
+	require.NoError(t, h.compose(ctx, "pause", validatorService))
+	t.Logf("paused %s for %s", validatorService, h.opts.orphanPause)
+
+	unpaused := false
+	defer func() {
+		if !unpaused {
+			_ = h.compose(context.Background(), "unpause", validatorService)
+		}
+	}()
+
+	requests, err := enqueueRequests(ctx, h, h.opts.batchSize)
+	require.NoError(t, err)
+	t.Logf("enqueued %d requests", len(requests))
+
+	time.Sleep(h.opts.orphanPause)
+
+	require.NoError(t, h.compose(ctx, "unpause", validatorService))
+	unpaused = true
+	t.Logf("unpaused %s", validatorService)
+
+	_, err = waitForPayouts(ctx, h, requests)
+	require.NoError(t, err)
+	t.Logf("paid out %d requests after the orphaned block", len(requests))
+}
@@ -0,0 +1,272 @@
+// faucetbench floods a running faucet with enqueue requests for new addresses at a constant rate
+// and reports the latency percentiles and the error rates of the requests.
+// It is used to size the batch timeouts and the queue capacity of the faucet before events.
+//
+// Usage:
+//
+//	go run ./tools/faucetbench -url http://localhost:8091 -rate 50 -duration 1m
+//
+// The limits of the faucet per client ("faucet.rateLimit" and "faucet.maxPendingRequestsPerIP") should be disabled,
+// otherwise most requests are rejected. Requests with a priority API key ("-apikey") are rate limited per key instead of per IP.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-faucet/pkg/faucet"
+	iotago "github.com/iotaledger/iota.go/v4"
+	"github.com/iotaledger/iota.go/v4/tpkg"
+)
+
+const (
+	headerAPIKey = "X-API-Key"
+)
+
+// result is the outcome of a single enqueue request.
+type result struct {
+	latency time.Duration
+	// the http status code of the response, 0 if the request failed.
+	statusCode int
+	// the error code of the faucet or the error of the request.
+	errorCode string
+}
+
+// bench sends the enqueue requests and collects the results.
+type bench struct {
+	faucetURL string
+	apiKey    string
+	hrp       iotago.NetworkPrefix
+	client    *http.Client
+
+	mutex   sync.Mutex
+	results []*result
+}
+
+func main() {
+	faucetURL := flag.String("url", "http://localhost:8091", "the URL of the faucet, including the base path")
+	rate := flag.Float64("rate", 10, "the amount of requests per second")
+	duration := flag.Duration("duration", 30*time.Second, "how long requests are sent")
+	concurrency := flag.Int("concurrency", 100, "the maximum amount of requests in flight, requests are skipped if the limit is reached")
+	timeout := flag.Duration("timeout", 10*time.Second, "the timeout of a single request")
+	apiKey := flag.String("apikey", "", "the priority API key that is sent with the requests")
+	flag.Parse()
+
+	if *rate <= 0 || *duration <= 0 || *concurrency <= 0 {
+		fmt.Fprintln(os.Stderr, "rate, duration and concurrency must be positive")
+		os.Exit(1)
+	}
+
+	b := &bench{
+		faucetURL: strings.TrimSuffix(*faucetURL, "/"),
+		apiKey:    *apiKey,
+		client:    &http.Client{Timeout: *timeout},
+		results:   make([]*result, 0),
+	}
+
+	info, err := b.info()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to query the faucet info: %s\n", err)
+		os.Exit(1)
+	}
+	b.hrp = info.Bech32HRP
+
+	fmt.Printf("sending %.1f requests/s for %s to %s (waiting requests: %d, queue size: %d)\n", *rate, *duration, b.faucetURL, info.WaitingRequests, info.QueueSize)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	start := time.Now()
+	skipped := b.run(ctx, time.Duration(float64(time.Second) / *rate), *concurrency)
+	elapsed := time.Since(start)
+
+	b.report(elapsed, skipped)
+
+	if info, err := b.info(); err == nil {
+		fmt.Printf("\nwaiting requests after the benchmark: %d of %d\n", info.WaitingRequests, info.QueueSize)
+	}
+}
+
+// run sends the requests in the given interval until the context is done.
+// It returns the amount of requests that were skipped because the concurrency limit was reached.
+func (b *bench) run(ctx context.Context, interval time.Duration, concurrency int) int {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, concurrency)
+
+	var skipped int
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+
+			return skipped
+
+		case <-ticker.C:
+			select {
+			case inFlight <- struct{}{}:
+			default:
+				// the faucet doesn't keep up, the request is skipped to keep the rate of the other requests
+				skipped++
+
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-inFlight
+					wg.Done()
+				}()
+
+				b.record(b.enqueue())
+			}()
+		}
+	}
+}
+
+func (b *bench) record(res *result) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.results = append(b.results, res)
+}
+
+// info returns the info of the faucet.
+func (b *bench) info() (*faucet.InfoResponse, error) {
+	//nolint:noctx // the client has a timeout
+	resp, err := b.client.Get(b.faucetURL + "/api/info")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ierrors.Errorf("http status code: %d", resp.StatusCode)
+	}
+
+	info := &faucet.InfoResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, ierrors.Errorf("unable to unmarshal response: %w", err)
+	}
+
+	return info, nil
+}
+
+// enqueue sends an enqueue request for a new address.
+func (b *bench) enqueue() *result {
+	address := iotago.Ed25519AddressFromPubKey(ed25519.PublicKey(tpkg.RandBytes(ed25519.PublicKeySize)))
+
+	jsonValue, err := json.Marshal(&faucet.EnqueueRequest{
+		Address: address.Bech32(b.hrp),
+	})
+	if err != nil {
+		return &result{errorCode: err.Error()}
+	}
+
+	//nolint:noctx // the client has a timeout
+	req, err := http.NewRequest(http.MethodPost, b.faucetURL+"/api/enqueue", bytes.NewReader(jsonValue))
+	if err != nil {
+		return &result{errorCode: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.apiKey != "" {
+		req.Header.Set(headerAPIKey, b.apiKey)
+	}
+
+	start := time.Now()
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return &result{latency: time.Since(start), errorCode: "request failed"}
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return &result{latency: latency, statusCode: resp.StatusCode, errorCode: "reading response failed"}
+	}
+
+	res := &result{latency: latency, statusCode: resp.StatusCode}
+	if resp.StatusCode != http.StatusAccepted {
+		errorResponse := &faucet.ErrorResponseEnvelope{}
+		if err := json.Unmarshal(responseBytes, errorResponse); err == nil && errorResponse.Error.Code != "" {
+			res.errorCode = string(errorResponse.Error.Code)
+		} else {
+			res.errorCode = http.StatusText(resp.StatusCode)
+		}
+	}
+
+	return res
+}
+
+// percentile returns the latency at the given percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	index := int(float64(len(sorted)-1) * p / 100)
+
+	return sorted[index]
+}
+
+// report prints the latency percentiles and the error rates.
+func (b *bench) report(elapsed time.Duration, skipped int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	latencies := make([]time.Duration, 0, len(b.results))
+	errorCodes := make(map[string]int)
+	var accepted int
+	for _, res := range b.results {
+		if res.statusCode == http.StatusAccepted {
+			accepted++
+			latencies = append(latencies, res.latency)
+
+			continue
+		}
+		errorCodes[fmt.Sprintf("%d %s", res.statusCode, res.errorCode)]++
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	total := len(b.results)
+	fmt.Printf("\nrequests:   %d sent in %s (%.1f/s), %d skipped\n", total, elapsed.Truncate(time.Millisecond), float64(total)/elapsed.Seconds(), skipped)
+	if total == 0 {
+		return
+	}
+	fmt.Printf("accepted:   %d (%.2f%%)\n", accepted, float64(accepted)*100/float64(total))
+	fmt.Printf("errors:     %d (%.2f%%)\n", total-accepted, float64(total-accepted)*100/float64(total))
+
+	codes := make([]string, 0, len(errorCodes))
+	for code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Printf("  %-40s %d\n", code, errorCodes[code])
+	}
+
+	if len(latencies) == 0 {
+		return
+	}
+	fmt.Printf("\nlatency of the accepted requests:\n")
+	for _, p := range []float64{50, 90, 95, 99} {
+		fmt.Printf("  p%-3.0f %s\n", p, percentile(latencies, p).Truncate(time.Microsecond))
+	}
+	fmt.Printf("  max  %s\n", latencies[len(latencies)-1].Truncate(time.Microsecond))
+}
@@ -0,0 +1,51 @@
+// faucetjwt issues a token for the admin API of the faucet.
+// The secret must match the "faucet.admin.jwtSecret" parameter and is read from the FAUCET_ADMIN_JWT_SECRET environment variable.
+//
+// Usage:
+//
+//	FAUCET_ADMIN_JWT_SECRET=<secret> go run ./tools/faucetjwt -subject ops -validity 720h
+//
+// The token is sent in the "Authorization: Bearer <token>" header of the admin API requests.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	envSecret = "FAUCET_ADMIN_JWT_SECRET"
+)
+
+func main() {
+	subject := flag.String("subject", "admin", "the subject of the token, it is logged for the admin API requests")
+	validity := flag.Duration("validity", 0, "the validity of the token (0 for a token that doesn't expire)")
+	flag.Parse()
+
+	secret := os.Getenv(envSecret)
+	if secret == "" {
+		fmt.Fprintf(os.Stderr, "environment variable %s is not set\n", envSecret)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	claims := &jwt.RegisteredClaims{
+		Subject:  *subject,
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+	if *validity > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(*validity))
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to issue token: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(token)
+}